@@ -0,0 +1,484 @@
+// internal/app/accounts.go
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"gofull/internal/storage"
+)
+
+// accountKeyPrefix is the storage key prefix of user accounts.
+const accountKeyPrefix = "accounts/"
+
+// Bounds of what an account keeps; the oldest read marks, starred and saved
+// items are dropped first.
+const (
+	maxProfiles = 50
+	maxRead     = 10000
+	maxStarred  = 1000
+	maxSaved    = 1000
+)
+
+// profileParams are the /feed parameters a profile may set.
+var profileParams = map[string]bool{
+	"url": true, "limit": true, "format": true, "sentiment": true, "cluster": true, "reemit_updated": true,
+	"filters": true, "include_types": true, "exclude_types": true,
+}
+
+// profileNameRegex matches valid profile names, used in URLs.
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// FeedProfile is a named /feed request a user keeps: a feed with its
+// filters and output options.
+type FeedProfile struct {
+	Name      string            `json:"name"`
+	Params    map[string]string `json:"params"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Account is the state of a user, identified by their API key: feed
+// profiles, the items they read, the items they starred and their reading
+// list.
+type Account struct {
+	Profiles map[string]FeedProfile `json:"profiles,omitempty"`
+	// Read holds when each item was read, by GUID
+	Read    map[string]time.Time `json:"read,omitempty"`
+	Starred []StarredItem        `json:"starred,omitempty"`
+	// Saved is the reading list, most recently saved first
+	Saved []SavedItem `json:"saved,omitempty"`
+}
+
+// StarredItem is an item a user starred, kept whole so the starred feed
+// outlives the archive.
+type StarredItem struct {
+	Item      Item      `json:"item"`
+	StarredAt time.Time `json:"starred_at"`
+}
+
+// SavedItem is an article saved for later reading.
+type SavedItem struct {
+	Item    Item      `json:"item"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// maxUpdateAttempts bounds how often an account update is retried when
+// concurrent updates of the same account, on this instance or another, keep
+// getting in first.
+const maxUpdateAttempts = 10
+
+// Accounts stores user accounts in the storage backend. Updates are applied
+// with the store's CompareAndSwap, so instances sharing a store do not lose
+// each other's changes.
+type Accounts struct {
+	store storage.Store
+}
+
+// NewAccounts creates Accounts kept in store.
+func NewAccounts(store storage.Store) *Accounts {
+	return &Accounts{store: store}
+}
+
+// accountKey is the storage key of the account of API key. The key is
+// hashed so it is never stored.
+func accountKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return accountKeyPrefix + hex.EncodeToString(sum[:8])
+}
+
+// Get returns the account of API key; new users get an empty one.
+func (a *Accounts) Get(key string) (*Account, error) {
+	acc, _, err := a.load(key)
+	return acc, err
+}
+
+// Update applies fn to the account of API key and stores the result unless
+// fn fails. When the account changes in between, fn is applied again to the
+// new version.
+func (a *Accounts) Update(key string, fn func(*Account) error) error {
+	for attempt := 1; ; attempt++ {
+		acc, old, err := a.load(key)
+		if err != nil {
+			return err
+		}
+		if err := fn(acc); err != nil {
+			return err
+		}
+		acc.trim()
+		data, err := json.Marshal(acc)
+		if err != nil {
+			return err
+		}
+		swapped, err := a.store.CompareAndSwap(accountKey(key), old, data)
+		if err != nil || swapped {
+			return err
+		}
+		if attempt == maxUpdateAttempts {
+			return newAPIError(http.StatusConflict, CodeConflict, "the account is being changed concurrently; try again")
+		}
+	}
+}
+
+// load returns the account of API key with its stored form, nil for new
+// users.
+func (a *Accounts) load(key string) (*Account, []byte, error) {
+	acc := &Account{}
+	data, err := a.store.Get(accountKey(key))
+	if errors.Is(err, storage.ErrNotFound) {
+		return acc, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, acc); err != nil {
+		return nil, nil, err
+	}
+	return acc, data, nil
+}
+
+// trim drops the oldest read marks, starred and saved items over the limits.
+func (acc *Account) trim() {
+	if len(acc.Read) > maxRead {
+		guids := make([]string, 0, len(acc.Read))
+		for guid := range acc.Read {
+			guids = append(guids, guid)
+		}
+		sort.Slice(guids, func(i, j int) bool { return acc.Read[guids[i]].Before(acc.Read[guids[j]]) })
+		for _, guid := range guids[:len(guids)-maxRead] {
+			delete(acc.Read, guid)
+		}
+	}
+	if len(acc.Starred) > maxStarred {
+		acc.Starred = acc.Starred[:maxStarred]
+	}
+	if len(acc.Saved) > maxSaved {
+		acc.Saved = acc.Saved[:maxSaved]
+	}
+}
+
+// starredIndex returns the position of guid in the starred items, or -1.
+func (acc *Account) starredIndex(guid string) int {
+	for i, s := range acc.Starred {
+		if s.Item.GUID == guid {
+			return i
+		}
+	}
+	return -1
+}
+
+// save puts item at the top of the reading list, replacing the copy saved
+// before.
+func (acc *Account) save(item Item, at time.Time) {
+	acc.Saved = slices.DeleteFunc(acc.Saved, func(s SavedItem) bool { return s.Item.GUID == item.GUID })
+	acc.Saved = append([]SavedItem{{Item: item, SavedAt: at}}, acc.Saved...)
+}
+
+// accountError turns an error of Accounts into an API error.
+func accountError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return newAPIError(http.StatusInternalServerError, CodeInternal, "could not access the account").with("error", err.Error())
+}
+
+// handleProfiles serves GET /profiles, the caller's feed profiles.
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	profiles := make([]FeedProfile, 0, len(acc.Profiles))
+	for _, p := range acc.Profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// handlePutProfile serves PUT /profiles/{name}: it creates or replaces a
+// feed profile from a JSON object of /feed parameters.
+func (s *Server) handlePutProfile(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("name")
+	if !profileNameRegex.MatchString(name) {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"profile names are 1-64 letters, digits, '-' or '_'").with("parameter", "name").with("value", name))
+		return
+	}
+	var params map[string]string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&params); err != nil {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidBody, "request body must be a JSON object of /feed parameters").with("error", err.Error()))
+		return
+	}
+	for param := range params {
+		if !profileParams[param] {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"profiles may only set url, limit, format, sentiment, cluster, reemit_updated, filters, include_types and exclude_types").with("parameter", param))
+			return
+		}
+	}
+	if _, apiErr := validateTargetURL("url", params["url"]); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	profile := FeedProfile{Name: name, Params: params, UpdatedAt: s.feedHandler.now().UTC()}
+	err := s.accounts.Update(key, func(acc *Account) error {
+		if _, exists := acc.Profiles[name]; !exists && len(acc.Profiles) >= maxProfiles {
+			return newAPIError(http.StatusConflict, CodeInvalidParameter, "too many profiles").with("max", maxProfiles)
+		}
+		if acc.Profiles == nil {
+			acc.Profiles = make(map[string]FeedProfile)
+		}
+		acc.Profiles[name] = profile
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// handleDeleteProfile serves DELETE /profiles/{name}.
+func (s *Server) handleDeleteProfile(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	name := r.PathValue("name")
+	err := s.accounts.Update(key, func(acc *Account) error {
+		if _, exists := acc.Profiles[name]; !exists {
+			return newAPIError(http.StatusNotFound, CodeNotFound, "no such profile").with("name", name)
+		}
+		delete(acc.Profiles, name)
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleProfileFeed serves GET /profiles/{name}/feed, the feed of a profile.
+// Parameters of the request override those of the profile.
+func (s *Server) handleProfileFeed(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	profile, ok := acc.Profiles[r.PathValue("name")]
+	if !ok {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no such profile").with("name", r.PathValue("name")))
+		return
+	}
+
+	query := r.URL.Query()
+	query.Del("key")
+	for param, value := range profile.Params {
+		if !query.Has(param) {
+			query.Set(param, value)
+		}
+	}
+	feedReq := r.Clone(r.Context())
+	feedReq.URL = &url.URL{Path: "/feed", RawQuery: query.Encode()}
+	// The key stays out of the query, and so of the cache key, but still
+	// authorizes feeds fetched with credentials
+	feedReq.Header.Set("Authorization", "Bearer "+key)
+	s.feedHandler.ServeHTTP(w, feedReq)
+}
+
+// ReadRequest is the body of POST /items/read and /items/unread.
+type ReadRequest struct {
+	GUIDs []string `json:"guids"`
+}
+
+// handleReadItems serves GET /items/read, the GUIDs of the items the caller
+// read with when they read them, and POST /items/read and /items/unread,
+// which mark items.
+func (s *Server) handleReadItems(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		acc, err := s.accounts.Get(key)
+		if err != nil {
+			writeError(w, r, accountError(err))
+			return
+		}
+		read := acc.Read
+		if read == nil {
+			read = map[string]time.Time{}
+		}
+		writeJSON(w, http.StatusOK, read)
+		return
+	}
+
+	var req ReadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil || len(req.GUIDs) == 0 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidBody, `request body must be {"guids": [...]}`))
+		return
+	}
+	markRead := r.URL.Path == "/items/read"
+	now := s.feedHandler.now().UTC()
+	err := s.accounts.Update(key, func(acc *Account) error {
+		if acc.Read == nil {
+			acc.Read = make(map[string]time.Time)
+		}
+		for _, guid := range req.GUIDs {
+			if markRead {
+				acc.Read[guid] = now
+			} else {
+				delete(acc.Read, guid)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StarRequest is the body of POST /starred.
+type StarRequest struct {
+	GUID string `json:"guid"`
+}
+
+// handleStar serves POST /starred: it stars an item of the caller's reading
+// list or an archived item of a feed the caller may have fetched.
+func (s *Server) handleStar(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	var req StarRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil || req.GUID == "" {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidBody, `request body must be {"guid": "..."}`))
+		return
+	}
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	i := slices.IndexFunc(acc.Saved, func(saved SavedItem) bool { return saved.Item.GUID == req.GUID })
+	var item Item
+	if i >= 0 {
+		item = acc.Saved[i].Item
+	} else {
+		entry, found := s.archive.Entry(req.GUID)
+		if !found {
+			writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no archived item with this GUID").with("guid", req.GUID))
+			return
+		}
+		// Starring must not hand out what the caller may not fetch
+		if apiErr := s.upstreamAuth.Authorize(r, entry.FeedURL, entry.Item.Link); apiErr != nil {
+			writeError(w, r, apiErr)
+			return
+		}
+		item = entry.Item
+	}
+	err = s.accounts.Update(key, func(acc *Account) error {
+		if acc.starredIndex(req.GUID) < 0 {
+			acc.Starred = append([]StarredItem{{Item: item, StarredAt: s.feedHandler.now().UTC()}}, acc.Starred...)
+		}
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// handleUnstar serves DELETE /starred/{guid}.
+func (s *Server) handleUnstar(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	guid := r.PathValue("guid")
+	err := s.accounts.Update(key, func(acc *Account) error {
+		i := acc.starredIndex(guid)
+		if i < 0 {
+			return newAPIError(http.StatusNotFound, CodeNotFound, "item is not starred").with("guid", guid)
+		}
+		acc.Starred = append(acc.Starred[:i], acc.Starred[i+1:]...)
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleStarred serves GET /starred, the caller's starred items as a feed,
+// most recently starred first.
+func (s *Server) handleStarred(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	format, apiErr := outputFormat(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	starred := acc.Starred
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"'limit' must be a positive integer").with("parameter", "limit").with("value", limitStr))
+			return
+		}
+		starred = starred[:min(n, len(starred))]
+	}
+
+	w.Header().Set("Content-Type", contentTypeFor(format))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	fw := newFeedWriter(format, w, nil)
+	if err := fw.Begin(feedMeta{Title: "Starred articles", Description: "Articles starred for keeping", Updated: s.feedHandler.now()}); err != nil {
+		return
+	}
+	for _, star := range starred {
+		if err := fw.WriteItem(star.Item); err != nil {
+			return
+		}
+	}
+	fw.End(feedSummary{Returned: len(starred)})
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/storage"
+)
+
+func newAccountsServer() *Server {
+	archive := NewArchive(0)
+	archive.Put("https://haber.test/rss", Item{Title: "Meclis açıldı", Link: "https://haber.test/meclis", GUID: "guid-meclis"})
+	return &Server{
+		cfg:         &Config{SaveKeys: []string{"anahtar", "diger"}},
+		archive:     archive,
+		accounts:    NewAccounts(storage.NewMemoryStore()),
+		feedHandler: &FeedHandler{Clock: newFakeClock()},
+	}
+}
+
+func accountRequest(method, target, key, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	return r
+}
+
+func TestProfiles(t *testing.T) {
+	s := newAccountsServer()
+
+	r := accountRequest(http.MethodPut, "/profiles/gundem", "anahtar", `{"url": "https://haber.test/rss", "sentiment": "positive", "filters": "strict", "include_types": "news,opinion"}`)
+	r.SetPathValue("name", "gundem")
+	rec := httptest.NewRecorder()
+	s.handlePutProfile(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{`{"sentiment": "positive"}`, `{"url": "https://haber.test/rss", "debug": "1"}`} {
+		r = accountRequest(http.MethodPut, "/profiles/bozuk", "anahtar", body)
+		r.SetPathValue("name", "bozuk")
+		rec = httptest.NewRecorder()
+		s.handlePutProfile(rec, r)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: status %d; want 400", body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleProfiles(rec, accountRequest(http.MethodGet, "/profiles", "anahtar", ""))
+	var profiles []FeedProfile
+	if err := json.Unmarshal(rec.Body.Bytes(), &profiles); err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "gundem" || profiles[0].Params["sentiment"] != "positive" || profiles[0].Params["include_types"] != "news,opinion" {
+		t.Errorf("profiles = %+v", profiles)
+	}
+
+	// Each key has its own account
+	rec = httptest.NewRecorder()
+	s.handleProfiles(rec, accountRequest(http.MethodGet, "/profiles", "diger", ""))
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("other account's profiles = %s", rec.Body)
+	}
+}
+
+func TestReadItems(t *testing.T) {
+	s := newAccountsServer()
+	for _, req := range []struct{ path, body string }{
+		{"/items/read", `{"guids": ["a", "b"]}`},
+		{"/items/unread", `{"guids": ["a"]}`},
+	} {
+		rec := httptest.NewRecorder()
+		s.handleReadItems(rec, accountRequest(http.MethodPost, req.path, "anahtar", req.body))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("POST %s: status %d: %s", req.path, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleReadItems(rec, accountRequest(http.MethodGet, "/items/read", "anahtar", ""))
+	var read map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read["b"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("read = %v", read)
+	}
+}
+
+func TestStarred(t *testing.T) {
+	s := newAccountsServer()
+
+	rec := httptest.NewRecorder()
+	s.handleStar(rec, accountRequest(http.MethodPost, "/starred", "anahtar", `{"guid": "guid-meclis"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	s.handleStar(rec, accountRequest(http.MethodPost, "/starred", "anahtar", `{"guid": "yok"}`))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("starring an unknown item: status %d; want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleStarred(rec, accountRequest(http.MethodGet, "/starred?format=json", "anahtar", ""))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Meclis açıldı") {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	r := accountRequest(http.MethodDelete, "/starred/guid-meclis", "anahtar", "")
+	r.SetPathValue("guid", "guid-meclis")
+	rec = httptest.NewRecorder()
+	s.handleUnstar(rec, r)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("unstar: status %d", rec.Code)
+	}
+	acc, err := s.accounts.Get("anahtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acc.Starred) != 0 {
+		t.Errorf("starred = %+v after unstarring", acc.Starred)
+	}
+}
+
+func TestStarRequiresAccess(t *testing.T) {
+	s := newAccountsServer()
+	s.upstreamAuth = NewUpstreamAuth(map[string]UpstreamCredential{"kapali.test": {Username: "okur", Password: "gizli", Keys: []string{"anahtar"}}}, nil)
+	s.archive.Put("https://kapali.test/rss", Item{Title: "Abonelere özel", Link: "https://kapali.test/analiz", GUID: "guid-analiz"})
+	star := func(key, guid string) int {
+		rec := httptest.NewRecorder()
+		s.handleStar(rec, accountRequest(http.MethodPost, "/starred", key, `{"guid": "`+guid+`"}`))
+		return rec.Code
+	}
+
+	// Archived items of sites fetched with credentials are starred only
+	// with a key allowed to use them
+	if code := star("diger", "guid-analiz"); code != http.StatusUnauthorized {
+		t.Errorf("starring a private item without access: status %d; want 401", code)
+	}
+	if code := star("anahtar", "guid-analiz"); code != http.StatusCreated {
+		t.Errorf("starring a private item with access: status %d", code)
+	}
+
+	// Reading list items are starred by their owner only
+	s.accounts.Update("diger", func(acc *Account) error {
+		acc.save(Item{Title: "Okunacak", Link: "https://blog.test/yazi", GUID: "guid-yazi"}, time.Now())
+		return nil
+	})
+	if code := star("diger", "guid-yazi"); code != http.StatusCreated {
+		t.Errorf("starring a saved item: status %d", code)
+	}
+	if code := star("anahtar", "guid-yazi"); code != http.StatusNotFound {
+		t.Errorf("starring another account's saved item: status %d; want 404", code)
+	}
+}
+
+// racingStore writes the account of key itself just before the first swap,
+// as another instance would.
+type racingStore struct {
+	storage.Store
+	key   string
+	raced bool
+}
+
+func (s *racingStore) CompareAndSwap(key string, old, value []byte) (bool, error) {
+	if !s.raced {
+		s.raced = true
+		other := NewAccounts(s.Store)
+		other.Update(s.key, func(acc *Account) error {
+			acc.Read = map[string]time.Time{"guid-diger": time.Now()}
+			return nil
+		})
+	}
+	return s.Store.CompareAndSwap(key, old, value)
+}
+
+func TestAccountsUpdateRetriesOnConflict(t *testing.T) {
+	accounts := NewAccounts(&racingStore{Store: storage.NewMemoryStore(), key: "anahtar"})
+	err := accounts.Update("anahtar", func(acc *Account) error {
+		if acc.Profiles == nil {
+			acc.Profiles = make(map[string]FeedProfile)
+		}
+		acc.Profiles["gundem"] = FeedProfile{Name: "gundem"}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err := accounts.Get("anahtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := acc.Profiles["gundem"]; !ok || acc.Read["guid-diger"].IsZero() {
+		t.Errorf("account %+v; want both updates", acc)
+	}
+}
+
+func TestAccountsRequireKey(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newAccountsServer().handleStarred(rec, accountRequest(http.MethodGet, "/starred", "yanlis", ""))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status %d; want 401", rec.Code)
+	}
+}
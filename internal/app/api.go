@@ -0,0 +1,40 @@
+// internal/app/api.go
+package app
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes the HTTP API. Keep it in sync with the handlers and
+// the response types below; clients in other languages are generated from it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// FeedJobAccepted is returned by /feed?async=true.
+type FeedJobAccepted struct {
+	JobID     string `json:"job_id"`
+	StatusURL string `json:"status_url"`
+	ResultURL string `json:"result_url"`
+}
+
+// WarmJobAccepted is returned by POST /admin/warm.
+type WarmJobAccepted struct {
+	JobID     string `json:"job_id"`
+	StatusURL string `json:"status_url"`
+	Feeds     int    `json:"feeds"`
+}
+
+// handleOpenAPI serves the OpenAPI specification.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(openAPISpec)
+}
+
+// handleDocs redirects the former Swagger UI page to the specification,
+// which API tools render themselves: the server serves no third-party
+// scripts.
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/openapi.json", http.StatusMovedPermanently)
+}
@@ -0,0 +1,170 @@
+// internal/app/archive.go
+package app
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Archive stores extracted items so that feed refreshes only need to run
+// extraction for items that have not been seen before.
+type Archive struct {
+	mu      sync.RWMutex
+	items   map[string]ArchivedItem // keyed by item GUID
+	feeds   map[string][]string     // feed URL -> GUIDs, oldest first
+	dropped map[string]int          // feed URL -> GUIDs dropped by the limit
+	perFeed int
+	lastID  int64
+}
+
+// ArchivedItem is an extracted item together with its bookkeeping data.
+type ArchivedItem struct {
+	// ID numbers items in the order they were first stored, for sync
+	// clients that page by ID
+	ID       int64
+	Item     Item
+	FeedURL  string
+	StoredAt time.Time
+}
+
+// NewArchive creates a new Archive keeping at most perFeed items per feed.
+// A non-positive perFeed disables the limit.
+func NewArchive(perFeed int) *Archive {
+	return &Archive{
+		items:   make(map[string]ArchivedItem),
+		feeds:   make(map[string][]string),
+		dropped: make(map[string]int),
+		perFeed: perFeed,
+	}
+}
+
+// Get returns the stored item for guid, if any.
+func (a *Archive) Get(guid string) (Item, bool) {
+	a.mu.RLock()
+	entry, ok := a.items[guid]
+	a.mu.RUnlock()
+	return entry.Item, ok
+}
+
+// Entry returns the stored item for guid with the feed it belongs to, if
+// any.
+func (a *Archive) Entry(guid string) (ArchivedItem, bool) {
+	a.mu.RLock()
+	entry, ok := a.items[guid]
+	a.mu.RUnlock()
+	return entry, ok
+}
+
+// Put stores item under its GUID and records it as belonging to feedURL.
+func (a *Archive) Put(feedURL string, item Item) {
+	if item.GUID == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := a.items[item.GUID].ID
+	if id == 0 {
+		a.lastID++
+		id = a.lastID
+		a.feeds[feedURL] = append(a.feeds[feedURL], item.GUID)
+	}
+	a.items[item.GUID] = ArchivedItem{ID: id, Item: item, FeedURL: feedURL, StoredAt: time.Now()}
+
+	// Drop the oldest items once the feed exceeds its limit
+	guids := a.feeds[feedURL]
+	if a.perFeed > 0 && len(guids) > a.perFeed {
+		for _, guid := range guids[:len(guids)-a.perFeed] {
+			if entry, ok := a.items[guid]; ok && entry.FeedURL == feedURL {
+				delete(a.items, guid)
+			}
+		}
+		a.feeds[feedURL] = append([]string(nil), guids[len(guids)-a.perFeed:]...)
+		a.dropped[feedURL] += len(guids) - a.perFeed
+	}
+}
+
+// Stored returns the number of items ever stored for feedURL and how many
+// of the oldest were dropped since by the per-feed limit.
+func (a *Archive) Stored(feedURL string) (stored, dropped int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	dropped = a.dropped[feedURL]
+	return dropped + len(a.feeds[feedURL]), dropped
+}
+
+// Span returns the items stored for feedURL in positions [from, to),
+// counted from the first one ever stored for it, most recently added first.
+// Items dropped by the per-feed limit are missing. A feed's positions never
+// change, so neither does a span once it is full.
+func (a *Archive) Span(feedURL string, from, to int) []Item {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	guids, dropped := a.feeds[feedURL], a.dropped[feedURL]
+	from, to = max(from-dropped, 0), min(to-dropped, len(guids))
+	items := make([]Item, 0, max(to-from, 0))
+	for i := to - 1; i >= from; i-- {
+		if entry, ok := a.items[guids[i]]; ok {
+			items = append(items, entry.Item)
+		}
+	}
+	return items
+}
+
+// Items returns the items stored for feedURL, most recently added first.
+func (a *Archive) Items(feedURL string) []Item {
+	entries := a.Entries(feedURL)
+	items := make([]Item, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.Item
+	}
+	return items
+}
+
+// Entries returns the items stored for feedURL with their bookkeeping data,
+// most recently added first.
+func (a *Archive) Entries(feedURL string) []ArchivedItem {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	guids := a.feeds[feedURL]
+	entries := make([]ArchivedItem, 0, len(guids))
+	for i := len(guids) - 1; i >= 0; i-- {
+		if entry, ok := a.items[guids[i]]; ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// All returns every archived item, in ID order.
+func (a *Archive) All() []ArchivedItem {
+	a.mu.RLock()
+	entries := make([]ArchivedItem, 0, len(a.items))
+	for _, entry := range a.items {
+		entries = append(entries, entry)
+	}
+	a.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// Feeds returns the URLs of all feeds with archived items.
+func (a *Archive) Feeds() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	feeds := make([]string, 0, len(a.feeds))
+	for feedURL := range a.feeds {
+		feeds = append(feeds, feedURL)
+	}
+	return feeds
+}
+
+// Size returns the number of stored items.
+func (a *Archive) Size() int {
+	a.mu.RLock()
+	sz := len(a.items)
+	a.mu.RUnlock()
+	return sz
+}
@@ -0,0 +1,74 @@
+// internal/app/augment.go
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+)
+
+// ContentBlocks are HTML templates rendered for each item and added before
+// and after its content, such as a source attribution footer or a donation
+// link. They are executed with the item's fields, e.g. {{.Link}},
+// {{.Title}}, {{.SourceName}} or {{.ArchivedURL}}, and ReaderURL, the link
+// to the item's reader view.
+type ContentBlocks struct {
+	prepend *template.Template
+	append  *template.Template
+}
+
+// contentBlockData is what content blocks are executed with.
+type contentBlockData struct {
+	Item
+	ReaderURL string
+}
+
+// NewContentBlocks parses the prepended and appended templates, either of
+// which may be empty. It returns nil when both are.
+func NewContentBlocks(prepend, appended string) (*ContentBlocks, error) {
+	if prepend == "" && appended == "" {
+		return nil, nil
+	}
+	blocks := &ContentBlocks{}
+	var err error
+	if prepend != "" {
+		if blocks.prepend, err = template.New("prepend").Parse(prepend); err != nil {
+			return nil, err
+		}
+	}
+	if appended != "" {
+		if blocks.append, err = template.New("append").Parse(appended); err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// augment adds the handler's content blocks around the content of item.
+// Items without content, such as tombstones, are left as they are.
+func (h *FeedHandler) augment(item Item) Item {
+	b := h.ContentBlocks
+	if b == nil || item.Content == "" || item.Deleted {
+		return item
+	}
+	data := contentBlockData{Item: item}
+	if item.Link != "" {
+		data.ReaderURL = h.readerURL(item.Link)
+	}
+	item.Content = b.render(b.prepend, data) + item.Content + b.render(b.append, data)
+	return item
+}
+
+// render executes tmpl, which may be nil, with data. A block failing to
+// render is logged and left out.
+func (b *ContentBlocks) render(tmpl *template.Template, data contentBlockData) string {
+	if tmpl == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("⚠️  Content block %s failed for %s: %v", tmpl.Name(), data.Link, err)
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,30 @@
+package app
+
+import "testing"
+
+func TestContentBlocks(t *testing.T) {
+	blocks, err := NewContentBlocks(`<p class="via">{{.SourceName}}</p>`, `<p><a href="{{.Link}}">Original article</a> · <a href="{{.ReaderURL}}">Reader</a></p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &FeedHandler{ContentBlocks: blocks, PublicURL: "https://gofull.example.com"}
+
+	item := h.augment(Item{Link: "https://news.example.com/a?x=1&y=<2>", SourceName: "Haber & Co", Content: "<p>body</p>"})
+	want := `<p class="via">Haber &amp; Co</p><p>body</p>` +
+		`<p><a href="https://news.example.com/a?x=1&amp;y=%3c2%3e">Original article</a> · <a href="https://gofull.example.com/read?url=https%3A%2F%2Fnews.example.com%2Fa%3Fx%3D1%26y%3D%3C2%3E">Reader</a></p>`
+	if item.Content != want {
+		t.Errorf("content = %s\nwant %s", item.Content, want)
+	}
+
+	tombstone := Item{Link: "https://news.example.com/b", Content: "", Deleted: true}
+	if got := h.augment(tombstone); got.Content != "" {
+		t.Errorf("tombstone content = %q", got.Content)
+	}
+
+	if _, err := NewContentBlocks("{{.Link", ""); err == nil {
+		t.Error("invalid template accepted")
+	}
+	if blocks, err := NewContentBlocks("", ""); blocks != nil || err != nil {
+		t.Errorf("NewContentBlocks() = %v, %v; want nil", blocks, err)
+	}
+}
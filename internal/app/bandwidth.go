@@ -0,0 +1,229 @@
+// internal/app/bandwidth.go
+package app
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bandwidthDays is how many days of usage are kept for /stats.
+const bandwidthDays = 7
+
+// Bandwidth accounts the bytes downloaded from each upstream domain per
+// day (UTC) and enforces daily quotas: once a domain has used its quota,
+// its articles are not extracted until the next day and items carry the
+// feed's own content instead.
+type Bandwidth struct {
+	quota  int64            // per domain, 0 for none
+	quotas map[string]int64 // overrides by domain
+	clock  Clock
+
+	mu     sync.Mutex
+	usage  map[string]map[string]int64 // day -> domain -> bytes
+	warned map[string]bool             // domains over quota today
+}
+
+// DomainBandwidth is the usage of one domain on one day, as reported by
+// /stats.
+type DomainBandwidth struct {
+	Day    string `json:"day"`
+	Domain string `json:"domain"`
+	Bytes  int64  `json:"bytes"`
+	// Quota is the domain's daily quota, 0 for none
+	Quota int64 `json:"quota,omitempty"`
+}
+
+// NewBandwidth creates a Bandwidth with a daily quota per domain, 0 for
+// none, and overrides by domain. clock may be nil.
+func NewBandwidth(quota int64, quotas map[string]int64, clock Clock) *Bandwidth {
+	if clock == nil {
+		clock = SystemClock
+	}
+	normalized := make(map[string]int64, len(quotas))
+	for domain, q := range quotas {
+		normalized[strings.TrimPrefix(strings.ToLower(domain), "www.")] = q
+	}
+	return &Bandwidth{quota: quota, quotas: normalized, clock: clock, usage: make(map[string]map[string]int64), warned: make(map[string]bool)}
+}
+
+// quotaFor returns the daily quota of domain, 0 for none. Quotas set for a
+// parent domain cover its subdomains.
+func (b *Bandwidth) quotaFor(domain string) int64 {
+	for d := domain; d != ""; {
+		if q, ok := b.quotas[d]; ok {
+			return q
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found {
+			break
+		}
+		d = parent
+	}
+	return b.quota
+}
+
+// today returns the current day as accounted.
+func (b *Bandwidth) today() string {
+	return b.clock.Now().UTC().Format("2006-01-02")
+}
+
+// Allow reports whether link's domain is within its quota today.
+func (b *Bandwidth) Allow(link string) bool {
+	domain := circuitDomain(link)
+	quota := b.quotaFor(domain)
+	if quota <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	day := b.today()
+	if b.usage[day][domain] < quota {
+		return true
+	}
+	if !b.warned[domain] {
+		b.warned[domain] = true
+		log.Printf("📉 %s used its daily quota of %d bytes, serving feed content until tomorrow", domain, quota)
+	}
+	return false
+}
+
+// Add accounts n bytes downloaded from link's domain.
+func (b *Bandwidth) Add(link string, n int64) {
+	if n <= 0 {
+		return
+	}
+	domain := circuitDomain(link)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	day := b.today()
+	if b.usage[day] == nil {
+		b.usage[day] = make(map[string]int64)
+		b.warned = make(map[string]bool)
+		b.prune()
+	}
+	b.usage[day][domain] += n
+}
+
+// prune drops the days past bandwidthDays.
+func (b *Bandwidth) prune() {
+	days := make([]string, 0, len(b.usage))
+	for day := range b.usage {
+		days = append(days, day)
+	}
+	slices.Sort(days)
+	for len(days) > bandwidthDays {
+		delete(b.usage, days[0])
+		days = days[1:]
+	}
+}
+
+// Usage returns the usage kept, newest day first and the heaviest domains
+// first within a day.
+func (b *Bandwidth) Usage() []DomainBandwidth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := []DomainBandwidth{}
+	for day, domains := range b.usage {
+		for domain, n := range domains {
+			usage = append(usage, DomainBandwidth{Day: day, Domain: domain, Bytes: n, Quota: b.quotaFor(domain)})
+		}
+	}
+	slices.SortFunc(usage, func(x, y DomainBandwidth) int {
+		if x.Day != y.Day {
+			return strings.Compare(y.Day, x.Day)
+		}
+		if x.Bytes != y.Bytes {
+			if x.Bytes > y.Bytes {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.Domain, y.Domain)
+	})
+	return usage
+}
+
+// Transport returns a RoundTripper accounting the response bodies read
+// through base (http.DefaultTransport when nil).
+func (b *Bandwidth) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &bandwidthTransport{base: base, bandwidth: b}
+}
+
+type bandwidthTransport struct {
+	base      http.RoundTripper
+	bandwidth *Bandwidth
+}
+
+func (t *bandwidthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	link := req.URL.String()
+	resp.Body = &meteredBody{ReadCloser: resp.Body, add: func(n int64) { t.bandwidth.Add(link, n) }}
+	return resp, nil
+}
+
+// meteredBody accounts the bytes read from a response body as they are
+// read, so long downloads count before they finish.
+type meteredBody struct {
+	io.ReadCloser
+	add func(n int64)
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.add(int64(n))
+	return n, err
+}
+
+// ParseByteSize reads a size such as "500000", "200KB", "50MB" or "1GB"
+// (powers of 1024).
+func ParseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(rest), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", strings.TrimSpace(size))
+	}
+	return n * multiplier, nil
+}
+
+// ParseBandwidthQuotas parses quotas written as ";"-separated
+// "domain=size" entries, e.g. "dunya.com=200MB;t24.com.tr=1GB".
+func ParseBandwidthQuotas(spec string) (map[string]int64, error) {
+	quotas := make(map[string]int64)
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		domain, size, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(domain) == "" {
+			return nil, fmt.Errorf("invalid quota %q (want \"domain=size\")", strings.TrimSpace(entry))
+		}
+		n, err := ParseByteSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("quota for %s: %w", strings.TrimSpace(domain), err)
+		}
+		quotas[strings.TrimSpace(domain)] = n
+	}
+	return quotas, nil
+}
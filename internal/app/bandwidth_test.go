@@ -0,0 +1,86 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBandwidthQuota(t *testing.T) {
+	clock := newFakeClock()
+	bw := NewBandwidth(1000, map[string]int64{"dunya.test": 0, "haber.test": 100}, clock)
+
+	bw.Add("https://spor.haber.test/a", 60)
+	bw.Add("https://www.haber.test/b", 50)
+	bw.Add("https://dunya.test/c", 5000)
+	bw.Add("https://other.test/d", 999)
+
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"https://www.haber.test/x", true}, // haber.test itself used 50 of 100
+		{"https://spor.haber.test/x", true},
+		{"https://dunya.test/x", true}, // no quota
+		{"https://other.test/x", true},
+	}
+	for _, tt := range tests {
+		if got := bw.Allow(tt.link); got != tt.want {
+			t.Errorf("Allow(%s) = %v; want %v", tt.link, got, tt.want)
+		}
+	}
+
+	bw.Add("https://haber.test/b", 50)
+	if bw.Allow("https://haber.test/x") {
+		t.Error("haber.test allowed over its quota")
+	}
+	// Quotas start over the next day
+	clock.Advance(24 * time.Hour)
+	if !bw.Allow("https://haber.test/x") {
+		t.Error("haber.test still over quota the next day")
+	}
+
+	usage := bw.Usage()
+	if len(usage) != 4 || usage[0].Domain != "dunya.test" || usage[0].Day != "2024-01-02" || usage[1].Quota != 1000 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestBandwidthTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 300)))
+	}))
+	defer upstream.Close()
+
+	bw := NewBandwidth(0, nil, nil)
+	client := &http.Client{Transport: bw.Transport(nil)}
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	usage := bw.Usage()
+	if len(usage) != 1 || usage[0].Bytes != 300 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestParseBandwidthQuotas(t *testing.T) {
+	quotas, err := ParseBandwidthQuotas("dunya.com=200MB; t24.com.tr = 1gb;haber.test=512")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quotas["dunya.com"] != 200<<20 || quotas["t24.com.tr"] != 1<<30 || quotas["haber.test"] != 512 {
+		t.Errorf("quotas = %v", quotas)
+	}
+	for _, bad := range []string{"dunya.com", "dunya.com=lots", "=1MB"} {
+		if _, err := ParseBandwidthQuotas(bad); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// internal/app/boilerplate.go
+package app
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DefaultBoilerplate are the promotional phrases news sites append to their
+// articles, matched case-insensitively.
+var DefaultBoilerplate = []string{
+	"whatsapp kanalımıza katıl",
+	"whatsapp kanallarımıza katıl",
+	"whatsapp kanalına katıl",
+	"whatsapp kanallarına katıl",
+	"telegram kanalımıza katıl",
+	"google news'te takip",
+	"google news üzerinden takip",
+	"google haberler'de takip",
+	"youtube kanalımıza abone",
+	"bültenimize abone",
+	"haberin devamı için tıklayın",
+	"haberin devamı uygulamada",
+	"uygulamamızı indirin",
+	"bizi sosyal medyada takip",
+}
+
+// maxBoilerplateBlock is the longest block dropped whole for containing a
+// phrase; longer blocks lose only the sentence holding it.
+const maxBoilerplateBlock = 280
+
+// boilerplateBlocks are the elements checked for boilerplate phrases.
+var boilerplateBlocks = atomSet(
+	atom.P, atom.Li, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+	atom.Blockquote, atom.Div, atom.Strong, atom.B, atom.Em, atom.Span,
+)
+
+// Boilerplate removes promotional sentences ("join our WhatsApp channel")
+// from extracted articles: a default phrase list for every site plus the
+// phrases registered for each domain.
+type Boilerplate struct {
+	mu       sync.RWMutex
+	defaults []string
+	domains  map[string][]string
+}
+
+// NewBoilerplate creates a Boilerplate removing defaults from every article.
+func NewBoilerplate(defaults []string) *Boilerplate {
+	b := &Boilerplate{domains: make(map[string][]string)}
+	for _, p := range defaults {
+		if p = normalizePhrase(p); p != "" {
+			b.defaults = append(b.defaults, p)
+		}
+	}
+	return b
+}
+
+// Register adds phrases removed from the articles of domain and its
+// subdomains, on top of the defaults.
+func (b *Boilerplate) Register(domain string, phrases ...string) {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, p := range phrases {
+		if p = normalizePhrase(p); p != "" {
+			b.domains[domain] = append(b.domains[domain], p)
+		}
+	}
+}
+
+// phrases returns the phrases removed from the articles of host.
+func (b *Boilerplate) phrases(host string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	phrases := b.defaults
+	for domain, extra := range b.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			phrases = append(phrases[:len(phrases):len(phrases)], extra...)
+		}
+	}
+	return phrases
+}
+
+// Strip removes the boilerplate of link's site from content, an article's
+// HTML. Short blocks containing a phrase are dropped whole; in longer ones
+// only the sentence holding the phrase is removed.
+func (b *Boilerplate) Strip(link, content string) string {
+	if b == nil || strings.TrimSpace(content) == "" {
+		return content
+	}
+	phrases := b.phrases(circuitDomain(link))
+	if len(phrases) == 0 {
+		return content
+	}
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	body := findBody(root)
+	if body == nil {
+		return content
+	}
+	if !stripBoilerplate(body, phrases) {
+		return content
+	}
+
+	var buf bytes.Buffer
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return content
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// stripBoilerplate removes the blocks and sentences below n holding phrases,
+// reporting whether it found any.
+func stripBoilerplate(n *html.Node, phrases []string) bool {
+	stripped := false
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.ElementNode:
+			text := normalizePhrase(nodeText(c))
+			if !containsPhrase(text, phrases) {
+				break
+			}
+			stripped = true
+			if boilerplateBlocks[c.DataAtom] && len([]rune(text)) <= maxBoilerplateBlock {
+				n.RemoveChild(c)
+				break
+			}
+			stripBoilerplate(c, phrases)
+			if !voidTags[c.DataAtom] && isBlank(c) {
+				n.RemoveChild(c)
+			}
+		case html.TextNode:
+			if data := stripSentences(c.Data, phrases); data != c.Data {
+				c.Data, stripped = data, true
+			}
+		}
+		c = next
+	}
+	return stripped
+}
+
+// stripSentences removes the sentences of text containing phrases.
+func stripSentences(text string, phrases []string) string {
+	if !containsPhrase(normalizePhrase(text), phrases) {
+		return text
+	}
+	var kept []string
+	for _, sentence := range splitSentences(text) {
+		if !containsPhrase(normalizePhrase(sentence), phrases) {
+			kept = append(kept, sentence)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+// splitSentences splits text after each sentence-ending punctuation mark,
+// keeping the punctuation and following space with the sentence.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '.' && runes[i] != '!' && runes[i] != '?' {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && unicode.IsSpace(runes[end]) {
+			end++
+		}
+		if end < len(runes) && end == i+1 {
+			// "3.5" or "vb.," is not the end of a sentence
+			continue
+		}
+		sentences = append(sentences, string(runes[start:end]))
+		start, i = end, end-1
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// normalizePhrase lower-cases s with Turkish casing rules and collapses its
+// whitespace, so phrases match however they are capitalised or wrapped.
+func normalizePhrase(s string) string {
+	s = strings.ToLowerSpecial(unicode.TurkishCase, s)
+	s = strings.NewReplacer("’", "'", " ", " ").Replace(s)
+	return strings.TrimSpace(collapseWhitespace(s))
+}
+
+func containsPhrase(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package app
+
+import "testing"
+
+func TestBoilerplateStrip(t *testing.T) {
+	b := NewBoilerplate(DefaultBoilerplate)
+	b.Register("t24.com.tr", "T24'ü takip edin")
+
+	tests := []struct {
+		name, link, content, want string
+	}{
+		{
+			name:    "short promo paragraph is dropped",
+			link:    "https://haber.test/a",
+			content: `<p>Faiz kararı açıklandı.</p><p><strong>Son dakika gelişmeler için WhatsApp Kanalımıza Katılın!</strong></p>`,
+			want:    `<p>Faiz kararı açıklandı.</p>`,
+		},
+		{
+			name:    "only the promo sentence of a long paragraph is removed",
+			link:    "https://haber.test/a",
+			content: `<p>Merkez Bankası politika faizini yüzde 50'de sabit tuttu. Haberlerimizi Google News'te takip edin. Karar metninde enflasyon beklentilerindeki bozulmanın sürdüğü, sıkı para politikasının dezenflasyon süreci belirgin şekilde görülene kadar korunacağı ve gerekirse ek adımlar atılacağı vurgulandı; piyasalar kararı yatay karşıladı.</p>`,
+			want:    `<p>Merkez Bankası politika faizini yüzde 50&#39;de sabit tuttu. Karar metninde enflasyon beklentilerindeki bozulmanın sürdüğü, sıkı para politikasının dezenflasyon süreci belirgin şekilde görülene kadar korunacağı ve gerekirse ek adımlar atılacağı vurgulandı; piyasalar kararı yatay karşıladı.</p>`,
+		},
+		{
+			name:    "domain phrases apply to their site",
+			link:    "https://t24.com.tr/haber/x",
+			content: `<p>Metin.</p><p>T24'ü TAKİP EDİN</p>`,
+			want:    `<p>Metin.</p>`,
+		},
+		{
+			name:    "domain phrases do not apply elsewhere",
+			link:    "https://haber.test/a",
+			content: `<p>Metin.</p><p>T24'ü takip edin</p>`,
+			want:    `<p>Metin.</p><p>T24'ü takip edin</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Strip(tt.link, tt.content); got != tt.want {
+				t.Errorf("Strip =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// internal/app/breaker.go
+package app
+
+import (
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit states.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker stops extraction from a site after repeated failures, so a
+// site that is down costs one timeout per cooldown instead of one per item.
+// Once the cooldown has passed a single probe request is let through: success
+// closes the circuit again, failure reopens it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	state     string
+	openedAt  time.Time
+	lastError string
+	probing   bool
+}
+
+// CircuitState describes the circuit of one domain, as reported by /stats.
+type CircuitState struct {
+	Domain    string    `json:"domain"`
+	State     string    `json:"state"`
+	Failures  int       `json:"consecutive_failures"`
+	LastError string    `json:"last_error,omitempty"`
+	OpenedAt  time.Time `json:"opened_at,omitzero"`
+	RetryAt   time.Time `json:"retry_at,omitzero"`
+}
+
+// NewCircuitBreaker creates a breaker opening after threshold consecutive
+// failures for cooldown. A nil clock means SystemClock.
+func NewCircuitBreaker(threshold int, cooldown time.Duration, clock Clock) *CircuitBreaker {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, clock: clock, circuits: make(map[string]*circuit)}
+}
+
+// Allow reports whether a request to link's domain may be made.
+func (b *CircuitBreaker) Allow(link string) bool {
+	domain := circuitDomain(link)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[domain]
+	if !ok {
+		return true
+	}
+	switch c.state {
+	case circuitOpen:
+		if b.clock.Now().Sub(c.openedAt) < b.cooldown {
+			return false
+		}
+		c.state = circuitHalfOpen
+		c.probing = true
+		log.Printf("🔌 Circuit for %s is half-open, probing", domain)
+		return true
+	case circuitHalfOpen:
+		// Only one probe at a time
+		if c.probing {
+			return false
+		}
+		c.probing = true
+	}
+	return true
+}
+
+// Record records the outcome of a request to link's domain; err is nil on
+// success.
+func (b *CircuitBreaker) Record(link string, err error) {
+	domain := circuitDomain(link)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[domain]
+	if err == nil {
+		if ok && c.state != circuitClosed {
+			log.Printf("🔌 Circuit for %s closed", domain)
+		}
+		delete(b.circuits, domain)
+		return
+	}
+	if !ok {
+		c = &circuit{state: circuitClosed}
+		b.circuits[domain] = c
+	}
+	c.failures++
+	c.lastError = err.Error()
+	c.probing = false
+	if c.state == circuitHalfOpen || c.failures >= b.threshold {
+		if c.state != circuitOpen {
+			log.Printf("🔌 Circuit for %s opened after %d consecutive failures: %v", domain, c.failures, err)
+		}
+		c.state = circuitOpen
+		c.openedAt = b.clock.Now()
+	}
+}
+
+// States returns the circuits of the domains that are failing, by domain.
+func (b *CircuitBreaker) States() []CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	states := make([]CircuitState, 0, len(b.circuits))
+	for domain, c := range b.circuits {
+		state := CircuitState{Domain: domain, State: c.state, Failures: c.failures, LastError: c.lastError}
+		if c.state != circuitClosed {
+			state.OpenedAt = c.openedAt
+			state.RetryAt = c.openedAt.Add(b.cooldown)
+		}
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b CircuitState) int { return strings.Compare(a.Domain, b.Domain) })
+	return states
+}
+
+// circuitDomain returns the domain link's circuit is kept under.
+func circuitDomain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newFakeClock()
+	b := NewCircuitBreaker(3, time.Minute, clock)
+	failure := errors.New("timeout")
+
+	for range 2 {
+		b.Record("https://www.site.test/a", failure)
+	}
+	if !b.Allow("https://site.test/b") {
+		t.Fatal("circuit opened before the threshold")
+	}
+	b.Record("https://site.test/c", failure)
+	if b.Allow("https://site.test/d") {
+		t.Fatal("circuit did not open after 3 failures")
+	}
+	if !b.Allow("https://other.test/") {
+		t.Error("other domains are affected")
+	}
+	states := b.States()
+	if len(states) != 1 || states[0].Domain != "site.test" || states[0].State != circuitOpen || !states[0].RetryAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("states %+v", states)
+	}
+
+	// After the cooldown one probe goes through; its failure reopens the circuit
+	clock.Advance(time.Minute)
+	if !b.Allow("https://site.test/e") || b.Allow("https://site.test/f") {
+		t.Fatal("half-open circuit should let exactly one probe through")
+	}
+	b.Record("https://site.test/e", failure)
+	if b.Allow("https://site.test/g") {
+		t.Fatal("failed probe did not reopen the circuit")
+	}
+
+	// A successful probe closes it
+	clock.Advance(time.Minute)
+	if !b.Allow("https://site.test/h") {
+		t.Fatal("no probe after the second cooldown")
+	}
+	b.Record("https://site.test/h", nil)
+	if !b.Allow("https://site.test/i") || len(b.States()) != 0 {
+		t.Errorf("circuit not closed after a successful probe: %+v", b.States())
+	}
+}
+
+// failingExtractor counts its calls and always fails.
+type failingExtractor struct{ calls int }
+
+func (e *failingExtractor) Extract(any) (string, []string, error) {
+	e.calls++
+	return "", nil, errors.New("connection refused")
+}
+
+func TestFeedHandlerCircuitBreakerSkipsExtraction(t *testing.T) {
+	h := newSiteHarness(t).handler
+	down := &failingExtractor{}
+	h.Registry.RegisterDomain("down.test", down)
+	h.Breaker = NewCircuitBreaker(2, time.Minute, nil)
+	feedItem := &gofeed.Item{Title: "Haber", Link: "https://down.test/haber", Content: "<p>Akıştaki özet.</p>"}
+
+	var item Item
+	for range 4 {
+		item = h.processItem(feedItem)
+	}
+	if down.calls != 2 {
+		t.Errorf("extractor called %d times, want 2", down.calls)
+	}
+	if !item.partial || item.Content != "<p>Akıştaki özet.</p>" {
+		t.Errorf("item while the circuit is open: partial %v, content %q", item.partial, item.Content)
+	}
+}
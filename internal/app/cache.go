@@ -2,8 +2,12 @@
 package app
 
 import (
+	"encoding/json"
+	"log"
 	"sync"
 	"time"
+
+	"gofull/internal/storage"
 )
 
 // Cache is a simple in-memory TTL cache for article contents.
@@ -11,45 +15,156 @@ type Cache struct {
 	mu    sync.RWMutex
 	items map[string]CachedEntry
 	ttl   time.Duration // Field is ttl
+	stale time.Duration // How long expired entries may still be served
+	// shared, when set, mirrors entries to a store used by other instances
+	shared storage.SharedStore
+	clock  Clock
 }
 
 // CachedEntry stores value and timestamp.
 type CachedEntry struct {
 	Value     string
 	Timestamp time.Time
+	// Encoded holds compressed variants of Value keyed by content encoding
+	Encoded map[string]string
 }
 
-// NewCache creates a new Cache.
-func NewCache(ttl time.Duration) *Cache {
+// NewCache creates a new Cache. Expired entries are kept for an additional
+// stale period so they can be served while being revalidated.
+func NewCache(ttl, stale time.Duration) *Cache {
 	return &Cache{
 		items: make(map[string]CachedEntry),
 		ttl:    ttl, // Fixed: was tl
+		stale:  stale,
+		clock:  SystemClock,
 	}
 }
 
-// Get returns value and true if present and fresh.
-func (c *Cache) Get(key string) (string, bool) {
+// SetClock replaces the clock used to timestamp and expire entries.
+func (c *Cache) SetClock(clock Clock) {
+	c.mu.Lock()
+	c.clock = clock
+	c.mu.Unlock()
+}
+
+// SetShared makes the cache read through to and write to store, so responses
+// rendered by one instance are served by all of them.
+func (c *Cache) SetShared(store storage.SharedStore) {
+	c.mu.Lock()
+	c.shared = store
+	c.mu.Unlock()
+}
+
+// sharedEntry is the form in which entries are kept in the shared store.
+type sharedEntry struct {
+	Value     string    `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// lookup returns the entry for key. Missing or expired entries are looked up
+// in the shared store, where another instance may hold a newer copy.
+func (c *Cache) lookup(key string) (CachedEntry, bool) {
 	c.mu.RLock()
 	entry, ok := c.items[key]
+	shared := c.shared
 	c.mu.RUnlock()
+	if shared == nil || (ok && c.clock.Now().Sub(entry.Timestamp) <= c.ttl) {
+		return entry, ok
+	}
+
+	data, err := shared.Get("cache/" + key)
+	if err != nil {
+		return entry, ok
+	}
+	var se sharedEntry
+	if err := json.Unmarshal(data, &se); err != nil || (ok && !se.Timestamp.After(entry.Timestamp)) {
+		return entry, ok
+	}
+	entry = CachedEntry{Value: se.Value, Timestamp: se.Timestamp}
+	c.mu.Lock()
+	c.items[key] = entry
+	c.mu.Unlock()
+	return entry, true
+}
+
+// Get returns value and true if present and fresh.
+func (c *Cache) Get(key string) (string, bool) {
+	entry, ok := c.lookup(key)
 	if !ok {
 		return "", false
 	}
-	if time.Since(entry.Timestamp) > c.ttl {
+	if c.clock.Now().Sub(entry.Timestamp) > c.ttl {
 		// stale
+		if c.stale <= 0 {
+			c.mu.Lock()
+			delete(c.items, key)
+			c.mu.Unlock()
+		}
+		return "", false
+	}
+	return entry.Value, true
+}
+
+// GetStale returns the value for key even if it has expired, as long as it is
+// still within the stale window. fresh reports whether the entry is within its TTL.
+func (c *Cache) GetStale(key string) (value string, age time.Duration, fresh bool, ok bool) {
+	entry, ok := c.lookup(key)
+	if !ok {
+		return "", 0, false, false
+	}
+	age = c.clock.Now().Sub(entry.Timestamp)
+	if age > c.ttl+c.stale {
 		c.mu.Lock()
 		delete(c.items, key)
 		c.mu.Unlock()
-		return "", false
+		return "", 0, false, false
 	}
-	return entry.Value, true
+	return entry.Value, age, age <= c.ttl, true
 }
 
 // Set inserts or updates key.
 func (c *Cache) Set(key string, value string) {
+	now := c.clock.Now()
 	c.mu.Lock()
-	c.items[key] = CachedEntry{Value: value, Timestamp: time.Now()}
+	c.items[key] = CachedEntry{Value: value, Timestamp: now}
+	shared := c.shared
 	c.mu.Unlock()
+
+	if shared != nil {
+		data, _ := json.Marshal(sharedEntry{Value: value, Timestamp: now})
+		if err := shared.PutTTL("cache/"+key, data, c.ttl+c.stale); err != nil {
+			log.Printf("⚠️  Failed to share cache entry %s: %v", key, err)
+		}
+	}
+}
+
+// GetEncoded returns a compressed variant of the value stored under key.
+func (c *Cache) GetEncoded(key, encoding string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	value, ok := entry.Encoded[encoding]
+	return value, ok
+}
+
+// SetEncoded stores a compressed variant alongside the entry for key. The
+// variant is dropped if the entry no longer holds source, so a refreshed
+// value is never paired with an outdated compressed body.
+func (c *Cache) SetEncoded(key, encoding, value, source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.items[key]
+	if !ok || entry.Value != source {
+		return
+	}
+	if entry.Encoded == nil {
+		entry.Encoded = make(map[string]string)
+	}
+	entry.Encoded[encoding] = value
+	c.items[key] = entry
 }
 
 // Size returns current number of items.
@@ -64,9 +179,9 @@ func (c *Cache) Size() int {
 func (c *Cache) Cleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	now := time.Now()
+	now := c.clock.Now()
 	for k, e := range c.items {
-		if now.Sub(e.Timestamp) > c.ttl {
+		if now.Sub(e.Timestamp) > c.ttl+c.stale {
 			delete(c.items, k)
 		}
 	}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestCacheExpiresAfterTTL(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(time.Minute, 0)
+	cache.SetClock(clock)
+
+	cache.Set("k", "v")
+	if v, ok := cache.Get("k"); !ok || v != "v" {
+		t.Fatalf("Get() = %q, %v; want fresh value", v, ok)
+	}
+
+	clock.Advance(time.Minute + time.Second)
+	if _, ok := cache.Get("k"); ok {
+		t.Fatal("Get() returned an expired entry")
+	}
+	if cache.Size() != 0 {
+		t.Errorf("expired entry was not removed without a stale window, size = %d", cache.Size())
+	}
+}
+
+func TestCacheServesStaleWithinWindow(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(time.Minute, 10*time.Minute)
+	cache.SetClock(clock)
+	cache.Set("k", "v")
+
+	clock.Advance(30 * time.Second)
+	if _, age, fresh, ok := cache.GetStale("k"); !ok || !fresh || age != 30*time.Second {
+		t.Fatalf("GetStale() = age %v, fresh %v, ok %v; want fresh entry aged 30s", age, fresh, ok)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if _, ok := cache.Get("k"); ok {
+		t.Error("Get() returned an entry past its TTL")
+	}
+	if v, _, fresh, ok := cache.GetStale("k"); !ok || fresh || v != "v" {
+		t.Fatalf("GetStale() = %q, fresh %v, ok %v; want stale value", v, fresh, ok)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if _, _, _, ok := cache.GetStale("k"); ok {
+		t.Error("GetStale() returned an entry past the stale window")
+	}
+}
+
+func TestCacheCleanup(t *testing.T) {
+	clock := newFakeClock()
+	cache := NewCache(time.Minute, time.Minute)
+	cache.SetClock(clock)
+
+	cache.Set("old", "1")
+	clock.Advance(90 * time.Second)
+	cache.Set("new", "2")
+	clock.Advance(45 * time.Second)
+	cache.Cleanup()
+
+	if _, _, _, ok := cache.GetStale("old"); ok {
+		t.Error("Cleanup() kept an entry past TTL and stale window")
+	}
+	if _, ok := cache.Get("new"); !ok {
+		t.Error("Cleanup() removed a fresh entry")
+	}
+}
+
+func TestCacheEncodedVariants(t *testing.T) {
+	cache := NewCache(time.Minute, 0)
+	cache.Set("k", "body")
+
+	cache.SetEncoded("k", encodingGzip, "gz", "body")
+	if v, ok := cache.GetEncoded("k", encodingGzip); !ok || v != "gz" {
+		t.Fatalf("GetEncoded() = %q, %v; want stored variant", v, ok)
+	}
+
+	// A refreshed entry drops old variants, and late writes for the old body are ignored
+	cache.Set("k", "new body")
+	cache.SetEncoded("k", encodingGzip, "gz", "body")
+	if _, ok := cache.GetEncoded("k", encodingGzip); ok {
+		t.Error("GetEncoded() returned a variant of an outdated body")
+	}
+}
@@ -0,0 +1,277 @@
+// internal/app/clean.go
+package app
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// unwantedTags are removed together with their content.
+var unwantedTags = atomSet(
+	// Basic elements
+	atom.Script, atom.Style, atom.Iframe, atom.Noscript, atom.Object, atom.Embed, atom.Video, atom.Audio,
+	atom.Form, atom.Input, atom.Button, atom.Select, atom.Textarea, atom.Label, atom.Fieldset,
+	atom.Header, atom.Footer, atom.Nav, atom.Aside, atom.Menu, atom.Dialog, atom.Figure, atom.Figcaption,
+)
+
+// unwantedClasses are class names marking ads, tracking and page chrome.
+var unwantedClasses = stringSet(
+	// Ads and tracking
+	"ad", "advertisement", "ad-container", "ad-wrapper", "ad-banner",
+	"ad-header", "ad-sidebar", "ad-slot", "ad-unit", "advert",
+	"social-share", "social-likes", "sharing", "share-buttons",
+	"related-news", "related-posts", "related-articles", "recommended",
+	"popular-posts", "trending", "newsletter", "subscribe",
+	"tags", "tag-cloud", "post-tags", "post-meta", "post-footer",
+	"author", "byline", "post-date", "timestamp", "comments",
+)
+
+// containerTags are checked against unwantedPatterns and unwantedRoles.
+var containerTags = atomSet(atom.Div, atom.Section, atom.Article, atom.Main)
+
+// unwantedPatterns mark non-article blocks by the words of container
+// classes or IDs, split on anything but letters and digits: "ad" matches
+// "ad-slot" or "top_ad" but not "readability-page-1" or "header". Words
+// longer than two letters also match as prefixes, so "comment" matches
+// "comments" and "sponsor" matches "sponsored".
+var unwantedPatterns = []string{
+	"ad", "ads", "advert", "banner", "sponsor", "recommend", "related", "popular",
+	"widget", "sidebar", "sticky", "modal", "popup", "newsletter",
+	"subscribe", "social", "share", "comment", "cookie", "consent",
+	"notification", "alert", "promo", "teaser", "recommendation",
+	"trending", "most-viewed", "most-read", "signup",
+}
+
+// unwantedPatternWords are unwantedPatterns split into words.
+var unwantedPatternWords = splitPatterns(unwantedPatterns)
+
+// unwantedRoles are ARIA landmark roles that never hold article text.
+var unwantedRoles = stringSet("banner", "complementary", "contentinfo")
+
+// voidTags never have children and are kept even though they are empty.
+var voidTags = atomSet(
+	atom.Area, atom.Base, atom.Br, atom.Col, atom.Embed, atom.Hr, atom.Img, atom.Input,
+	atom.Link, atom.Meta, atom.Source, atom.Track, atom.Wbr,
+)
+
+var renderBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func atomSet(atoms ...atom.Atom) map[atom.Atom]bool {
+	set := make(map[atom.Atom]bool, len(atoms))
+	for _, a := range atoms {
+		set[a] = true
+	}
+	return set
+}
+
+func stringSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// cleanHTMLContent cleans and normalizes HTML content by removing unwanted elements
+// and ensuring proper UTF-8 encoding. The document is walked once: unwanted
+// subtrees are dropped, text whitespace is collapsed and elements left empty
+// are pruned on the way back up.
+func cleanHTMLContent(htmlContent string) string {
+	// Return early if content is empty or whitespace only
+	if strings.TrimSpace(htmlContent) == "" {
+		return ""
+	}
+
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return htmlContent
+	}
+
+	body := findBody(doc)
+	if body == nil {
+		return ""
+	}
+	cleanChildren(body)
+
+	buf := renderBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer renderBufferPool.Put(buf)
+
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(buf, c); err != nil {
+			return htmlContent
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// findBody returns the <body> element of a parsed document.
+func findBody(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if body := findBody(c); body != nil {
+			return body
+		}
+	}
+	return nil
+}
+
+// cleanChildren cleans the subtree below n in place.
+func cleanChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.ElementNode:
+			if isUnwantedElement(c) {
+				n.RemoveChild(c)
+				break
+			}
+			cleanChildren(c)
+			if !voidTags[c.DataAtom] && isBlank(c) {
+				n.RemoveChild(c)
+			}
+		case html.TextNode:
+			c.Data = collapseWhitespace(c.Data)
+		case html.CommentNode:
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
+
+// isUnwantedElement reports whether an element and its subtree should be dropped.
+func isUnwantedElement(n *html.Node) bool {
+	if unwantedTags[n.DataAtom] {
+		return true
+	}
+
+	var class, id, role string
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "class":
+			class = attr.Val
+		case "id":
+			id = attr.Val
+		case "role":
+			role = attr.Val
+		}
+	}
+
+	for _, name := range strings.Fields(class) {
+		if unwantedClasses[name] {
+			return true
+		}
+	}
+
+	if !containerTags[n.DataAtom] {
+		return false
+	}
+	if unwantedRoles[role] {
+		return true
+	}
+	classWords := attrWords(class)
+	idWords := attrWords(id)
+	for _, pattern := range unwantedPatternWords {
+		if containsWords(classWords, pattern) || containsWords(idWords, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// attrWords splits a lowercased class or ID value into its words.
+func attrWords(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func splitPatterns(patterns []string) [][]string {
+	split := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		split[i] = attrWords(pattern)
+	}
+	return split
+}
+
+// containsWords reports whether pattern occurs as a run of consecutive
+// words, the last of which may be a prefix of longer words when it has
+// more than two letters.
+func containsWords(words, pattern []string) bool {
+	for i := 0; i+len(pattern) <= len(words); i++ {
+		if matchesWords(words[i:i+len(pattern)], pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWords(words, pattern []string) bool {
+	last := len(pattern) - 1
+	for j, want := range pattern {
+		if words[j] == want {
+			continue
+		}
+		if j != last || len(want) <= 2 || !strings.HasPrefix(words[j], want) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBlank reports whether an element has no children other than whitespace text.
+func isBlank(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.TextNode || strings.TrimSpace(c.Data) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// collapseWhitespace replaces each run of whitespace with a single space,
+// returning s unchanged (without allocating) when there is nothing to collapse.
+func collapseWhitespace(s string) string {
+	needsWork := false
+	prevSpace := false
+	for i := 0; i < len(s); i++ {
+		isSpace := s[i] == ' ' || s[i] == '\n' || s[i] == '\t' || s[i] == '\r' || s[i] == '\f'
+		if isSpace && (prevSpace || s[i] != ' ') {
+			needsWork = true
+			break
+		}
+		prevSpace = isSpace
+	}
+	if !needsWork {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	prevSpace = false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', '\n', '\t', '\r', '\f':
+			if !prevSpace {
+				b.WriteByte(' ')
+			}
+			prevSpace = true
+		default:
+			b.WriteByte(s[i])
+			prevSpace = false
+		}
+	}
+	return b.String()
+}
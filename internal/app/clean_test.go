@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkArticle builds a large article resembling a real news page, with
+// ads, share widgets and related-news blocks between the paragraphs.
+func benchmarkArticle(paragraphs int) string {
+	var b strings.Builder
+	b.WriteString(`<html><body><article class="news-detail"><header class="post-meta"><span class="author">Muhabir</span></header>`)
+	for i := 0; i < paragraphs; i++ {
+		fmt.Fprintf(&b, "<p>Paragraf %d: Borsa   İstanbul'da   gün &amp; içi işlemlerde <a href=\"/haber/%d\">BIST 100</a> endeksi yükselişle açıldı.\n\t Piyasalar <strong>faiz</strong> kararını bekliyor.</p>", i, i)
+		if i%5 == 0 {
+			fmt.Fprintf(&b, `<div class="ad-wrapper"><script>googletag.display("ad-%d")</script></div>`, i)
+			b.WriteString(`<div class="social-share"><a href="#">Paylaş</a></div><p>  </p><span></span>`)
+		}
+		if i%10 == 0 {
+			fmt.Fprintf(&b, `<figure><img src="https://example.com/img/%d.jpg"><figcaption>Foto</figcaption></figure>`, i)
+			b.WriteString(`<div class="related-news"><ul><li><a href="/x">İlgili haber</a></li></ul></div>`)
+		}
+	}
+	b.WriteString(`<aside class="sidebar">Popüler</aside><footer>© Site</footer></article></body></html>`)
+	return b.String()
+}
+
+func TestCleanHTMLContent(t *testing.T) {
+	input := `<div><p>Hello   <b>world</b></p><script>alert(1)</script><div class="ad-slot">Buy</div>` +
+		`<p> </p><div role="complementary">Side</div><p>a &amp; b &lt;tag&gt;</p><figure><img src="x.jpg"></figure><img src="y.jpg"></div>`
+
+	got := cleanHTMLContent(input)
+
+	for _, unwanted := range []string{"<script", "alert", "ad-slot", "Buy", "Side", "<figure", "x.jpg", "<p> </p>", "<body"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("cleaned content still contains %q: %s", unwanted, got)
+		}
+	}
+	for _, wanted := range []string{"<p>Hello <b>world</b></p>", "a &amp; b &lt;tag&gt;", `<img src="y.jpg"/>`} {
+		if !strings.Contains(got, wanted) {
+			t.Errorf("cleaned content missing %q: %s", wanted, got)
+		}
+	}
+
+	if got := cleanHTMLContent("   \n\t"); got != "" {
+		t.Errorf("expected empty result for blank input, got %q", got)
+	}
+}
+
+func TestCleanHTMLContentMatchesWholeWords(t *testing.T) {
+	// go-readability wraps its output in <div id="readability-page-1">
+	input := `<div id="readability-page-1" class="page"><div class="shadow-box"><p>Article text</p></div>` +
+		`<div class="top_ad">Buy</div><section id="user-comments">Said</section><div class="is-sponsored">Paid</div>` +
+		`<div class="most-viewed-list">Read</div></div>`
+
+	got := cleanHTMLContent(input)
+
+	for _, unwanted := range []string{"Buy", "Said", "Paid", "Read"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("cleaned content still contains %q: %s", unwanted, got)
+		}
+	}
+	if !strings.Contains(got, "<p>Article text</p>") {
+		t.Errorf("cleaned content lost the article: %s", got)
+	}
+}
+
+func BenchmarkCleanHTMLContent(b *testing.B) {
+	for _, size := range []int{10, 100, 500} {
+		article := benchmarkArticle(size)
+		b.Run(fmt.Sprintf("paragraphs=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(article)))
+			for i := 0; i < b.N; i++ {
+				cleanHTMLContent(article)
+			}
+		})
+	}
+}
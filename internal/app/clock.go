@@ -0,0 +1,17 @@
+// internal/app/clock.go
+package app
+
+import "time"
+
+// Clock tells the current time. It is injected into the cache and handlers
+// so tests can control expiry.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = systemClock{}
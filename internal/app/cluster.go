@@ -0,0 +1,125 @@
+// internal/app/cluster.go
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+	"gofull/internal/storage"
+)
+
+const (
+	// extractLeaseTTL bounds how long one instance may hold an extraction lock
+	extractLeaseTTL = 2 * time.Minute
+	// extractWait is how long an instance waits for another one's extraction
+	// before doing the work itself
+	extractWait = 30 * time.Second
+	// sharedItemTTL is how long extracted items are kept in the shared store.
+	// It only spares other instances the same extraction; items are reused
+	// for longer from the archive, which notices updated articles.
+	sharedItemTTL = time.Hour
+)
+
+// Cluster coordinates several server instances sharing a storage backend:
+// each article is extracted by a single instance and background work is
+// guarded by leases so it does not run on every replica.
+type Cluster struct {
+	store    storage.SharedStore
+	instance string
+}
+
+// NewCluster creates a Cluster coordinating through store.
+func NewCluster(store storage.SharedStore) *Cluster {
+	return &Cluster{store: store, instance: extractors.GenerateUniqueID()}
+}
+
+// Lease tries to take the named lease for ttl. On success it returns a
+// function releasing the lease early.
+func (c *Cluster) Lease(name string, ttl time.Duration) (release func(), ok bool) {
+	owner := c.instance + ":" + extractors.GenerateUniqueID()
+	ok, err := c.store.Acquire("locks/"+name, owner, ttl)
+	if err != nil {
+		// Without the backend we cannot coordinate; prefer doing the work twice
+		log.Printf("⚠️  Could not acquire lease %s: %v", name, err)
+		return func() {}, true
+	}
+	if !ok {
+		return nil, false
+	}
+	return func() {
+		if err := c.store.Release("locks/"+name, owner); err != nil {
+			log.Printf("⚠️  Could not release lease %s: %v", name, err)
+		}
+	}, true
+}
+
+// Extract returns the extracted item for guid. extract only runs if no
+// instance has produced the item yet; while another instance is working on
+// it, Extract waits for that result instead.
+func (c *Cluster) Extract(guid string, extract func() Item) Item {
+	if item, ok := c.sharedItem(guid); ok {
+		return item
+	}
+
+	deadline := time.Now().Add(extractWait)
+	for {
+		if release, ok := c.Lease("extract/"+guid, extractLeaseTTL); ok {
+			defer release()
+			item := extract()
+			if item.Content != "" && !item.partial {
+				c.putSharedItem(guid, item)
+			}
+			return item
+		}
+
+		if time.Now().After(deadline) {
+			log.Printf("⏳ Gave up waiting for shared extraction of %s", guid)
+			return extract()
+		}
+		time.Sleep(500 * time.Millisecond)
+		if item, ok := c.sharedItem(guid); ok {
+			return item
+		}
+	}
+}
+
+// sharedItemKey returns the key feedItem's extraction is shared under.
+// Each revision of an article the feed tells of, by a new update date,
+// title or summary, is extracted anew.
+func sharedItemKey(feedItem *gofeed.Item) string {
+	key := extractors.GenerateGUIDFromURL(feedItem.Link)
+	revision := formatTime(feedItem.UpdatedParsed) + "\n" + feedItem.Title + "\n" + feedItem.Description
+	if revision == "\n\n" {
+		return key
+	}
+	sum := sha256.Sum256([]byte(revision))
+	return key + "@" + hex.EncodeToString(sum[:8])
+}
+
+func (c *Cluster) sharedItem(guid string) (Item, bool) {
+	data, err := c.store.Get("items/" + guid)
+	if err != nil {
+		return Item{}, false
+	}
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return Item{}, false
+	}
+	return item, true
+}
+
+func (c *Cluster) putSharedItem(guid string, item Item) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+	if err := c.store.PutTTL("items/"+guid, data, sharedItemTTL); err != nil {
+		log.Printf("⚠️  Failed to share item %s: %v", guid, err)
+	}
+}
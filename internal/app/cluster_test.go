@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestSharedItemKey(t *testing.T) {
+	updated := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	item := &gofeed.Item{Link: "https://news.example.com/a/1", Title: "Deprem", UpdatedParsed: &updated}
+	key := sharedItemKey(item)
+
+	later := updated.Add(time.Hour)
+	revisions := []*gofeed.Item{
+		{Link: item.Link, Title: item.Title, UpdatedParsed: &later},
+		{Link: item.Link, Title: "Deprem: büyüklük 5,1", UpdatedParsed: &updated},
+		{Link: item.Link, Title: item.Title, Description: "Güncellendi", UpdatedParsed: &updated},
+	}
+	for _, revision := range revisions {
+		if sharedItemKey(revision) == key {
+			t.Errorf("revision %+v shares the key of the original", revision)
+		}
+	}
+	if got := sharedItemKey(&gofeed.Item{Link: item.Link, Title: item.Title, UpdatedParsed: &updated}); got != key {
+		t.Errorf("same revision keyed %s; want %s", got, key)
+	}
+}
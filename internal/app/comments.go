@@ -0,0 +1,134 @@
+// internal/app/comments.go
+package app
+
+import (
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/atom"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// Custom keys holding values the universal feed model drops.
+const (
+	// commentsKey holds an item's comments URL
+	commentsKey = "comments"
+	// ttlKey holds the RSS <ttl> of a feed, in minutes
+	ttlKey = "ttl"
+)
+
+// newFeedParser returns a gofeed parser that also keeps each item's comments
+// link and category domains and the feed's ttl, which the universal feed
+// model drops.
+func newFeedParser() *gofeed.Parser {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &feedRSSTranslator{}
+	parser.AtomTranslator = &commentsAtomTranslator{}
+	return parser
+}
+
+// feedRSSTranslator copies the channel <ttl> and the <comments> element and
+// category domains of each item.
+type feedRSSTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *feedRSSTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	src, ok := feed.(*rss.Feed)
+	if !ok {
+		return result, nil
+	}
+	if src.TTL != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom[ttlKey] = src.TTL
+	}
+	if len(src.Items) == len(result.Items) {
+		for i, item := range src.Items {
+			setCommentsURL(result.Items[i], item.Comments)
+			setCategoryDomains(result.Items[i], item.Categories)
+		}
+	}
+	return result, nil
+}
+
+// commentsAtomTranslator copies the HTML rel="replies" link of each entry.
+type commentsAtomTranslator struct {
+	gofeed.DefaultAtomTranslator
+}
+
+func (t *commentsAtomTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultAtomTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+	if src, ok := feed.(*atom.Feed); ok && len(src.Entries) == len(result.Items) {
+		for i, entry := range src.Entries {
+			for _, link := range entry.Links {
+				if link.Rel == "replies" && (link.Type == "" || link.Type == "text/html") {
+					setCommentsURL(result.Items[i], link.Href)
+					break
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func setCommentsURL(item *gofeed.Item, commentsURL string) {
+	if commentsURL == "" {
+		return
+	}
+	if item.Custom == nil {
+		item.Custom = make(map[string]string)
+	}
+	item.Custom[commentsKey] = commentsURL
+}
+
+// itemCommentsURL returns the comments URL the feed gave for item.
+func itemCommentsURL(item *gofeed.Item) string {
+	return item.Custom[commentsKey]
+}
+
+// detectCommentsURL fetches the article page and looks for a comment thread:
+// a Disqus embed, an element with id "comments" or a link to one. It costs an
+// extra request per article, so it only runs when DetectComments is set.
+func (h *FeedHandler) detectCommentsURL(pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	doc, ok := h.getDocument(pageURL)
+	if !ok {
+		return ""
+	}
+	return findCommentsURL(doc, base)
+}
+
+// findCommentsURL returns the comments URL marked up on an article page.
+func findCommentsURL(doc *goquery.Document, base *url.URL) string {
+	anchor := func(fragment string) string {
+		u := *base
+		u.Fragment = fragment
+		return u.String()
+	}
+
+	if doc.Find("#disqus_thread, [data-disqus-identifier], .disqus-comment-count").Length() > 0 {
+		return anchor("disqus_thread")
+	}
+	if doc.Find("#comments").Length() > 0 {
+		return anchor("comments")
+	}
+	if href, ok := doc.Find(`a[href*="#comments"], a[href*="#respond"], a.comments-link`).First().Attr("href"); ok {
+		if ref, err := url.Parse(href); err == nil {
+			return base.ResolveReference(ref).String()
+		}
+	}
+	return ""
+}
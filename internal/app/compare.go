@@ -0,0 +1,163 @@
+// internal/app/compare.go
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/go-shiori/go-readability"
+
+	"gofull/internal/extractors"
+)
+
+// comparedBlocks are the elements whose text is compared paragraph by
+// paragraph.
+const comparedBlocks = "p, li, blockquote, h2, h3, h4, h5, h6, figcaption, pre"
+
+// ExtractorComparison compares what a URL's extractor and plain
+// readability extract from the same page.
+type ExtractorComparison struct {
+	URL       string `json:"url"`
+	Extractor string `json:"extractor"`
+	// ExtractorWords and ReadabilityWords count the words of the cleaned
+	// articles; WordDelta is the extractor's count less readability's
+	ExtractorWords   int `json:"extractor_words"`
+	ReadabilityWords int `json:"readability_words"`
+	WordDelta        int `json:"word_delta"`
+	// MissingParagraphs are paragraphs readability found the extractor's
+	// article lacks, ExtraParagraphs the other way round
+	MissingParagraphs []string `json:"missing_paragraphs"`
+	ExtraParagraphs   []string `json:"extra_paragraphs"`
+	ExtractorError    string   `json:"extractor_error,omitempty"`
+	ReadabilityError  string   `json:"readability_error,omitempty"`
+}
+
+// compareExtractors fetches the page at target once and extracts it with
+// its extractor and with readability.
+func (h *FeedHandler) compareExtractors(target string) (ExtractorComparison, error) {
+	extractor := h.Registry.ForURL(target)
+	result := ExtractorComparison{
+		URL:               target,
+		Extractor:         fmt.Sprintf("%T", extractor),
+		MissingParagraphs: []string{},
+		ExtraParagraphs:   []string{},
+	}
+	page, err := h.fetchPage(target)
+	if err != nil {
+		return result, err
+	}
+
+	var extracted, baseline string
+	content, _, err := safeExtract(extractor, map[string]interface{}{"html": page, "link": target})
+	if err != nil {
+		result.ExtractorError = err.Error()
+	} else {
+		extracted = h.cleanArticle(target, cleanHTMLContent(content))
+	}
+	pageURL, _ := url.Parse(target)
+	article, err := readability.FromReader(strings.NewReader(page), pageURL)
+	if err != nil {
+		result.ReadabilityError = err.Error()
+	} else {
+		baseline = h.cleanArticle(target, cleanHTMLContent(article.Content))
+	}
+
+	result.ExtractorWords = len(strings.Fields(cleanHTMLTags(extracted)))
+	result.ReadabilityWords = len(strings.Fields(cleanHTMLTags(baseline)))
+	result.WordDelta = result.ExtractorWords - result.ReadabilityWords
+	result.MissingParagraphs = append(result.MissingParagraphs, missingParagraphs(baseline, extracted)...)
+	result.ExtraParagraphs = append(result.ExtraParagraphs, missingParagraphs(extracted, baseline)...)
+	return result, nil
+}
+
+// missingParagraphs returns the paragraphs of from whose text other does
+// not contain, whitespace aside.
+func missingParagraphs(from, other string) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(from))
+	if err != nil {
+		return nil
+	}
+	text := cleanHTMLTags(other)
+	seen := map[string]bool{}
+	var missing []string
+	doc.Find(comparedBlocks).Each(func(_ int, s *goquery.Selection) {
+		// Blocks within compared blocks are compared as part of them
+		if s.ParentsFiltered(comparedBlocks).Length() > 0 {
+			return
+		}
+		paragraph := strings.Join(strings.Fields(s.Text()), " ")
+		if paragraph == "" || seen[paragraph] {
+			return
+		}
+		seen[paragraph] = true
+		if !strings.Contains(text, paragraph) {
+			missing = append(missing, paragraph)
+		}
+	})
+	return missing
+}
+
+// fetchPage fetches the HTML of the page at pageURL.
+func (h *FeedHandler) fetchPage(pageURL string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := h.articleClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &extractors.UpstreamStatusError{Code: resp.StatusCode}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// readArticle fetches the page at pageURL through the article client, so
+// it is logged, metered and authenticated like the extractors' fetches, and
+// runs plain readability on it.
+func (h *FeedHandler) readArticle(pageURL string) (readability.Article, error) {
+	page, err := h.fetchPage(pageURL)
+	if err != nil {
+		return readability.Article{}, err
+	}
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return readability.Article{}, err
+	}
+	return readability.FromReader(strings.NewReader(page), parsed)
+}
+
+// handlePlaygroundCompare serves GET /playground/compare, comparing the
+// article at url as its extractor and plain readability extract it, to
+// check that a site's extractor does better than the generic path.
+func (s *Server) handlePlaygroundCompare(w http.ResponseWriter, r *http.Request) {
+	h := s.feedHandler
+	target := strings.TrimSpace(r.URL.Query().Get("url"))
+	if _, apiErr := validateTargetURL("url", target); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if h.shed(w, r) {
+		return
+	}
+
+	release := h.acquireExtraction(nil)
+	result, err := h.compareExtractors(target)
+	release()
+	if err != nil {
+		writeError(w, r, newAPIError(http.StatusBadGateway, CodeExtractionFailed,
+			fmt.Sprintf("failed to fetch the article: %v", err)).with("url", target))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMissingParagraphs(t *testing.T) {
+	from := `<div><p>First  paragraph.</p><ul><li><p>Listed point.</p></li></ul><p>Third paragraph.</p><p>First paragraph.</p></div>`
+	other := `<div><p>First paragraph.</p><p>Something else.</p></div>`
+	got := missingParagraphs(from, other)
+	if strings.Join(got, "|") != "Listed point.|Third paragraph." {
+		t.Errorf("missingParagraphs = %q", got)
+	}
+}
+
+func TestHandlePlaygroundCompare(t *testing.T) {
+	story := strings.Repeat("Belediye meclisi bu hafta yeni bütçeyi görüştü ve kabul etti. ", 12)
+	analysis := strings.Repeat("Muhalefet sözcüleri bütçenin sosyal harcamaları yeterince kapsamadığını söyledi. ", 12)
+	page := `<html><head><title>Story</title></head><body><nav>Home News Sports</nav>
+<article><h1>Story</h1><p>` + story + `</p><p>` + analysis + `</p></article>
+<footer>Contact</footer></body></html>`
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, page), nil
+	}, newFakeClock(), time.Hour)
+	h.Registry.RegisterDomain("news.example.com", extractorFunc(func(any) (string, []string, error) {
+		return "<p>" + story + "</p><p>Subscribe to our newsletter.</p>", nil, nil
+	}))
+	s := &Server{cfg: &Config{}, feedHandler: h}
+
+	rec := httptest.NewRecorder()
+	s.handlePlaygroundCompare(rec, httptest.NewRequest(http.MethodGet, "/playground/compare?url=https://news.example.com/a/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var result ExtractorComparison
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ReadabilityError != "" || result.WordDelta >= 0 {
+		t.Errorf("extractor %d words, readability %d (%s)", result.ExtractorWords, result.ReadabilityWords, result.ReadabilityError)
+	}
+	if len(result.MissingParagraphs) == 0 || !strings.HasPrefix(result.MissingParagraphs[0], "Muhalefet") {
+		t.Errorf("missing paragraphs %q; want the analysis", result.MissingParagraphs)
+	}
+	if strings.Join(result.ExtraParagraphs, "|") != "Subscribe to our newsletter." {
+		t.Errorf("extra paragraphs %q", result.ExtraParagraphs)
+	}
+}
@@ -0,0 +1,207 @@
+// internal/app/compress.go
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Supported content encodings in order of preference.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// minCompressSize is the smallest body worth compressing.
+const minCompressSize = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+// negotiateEncoding picks the best supported encoding from an Accept-Encoding
+// header. It returns "" when the client accepts neither brotli nor gzip.
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[name] = q > 0
+	}
+
+	for _, enc := range []string{encodingBrotli, encodingGzip} {
+		if ok, listed := accepted[enc]; listed {
+			if ok {
+				return enc
+			}
+			continue
+		}
+		if accepted["*"] {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressBytes encodes data with the given content encoding.
+func compressBytes(encoding string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch encoding {
+	case encodingBrotli:
+		bw := brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+		if _, err := bw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := bw.Close(); err != nil {
+			return nil, err
+		}
+	case encodingGzip:
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		defer gzipWriterPool.Put(gw)
+		gw.Reset(&buf)
+		if _, err := gw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := gw.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// writeCachedBody writes body to the client, compressing it when the client
+// supports it. Compressed variants are stored in the cache next to the
+// uncompressed entry so repeated hits don't recompress.
+func writeCachedBody(w http.ResponseWriter, r *http.Request, cache *Cache, cacheKey string, body []byte) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" || len(body) < minCompressSize {
+		w.Write(body)
+		return
+	}
+
+	encoded, ok := cache.GetEncoded(cacheKey, encoding)
+	if !ok {
+		compressed, err := compressBytes(encoding, body)
+		if err != nil {
+			w.Write(body)
+			return
+		}
+		encoded = string(compressed)
+		cache.SetEncoded(cacheKey, encoding, encoded, string(body))
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Del("Content-Length")
+	w.Write([]byte(encoded))
+}
+
+// Compress is middleware that transparently compresses responses using
+// brotli or gzip based on the request's Accept-Encoding header. Handlers that
+// already set Content-Encoding are passed through untouched.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter lazily decides whether to compress once headers are written.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	wroteHeader bool
+	passthrough bool
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	h.Add("Vary", "Accept-Encoding")
+	if h.Get("Content-Encoding") != "" || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	switch cw.encoding {
+	case encodingBrotli:
+		cw.writer = brotli.NewWriterLevel(cw.ResponseWriter, brotli.DefaultCompression)
+	default:
+		gw := gzipWriterPool.Get().(*gzip.Writer)
+		gw.Reset(cw.ResponseWriter)
+		cw.writer = gw
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(p)
+	}
+	return cw.writer.Write(p)
+}
+
+// Flush flushes buffered compressed data to the client.
+func (cw *compressWriter) Flush() {
+	if f, ok := cw.writer.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the compressed stream.
+func (cw *compressWriter) Close() {
+	if cw.writer == nil {
+		return
+	}
+	cw.writer.Close()
+	if gw, ok := cw.writer.(*gzip.Writer); ok {
+		gzipWriterPool.Put(gw)
+	}
+	cw.writer = nil
+}
@@ -0,0 +1,358 @@
+// internal/app/credentials.go
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// encryptedPrefix marks a credential secret encrypted with the credentials
+// key.
+const encryptedPrefix = "enc:"
+
+// UpstreamCredential authenticates the requests to a private upstream
+// domain: HTTP Basic auth with Username and Password, or Header set to
+// Value, such as "Authorization: Bearer <token>". Keys are the API keys,
+// and so the accounts with their feed profiles, that may have the domain
+// fetched; any of the server's API keys may when it is empty.
+type UpstreamCredential struct {
+	Username string
+	Password string
+	Header   string
+	Value    string
+	Keys     []string
+}
+
+// UpstreamAuth attaches credentials to the requests for the feeds and
+// articles of their domains. Credentials are only sent over HTTPS and to the
+// domain they were set for, redirects included; requests redirected there
+// from another domain go without them. Since they are attached to every
+// request to their domain, callers are only let to have those domains
+// fetched with an API key the credential allows (see Authorize), and the
+// articles of those domains that other feeds link to are only extracted for
+// them (see Scope).
+type UpstreamAuth struct {
+	credentials map[string]UpstreamCredential // by domain
+	apiKeys     []string
+}
+
+// NewUpstreamAuth creates an UpstreamAuth with credentials by domain, which
+// cover its subdomains. apiKeys are the server's API keys, those allowed to
+// use the credentials that do not list their own.
+func NewUpstreamAuth(credentials map[string]UpstreamCredential, apiKeys []string) *UpstreamAuth {
+	normalized := make(map[string]UpstreamCredential, len(credentials))
+	for domain, cred := range credentials {
+		normalized[strings.TrimPrefix(strings.ToLower(domain), "www.")] = cred
+	}
+	return &UpstreamAuth{credentials: normalized, apiKeys: apiKeys}
+}
+
+// Authorize returns an error unless the caller of r may have the links
+// fetched: links of domains without credentials are open to anyone, the
+// others need an API key their credential allows, given as a bearer token
+// or a key parameter. It is nil-safe.
+func (a *UpstreamAuth) Authorize(r *http.Request, links ...string) *APIError {
+	if a == nil {
+		return nil
+	}
+	key := requestKey(r)
+	for _, link := range links {
+		if !a.allows(key, link) {
+			return newAPIError(http.StatusUnauthorized, CodeUnauthorized,
+				"this site is fetched with the server's credentials; an API key allowed to use them is required").with("url", link)
+		}
+	}
+	return nil
+}
+
+// allows reports whether API key may have every one of links fetched, as
+// Authorize does for requests. It is nil-safe.
+func (a *UpstreamAuth) allows(key string, links ...string) bool {
+	if a == nil {
+		return true
+	}
+	for _, link := range links {
+		if _, cred, ok := a.credentialFor(link); ok && !a.mayUse(key, cred) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scope returns the domains with credentials the caller of r may have
+// fetched, sorted and joined by commas; it is part of the cache key of what
+// is rendered for the caller. It is nil-safe.
+func (a *UpstreamAuth) Scope(r *http.Request) string {
+	if a == nil {
+		return ""
+	}
+	key := requestKey(r)
+	var domains []string
+	for domain, cred := range a.credentials {
+		if a.mayUse(key, cred) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return strings.Join(domains, ",")
+}
+
+// Covers reports whether scope, as returned by Scope, lets link be fetched:
+// links of domains without credentials always are. It is nil-safe.
+func (a *UpstreamAuth) Covers(scope, link string) bool {
+	if a == nil {
+		return true
+	}
+	domain, _, ok := a.credentialFor(link)
+	return !ok || slices.Contains(strings.Split(scope, ","), domain)
+}
+
+// mayUse reports whether key may use cred.
+func (a *UpstreamAuth) mayUse(key string, cred UpstreamCredential) bool {
+	allowed := cred.Keys
+	if len(allowed) == 0 {
+		allowed = a.apiKeys
+	}
+	return matchesKey(key, allowed)
+}
+
+// apiKeys returns the API keys of cfg: its SaveKeys and those with a limit
+// of their own.
+func apiKeys(cfg *Config) []string {
+	keys := append([]string(nil), cfg.SaveKeys...)
+	for key := range cfg.KeyMaxLimits {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// requestKey returns the API key r carries as a bearer token or, for
+// bookmarklets and feed readers, as a key parameter.
+func requestKey(r *http.Request) string {
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return key
+	}
+	return r.URL.Query().Get("key")
+}
+
+// matchesKey reports whether key is one of keys, comparing in constant time.
+func matchesKey(key string, keys []string) bool {
+	for _, allowed := range keys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialFor returns the credential of link's domain or of its closest
+// parent domain having one, with that domain.
+func (a *UpstreamAuth) credentialFor(link string) (string, UpstreamCredential, bool) {
+	for d := circuitDomain(link); d != ""; {
+		if cred, ok := a.credentials[d]; ok {
+			return d, cred, true
+		}
+		_, parent, found := strings.Cut(d, ".")
+		if !found {
+			break
+		}
+		d = parent
+	}
+	return "", UpstreamCredential{}, false
+}
+
+// Transport returns a RoundTripper authenticating the HTTPS requests sent
+// through base (http.DefaultTransport when nil) to domains with credentials.
+// Requests already carrying the header are left alone.
+func (a *UpstreamAuth) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &authTransport{base: base, auth: a}
+}
+
+type authTransport struct {
+	base http.RoundTripper
+	auth *UpstreamAuth
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return t.base.RoundTrip(req)
+	}
+	domain, cred, ok := t.auth.credentialFor(req.URL.String())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	// A redirect from another domain must not have the credentials sent
+	// where it points
+	if first := redirectOrigin(req); first != req {
+		if from, _, _ := t.auth.credentialFor(first.URL.String()); from != domain {
+			return t.base.RoundTrip(req)
+		}
+	}
+	header := cred.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	if req.Header.Get(header) != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if cred.Header != "" {
+		req.Header.Set(cred.Header, cred.Value)
+	} else {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// redirectOrigin returns the request the redirect chain of req started with,
+// req itself when it was not redirected.
+func redirectOrigin(req *http.Request) *http.Request {
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+	return req
+}
+
+// ParseUpstreamCredentials parses credentials by domain, separated by
+// semicolons or newlines, such as "private.example.com=basic:user:pass;
+// api.example.org=bearer:token;feeds.example.net=header:X-Api-Key:value".
+// A domain may be prefixed with the API keys allowed to use its credential,
+// as in "key1,key2@private.example.com=...". Passwords, tokens and header
+// values may be encrypted with EncryptCredential and given as "enc:...",
+// decrypted with key.
+func ParseUpstreamCredentials(spec string, key []byte) (map[string]UpstreamCredential, error) {
+	credentials := make(map[string]UpstreamCredential)
+	entries := strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		domain, value, ok := strings.Cut(entry, "=")
+		domain = strings.TrimSpace(domain)
+		var cred UpstreamCredential
+		if keys, scoped, found := strings.Cut(domain, "@"); found {
+			for _, k := range strings.Split(keys, ",") {
+				if k = strings.TrimSpace(k); k != "" {
+					cred.Keys = append(cred.Keys, k)
+				}
+			}
+			domain = strings.TrimSpace(scoped)
+			if len(cred.Keys) == 0 {
+				return nil, fmt.Errorf("credential for %s: no API keys before '@'", domain)
+			}
+		}
+		if !ok || domain == "" {
+			return nil, fmt.Errorf("invalid credential %q (want \"domain=scheme:...\")", strings.TrimSpace(entry))
+		}
+		if _, dup := credentials[domain]; dup {
+			return nil, fmt.Errorf("credential for %s given twice", domain)
+		}
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(value), ":")
+		var secret *string
+		switch scheme {
+		case "basic":
+			user, password, ok := strings.Cut(rest, ":")
+			if !ok || user == "" {
+				return nil, fmt.Errorf("credential for %s: want basic:user:password", domain)
+			}
+			cred.Username, cred.Password = user, password
+			secret = &cred.Password
+		case "bearer":
+			if rest == "" {
+				return nil, fmt.Errorf("credential for %s: want bearer:token", domain)
+			}
+			cred.Header, cred.Value = "Authorization", rest
+			secret = &cred.Value
+		case "header":
+			name, v, ok := strings.Cut(rest, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("credential for %s: want header:name:value", domain)
+			}
+			cred.Header, cred.Value = http.CanonicalHeaderKey(strings.TrimSpace(name)), v
+			secret = &cred.Value
+		default:
+			return nil, fmt.Errorf("credential for %s: unsupported scheme %q (use basic, bearer or header)", domain, scheme)
+		}
+		if encrypted, ok := strings.CutPrefix(*secret, encryptedPrefix); ok {
+			plain, err := decryptCredential(key, encrypted)
+			if err != nil {
+				return nil, fmt.Errorf("credential for %s: %w", domain, err)
+			}
+			*secret = plain
+		}
+		if scheme == "bearer" {
+			cred.Value = "Bearer " + cred.Value
+		}
+		credentials[domain] = cred
+	}
+	return credentials, nil
+}
+
+// ParseCredentialsKey decodes a base64 AES-256 key for credential secrets.
+func ParseCredentialsKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("key is not base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key is %d bytes, want 32", len(key))
+	}
+	return key, nil
+}
+
+// EncryptCredential encrypts a credential secret with key, returning the
+// "enc:..." form ParseUpstreamCredentials accepts.
+func EncryptCredential(key []byte, secret string) (string, error) {
+	gcm, err := credentialCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptCredential decrypts the base64 form of a secret EncryptCredential
+// encrypted.
+func decryptCredential(key []byte, encoded string) (string, error) {
+	if key == nil {
+		return "", errors.New("encrypted secret needs the credentials key")
+	}
+	gcm, err := credentialCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("encrypted secret does not decrypt with the credentials key")
+	}
+	return string(plain), nil
+}
+
+// credentialCipher returns the AES-GCM cipher of key.
+func credentialCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
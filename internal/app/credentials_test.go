@@ -0,0 +1,204 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gofull/internal/extractors"
+)
+
+func TestParseUpstreamCredentials(t *testing.T) {
+	key := make([]byte, 32)
+	secret, err := EncryptCredential(key, "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := "private.example.com=basic:reader:" + secret + "; api.example.org=bearer:tok;feeds.example.net=header:x-api-key:abc:def\n" +
+		"alice, bob@team.example.com=header:Authorization:Token abc\n"
+	credentials, err := ParseUpstreamCredentials(spec, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]UpstreamCredential{
+		"private.example.com": {Username: "reader", Password: "s3cr3t"},
+		"api.example.org":     {Header: "Authorization", Value: "Bearer tok"},
+		"feeds.example.net":   {Header: "X-Api-Key", Value: "abc:def"},
+		"team.example.com":    {Header: "Authorization", Value: "Token abc", Keys: []string{"alice", "bob"}},
+	}
+	if !reflect.DeepEqual(credentials, want) {
+		t.Errorf("got %+v; want %+v", credentials, want)
+	}
+
+	for _, bad := range []string{"private.example.com", "private.example.com=digest:x", "private.example.com=basic:", "private.example.com=bearer:" + secret,
+		"@private.example.com=bearer:tok", "a.example.com=bearer:x;a.example.com=bearer:y"} {
+		if _, err := ParseUpstreamCredentials(bad, nil); err == nil {
+			t.Errorf("ParseUpstreamCredentials(%q) succeeded; want an error", bad)
+		}
+	}
+	otherKey := make([]byte, 32)
+	otherKey[0] = 1
+	if _, err := ParseUpstreamCredentials(spec, otherKey); err == nil || !strings.Contains(err.Error(), "private.example.com") {
+		t.Errorf("decrypting with the wrong key: %v; want an error naming the domain", err)
+	}
+}
+
+func TestUpstreamAuthTransport(t *testing.T) {
+	auth := NewUpstreamAuth(map[string]UpstreamCredential{
+		"example.com":     {Username: "reader", Password: "s3cr3t"},
+		"api.example.org": {Header: "X-Api-Key", Value: "abc"},
+	}, nil)
+	var got *http.Request
+	transport := auth.Transport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+	send := func(link string, header http.Header) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, link, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if user, pass, ok := send("https://www.feeds.example.com/rss", nil).BasicAuth(); !ok || user != "reader" || pass != "s3cr3t" {
+		t.Errorf("subdomain request: basic auth %q, %q, %v; want the domain's credential", user, pass, ok)
+	}
+	if v := send("https://api.example.org/feed", nil).Header.Get("X-Api-Key"); v != "abc" {
+		t.Errorf("X-Api-Key = %q; want abc", v)
+	}
+	if _, _, ok := send("http://example.com/rss", nil).BasicAuth(); ok {
+		t.Error("credential sent over plain HTTP")
+	}
+	if _, _, ok := send("https://other.example.net/rss", nil).BasicAuth(); ok {
+		t.Error("credential sent to another domain")
+	}
+	if v := send("https://example.com/rss", http.Header{"Authorization": {"Bearer own"}}).Header.Get("Authorization"); v != "Bearer own" {
+		t.Errorf("Authorization = %q; want the request's own", v)
+	}
+
+	// Redirects keep the credential within its domain only
+	redirected := func(from, to string) *http.Request {
+		first, _ := http.NewRequest(http.MethodGet, from, nil)
+		req, _ := http.NewRequest(http.MethodGet, to, nil)
+		req.Response = &http.Response{StatusCode: http.StatusFound, Request: first}
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	if _, _, ok := redirected("https://other.example.net/go", "https://example.com/rss").BasicAuth(); ok {
+		t.Error("credential sent on a redirect from another domain")
+	}
+	if _, _, ok := redirected("https://www.example.com/rss", "https://example.com/rss").BasicAuth(); !ok {
+		t.Error("credential not sent on a redirect within its domain")
+	}
+}
+
+func TestUpstreamAuthAuthorize(t *testing.T) {
+	auth := NewUpstreamAuth(map[string]UpstreamCredential{
+		"private.example.com": {Username: "reader", Password: "s3cr3t"},
+		"team.example.com":    {Header: "X-Api-Key", Value: "abc", Keys: []string{"alice"}},
+	}, []string{"alice", "bob"})
+
+	tests := []struct {
+		link, auth, key string
+		ok              bool
+	}{
+		{"https://news.example.com/rss", "", "", true},
+		{"https://private.example.com/rss", "", "", false},
+		{"https://feeds.private.example.com/rss", "Bearer mallory", "", false},
+		{"https://private.example.com/rss", "Bearer bob", "", true},
+		{"https://private.example.com/rss", "", "alice", true},
+		{"https://team.example.com/rss", "Bearer bob", "", false},
+		{"https://team.example.com/rss", "Bearer alice", "", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/feed?key="+tt.key, nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		if apiErr := auth.Authorize(r, tt.link); (apiErr == nil) != tt.ok {
+			t.Errorf("%s with %q/%q: %v; want allowed %v", tt.link, tt.auth, tt.key, apiErr, tt.ok)
+		}
+	}
+	if apiErr := (*UpstreamAuth)(nil).Authorize(httptest.NewRequest(http.MethodGet, "/", nil), "https://private.example.com/rss"); apiErr != nil {
+		t.Errorf("nil UpstreamAuth: %v", apiErr)
+	}
+}
+
+func TestFeedPrivateUpstream(t *testing.T) {
+	fetched := 0
+	doer := func(*http.Request) (*http.Response, error) {
+		fetched++
+		return respond(http.StatusOK, testFeed), nil
+	}
+	h := newTestFeedHandler(doer, newFakeClock(), 0)
+	h.Upstream = NewUpstreamAuth(map[string]UpstreamCredential{"news.example.com": {Username: "reader", Password: "s3cr3t"}}, []string{"alice"})
+
+	get := func(auth string) int {
+		r := httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL), nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	if code := get("Bearer alice"); code != http.StatusOK {
+		t.Fatalf("with an API key: status %d", code)
+	}
+	// The cached feed is not served to anonymous callers either
+	before := fetched
+	if code := get(""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous: status %d; want 401", code)
+	}
+	if code := get("Bearer mallory"); code != http.StatusUnauthorized {
+		t.Errorf("unknown key: status %d; want 401", code)
+	}
+	if fetched != before {
+		t.Errorf("%d more fetches for unauthorized callers", fetched-before)
+	}
+}
+
+func TestFeedPrivateItemLinks(t *testing.T) {
+	const feed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Example News</title><link>https://news.example.com/</link>
+<item><title>Members only</title><link>https://private.example.org/a/1</link><description>Feed summary</description></item>
+</channel></rss>`
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, feed), nil }, newFakeClock(), 0)
+	h.Upstream = NewUpstreamAuth(map[string]UpstreamCredential{"private.example.org": {Username: "reader", Password: "s3cr3t"}}, []string{"alice"})
+	link := "https://private.example.org/a/1"
+	h.Archive.Put("https://private.example.org/rss", Item{Title: "Members only", Link: link, GUID: extractors.GenerateGUIDFromURL(link), Content: "<p>members only</p>"})
+
+	get := func(auth string) Item {
+		r := httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL), nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		var resp feedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Items) != 1 {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		return resp.Items[0]
+	}
+	// Keys allowed to use the credential get the article, and what they
+	// get is not cached for others
+	if item := get("Bearer alice"); item.Content != "<p>members only</p>" {
+		t.Errorf("with an API key: content %q", item.Content)
+	}
+	for _, auth := range []string{"", "Bearer mallory"} {
+		if item := get(auth); strings.Contains(item.Content, "members only") {
+			t.Errorf("with %q: content %q; want the feed's own", auth, item.Content)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// internal/app/dates.go
+package app
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+// turkeyTime is Turkey's time zone, UTC+3 all year round since 2016. Dates
+// given without a zone, or with one Go does not know, are read in it.
+var turkeyTime = time.FixedZone("TRT", 3*60*60)
+
+// dateLayouts are the layouts feed and page dates are tried against, after
+// Turkish month names were translated and weekdays dropped.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 2006 15:04",
+	"Jan 2 2006",
+	"15:04 2 Jan 2006",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2.1.2006",
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2/1/2006",
+	"15:04 2.1.2006",
+}
+
+// turkishMonths maps Turkish month names and their abbreviations, lower-cased,
+// to the English abbreviations time.Parse knows.
+var turkishMonths = map[string]string{
+	"ocak": "Jan", "şubat": "Feb", "mart": "Mar", "nisan": "Apr", "mayıs": "May", "haziran": "Jun",
+	"temmuz": "Jul", "ağustos": "Aug", "eylül": "Sep", "ekim": "Oct", "kasım": "Nov", "aralık": "Dec",
+	"oca": "Jan", "şub": "Feb", "nis": "Apr", "haz": "Jun", "tem": "Jul", "ağu": "Aug", "eyl": "Sep",
+	"eki": "Oct", "kas": "Nov", "ara": "Dec",
+}
+
+// dateNoise are the words dropped from dates: weekdays in Turkish and English
+// and the labels sites put around them.
+var dateNoise = stringSet(
+	"pazartesi", "salı", "çarşamba", "perşembe", "cuma", "cumartesi", "pazar",
+	"pzt", "sal", "çar", "per", "cum", "cmt", "paz",
+	"mon", "tue", "wed", "thu", "fri", "sat", "sun",
+	"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+	"saat", "güncelleme", "güncellendi", "yayınlanma", "yayın", "tarihi", "tarih",
+)
+
+var dateWordRegex = regexp.MustCompile(`\p{L}+`)
+
+// parseDate reads a date in any of the forms feeds and Turkish news sites
+// use, such as "12 Mart 2024 Salı 14:30" or "12.03.2024 14:30".
+func parseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, ok := parseDateLayouts(s); ok {
+		return t, true
+	}
+
+	s = strings.ToLowerSpecial(unicode.TurkishCase, s)
+	s = dateWordRegex.ReplaceAllStringFunc(s, func(word string) string {
+		if month, ok := turkishMonths[word]; ok {
+			return month
+		}
+		if dateNoise[word] {
+			return ""
+		}
+		return word
+	})
+	s = strings.NewReplacer(",", " ", ": ", " ", " - ", " ", "|", " ").Replace(s)
+	s = strings.TrimSpace(collapseWhitespace(s))
+	// Layouts with an upper-case zone abbreviation need it back
+	if i := strings.LastIndexByte(s, ' '); i > 0 && isZoneName(s[i+1:]) {
+		s = s[:i+1] + strings.ToUpper(s[i+1:])
+	}
+	return parseDateLayouts(s)
+}
+
+// isZoneName reports whether word looks like a time zone abbreviation.
+func isZoneName(word string) bool {
+	if len(word) < 2 || len(word) > 5 {
+		return false
+	}
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDateLayouts tries s against the RSS dates and dateLayouts.
+func parseDateLayouts(s string) (time.Time, bool) {
+	layouts := append([]string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC822Z, time.RFC822}, dateLayouts...)
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, turkeyTime); err == nil {
+			return fixZone(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// fixZone reads t in Turkey's time when it carries a zone abbreviation Go
+// does not know, which time.Parse takes for UTC.
+func fixZone(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if offset != 0 || name == "" || name == "UTC" || name == "GMT" || name == "UT" || name == "Z" {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), turkeyTime)
+}
+
+// fixItemDates parses the dates of feed items gofeed could not read and
+// corrects those given in an unknown zone.
+func fixItemDates(feed *gofeed.Feed) {
+	for _, item := range feed.Items {
+		item.PublishedParsed = fixDate(item.Published, item.PublishedParsed)
+		item.UpdatedParsed = fixDate(item.Updated, item.UpdatedParsed)
+	}
+}
+
+func fixDate(raw string, parsed *time.Time) *time.Time {
+	if parsed != nil {
+		t := fixZone(*parsed)
+		return &t
+	}
+	if t, ok := parseDate(raw); ok {
+		return &t
+	}
+	return nil
+}
+
+// pageDateSel finds the publication date marked up on article pages.
+var pageDateSel = []struct{ selector, attr string }{
+	{`meta[property="article:published_time"]`, "content"},
+	{`meta[property="og:published_time"]`, "content"},
+	{`meta[itemprop="datePublished"]`, "content"},
+	{`meta[name="pubdate"], meta[name="publishdate"], meta[name="date"]`, "content"},
+	{`[itemprop="datePublished"]`, "datetime"},
+	{`time[datetime]`, "datetime"},
+}
+
+// pageDate fetches the article page for the publication date its metadata
+// gives, for items the feed gives no date for.
+func (h *FeedHandler) pageDate(pageURL string) *time.Time {
+	doc, ok := h.getDocument(pageURL)
+	if !ok {
+		return nil
+	}
+	return findPageDate(doc)
+}
+
+// findPageDate returns the publication date marked up on an article page.
+func findPageDate(doc *goquery.Document) *time.Time {
+	for _, sel := range pageDateSel {
+		if value, ok := doc.Find(sel.selector).First().Attr(sel.attr); ok {
+			if t, ok := parseDate(value); ok {
+				return &t
+			}
+		}
+	}
+	return nil
+}
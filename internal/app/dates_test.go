@@ -0,0 +1,56 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Wed, 01 May 2024 10:00:00 +0000", "2024-05-01T10:00:00Z"},
+		{"Wed, 01 May 2024 10:00:00 TRT", "2024-05-01T10:00:00+03:00"},
+		{"2024-05-01T10:00:00+03:00", "2024-05-01T10:00:00+03:00"},
+		{"2024-05-01 10:00:00", "2024-05-01T10:00:00+03:00"},
+		{"12 Mart 2024 Salı 14:30", "2024-03-12T14:30:00+03:00"},
+		{"12 MART 2024, 14:30", "2024-03-12T14:30:00+03:00"},
+		{"Çarşamba, 1 Mayıs 2024 09:05:00 +0300", "2024-05-01T09:05:00+03:00"},
+		{"14:30 - 3 Ağustos 2024", "2024-08-03T14:30:00+03:00"},
+		{"03.08.2024 14:30", "2024-08-03T14:30:00+03:00"},
+		{"Güncelleme: 3 Eyl 2024", "2024-09-03T00:00:00+03:00"},
+		{"31 Aralık 2023 Pazar", "2023-12-31T00:00:00+03:00"},
+	}
+	for _, tt := range tests {
+		got, ok := parseDate(tt.in)
+		if !ok {
+			t.Errorf("parseDate(%q) failed", tt.in)
+			continue
+		}
+		if s := got.Format(time.RFC3339); s != tt.want {
+			t.Errorf("parseDate(%q) = %s, want %s", tt.in, s, tt.want)
+		}
+	}
+	for _, in := range []string{"", "dün", "geçen hafta salı"} {
+		if got, ok := parseDate(in); ok {
+			t.Errorf("parseDate(%q) = %v, want failure", in, got)
+		}
+	}
+}
+
+func TestFindPageDate(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+<meta property="article:published_time" content="2024-05-01T08:15:00+03:00">
+</head><body><time datetime="2020-01-01">eski</time></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := findPageDate(doc)
+	if got == nil || got.Format(time.RFC3339) != "2024-05-01T08:15:00+03:00" {
+		t.Errorf("findPageDate = %v", got)
+	}
+}
@@ -0,0 +1,75 @@
+// internal/app/deadline.go
+package app
+
+import (
+	"log"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// extractWithin extracts feedItem, giving up after budget. An item that runs
+// out of time, or has none left, is served from the feed's own content; its
+// extraction carries on in the background and is archived when it finishes,
+// so the next refresh of the feed gets the full article. previous is the
+// archived version of an updated article. done delivers an extraction
+// already started ahead of time; when nil, extraction starts now in flow.
+func (h *FeedHandler) extractWithin(feed *gofeed.Feed, feedURL string, feedItem *gofeed.Item, previous *Item, budget time.Duration, done <-chan Item, flow *extractionFlow) Item {
+	if budget <= 0 && done == nil {
+		log.Printf("⏱️  Out of time, skipping extraction of %s", feedItem.Link)
+		return h.fallbackItem(feedItem)
+	}
+	if done == nil {
+		done = h.extractAsync(feedItem, flow)
+	}
+
+	if budget > 0 {
+		timer := time.NewTimer(budget)
+		defer timer.Stop()
+		select {
+		case item := <-done:
+			return item
+		case <-timer.C:
+		}
+		log.Printf("⏱️  Extraction of %s exceeded %v, serving the feed's own content", feedItem.Link, budget)
+	} else {
+		select {
+		case item := <-done:
+			return item
+		default:
+		}
+		log.Printf("⏱️  Out of time, serving the feed's own content for %s", feedItem.Link)
+	}
+	go func() {
+		item := <-done
+		if feedItem.Link != "" && item.Content != "" && !item.partial {
+			item.attribute(feed, feedURL)
+			h.archiveItem(feedURL, previous, item)
+		}
+	}()
+	return h.fallbackItem(feedItem)
+}
+
+// extractAsync starts extracting feedItem in flow and returns the channel
+// its item is delivered on.
+func (h *FeedHandler) extractAsync(feedItem *gofeed.Item, flow *extractionFlow) <-chan Item {
+	done := make(chan Item, 1)
+	go func() { done <- h.extract(feedItem, flow) }()
+	return done
+}
+
+// itemBudget returns how long the extraction of the next item may take: the
+// item timeout, cut short by the request deadline. Zero values mean no limit.
+func (h *FeedHandler) itemBudget(deadline time.Time) time.Duration {
+	budget := h.ItemTimeout
+	if !deadline.IsZero() {
+		remaining := deadline.Sub(h.now())
+		if remaining <= 0 {
+			return 0
+		}
+		if budget <= 0 || remaining < budget {
+			budget = remaining
+		}
+	}
+	return budget
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+// extractorFunc adapts a function to extractors.Extractor.
+type extractorFunc func(input any) (string, []string, error)
+
+func (f extractorFunc) Extract(input any) (string, []string, error) { return f(input) }
+
+func newDeadlineTestHandler(clock Clock, extract extractorFunc) *FeedHandler {
+	registry := extractors.NewRegistry()
+	registry.RegisterDefault(extract)
+	doer := func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }
+	h := NewFeedHandler(NewCache(time.Minute, 0), doerFunc(doer), registry, filters.NewFilterRegistry(), NewArchive(0), nil)
+	h.Clock = clock
+	return h
+}
+
+func getTestFeed(t *testing.T, h *FeedHandler) (*httptest.ResponseRecorder, feedResponse, int) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL), nil))
+	var resp struct {
+		feedResponse
+		Partial int `json:"items_partial"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v\n%s", err, rec.Body)
+	}
+	return rec, resp.feedResponse, resp.Partial
+}
+
+func TestFeedHandlerItemTimeout(t *testing.T) {
+	release := make(chan struct{})
+	h := newDeadlineTestHandler(newFakeClock(), func(any) (string, []string, error) {
+		<-release
+		return "<p>full article</p>", nil, nil
+	})
+	h.ItemTimeout = 20 * time.Millisecond
+
+	rec, resp, partial := getTestFeed(t, h)
+	if resp.Returned != 2 || partial != 2 {
+		t.Fatalf("returned %d, partial %d", resp.Returned, partial)
+	}
+	if _, ok := h.Cache.Get(feedCacheKey(testFeedURL, 10, formatJSON)); ok || rec.Header().Get("X-Cache") != "MISS" {
+		t.Error("response with partial items was cached")
+	}
+
+	// Extractions finishing late are archived for the next request
+	close(release)
+	for deadline := time.Now().Add(time.Second); h.Archive.Size() < 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("archived %d late extractions, want 2", h.Archive.Size())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, resp, partial := getTestFeed(t, h); partial != 0 || resp.Items[0].Content != "<p>full article</p>" {
+		t.Errorf("second request: partial %d, content %q", partial, resp.Items[0].Content)
+	}
+}
+
+func TestFeedHandlerDeadline(t *testing.T) {
+	clock := newFakeClock()
+	var calls atomic.Int32
+	h := newDeadlineTestHandler(clock, func(any) (string, []string, error) {
+		calls.Add(1)
+		clock.Advance(2 * time.Minute)
+		return "<p>full article</p>", nil, nil
+	})
+	h.Deadline = time.Minute
+
+	_, resp, partial := getTestFeed(t, h)
+	if calls.Load() != 1 {
+		t.Errorf("extractor called %d times after the deadline passed", calls.Load())
+	}
+	if resp.Returned != 2 || partial != 1 || resp.Items[0].Content != "<p>full article</p>" {
+		t.Errorf("returned %d, partial %d, first content %q", resp.Returned, partial, resp.Items[0].Content)
+	}
+}
@@ -0,0 +1,62 @@
+// internal/app/debug.go
+package app
+
+// feedDebug explains, for feeds requested with debug=true, why items were
+// left out and where the content of the others came from, so users can
+// tune their filters.
+type feedDebug struct {
+	Skipped []debugSkip `json:"skipped"`
+	Items   []debugItem `json:"items"`
+	GUIDs   []debugGUID `json:"guids,omitempty"`
+}
+
+// debugSkip is an item left out of the feed.
+type debugSkip struct {
+	URL string `json:"url"`
+	// Reason is "filter", "sentiment" or "clustered"
+	Reason string `json:"reason"`
+	// Rule is the filter rule excluding the URL
+	Rule string `json:"rule,omitempty"`
+}
+
+// debugItem tells where a returned item's content came from: "archive",
+// "extractor", "readability", "variant", "wayback" or "feed".
+type debugItem struct {
+	URL    string `json:"url"`
+	Source string `json:"source"`
+}
+
+// debugGUID is an item whose GUID was fixed up: "link_changed" keeps the
+// GUID of an article whose link changed, "guid_reused" notes an upstream
+// GUID given to another article and "guid_collision" suffixes the GUID of
+// an article sharing its link with another.
+type debugGUID struct {
+	URL          string `json:"url"`
+	UpstreamGUID string `json:"upstream_guid,omitempty"`
+	// GUID is the item's own GUID and Published the one it is published under
+	GUID      string `json:"guid"`
+	Published string `json:"published_guid"`
+	Problem   string `json:"problem"`
+}
+
+func (d *feedDebug) skip(url, reason, rule string) {
+	if d != nil {
+		d.Skipped = append(d.Skipped, debugSkip{URL: url, Reason: reason, Rule: rule})
+	}
+}
+
+func (d *feedDebug) item(url, source string) {
+	if d == nil {
+		return
+	}
+	if source == "" {
+		source = "archive"
+	}
+	d.Items = append(d.Items, debugItem{URL: url, Source: source})
+}
+
+func (d *feedDebug) guid(url, upstream, guid, published, problem string) {
+	if d != nil {
+		d.GUIDs = append(d.GUIDs, debugGUID{URL: url, UpstreamGUID: upstream, GUID: guid, Published: published, Problem: problem})
+	}
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors/filters"
+)
+
+func TestFeedDebugExplainsItems(t *testing.T) {
+	doer := func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }
+	h := newTestFeedHandler(doer, newFakeClock(), 0)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/a/2"}})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL)+query, nil))
+		return rec
+	}
+
+	var resp struct {
+		Skipped int        `json:"items_skipped"`
+		Debug   *feedDebug `json:"debug"`
+	}
+	if err := json.Unmarshal(get("").Body.Bytes(), &resp); err != nil || resp.Debug != nil {
+		t.Fatalf("debug output without debug=true: %+v (%v)", resp.Debug, err)
+	}
+
+	rec := get("&debug=true")
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body)
+	}
+	if resp.Debug == nil || resp.Skipped != 1 {
+		t.Fatalf("response = %s", rec.Body)
+	}
+	if got := resp.Debug.Skipped; len(got) != 1 || got[0].URL != "https://news.example.com/a/2" || got[0].Reason != "filter" || got[0].Rule != `news.example.com: blocked path "/a/2"` {
+		t.Errorf("skipped = %+v", got)
+	}
+	if got := resp.Debug.Items; len(got) != 1 || got[0].URL != "https://news.example.com/a/1" || got[0].Source != "archive" {
+		t.Errorf("items = %+v", got)
+	}
+}
+
+func TestFeedCacheKeyDebug(t *testing.T) {
+	p := feedParams{URL: testFeedURL, Limit: 10, Format: "json", Deadline: time.Now()}
+	debug := p
+	debug.Debug = true
+	if p.cacheKey() == debug.cacheKey() {
+		t.Error("debug responses share the cache entry of plain ones")
+	}
+}
@@ -0,0 +1,137 @@
+// internal/app/errors.go
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gofull/internal/extractors"
+)
+
+// Error codes reported in API error responses.
+const (
+	CodeMissingParameter    = "missing_parameter"
+	CodeInvalidParameter    = "invalid_parameter"
+	CodeNotAcceptable       = "not_acceptable"
+	CodeInvalidBody         = "invalid_body"
+	CodeInvalidURL          = "invalid_url"
+	CodeUnsupportedScheme   = "unsupported_scheme"
+	CodeFilteredURL         = "filtered_url"
+	CodeUpstreamClientError = "upstream_client_error"
+	CodeUpstreamServerError = "upstream_server_error"
+	CodeUpstreamTimeout     = "upstream_timeout"
+	CodeUpstreamUnreachable = "upstream_unreachable"
+	CodeInvalidFeed         = "invalid_feed"
+	CodeExtractionFailed    = "extraction_failed"
+	CodeNotFound            = "not_found"
+	CodeUnauthorized        = "unauthorized"
+	CodeConflict            = "conflict"
+	CodeRateLimited         = "rate_limited"
+	CodeOverloaded          = "overloaded"
+	CodeJobFailed           = "job_failed"
+	CodeInternal            = "internal_error"
+)
+
+// APIError is the body of every error response.
+type APIError struct {
+	Status    int            `json:"-"`
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// newAPIError creates an APIError with the given HTTP status and code.
+func newAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// with adds a detail to the error and returns it for chaining.
+func (e *APIError) with(key string, value any) *APIError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// writeError writes err as a JSON error response. Errors other than
+// *APIError are reported as internal errors.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = newAPIError(http.StatusInternalServerError, CodeInternal, err.Error())
+	}
+	body := *apiErr
+	body.RequestID = requestID(r)
+	writeJSON(w, body.Status, body)
+}
+
+// validateTargetURL checks that raw, taken from the named parameter, is an
+// absolute http(s) URL.
+func validateTargetURL(param, raw string) (*url.URL, *APIError) {
+	if raw == "" {
+		return nil, newAPIError(http.StatusBadRequest, CodeMissingParameter,
+			fmt.Sprintf("missing '%s' parameter", param)).with("parameter", param)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, newAPIError(http.StatusBadRequest, CodeInvalidURL,
+			fmt.Sprintf("'%s' is not a valid absolute URL", param)).with("parameter", param).with("value", raw)
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return nil, newAPIError(http.StatusBadRequest, CodeUnsupportedScheme,
+			fmt.Sprintf("unsupported URL scheme '%s' (use http or https)", u.Scheme)).with("parameter", param).with("scheme", u.Scheme)
+	}
+	return u, nil
+}
+
+// upstreamError classifies a failed upstream request. resp may be nil.
+func upstreamError(target string, resp *http.Response, err error) *APIError {
+	var netErr net.Error
+	switch {
+	case err != nil && (errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())):
+		return newAPIError(http.StatusGatewayTimeout, CodeUpstreamTimeout, "upstream request timed out").
+			with("url", target)
+	case err != nil:
+		return newAPIError(http.StatusBadGateway, CodeUpstreamUnreachable, "upstream could not be reached").
+			with("url", target).with("error", err.Error())
+	case resp.StatusCode >= 500:
+		return newAPIError(http.StatusBadGateway, CodeUpstreamServerError,
+			fmt.Sprintf("upstream returned %s", resp.Status)).with("url", target).with("upstream_status", resp.StatusCode)
+	default:
+		return newAPIError(http.StatusBadGateway, CodeUpstreamClientError,
+			fmt.Sprintf("upstream returned %s", resp.Status)).with("url", target).with("upstream_status", resp.StatusCode)
+	}
+}
+
+// extractionError classifies the failure to extract the article at target
+// from the error its extractor returned, which may be nil.
+func extractionError(target string, err error) *APIError {
+	var status *extractors.UpstreamStatusError
+	switch {
+	case errors.Is(err, extractors.ErrFiltered):
+		return newAPIError(http.StatusUnprocessableEntity, CodeFilteredURL,
+			"URL is not an article the site's extractor reads").with("url", target).with("error", err.Error())
+	case errors.Is(err, extractors.ErrTimeout):
+		return newAPIError(http.StatusGatewayTimeout, CodeUpstreamTimeout, "upstream request timed out").
+			with("url", target)
+	case errors.As(err, &status) && status.Code >= 500:
+		return newAPIError(http.StatusBadGateway, CodeUpstreamServerError,
+			fmt.Sprintf("upstream returned %d %s", status.Code, http.StatusText(status.Code))).with("url", target).with("upstream_status", status.Code)
+	case errors.As(err, &status):
+		return newAPIError(http.StatusBadGateway, CodeUpstreamClientError,
+			fmt.Sprintf("upstream returned %d %s", status.Code, http.StatusText(status.Code))).with("url", target).with("upstream_status", status.Code)
+	default:
+		return newAPIError(http.StatusBadGateway, CodeExtractionFailed, "could not extract content").with("url", target)
+	}
+}
@@ -0,0 +1,244 @@
+// internal/app/export.go
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Export formats of the archive.
+const (
+	exportJSONL    = "jsonl"
+	exportCSV      = "csv"
+	exportMarkdown = "md"
+)
+
+// exportContentTypes maps export formats to their content types.
+var exportContentTypes = map[string]string{
+	exportJSONL:    "application/x-ndjson; charset=utf-8",
+	exportCSV:      "text/csv; charset=utf-8",
+	exportMarkdown: "text/markdown; charset=utf-8",
+}
+
+// exportColumns are the CSV columns, in order.
+var exportColumns = []string{"feed", "stored_at", "guid", "title", "link", "published", "source_name", "author", "category", "tags", "image", "text", "content"}
+
+// exportRecord is one line of a JSONL export.
+type exportRecord struct {
+	Feed     string `json:"feed"`
+	StoredAt string `json:"stored_at"`
+	Item
+}
+
+// handleArchiveExport serves GET /archive/export?feed=...&format=..., the
+// extracted articles of the archive as JSON Lines, CSV or Markdown, for
+// building datasets. feed may be repeated; without it every feed is exported.
+// The export is streamed, so large archives do not have to fit in a buffer.
+func (s *Server) handleArchiveExport(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = exportJSONL
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unsupported format '%s' (use jsonl, csv or md)", format)).with("parameter", "format").with("value", format))
+		return
+	}
+
+	known := s.archive.Feeds()
+	var feeds []string
+	for _, f := range r.URL.Query()["feed"] {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		if !slices.Contains(known, f) {
+			writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "feed has no archived articles").with("feed", f))
+			return
+		}
+		feeds = append(feeds, f)
+	}
+	if len(feeds) == 0 {
+		feeds = known
+		slices.Sort(feeds)
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="archive-%s.%s"`, s.feedHandler.now().Format("20060102"), format))
+	flush := func() {}
+	if f, ok := w.(http.Flusher); ok {
+		flush = f.Flush
+	}
+
+	var write func(feedURL string, entry ArchivedItem) error
+	switch format {
+	case exportJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		write = func(feedURL string, entry ArchivedItem) error {
+			return enc.Encode(exportRecord{Feed: feedURL, StoredAt: entry.StoredAt.UTC().Format(time.RFC3339), Item: entry.Item})
+		}
+	case exportCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(exportColumns)
+		write = func(feedURL string, entry ArchivedItem) error {
+			it := entry.Item
+			cw.Write([]string{
+				feedURL, entry.StoredAt.UTC().Format(time.RFC3339), it.GUID, it.Title, it.Link, it.Published,
+				it.SourceName, it.Author, it.Category, strings.Join(it.Tags, ";"), it.Image,
+				cleanHTMLTags(it.Content), it.Content,
+			})
+			cw.Flush()
+			return cw.Error()
+		}
+	case exportMarkdown:
+		write = func(feedURL string, entry ArchivedItem) error {
+			return writeMarkdownArticle(w, feedURL, entry)
+		}
+	}
+
+	exported := 0
+	for _, feedURL := range feeds {
+		for _, entry := range s.archive.Entries(feedURL) {
+			if err := write(feedURL, entry); err != nil {
+				return
+			}
+			exported++
+		}
+		flush()
+	}
+	log.Printf("📦 Exported %d archived articles from %d feeds as %s", exported, len(feeds), format)
+}
+
+// writeMarkdownArticle writes one archived article as a Markdown section.
+func writeMarkdownArticle(w io.Writer, feedURL string, entry ArchivedItem) error {
+	it := entry.Item
+	var byline []string
+	for _, v := range []string{it.SourceName, it.Author, it.Published} {
+		if v != "" {
+			byline = append(byline, v)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscape(it.Title))
+	if len(byline) > 0 {
+		fmt.Fprintf(&b, "*%s*\n\n", markdownEscape(strings.Join(byline, " · ")))
+	}
+	fmt.Fprintf(&b, "<%s>\n\n", it.Link)
+	if body := htmlToMarkdown(it.Content); body != "" {
+		b.WriteString(body)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "<!-- feed: %s, guid: %s -->\n\n---\n\n", feedURL, it.GUID)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlToMarkdown converts extracted article HTML to Markdown: paragraphs,
+// headings, lists, quotes, links, emphasis and images.
+func htmlToMarkdown(content string) string {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	var blocks []string
+	var inline strings.Builder
+	endBlock := func(prefix string) {
+		if text := strings.TrimSpace(collapseWhitespace(inline.String())); text != "" {
+			blocks = append(blocks, prefix+text)
+		}
+		inline.Reset()
+	}
+
+	var walk func(n *html.Node, prefix string)
+	walk = func(n *html.Node, prefix string) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				inline.WriteString(markdownEscape(c.Data))
+				continue
+			case html.ElementNode:
+			default:
+				continue
+			}
+
+			switch c.DataAtom {
+			case atom.P, atom.Div, atom.Section, atom.Article, atom.Figure, atom.Figcaption, atom.Table, atom.Tr:
+				endBlock(prefix)
+				walk(c, prefix)
+				endBlock(prefix)
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				// One level down, below the article title
+				endBlock(prefix)
+				walk(c, prefix)
+				endBlock(prefix + strings.Repeat("#", int(c.Data[1]-'0')+1) + " ")
+			case atom.Li:
+				endBlock(prefix)
+				walk(c, prefix)
+				endBlock(prefix + "- ")
+			case atom.Blockquote:
+				endBlock(prefix)
+				walk(c, prefix+"> ")
+				endBlock(prefix + "> ")
+			case atom.Br:
+				inline.WriteString(" ")
+			case atom.A:
+				start := inline.Len()
+				walk(c, prefix)
+				text := strings.TrimSpace(inline.String()[start:])
+				href := nodeAttr(c, "href")
+				if href != "" && text != "" {
+					rest := inline.String()[:start]
+					inline.Reset()
+					inline.WriteString(rest)
+					fmt.Fprintf(&inline, "[%s](%s)", text, href)
+				}
+			case atom.Strong, atom.B:
+				inline.WriteString("**")
+				walk(c, prefix)
+				inline.WriteString("**")
+			case atom.Em, atom.I:
+				inline.WriteString("*")
+				walk(c, prefix)
+				inline.WriteString("*")
+			case atom.Img:
+				if src := nodeAttr(c, "src"); src != "" {
+					endBlock(prefix)
+					blocks = append(blocks, fmt.Sprintf("%s![%s](%s)", prefix, markdownEscape(nodeAttr(c, "alt")), src))
+				}
+			default:
+				walk(c, prefix)
+			}
+		}
+	}
+	walk(root, "")
+	endBlock("")
+	return strings.Join(blocks, "\n\n")
+}
+
+// nodeAttr returns the value of the named attribute of n.
+func nodeAttr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// markdownEscaper escapes the characters that would start Markdown markup.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`")
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newExportServer() *Server {
+	archive := NewArchive(0)
+	archive.Put("https://haber.test/rss", Item{
+		Title:      "Meclis yeni yasama yılına başladı",
+		Link:       "https://haber.test/gundem/meclis",
+		GUID:       "guid-meclis",
+		Author:     "Ayşe Yılmaz",
+		SourceName: "Haber Test",
+		Tags:       []string{"meclis", "yasama"},
+		Content:    `<p>Meclis, <strong>törenle</strong> açıldı.</p><p>Gündemde <a href="https://haber.test/ekonomi">ekonomi</a> var.</p>`,
+	})
+	archive.Put("https://dunya.com/rss", Item{Title: "Borsa güne yükselişle başladı", Link: "https://dunya.com/borsa", GUID: "guid-borsa", Content: "<p>Endeks yükseldi.</p>"})
+	return &Server{cfg: &Config{}, archive: archive, feedHandler: &FeedHandler{}}
+}
+
+func export(t *testing.T, s *Server, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.handleArchiveExport(rec, httptest.NewRequest(http.MethodGet, "/archive/export?"+query, nil))
+	return rec
+}
+
+func TestArchiveExportJSONL(t *testing.T) {
+	rec := export(t, newExportServer(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("exported %d lines, want 2 (reading lists are not exported):\n%s", len(lines), rec.Body)
+	}
+	var first exportRecord
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Feed != "https://dunya.com/rss" || first.GUID != "guid-borsa" || first.StoredAt == "" {
+		t.Errorf("first record %+v", first)
+	}
+}
+
+func TestArchiveExportCSV(t *testing.T) {
+	rec := export(t, newExportServer(), "format=csv&feed="+"https://haber.test/rss")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "feed" {
+		t.Fatalf("rows %q", rows)
+	}
+	row := map[string]string{}
+	for i, col := range rows[0] {
+		row[col] = rows[1][i]
+	}
+	if row["title"] != "Meclis yeni yasama yılına başladı" || row["tags"] != "meclis;yasama" || !strings.Contains(row["text"], "Meclis, törenle açıldı.") {
+		t.Errorf("row %v", row)
+	}
+}
+
+func TestArchiveExportMarkdown(t *testing.T) {
+	rec := export(t, newExportServer(), "format=md&feed=https://haber.test/rss")
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# Meclis yeni yasama yılına başladı\n\n*Haber Test · Ayşe Yılmaz*\n\n<https://haber.test/gundem/meclis>",
+		"Meclis, **törenle** açıldı.\n\nGündemde [ekonomi](https://haber.test/ekonomi) var.",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("export does not contain %q:\n%s", want, body)
+		}
+	}
+	if rec.Header().Get("Content-Type") != "text/markdown; charset=utf-8" {
+		t.Errorf("content type %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestArchiveExportRejectsUnknownFeeds(t *testing.T) {
+	s := newExportServer()
+	if rec := export(t, s, "feed=https://bilinmeyen.test/rss"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown feed: status %d", rec.Code)
+	}
+	if rec := export(t, s, "format=xml"); rec.Code != http.StatusBadRequest {
+		t.Errorf("unsupported format: status %d", rec.Code)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	got := htmlToMarkdown(`<h2>Ara başlık</h2><ul><li>Bir</li><li>İki</li></ul><blockquote><p>Alıntı</p></blockquote><img src="https://cdn.test/a.jpg" alt="Foto">`)
+	want := "### Ara başlık\n\n- Bir\n\n- İki\n\n> Alıntı\n\n![Foto](https://cdn.test/a.jpg)"
+	if got != want {
+		t.Errorf("htmlToMarkdown = %q; want %q", got, want)
+	}
+}
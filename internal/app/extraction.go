@@ -0,0 +1,80 @@
+// internal/app/extraction.go
+package app
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+)
+
+// ItemExtraction tells consumers how an item's content was obtained and how
+// far to trust it, so they can re-process weak items.
+type ItemExtraction struct {
+	// Method is "domain-extractor", "site-rule", "browser", "state",
+	// "readability", "variant", "wayback" or "feed"
+	Method string `json:"method"`
+	// Confidence runs from 0 to 1
+	Confidence float64 `json:"confidence"`
+	// DurationMS is how long extraction took
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// methodConfidence is the confidence in a full-length article by method:
+// hand-written extractors know their sites, fallbacks only guess.
+var methodConfidence = map[string]float64{
+	"domain-extractor": 0.95,
+	"site-rule":        0.9,
+	"browser":          0.85,
+	"state":            0.75,
+	"readability":      0.7,
+	"variant":          0.6,
+	"gallery":          0.6,
+	"video":            0.6,
+	"wayback":          0.5,
+	"feed":             0.3,
+}
+
+// fullTextConfidence is the confidence in the content of feeds carrying
+// whole articles: the publisher's own, if not cleaned up by an extractor.
+const fullTextConfidence = 0.8
+
+// isFullText reports whether the feed item carries its whole article, at
+// least FullTextWords words of content.
+func (h *FeedHandler) isFullText(i *gofeed.Item) bool {
+	return h.FullTextWords > 0 && len(strings.Fields(cleanHTMLTags(i.Content))) >= h.FullTextWords
+}
+
+// extractorMethod returns the method name of the extractor the registry
+// picked for an item.
+func extractorMethod(e extractors.Extractor) string {
+	switch e.(type) {
+	case *extractors.RenderExtractor:
+		return "browser"
+	case *extractors.SelectorExtractor:
+		return "site-rule"
+	case *extractors.StateExtractor:
+		return "state"
+	case *extractors.DefaultExtractor:
+		return "readability"
+	}
+	return "domain-extractor"
+}
+
+// newItemExtraction describes an extraction by method that yielded content
+// in took. Articles shorter than minArticleText lower the confidence in
+// proportion.
+func newItemExtraction(method, content string, took time.Duration) *ItemExtraction {
+	confidence := methodConfidence[method]
+	if n := textLength(content); n < minArticleText {
+		confidence *= float64(n) / minArticleText
+	}
+	return &ItemExtraction{
+		Method:     method,
+		Confidence: math.Round(confidence*100) / 100,
+		DurationMS: took.Milliseconds(),
+	}
+}
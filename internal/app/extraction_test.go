@@ -0,0 +1,168 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+func TestNewItemExtraction(t *testing.T) {
+	full := "<p>" + strings.Repeat("a", minArticleText) + "</p>"
+	half := "<p>" + strings.Repeat("a", minArticleText/2) + "</p>"
+
+	tests := []struct {
+		method, content string
+		want            float64
+	}{
+		{"domain-extractor", full, 0.95},
+		{"readability", full, 0.7},
+		// Short articles are trusted less
+		{"readability", half, 0.35},
+		{"feed", "", 0},
+	}
+	for _, tt := range tests {
+		got := newItemExtraction(tt.method, tt.content, 1500*time.Millisecond)
+		if got.Method != tt.method || got.Confidence != tt.want || got.DurationMS != 1500 {
+			t.Errorf("newItemExtraction(%s, %d chars) = %+v; want confidence %v", tt.method, textLength(tt.content), got, tt.want)
+		}
+	}
+}
+
+func TestExtractorMethod(t *testing.T) {
+	tests := []struct {
+		extractor extractors.Extractor
+		want      string
+	}{
+		{extractors.NewNTVExtractor(nil), "domain-extractor"},
+		{extractors.NewDefaultExtractor(nil), "readability"},
+		{&extractors.RenderExtractor{}, "browser"},
+	}
+	for _, tt := range tests {
+		if got := extractorMethod(tt.extractor); got != tt.want {
+			t.Errorf("extractorMethod(%T) = %s; want %s", tt.extractor, got, tt.want)
+		}
+	}
+}
+
+func TestFullTextFeedContent(t *testing.T) {
+	extracted := 0
+	reg := extractors.NewRegistry()
+	reg.RegisterDefault(extractorFunc(func(any) (string, []string, error) {
+		extracted++
+		return "<p>Extracted article</p>", nil, nil
+	}))
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, reg, filters.NewFilterRegistry(), nil, nil)
+	h.FullTextWords = 50
+	published := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	full := &gofeed.Item{Title: "Full", Link: "https://news.example.com/full", Content: "<p>" + strings.Repeat("kelime ", 50) + "</p>", PublishedParsed: &published}
+	item := h.buildItem(full, false)
+	if extracted != 0 || item.source != "feed" || item.partial || !strings.Contains(item.Content, "kelime") {
+		t.Errorf("full-text item extracted %d times, source %q, partial %v", extracted, item.source, item.partial)
+	}
+	if item.Extraction == nil || item.Extraction.Method != "feed" || item.Extraction.Confidence != fullTextConfidence {
+		t.Errorf("full-text item extraction %+v", item.Extraction)
+	}
+
+	summary := &gofeed.Item{Title: "Summary", Link: "https://news.example.com/summary", Content: "<p>" + strings.Repeat("kelime ", 49) + "</p>", PublishedParsed: &published}
+	if item := h.buildItem(summary, false); extracted != 1 || item.source != "extractor" {
+		t.Errorf("summary item extracted %d times, source %q", extracted, item.source)
+	}
+
+	h.FullTextWords = 0
+	if h.buildItem(full, false); extracted != 2 {
+		t.Error("full-text item not extracted with FullTextWords 0")
+	}
+}
+
+func TestBuildItemExtractionErrors(t *testing.T) {
+	fetched := 0
+	doer := func(*http.Request) (*http.Response, error) {
+		fetched++
+		return respond(http.StatusOK, "<html><body><p>page</p></body></html>"), nil
+	}
+	tests := []struct {
+		name string
+		err  error
+		gone bool
+	}{
+		{"filtered", fmt.Errorf("%w: a gallery", extractors.ErrFiltered), false},
+		{"site down", &extractors.UpstreamStatusError{Code: http.StatusServiceUnavailable}, false},
+		{"timeout", fmt.Errorf("failed to fetch URL: %w", extractors.ErrTimeout), false},
+		{"gone", &extractors.UpstreamStatusError{Code: http.StatusGone}, true},
+	}
+	for _, tt := range tests {
+		reg := extractors.NewRegistry()
+		reg.RegisterDefault(extractorFunc(func(any) (string, []string, error) { return "", nil, tt.err }))
+		h := NewFeedHandler(NewCache(time.Minute, 0), doerFunc(doer), reg, filters.NewFilterRegistry(), nil, nil)
+		h.Tombstones = true
+		fetched = 0
+
+		item := h.buildItem(&gofeed.Item{Title: "Article", Link: "https://news.example.com/a"}, false)
+		if !errors.Is(item.err, tt.err) {
+			t.Errorf("%s: item err = %v; want %v", tt.name, item.err, tt.err)
+		}
+		if item.source != "feed" || item.Deleted != tt.gone {
+			t.Errorf("%s: source %q, deleted %v; want feed, %v", tt.name, item.source, item.Deleted, tt.gone)
+		}
+		// The extractor's error settles the tombstone check
+		if fetched != 0 {
+			t.Errorf("%s: page fetched %d times after the extractor failed", tt.name, fetched)
+		}
+		if trace := strings.Join(item.trace, "\n"); !tt.gone && !strings.Contains(trace, "not falling back to readability") {
+			t.Errorf("%s: fell back to readability:\n%s", tt.name, trace)
+		}
+	}
+}
+
+func TestBuildItemReadabilityFallback(t *testing.T) {
+	story := strings.Repeat("Belediye meclisi bu hafta yeni bütçeyi görüştü ve kabul etti. ", 12)
+	var fetched []string
+	doer := func(r *http.Request) (*http.Response, error) {
+		fetched = append(fetched, r.URL.String())
+		return respond(http.StatusOK, "<html><body><article><h1>Story</h1><p>"+story+"</p></article></body></html>"), nil
+	}
+	reg := extractors.NewRegistry()
+	reg.RegisterDefault(extractorFunc(func(any) (string, []string, error) { return "", nil, errors.New("no article body") }))
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, reg, filters.NewFilterRegistry(), nil, nil)
+	h.ArticleClient = doerFunc(doer)
+
+	published := time.Now()
+	item := h.buildItem(&gofeed.Item{Title: "Story", Link: "https://news.example.com/a", PublishedParsed: &published}, false)
+	// The page is fetched through the handler's article client
+	if len(fetched) != 1 || fetched[0] != "https://news.example.com/a" {
+		t.Errorf("fetched %q; want the article once", fetched)
+	}
+	if item.source != "readability" || !strings.Contains(item.Content, "Belediye meclisi") {
+		t.Errorf("source %q, content %q; want readability's", item.source, item.Content)
+	}
+}
+
+func TestExtractionError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: a gallery", extractors.ErrFiltered), http.StatusUnprocessableEntity, CodeFilteredURL},
+		{fmt.Errorf("failed to fetch URL: %w", extractors.ErrTimeout), http.StatusGatewayTimeout, CodeUpstreamTimeout},
+		{&extractors.UpstreamStatusError{Code: http.StatusInternalServerError}, http.StatusBadGateway, CodeUpstreamServerError},
+		{&extractors.UpstreamStatusError{Code: http.StatusForbidden}, http.StatusBadGateway, CodeUpstreamClientError},
+		{extractors.ErrNoContent, http.StatusBadGateway, CodeExtractionFailed},
+		{nil, http.StatusBadGateway, CodeExtractionFailed},
+	}
+	for _, tt := range tests {
+		apiErr := extractionError("https://news.example.com/a", tt.err)
+		if apiErr.Status != tt.status || apiErr.Code != tt.code {
+			t.Errorf("extractionError(%v) = %d %s; want %d %s", tt.err, apiErr.Status, apiErr.Code, tt.status, tt.code)
+		}
+	}
+}
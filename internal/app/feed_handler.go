@@ -2,40 +2,180 @@
 package app
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/go-shiori/go-readability"
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/mmcdole/gofeed"
 
+	"gofull/internal/entities"
 	"gofull/internal/extractors"
 	"gofull/internal/extractors/filters"
+	"gofull/internal/fetch"
+	"gofull/internal/sentiment"
 )
 
 // FeedHandler handles fetching and returning RSS feed content.
 type FeedHandler struct {
 	Cache     *Cache
-	Client    *http.Client
 	Registry  *extractors.Registry
 	FilterReg *filters.FilterRegistry
+	Archive   *Archive
+	Jobs      *JobStore
+	// Client fetches upstream feeds; a retrying client is used when nil
+	Client fetch.Doer
+	// Upstream, when set, holds the credentials of private upstream domains,
+	// whose feeds only the callers it authorizes may have fetched
+	Upstream *UpstreamAuth
+	// ArticleClient fetches article pages outside the extractors, such as
+	// for the readability fallback; Client is used when nil
+	ArticleClient fetch.Doer
+	// Clock stamps rendered feeds; SystemClock is used when nil
+	Clock Clock
+	// FilterProfiles are named sets of URL filters requests pick instead
+	// of FilterReg with filters=<name>
+	FilterProfiles map[string]*filters.FilterRegistry
+	// DefaultFilters is the filter set of requests naming none: "default"
+	// (or empty) for FilterReg, "off" for none, or a profile
+	DefaultFilters string
+	// FeedHooks transform parsed feeds before their items are extracted;
+	// ItemHooks transform the items after extraction, before they are
+	// written
+	FeedHooks []FeedHook
+	ItemHooks []ItemHook
+	// Cluster, when set, shares extraction work with other instances
+	Cluster *Cluster
+	// DetectComments looks for a comment thread on article pages when the
+	// feed gives no comments link
+	DetectComments bool
+	// OEmbed adds the oEmbed description of articles whose pages advertise
+	// an oEmbed endpoint
+	OEmbed bool
+	// Locations tags items with where the news happened, from the dateline
+	// or the article page's markup; georss:point from the feed is always
+	// kept
+	Locations bool
+	// ExtractTags fills Item.Tags with keywords picked from the content
+	ExtractTags bool
+	// Entities tags items from EntityDomains with the companies and ticker
+	// symbols they mention
+	Entities      *entities.Recognizer
+	EntityDomains []string
+	// Sentiment, when set, scores the tone of every item
+	Sentiment sentiment.Analyzer
+	// Variants, when set, lists print and reader versions of article pages
+	// tried when the page itself yields little text
+	Variants *extractors.Variants
+	// Wayback extracts articles that are gone upstream from their latest
+	// Wayback Machine snapshot; WaybackAPI overrides the availability API
+	Wayback    bool
+	WaybackAPI string
+	// Breaker, when set, skips extraction from sites that keep failing
+	Breaker *CircuitBreaker
+	// Bandwidth, when set, skips extraction from sites over their daily
+	// quota
+	Bandwidth *Bandwidth
+	// Monitor, when set, alerts on failing extractions and feeds
+	Monitor *Monitor
+	// Deadline bounds the extraction time of a /feed request and ItemTimeout
+	// that of each item; items out of time get the feed's own content.
+	// Background refreshes and async jobs only use ItemTimeout.
+	Deadline    time.Duration
+	ItemTimeout time.Duration
+	// ItemConcurrency is how many items of a feed are extracted at a time
+	// (one when zero) and MaxExtractions caps the extractions running
+	// across all requests (no cap when zero)
+	ItemConcurrency int
+	MaxExtractions  int
+	// DefaultLimit is the number of items a feed returns without a limit
+	// parameter (10 when zero); larger limits than MaxLimit are rejected
+	// when it is set. KeyMaxLimits overrides MaxLimit for requests carrying
+	// one of its API keys (0 for no maximum).
+	DefaultLimit int
+	MaxLimit     int
+	KeyMaxLimits map[string]int
+	// ImageRules, when set, replaces matching images of every item
+	ImageRules *extractors.ImageSubstitutions
+	// TextRules, when set, rewrites the title and content of every item
+	TextRules *extractors.TextRewrites
+	// LinkRules, when set, points links and embeds in content at the
+	// configured front ends
+	LinkRules *extractors.LinkRewrites
+	// Requests, when set, attributes the logged outbound requests to the
+	// feeds and items they are made for
+	Requests *RequestLog
+	// Boilerplate, when set, removes promotional sentences from every item
+	Boilerplate *Boilerplate
+	// NormalizeTurkish repairs mojibake and normalizes the typography of
+	// item titles, descriptions and content
+	NormalizeTurkish bool
+	// Tombstones marks items taken down (404) or removed from the feed as
+	// deleted so mirrors can drop them
+	Tombstones bool
+	// FullTextWords keeps the feed's own content of items carrying at least
+	// this many words instead of extracting their article (0 disables)
+	FullTextWords int
+	// EarlyRefresh, when set, refreshes fresh entries in the background with
+	// a probability growing over the last EarlyRefresh of their TTL
+	EarlyRefresh time.Duration
+	// MaxContentSize caps the bytes of content written per item (0 for no
+	// cap); longer content is cut with the ContentTruncation strategy,
+	// TruncateParagraph when empty, and links to the reader view on
+	// PublicURL, the base URL clients reach the server at
+	MaxContentSize    int
+	ContentTruncation string
+	PublicURL         string
+	// ContentBlocks, when set, are added around the content of every item
+	// written
+	ContentBlocks *ContentBlocks
+	// RollupLocation is the time zone the windows of rollups start in, UTC
+	// when nil
+	RollupLocation *time.Location
+	// Load, when set, sheds requests that would extract while the heap or
+	// the extractions in flight are over their thresholds
+	Load *LoadGuard
+
+	refreshMu  sync.Mutex
+	refreshing map[string]bool
+	// generating holds, by cache key, a channel closed when the request
+	// generating the entry is done
+	generating map[string]chan struct{}
+	// random returns a number in [0, 1) for early refreshes (math/rand
+	// when nil)
+	random func() float64
+	// maxAges holds the client max-age computed for each feed URL
+	maxAges sync.Map
+	// builds holds the feedBuild of each cached feed, by cache key
+	builds sync.Map
+	// guidTrackers holds the *feedGUIDs of each feed URL
+	guidTrackers sync.Map
+	// scheduler shares the MaxExtractions extraction slots fairly among
+	// requests
+	scheduler     *extractionScheduler
+	schedulerOnce sync.Once
 }
 
 // NewFeedHandler creates a new FeedHandler with filter support
-func NewFeedHandler(cache *Cache, client *http.Client, registry *extractors.Registry, filterReg *filters.FilterRegistry) *FeedHandler {
+func NewFeedHandler(cache *Cache, client fetch.Doer, registry *extractors.Registry, filterReg *filters.FilterRegistry, archive *Archive, jobs *JobStore) *FeedHandler {
 	return &FeedHandler{
 		Cache:     cache,
 		Client:    client,
 		Registry:  registry,
 		FilterReg: filterReg,
+		Archive:   archive,
+		Jobs:      jobs,
 	}
 }
 
@@ -48,100 +188,1011 @@ type Item struct {
 	Description string `json:"description,omitempty"`
 	Content     string `json:"content,omitempty"`
 	Image       string `json:"image,omitempty"`
-	Category    string `json:"category,omitempty"`
+	// Images lists the article's images, the lead image first, with their
+	// captions and credits
+	Images   []ItemImage `json:"images,omitempty"`
+	Category string      `json:"category,omitempty"`
+	// OriginalTitle is the title as the feed gave it, when it was cleaned up
+	OriginalTitle string `json:"original_title,omitempty"`
+	// Provenance and metadata passed through from the upstream feed
+	SourceName string   `json:"source_name,omitempty"`
+	SourceURL  string   `json:"source_url,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// CategoryDomains maps upstream categories to the taxonomy (RSS domain)
+	// they belong to
+	CategoryDomains map[string]string `json:"category_domains,omitempty"`
+	// DublinCore is the upstream item's Dublin Core metadata
+	DublinCore *ItemDublinCore `json:"dublin_core,omitempty"`
+	// Enclosures are the files the upstream item attaches, such as podcast
+	// episodes
+	Enclosures []ItemEnclosure `json:"enclosures,omitempty"`
+	// ITunes is the upstream item's podcast metadata
+	ITunes *ItemITunes `json:"itunes,omitempty"`
+	// Location is where the news happened
+	Location *ItemLocation `json:"location,omitempty"`
+	// CommentsURL links to the article's discussion
+	CommentsURL string `json:"comments_url,omitempty"`
+	// Tags are keywords extracted from the article text
+	Tags []string `json:"tags,omitempty"`
+	// Companies and Tickers are the listed companies the article mentions
+	Companies []string `json:"companies,omitempty"`
+	Tickers   []string `json:"tickers,omitempty"`
+	// Sentiment is the tone of the article when scoring is enabled
+	Sentiment *sentiment.Result `json:"sentiment,omitempty"`
+	// Related lists other items covering the same story (cluster=grouped)
+	Related []RelatedItem `json:"related,omitempty"`
+	// ArchivedURL and ArchivedAt are set when the article was gone and its
+	// content comes from a Wayback Machine snapshot
+	ArchivedURL string `json:"archived_url,omitempty"`
+	ArchivedAt  string `json:"archived_at,omitempty"`
+	// UpdatedAt is when the feed says the article was last updated. Updated
+	// marks articles that changed since they were first extracted, Changes
+	// tells how and Revision counts their versions.
+	UpdatedAt string       `json:"updated_at,omitempty"`
+	Updated   bool         `json:"updated,omitempty"`
+	Changes   *ItemChanges `json:"changes,omitempty"`
+	Revision  int          `json:"revision,omitempty"`
+	// Deleted marks tombstones of articles taken down or removed from the
+	// feed, DeletedAt when that was noticed
+	Deleted   bool   `json:"deleted,omitempty"`
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// Embed is the article's oEmbed description, for rich previews
+	Embed *ItemEmbed `json:"embed,omitempty"`
+	// Extraction tells how the content was obtained, with a confidence
+	// score, for items extracted from their article page
+	Extraction *ItemExtraction `json:"extraction,omitempty"`
+	// Gallery marks photo galleries, whose images are the slides and
+	// content their captions
+	Gallery bool `json:"gallery,omitempty"`
+	// Video describes the video of video pages (media=include)
+	Video *ItemVideo `json:"video,omitempty"`
+	// ItemType labels the item as news, opinion, gallery or video
+	ItemType string `json:"item_type,omitempty"`
+	// Truncated marks content cut to the maximum size; FullTextURL is the
+	// reader view of the whole article
+	Truncated   bool   `json:"truncated,omitempty"`
+	FullTextURL string `json:"full_text_url,omitempty"`
+
+	// partial marks items served without extraction; they are not archived
+	// so they are extracted properly on a later refresh
+	partial bool
+	// source tells where the content came from: the site's extractor,
+	// "readability", a print "variant", the "wayback" machine, a "gallery"'s
+	// captions, a "video"'s description or the "feed"
+	source string
+	// trace lists the steps of the item's extraction, for the playground
+	trace []string
+	// err is the error the item's extractor failed with, which picks the
+	// status of single-article endpoints that end up without content
+	err error
+}
+
+// attribute records the feed an item came from unless it already carries a
+// source, so provenance survives when feeds are aggregated downstream.
+func (it *Item) attribute(feed *gofeed.Feed, feedURL string) {
+	if it.SourceName == "" {
+		it.SourceName = feed.Title
+	}
+	if it.SourceURL == "" {
+		it.SourceURL = feedURL
+	}
+}
+
+// itemAuthor returns the name of the feed item's first author, which gofeed
+// fills from author, dc:creator or itunes:author.
+func itemAuthor(i *gofeed.Item) string {
+	if i.Author != nil && i.Author.Name != "" {
+		return i.Author.Name
+	}
+	for _, a := range i.Authors {
+		if a != nil && a.Name != "" {
+			return a.Name
+		}
+	}
+	return ""
 }
 
 // ServeHTTP implements http.Handler for FeedHandler.
 func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	urlParam := strings.TrimSpace(r.URL.Query().Get("url"))
-	if urlParam == "" {
-		http.Error(w, "missing 'url' parameter", http.StatusBadRequest)
+	// POST takes the parameters as a JSON body, for requests too long for a URL
+	if r.Method == http.MethodPost {
+		query, apiErr := postedQuery(w, r)
+		if apiErr != nil {
+			writeError(w, r, apiErr)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+	}
+
+	start := h.now()
+	urls, apiErr := feedURLs(r.URL.Query())
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	urlParam := urls[0]
+
+	// Feeds fetched with credentials are not served to anonymous callers,
+	// from the cache either
+	if apiErr := h.Upstream.Authorize(r, urls...); apiErr != nil {
+		writeError(w, r, apiErr)
 		return
 	}
 
-	// Parse limit param (default: 10)
-	limitStr := r.URL.Query().Get("limit")
-	limit := 10
-	if limitStr != "" {
-		if n, err := strconv.Atoi(limitStr); err == nil && n > 0 {
-			limit = n
+	// Parse limit param (default: DefaultLimit, at most the caller's maximum)
+	limit, apiErr := h.parseLimit(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	// Parse offset param: the first items of the feed are skipped
+	offset, apiErr := parseOffset(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	// Parse format param, falling back to the Accept header (default: json)
+	w.Header().Add("Vary", "Accept")
+	format, apiErr := outputFormat(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	// Parse v param, falling back to the Accept-Version header: the JSON
+	// response schema version
+	w.Header().Add("Vary", "Accept-Version")
+	version, apiErr := parseSchemaVersion(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	// Parse sentiment param: only items with this label are returned
+	tone := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sentiment")))
+	switch tone {
+	case "", sentiment.Positive, sentiment.Neutral, sentiment.Negative:
+	default:
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unsupported sentiment '%s' (use positive, neutral or negative)", tone)).with("parameter", "sentiment").with("value", tone))
+		return
+	}
+	if tone != "" && h.Sentiment == nil {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"sentiment scoring is not enabled on this server").with("parameter", "sentiment"))
+		return
+	}
+
+	// Parse cluster param: group items covering the same story
+	clusterMode := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("cluster")))
+	switch clusterMode {
+	case "", clusterRepresentatives, clusterGrouped:
+	default:
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unsupported cluster mode '%s' (use representatives or grouped)", clusterMode)).with("parameter", "cluster").with("value", clusterMode))
+		return
+	}
+
+	// Parse rollup param: combine the items published the same hour, day
+	// or week
+	rollupWindow := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("rollup")))
+	switch rollupWindow {
+	case "", rollupHourly, rollupDaily, rollupWeekly:
+	default:
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unsupported rollup '%s' (use hourly, daily or weekly)", rollupWindow)).with("parameter", "rollup").with("value", rollupWindow))
+		return
+	}
+
+	// Parse reemit_updated param: updated items get a new GUID
+	reemit, _ := strconv.ParseBool(r.URL.Query().Get("reemit_updated"))
+	// Parse debug param: explain skipped items and content sources
+	debug, _ := strconv.ParseBool(r.URL.Query().Get("debug"))
+	// Parse strict param: malformed upstream XML is not repaired
+	strict, _ := strconv.ParseBool(r.URL.Query().Get("strict"))
+	// Parse galleries param: photo galleries pass the site filters
+	galleries, _ := strconv.ParseBool(r.URL.Query().Get("galleries"))
+	// Parse include_types and exclude_types params: only items of the
+	// included types are returned, none of the excluded ones
+	includeTypes, apiErr := parseItemTypes(r, "include_types")
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	excludeTypes, apiErr := parseItemTypes(r, "exclude_types")
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	// Parse media param: video pages and galleries pass the site filters
+	media := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("media")))
+	switch media {
+	case "", mediaExclude, mediaInclude:
+	default:
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unsupported media mode '%s' (use include or exclude)", media)).with("parameter", "media").with("value", media))
+		return
+	}
+
+	// Parse filters param: the site filters applied, off, default or a
+	// profile
+	filterSet, apiErr := h.parseFilters(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	params := feedParams{URL: urlParam, Limit: limit, Offset: offset, Format: format, Sentiment: tone, Cluster: clusterMode, Rollup: rollupWindow, Reemit: reemit, Debug: debug, Strict: strict, Galleries: galleries, Media: media == mediaInclude, IncludeTypes: includeTypes, ExcludeTypes: excludeTypes, Filters: filterSet, Version: version, Scope: h.Upstream.Scope(r)}
+	cacheKey := params.cacheKey()
+
+	// Several feeds are merged into one
+	if len(urls) > 1 {
+		h.serveMerged(w, r, urls, params)
+		return
+	}
+
+	// HEAD reports on the feed without rendering it
+	if r.Method == http.MethodHead {
+		h.serveHead(w, r, params, cacheKey)
+		return
+	}
+
+	// Long feeds can exceed client timeouts: hand them off to a background job
+	if async, _ := strconv.ParseBool(r.URL.Query().Get("async")); async && h.Jobs != nil {
+		if _, ok := h.Cache.Get(cacheKey); !ok && h.shed(w, r) {
+			return
+		}
+		h.startAsync(w, cacheKey, params)
+		return
+	}
+
+	// Check cache, serving expired entries while they are refreshed in the background
+	serveCached := func() bool {
+		cached, age, fresh, ok := h.Cache.GetStale(cacheKey)
+		if !ok {
+			return false
+		}
+		w.Header().Set("Content-Type", contentTypeFor(format))
+		w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+		h.setCacheControl(w, urlParam, age)
+		h.setBuildHeaders(w, cacheKey)
+		if fresh {
+			w.Header().Set("X-Cache", "HIT")
+			if h.refreshEarly(age) {
+				h.revalidate(cacheKey, params)
+			}
+		} else {
+			w.Header().Set("X-Cache", "STALE")
+			h.revalidate(cacheKey, params)
 		}
+		writeCachedBody(w, r, h.Cache, cacheKey, []byte(cached))
+		return true
+	}
+	if serveCached() {
+		return
+	}
+	if h.shed(w, r) {
+		return
 	}
 
-	cacheKey := fmt.Sprintf("%s|%d", urlParam, limit)
+	// Only one request generates a missing entry; the others wait for it
+	// and are served what it cached
+	release, ok := h.lockGeneration(r.Context(), cacheKey)
+	if !ok {
+		return
+	}
+	defer release()
+	if serveCached() {
+		return
+	}
 
-	// Check cache
-	if cached, ok := h.Cache.Get(cacheKey); ok {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.Write([]byte(cached))
+	feed, err := h.cachedFeed(urlParam, strict)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	// Use retryable HTTP client
-	client := retryablehttp.NewClient()
-	client.RetryMax = 3
-	client.Logger = nil
+	// Stream items to the client as they are extracted while keeping a copy for the cache
+	w.Header().Set("Content-Type", contentTypeFor(format))
+	w.Header().Set("X-Cache", "MISS")
+	h.setCacheControl(w, urlParam, 0)
+	flush := func() {}
+	if f, ok := w.(http.Flusher); ok {
+		flush = f.Flush
+	}
 
-	// Fetch RSS feed
-	resp, err := client.StandardClient().Get(urlParam)
+	// The deadline counts from the start of the request
+	if h.Deadline > 0 {
+		params.Deadline = start.Add(h.Deadline)
+	}
+
+	var buf bytes.Buffer
+	fw := &summaryFeedWriter{feedWriter: newFeedWriter(format, io.MultiWriter(w, &buf), flush)}
+	if err := h.render(feed, params, fw); err != nil {
+		log.Printf("⚠️  Failed to stream response for %s: %v", urlParam, err)
+		return
+	}
+
+	// Responses missing articles are not cached so the next request retries them
+	if fw.summary.Partial > 0 {
+		log.Printf("⏱️  Not caching %s: %d items without extraction", urlParam, fw.summary.Partial)
+		return
+	}
+
+	// Cache the complete response
+	h.cacheFeed(cacheKey, buf.String(), fw.summary)
+}
+
+// feedParams are the options a feed is rendered with.
+type feedParams struct {
+	URL   string
+	Limit int
+	// Offset is the number of items of the feed skipped before the first
+	// one rendered
+	Offset int
+	Format string
+	// Sentiment keeps only items with this label when set
+	Sentiment string
+	// Cluster groups items covering the same story when set
+	Cluster string
+	// Rollup combines the items published within the same window when set
+	Rollup string
+	// Deadline, when set, is when extraction stops and the remaining items
+	// are served from the feed's own content
+	Deadline time.Time
+	// Reemit gives updated items a new GUID so readers show them again
+	Reemit bool
+	// Debug adds to JSON output why items were skipped and where the
+	// content of the others came from
+	Debug bool
+	// Strict parses the upstream feed as it is, without repairing
+	// malformed XML first
+	Strict bool
+	// Galleries lets photo galleries the site filters block through, to be
+	// extracted as their slides
+	Galleries bool
+	// Media lets video pages through as well as galleries, video pages
+	// extracted as their video's metadata
+	Media bool
+	// IncludeTypes, when set, keeps only the items of these types and lets
+	// those the site filters block through; ExcludeTypes drops the items
+	// of its types. Both are sorted.
+	IncludeTypes []string
+	ExcludeTypes []string
+	// Background renders, which no client waits on, get a smaller share
+	// of the extraction slots
+	Background bool
+	// Filters names the site filters applied, the server's default when
+	// empty
+	Filters string
+	// Version is the JSON response schema version, SchemaVersion when 0
+	Version int
+	// Scope is the credentialed domains the caller may have fetched (see
+	// UpstreamAuth.Scope); items linking to others are served from the
+	// feed's own content
+	Scope string
+}
+
+// cacheKeyVersion is part of every feed cache key. Bump it when the output
+// changes shape so responses cached before the change are not served.
+const cacheKeyVersion = 4
+
+// cacheKey returns the cache key of the feed rendered with p: the
+// normalized feed URL followed by every option affecting the output, in a
+// fixed order. Deadline only decides how long rendering may take and is left
+// out.
+func (p feedParams) cacheKey() string {
+	options := url.Values{}
+	options.Set("limit", strconv.Itoa(p.Limit))
+	options.Set("format", p.Format)
+	if p.Offset > 0 {
+		options.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Sentiment != "" {
+		options.Set("sentiment", p.Sentiment)
+	}
+	if p.Cluster != "" {
+		options.Set("cluster", p.Cluster)
+	}
+	if p.Rollup != "" {
+		options.Set("rollup", p.Rollup)
+	}
+	if p.Reemit {
+		options.Set("reemit_updated", "true")
+	}
+	if p.Debug {
+		options.Set("debug", "true")
+	}
+	if p.Strict {
+		options.Set("strict", "true")
+	}
+	if p.Galleries {
+		options.Set("galleries", "true")
+	}
+	if p.Media {
+		options.Set("media", mediaInclude)
+	}
+	if len(p.IncludeTypes) > 0 {
+		options.Set("include_types", strings.Join(p.IncludeTypes, ","))
+	}
+	if len(p.ExcludeTypes) > 0 {
+		options.Set("exclude_types", strings.Join(p.ExcludeTypes, ","))
+	}
+	if p.Filters != "" {
+		options.Set("filters", p.Filters)
+	}
+	if p.Version != 0 {
+		options.Set("v", strconv.Itoa(p.Version))
+	}
+	if p.Scope != "" {
+		options.Set("scope", p.Scope)
+	}
+	return fmt.Sprintf("v%d|%s|%s", cacheKeyVersion, canonicalURL(p.URL), options.Encode())
+}
+
+// feedCacheKey returns the cache key of a feed rendered without options.
+func feedCacheKey(urlParam string, limit int, format string) string {
+	return feedParams{URL: urlParam, Limit: limit, Format: format}.cacheKey()
+}
+
+// canonicalURL normalizes raw so equivalent spellings of a URL share a
+// cache entry: the scheme and host are lower-cased, default ports and the
+// fragment dropped and the query parameters sorted.
+func canonicalURL(raw string) string {
+	u, err := url.Parse(raw)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to fetch RSS: %v", err), http.StatusBadGateway)
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+	if port != "" {
+		u.Host += ":" + port
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	u.Fragment, u.RawFragment = "", ""
+	if u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String()
+}
+
+// revalidate regenerates a stale cache entry in the background. Only one
+// refresh per key runs at a time.
+func (h *FeedHandler) revalidate(cacheKey string, params feedParams) {
+	h.refreshMu.Lock()
+	if h.refreshing == nil {
+		h.refreshing = make(map[string]bool)
+	}
+	if h.refreshing[cacheKey] {
+		h.refreshMu.Unlock()
 		return
 	}
+	h.refreshing[cacheKey] = true
+	h.refreshMu.Unlock()
+	params.Background = true
+
+	go func() {
+		defer func() {
+			h.refreshMu.Lock()
+			delete(h.refreshing, cacheKey)
+			h.refreshMu.Unlock()
+		}()
+
+		// In distributed mode only one instance refreshes each entry
+		if h.Cluster != nil {
+			release, ok := h.Cluster.Lease("refresh/"+cacheKey, 2*time.Minute)
+			if !ok {
+				return
+			}
+			defer release()
+		}
+
+		// A request may be generating the entry already; an early refresh
+		// it cached in the meantime needs no other
+		release, _ := h.lockGeneration(context.Background(), cacheKey)
+		defer release()
+		if _, age, fresh, ok := h.Cache.GetStale(cacheKey); ok && fresh && age < h.Cache.ttl-h.EarlyRefresh {
+			return
+		}
+
+		// Stale entries keep being served while the server sheds load
+		if reason, overloaded := h.Load.Overloaded(); overloaded {
+			log.Printf("⏸️  Not refreshing %s while overloaded (%s)", cacheKey, reason)
+			return
+		}
+
+		log.Printf("🔄 Revalidating stale cache entry: %s", cacheKey)
+		feed, err := h.fetchFeed(params.URL, params.Strict)
+		if err != nil {
+			log.Printf("⚠️  Background refresh failed for %s: %v", cacheKey, err)
+			return
+		}
+		var buf bytes.Buffer
+		fw := &summaryFeedWriter{feedWriter: newFeedWriter(params.Format, &buf, nil)}
+		if err := h.render(feed, params, fw); err != nil {
+			log.Printf("⚠️  Background refresh failed for %s: %v", cacheKey, err)
+			return
+		}
+		// Keep serving the stale entry rather than one missing articles
+		if fw.summary.Partial > 0 {
+			log.Printf("⏱️  Background refresh of %s left %d items without extraction", cacheKey, fw.summary.Partial)
+			return
+		}
+		h.cacheFeed(cacheKey, buf.String(), fw.summary)
+	}()
+}
+
+// startAsync renders the feed in a background job and responds with the job
+// ID right away. Clients poll /jobs/{id} and fetch /jobs/{id}/result.
+func (h *FeedHandler) startAsync(w http.ResponseWriter, cacheKey string, params feedParams) {
+	job := h.Jobs.Create("feed")
+	params.Background = true
+
+	go func() {
+		h.Jobs.Update(job.ID, func(j *Job) { j.Status = JobRunning })
+
+		// Reuse a cached response when one is still fresh, waiting for a
+		// request generating it
+		release, _ := h.lockGeneration(context.Background(), cacheKey)
+		defer release()
+		if cached, ok := h.Cache.Get(cacheKey); ok {
+			h.Jobs.SetResult(job.ID, contentTypeFor(params.Format), []byte(cached))
+			return
+		}
+
+		feed, err := h.cachedFeed(params.URL, params.Strict)
+		if err != nil {
+			log.Printf("⚠️  Async job %s failed: %v", job.ID, err)
+			h.Jobs.Update(job.ID, func(j *Job) {
+				j.Status = JobFailed
+				j.Error = err.Error()
+			})
+			return
+		}
+		h.Jobs.Update(job.ID, func(j *Job) { j.Total = min(params.Limit, max(len(feed.Items)-params.Offset, 0)) })
+
+		var buf bytes.Buffer
+		fw := &summaryFeedWriter{feedWriter: &progressFeedWriter{feedWriter: newFeedWriter(params.Format, &buf, nil), jobs: h.Jobs, jobID: job.ID}}
+		if err := h.render(feed, params, fw); err != nil {
+			log.Printf("⚠️  Async job %s failed: %v", job.ID, err)
+			h.Jobs.Update(job.ID, func(j *Job) {
+				j.Status = JobFailed
+				j.Error = err.Error()
+			})
+			return
+		}
+
+		h.cacheFeed(cacheKey, buf.String(), fw.summary)
+		h.Jobs.SetResult(job.ID, contentTypeFor(params.Format), buf.Bytes())
+		log.Printf("📦 Async job %s finished", job.ID)
+	}()
+
+	writeJSON(w, http.StatusAccepted, FeedJobAccepted{
+		JobID:     job.ID,
+		StatusURL: "/jobs/" + job.ID,
+		ResultURL: "/jobs/" + job.ID + "/result",
+	})
+}
+
+// progressFeedWriter reports each written item as progress of a job.
+type progressFeedWriter struct {
+	feedWriter
+	jobs  *JobStore
+	jobID string
+}
+
+func (p *progressFeedWriter) WriteItem(item Item) error {
+	if err := p.feedWriter.WriteItem(item); err != nil {
+		return err
+	}
+	p.jobs.Update(p.jobID, func(j *Job) {
+		j.Done++
+		if item.Content == "" {
+			j.Failed++
+		}
+	})
+	return nil
+}
+
+// fetchFeed fetches and parses the upstream feed, repairing malformed XML
+// first unless strict is set. Failures are returned as *APIError describing
+// what went wrong upstream.
+func (h *FeedHandler) fetchFeed(urlParam string, strict bool) (*gofeed.Feed, error) {
+	feed, err := h.fetchFeedOnce(urlParam, strict)
+	h.Monitor.RecordFeed(urlParam, err)
+	return feed, err
+}
+
+func (h *FeedHandler) fetchFeedOnce(urlParam string, strict bool) (*gofeed.Feed, error) {
+	req, err := http.NewRequest(http.MethodGet, urlParam, nil)
+	if err != nil {
+		return nil, newAPIError(http.StatusBadRequest, CodeInvalidURL, "invalid feed URL").with("url", urlParam)
+	}
+
+	// Fetch RSS feed
+	h.Requests.Attribute(urlParam, urlParam, "")
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return nil, upstreamError(urlParam, nil, err)
+	}
 	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, upstreamError(urlParam, resp, nil)
+	}
 
-	parser := gofeed.NewParser()
-	feed, err := parser.Parse(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to parse feed: %v", err), http.StatusInternalServerError)
-		return
+		return nil, upstreamError(urlParam, resp, err)
+	}
+	feed, err := h.parseUpstream(urlParam, body, strict)
+	if err != nil {
+		return nil, err
+	}
+	h.Cache.Set(feedDocKey(urlParam), string(body))
+	return feed, nil
+}
+
+// parseUpstream parses the upstream feed document body, repairing malformed
+// XML first unless strict is set.
+func (h *FeedHandler) parseUpstream(urlParam string, body []byte, strict bool) (*gofeed.Feed, error) {
+	if !strict {
+		var repairs []string
+		if body, repairs = repairFeed(body); len(repairs) > 0 {
+			log.Printf("🩹 Repaired malformed feed %s: %s", urlParam, strings.Join(repairs, ", "))
+		}
+	}
+	feed, err := parseFeed(body)
+	if err != nil {
+		return nil, invalidFeedError(urlParam, err)
+	}
+	h.rememberMaxAge(urlParam, feedMaxAge(feed, h.Cache.ttl))
+	return feed, nil
+}
+
+// client returns the Doer used for upstream requests.
+func (h *FeedHandler) client() fetch.Doer {
+	if h.Client != nil {
+		return h.Client
+	}
+	return defaultFeedClient
+}
+
+// articleClient returns the Doer used for article pages.
+func (h *FeedHandler) articleClient() fetch.Doer {
+	if h.ArticleClient != nil {
+		return h.ArticleClient
+	}
+	return h.client()
+}
+
+// now returns the current time from the handler's clock.
+func (h *FeedHandler) now() time.Time {
+	if h.Clock != nil {
+		return h.Clock.Now()
+	}
+	return SystemClock.Now()
+}
+
+// defaultFeedClient retries failed feed fetches with backoff.
+var defaultFeedClient = fetch.NewClient(fetch.ClientOptions{
+	Timeout:  30 * time.Second,
+	RetryMax: 3,
+})
+
+// render extracts the feed's items and writes them through fw one at a time.
+func (h *FeedHandler) render(feed *gofeed.Feed, params feedParams, fw feedWriter) error {
+	urlParam, limit := params.URL, params.Limit
+	h.runFeedHooks(urlParam, feed)
+	meta := feedMeta{Title: feed.Title, Link: feed.Link, Description: feed.Description, Updated: h.now(), ITunes: channelITunes(feed), Links: h.archiveLinks(urlParam, params.Format), SchemaVersion: params.Version}
+	if err := fw.Begin(meta); err != nil {
+		return err
 	}
 
 	// Process items with filtering
-	var items []Item
 	processedCount := 0
 	skippedCount := 0
+	reusedCount := 0
+	clusteredCount := 0
+	partialCount := 0
+	deletedCount := 0
+	var debug *feedDebug
+	if params.Debug {
+		debug = &feedDebug{Skipped: []debugSkip{}, Items: []debugItem{}}
+	}
 
-	for _, feedItem := range feed.Items {
+	// With clustering, only the first item of each story is written. Grouped
+	// output needs every item first, so it is written once the loop is done.
+	var stories *storyGroups
+	if params.Cluster != "" {
+		stories = &storyGroups{}
+	}
+	var rollup *rollup
+	if params.Rollup != "" {
+		rollup = h.newRollup(params.Rollup, urlParam, meta)
+	}
+	// emit writes item, or files it under its window to be written with
+	// the other items of the window once all are processed
+	emit := func(item Item) error {
+		item = h.augment(h.truncate(params.present(h.runItemHooks(urlParam, item))))
+		if rollup != nil {
+			rollup.add(item)
+			return nil
+		}
+		return fw.WriteItem(item)
+	}
+	write := func(item Item) (bool, error) {
+		if stories != nil && !stories.add(item) {
+			clusteredCount++
+			debug.skip(item.Link, "clustered", "")
+			return false, nil
+		}
+		debug.item(item.Link, item.source)
+		if params.Cluster == clusterGrouped {
+			return true, nil
+		}
+		return true, emit(item)
+	}
+
+	// The extractions of this render wait their turn among those of other
+	// requests
+	weight := foregroundWeight
+	if params.Background {
+		weight = backgroundWeight
+	}
+	flow := h.extractions().newFlow(float64(weight))
+	feedItems := feed.Items[min(params.Offset, len(feed.Items)):]
+	filterReg := h.filterSet(params.Filters)
+	ahead := h.newLookahead(urlParam, feedItems, filterReg, params.Scope, flow)
+	for idx, feedItem := range feedItems {
 		// Stop if we reached the limit
 		if processedCount >= limit {
 			break
 		}
 
-		// Apply URL filter
-		if feedItem.Link != "" && !h.FilterReg.ShouldProcess(feedItem.Link) {
-			log.Printf("⏭️  Skipping filtered URL: %s", feedItem.Link)
+		// Apply URL filter, unless galleries, videos or opinion pieces are
+		// asked for
+		if feedItem.Link != "" && !params.letThrough(feedItem.Link) {
+			if ok, rule := filterReg.Explain(feedItem.Link); !ok {
+				log.Printf("⏭️  Skipping filtered URL: %s", feedItem.Link)
+				skippedCount++
+				debug.skip(feedItem.Link, "filter", rule)
+				continue
+			}
+		}
+
+		// Skip items whose URL reveals an unwanted type before extracting
+		if t := urlItemType(feedItem.Link); t != "" && !params.wantsType(t) {
 			skippedCount++
+			debug.skip(feedItem.Link, "item_type", t)
 			continue
 		}
 
-		// Process the item
-		item := h.processItem(feedItem)
-		items = append(items, item)
+		// Articles fetched with credentials the caller may not use are
+		// neither extracted nor taken from the archive
+		restricted := feedItem.Link != "" && !h.Upstream.Covers(params.Scope, feedItem.Link)
+
+		// Serve previously extracted items from the archive, unless the feed
+		// says they were updated since
+		var previous *Item
+		if feedItem.Link != "" && h.Archive != nil && !restricted {
+			if stored, ok := h.Archive.Get(extractors.GenerateGUIDFromURL(feedItem.Link)); ok && (revisedUpstream(feedItem, stored) || stored.Deleted) {
+				log.Printf("📝 Updated upstream or deleted, extracting again: %s", feedItem.Link)
+				previous = &stored
+			} else if ok {
+				stored.attribute(feed, urlParam)
+				h.stabilizeGUID(urlParam, feedItem, &stored, debug)
+				if !h.matchesSentiment(&stored, params.Sentiment) {
+					skippedCount++
+					debug.skip(stored.Link, "sentiment", "")
+					continue
+				}
+				// Items archived before they were labeled are labeled now
+				if stored.ItemType == "" {
+					stored.ItemType = classifyItem(stored)
+				}
+				if !params.wantsType(stored.ItemType) {
+					skippedCount++
+					debug.skip(stored.Link, "item_type", stored.ItemType)
+					continue
+				}
+				written, err := write(stored)
+				if err != nil {
+					return err
+				}
+				if !written {
+					continue
+				}
+				processedCount++
+				reusedCount++
+				log.Printf("♻️  [%d/%d] Reused archived item: %s", processedCount, limit, feedItem.Title)
+				continue
+			}
+		}
+
+		// Process the item within the time left, extracting the next ones
+		// meanwhile
+		h.Requests.Attribute(feedItem.Link, urlParam, feedItem.Link)
+		done := ahead.take(feedItem)
+		ahead.fill(idx+1, limit-processedCount-1)
+		var item Item
+		if restricted {
+			item = h.fallbackItem(feedItem)
+		} else if h.ItemTimeout > 0 || !params.Deadline.IsZero() {
+			item = h.extractWithin(feed, urlParam, feedItem, previous, h.itemBudget(params.Deadline), done, flow)
+		} else if done != nil {
+			item = <-done
+		} else {
+			item = h.extract(feedItem, flow)
+		}
+		item.attribute(feed, urlParam)
+
+		// Articles taken down are archived and written as tombstones
+		if item.Deleted {
+			if previous != nil && previous.Deleted {
+				item.DeletedAt = previous.DeletedAt
+			}
+			if h.Archive != nil {
+				h.Archive.Put(urlParam, item)
+			}
+			if err := fw.WriteItem(h.truncate(params.present(item))); err != nil {
+				return err
+			}
+			deletedCount++
+			continue
+		}
+
+		// Only archive items that produced content so failures are retried;
+		// until an update is extracted, the previous version is served
+		if item.Content != "" && !item.partial {
+			item = h.archiveItem(urlParam, previous, item)
+		} else if previous != nil {
+			item = *previous
+			item.attribute(feed, urlParam)
+		}
+		if item.Link != "" {
+			h.stabilizeGUID(urlParam, feedItem, &item, debug)
+		}
+
+		if !h.matchesSentiment(&item, params.Sentiment) {
+			skippedCount++
+			debug.skip(item.Link, "sentiment", "")
+			continue
+		}
+		if item.ItemType == "" {
+			item.ItemType = classifyItem(item)
+		}
+		if !params.wantsType(item.ItemType) {
+			skippedCount++
+			debug.skip(item.Link, "item_type", item.ItemType)
+			continue
+		}
+		written, err := write(item)
+		if err != nil {
+			return err
+		}
+		if !written {
+			continue
+		}
 		processedCount++
+		if item.partial {
+			partialCount++
+		}
 
 		log.Printf("✅ [%d/%d] Processed: %s (skipped: %d)", processedCount, limit, feedItem.Title, skippedCount)
 	}
 
-	data := map[string]any{
-		"feed_title":     feed.Title,
-		"feed_link":      feed.Link,
-		"items_returned": len(items),
-		"items_skipped":  skippedCount,
-		"items":          items,
+	if params.Cluster == clusterGrouped {
+		for _, item := range stories.items() {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+	}
+	if rollup != nil {
+		for _, item := range rollup.items() {
+			if err := fw.WriteItem(item); err != nil {
+				return err
+			}
+		}
 	}
 
-	jsonBytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		http.Error(w, "failed to serialize response", http.StatusInternalServerError)
-		return
+	// Items retracted from the feed follow as tombstones
+	if h.Tombstones && h.Archive != nil {
+		for _, item := range h.removedItems(feed, urlParam) {
+			item.attribute(feed, urlParam)
+			if err := fw.WriteItem(h.truncate(params.present(item))); err != nil {
+				return err
+			}
+			deletedCount++
+		}
 	}
 
-	// Cache the JSON response
-	h.Cache.Set(cacheKey, string(jsonBytes))
+	return fw.End(feedSummary{Returned: processedCount, Skipped: skippedCount, Reused: reusedCount, Clustered: clusteredCount, Partial: partialCount, Deleted: deletedCount, Debug: debug})
+}
+
+// minArticleText is the amount of text below which an extraction is
+// considered poor.
+const minArticleText = 500
+
+// Patterns of cleanHTMLTags and removeHaberMerkezi, compiled once: both run
+// on every item, textLength on every extraction attempt.
+var (
+	htmlTagRegex           = regexp.MustCompile(`<[^>]*>`)
+	haberMerkeziParenRegex = regexp.MustCompile(`\s*\(\s*Haber\s+Merkezi\s*\)\s*$`)
+	haberMerkeziRegex      = regexp.MustCompile(`\s*Haber\s+Merkezi\s*$`)
+)
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Write(jsonBytes)
+// textLength returns the number of characters of text in an HTML fragment.
+func textLength(content string) int {
+	return len([]rune(cleanHTMLTags(content)))
+}
+
+// extractVariant runs the default extractor on the print and reader versions
+// of articleURL and returns the first one yielding enough text, or the one
+// yielding the most text if it beats have.
+func (h *FeedHandler) extractVariant(articleURL string, have int) (content, image string) {
+	extractor := h.Registry.Default()
+	if extractor == nil {
+		return "", ""
+	}
+	best := have
+	for _, variantURL := range h.Variants.URLs(articleURL) {
+		extracted, images, err := safeExtract(extractor, variantURL)
+		if err != nil {
+			continue
+		}
+		extracted = cleanHTMLContent(extracted)
+		if n := textLength(extracted); n > best {
+			best, content, image = n, extracted, ""
+			if len(images) > 0 {
+				image = images[0]
+			}
+			log.Printf("🖨️  Variant %s yielded %d characters for %s", variantURL, n, articleURL)
+			if n >= minArticleText {
+				break
+			}
+		}
+	}
+	return content, image
+}
+
+// matchesSentiment reports whether item has the wanted sentiment label,
+// scoring items archived before sentiment scoring was enabled.
+func (h *FeedHandler) matchesSentiment(item *Item, want string) bool {
+	if want == "" {
+		return true
+	}
+	if item.Sentiment == nil {
+		item.Sentiment = h.scoreSentiment(item.Title, item.Content)
+	}
+	return item.Sentiment != nil && item.Sentiment.Label == want
+}
+
+// scoreSentiment returns the tone of an article, or nil when scoring is
+// disabled or fails.
+func (h *FeedHandler) scoreSentiment(title, content string) *sentiment.Result {
+	if h.Sentiment == nil || content == "" {
+		return nil
+	}
+	res, err := h.Sentiment.Analyze(title + "\n" + cleanHTMLTags(content))
+	if err != nil {
+		log.Printf("⚠️  Sentiment scoring failed for %q: %v", title, err)
+		return nil
+	}
+	return &res
 }
 
 // getCategoryFromURL determines the category of a news article based on its URL
@@ -206,14 +1257,62 @@ func getCategoryFromURL(url string) string {
 	return "turkiye" // Default category
 }
 
-// processItem extracts content and image using registered extractors.
+// extract runs processItem in flow's turn for an extraction slot,
+// deduplicating the work across instances when running in distributed mode.
+func (h *FeedHandler) extract(feedItem *gofeed.Item, flow *extractionFlow) Item {
+	defer h.acquireExtraction(flow)()
+	if h.Cluster == nil || feedItem.Link == "" {
+		return h.processItem(feedItem)
+	}
+	return h.Cluster.Extract(sharedItemKey(feedItem), func() Item {
+		return h.processItem(feedItem)
+	})
+}
+
+// processItem extracts content and image using registered extractors,
+// unless the site's circuit is open or its bandwidth budget spent.
 func (h *FeedHandler) processItem(i *gofeed.Item) Item {
+	// Sites failing repeatedly are skipped until their circuit closes
+	skipExtraction := i.Link != "" && h.Breaker != nil && !h.Breaker.Allow(i.Link)
+	if skipExtraction {
+		log.Printf("🔌 Circuit open for %s, serving the feed's own content", i.Link)
+	}
+	if !skipExtraction && i.Link != "" && h.Bandwidth != nil && !h.Bandwidth.Allow(i.Link) {
+		skipExtraction = true
+	}
+	return h.buildItem(i, skipExtraction)
+}
+
+// fallbackItem builds the item from what the feed itself provides, without
+// fetching the article.
+func (h *FeedHandler) fallbackItem(i *gofeed.Item) Item {
+	return h.buildItem(i, true)
+}
+
+// buildItem converts a feed item, extracting its article unless
+// skipExtraction is set. Items built without extraction are marked partial.
+func (h *FeedHandler) buildItem(i *gofeed.Item, skipExtraction bool) Item {
 	content := i.Content
 	imageURL := ""
+	var images []string
+	var captions map[string]ItemImage
+	deleted := false
+	source := "feed"
+	method := "feed"
+	start := time.Now()
+	// trace records the steps of extraction for the playground
+	var trace []string
+	// extractErr is the error the extractor failed with, if it did
+	var extractErr error
+	if skipExtraction && i.Link != "" {
+		trace = append(trace, "extraction skipped: circuit open or bandwidth quota used")
+	}
 
 	// Create a map to pass feed item data to extractor
+	// Extractors that understand feed items get the parsed item as well
 	itemData := map[string]interface{}{
 		"link": i.Link,
+		"item": i,
 	}
 
 	// If the feed item has an image, add it to the data
@@ -222,33 +1321,96 @@ func (h *FeedHandler) processItem(i *gofeed.Item) Item {
 		imageURL = i.Image.URL
 	}
 
-	if i.Link != "" {
+	// Feeds shipping whole articles in content:encoded are not extracted again
+	fullText := i.Link != "" && !skipExtraction && h.isFullText(i)
+	if fullText {
+		log.Printf("📰 Feed carries the full text of %s, keeping it", i.Link)
+		content = cleanHTMLContent(content)
+		trace = append(trace, "feed carries the full text, not extracted")
+	}
+
+	// Photo galleries are extracted as their slides, the captions making
+	// up the content
+	var gallery []ItemImage
+	if i.Link != "" && !skipExtraction && !fullText && isGalleryURL(i.Link) {
+		if gallery = h.extractGallery(i.Link); gallery != nil {
+			log.Printf("🖼️  Extracted gallery of %d images: %s", len(gallery), i.Link)
+			content, imageURL = galleryContent(gallery), gallery[0].URL
+			source, method = "gallery", "gallery"
+		}
+		trace = append(trace, fmt.Sprintf("gallery URL: %d slides found", len(gallery)))
+	}
+	// Video pages are extracted as their video's metadata
+	var video *ItemVideo
+	if i.Link != "" && !skipExtraction && !fullText && gallery == nil && isVideoURL(i.Link) {
+		if video = h.extractVideo(i.Link); video != nil {
+			log.Printf("🎬 Extracted video: %s", i.Link)
+			content, imageURL = videoContent(video), video.ThumbnailURL
+			source, method = "video", "video"
+			trace = append(trace, "video URL: video metadata found")
+		} else {
+			trace = append(trace, "video URL: no video metadata found")
+		}
+	}
+	media := gallery != nil || video != nil
+
+	if i.Link != "" && !skipExtraction && !fullText && !media {
 		// Get appropriate extractor from registry
 		extractor := h.Registry.ForURL(i.Link)
 
 		// Log which extractor is being used
 		extractorType := fmt.Sprintf("%T", extractor)
 		log.Printf("🔍 Using extractor: %s for URL: %s", extractorType, i.Link)
+		trace = append(trace, fmt.Sprintf("extractor %s (%s)", extractorType, extractorMethod(extractor)))
 
 		// Extract content and images using the extractor with item data
-		extractedContent, extractedImages, err := extractor.Extract(itemData)
+		extractedContent, extractedImages, err := safeExtract(extractor, itemData)
+		// URLs the extractor turns down say nothing of the site's health
+		if h.Breaker != nil && !errors.Is(err, extractors.ErrFiltered) {
+			h.Breaker.Record(i.Link, err)
+		}
+		h.Monitor.RecordExtraction(i.Link, err)
+		extractErr = err
 		if err == nil {
+			trace = append(trace, fmt.Sprintf("extractor returned %d bytes of content and %d images", len(extractedContent), len(extractedImages)))
 			if extractedContent != "" {
+				// Captions are read before cleaning drops the figures
+				captions = captionedImages(extractedContent, i.Link)
 				content = cleanHTMLContent(extractedContent)
+				source, method = "extractor", extractorMethod(extractor)
 			}
-			if len(extractedImages) > 0 {
-				imageURL = extractedImages[0]
+			images = extractors.CollapseImageVariants(extractedImages)
+			if len(images) > 0 {
+				imageURL = images[0]
 				log.Printf("🖼️  Found image for %s: %s", i.Link, imageURL)
 			} else {
 				log.Printf("⚠️  No images found for URL: %s", i.Link)
 			}
+		} else if errors.Is(err, extractors.ErrFiltered) {
+			// Readability would extract the section or gallery page the
+			// extractor turned down
+			log.Printf("🚫 Extractor does not read %s: %v", i.Link, err)
+			trace = append(trace, fmt.Sprintf("extractor failed: %v; not falling back to readability", err))
+		} else if h.Tombstones && h.articleGone(i.Link, err) {
+			log.Printf("🪦 Article is gone: %s", i.Link)
+			deleted = true
+			trace = append(trace, fmt.Sprintf("extractor failed: %v; the article is gone", err))
+		} else if extractors.SiteDown(err) {
+			// Readability would fetch the page from the same failing site
+			log.Printf("⚠️  Site of %s is down, not using readability: %v", i.Link, err)
+			trace = append(trace, fmt.Sprintf("extractor failed: %v; the site is down, not falling back to readability", err))
 		} else {
 			// Fallback to readability
 			log.Printf("⚠️  Extractor failed for %s, using readability: %v", i.Link, err)
+			trace = append(trace, fmt.Sprintf("extractor failed: %v", err))
 			if content == "" {
-				article, err := readability.FromURL(i.Link, 15*time.Second)
-				if err == nil {
+				article, err := h.readArticle(i.Link)
+				if err != nil {
+					trace = append(trace, fmt.Sprintf("readability fallback failed: %v", err))
+				} else {
 					content = cleanHTMLContent(article.Content)
+					source, method = "readability", "readability"
+					trace = append(trace, fmt.Sprintf("readability fallback returned %d bytes of content", len(article.Content)))
 					// Try to extract images from the readability content
 					doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content))
 					if err == nil {
@@ -266,6 +1428,43 @@ func (h *FeedHandler) processItem(i *gofeed.Item) Item {
 		}
 	}
 
+	// Poor extractions are retried on print and reader versions of the page
+	if i.Link != "" && !skipExtraction && !fullText && !media && h.Variants != nil {
+		if have := textLength(content); have < minArticleText {
+			trace = append(trace, fmt.Sprintf("%d characters of text are under %d, trying print and reader versions", have, minArticleText))
+			if variantContent, variantImage := h.extractVariant(i.Link, have); variantContent != "" {
+				content = variantContent
+				source, method = "variant", "variant"
+				trace = append(trace, fmt.Sprintf("a print or reader version has %d characters of text", textLength(content)))
+				if imageURL == "" {
+					imageURL = variantImage
+				}
+			}
+		}
+	}
+
+	// Dead links are recovered from the Wayback Machine
+	var archivedURL, archivedAt string
+	if i.Link != "" && !skipExtraction && !media && h.Wayback && strings.TrimSpace(content) == "" {
+		if snapshotContent, snapshotImage, snapshotURL, at := h.extractFromWayback(i.Link); snapshotContent != "" {
+			content, archivedURL, source, method = snapshotContent, snapshotURL, "wayback", "wayback"
+			trace = append(trace, "recovered from the Wayback Machine snapshot "+snapshotURL)
+			if !at.IsZero() {
+				archivedAt = at.Format(time.RFC3339)
+			}
+			if imageURL == "" {
+				imageURL = snapshotImage
+			}
+		}
+	}
+
+	var extraction *ItemExtraction
+	if fullText {
+		extraction = &ItemExtraction{Method: "feed", Confidence: fullTextConfidence}
+	} else if i.Link != "" && !skipExtraction {
+		extraction = newItemExtraction(method, content, time.Since(start))
+	}
+
 	// If we still don't have an image, try to get it from the feed item's enclosures
 	if imageURL == "" && len(i.Enclosures) > 0 {
 		for _, enc := range i.Enclosures {
@@ -283,168 +1482,152 @@ func (h *FeedHandler) processItem(i *gofeed.Item) Item {
 		log.Printf("🖼️  Found image from feed item: %s", imageURL)
 	}
 
+	// Stock images are swapped whichever extractor found them
+	if h.ImageRules != nil {
+		imageURL = h.ImageRules.Apply(i.Link, imageURL)
+		for k, u := range images {
+			images[k] = h.ImageRules.Apply(i.Link, u)
+		}
+	}
+
 	// Determine category from URL
 	category := getCategoryFromURL(i.Link)
 
+	commentsURL := itemCommentsURL(i)
+	if commentsURL == "" && h.DetectComments && i.Link != "" && !skipExtraction {
+		commentsURL = h.detectCommentsURL(i.Link)
+	}
+
 	// Clean HTML tags from description
 	cleanDescription := cleanHTMLTags(i.Description)
 	log.Printf("🧹 Cleaned description (original length: %d, cleaned length: %d)", len(i.Description), len(cleanDescription))
 
-	// Remove "(Haber Merkezi)" from content
-	cleanContent := removeHaberMerkezi(strings.TrimSpace(content))
+	title := h.TextRules.Apply(i.Link, extractors.FieldTitle, normalizeTitle(i.Title, i.Link))
+	cleanContent := h.cleanArticle(i.Link, content)
+	if h.NormalizeTurkish {
+		title = normalizeTurkish(title)
+		cleanDescription = normalizeTurkish(cleanDescription)
+	}
 	log.Printf("🧹 Cleaned content (original length: %d, cleaned length: %d)", len(content), len(cleanContent))
+	trace = append(trace, fmt.Sprintf("content from %s: %d bytes, %d after cleaning", source, len(content), len(cleanContent)))
 
-	return Item{
-		Title:       i.Title,
-		Link:        i.Link,
-		GUID:        extractors.GenerateGUIDFromURL(i.Link),
-		Published:   formatTime(i.PublishedParsed),
-		Description: cleanDescription,
-		Content:     cleanContent,
-		Image:       imageURL,
-		Category:    category,
+	// Undated items take their update date or the one on the article page,
+	// unless the extractor found the page gone, turned down or its site down
+	published := i.PublishedParsed
+	if published == nil {
+		published = i.UpdatedParsed
+	}
+	pageUnreadable := deleted || errors.Is(extractErr, extractors.ErrFiltered) || extractors.SiteDown(extractErr)
+	if published == nil && i.Link != "" && !skipExtraction && !pageUnreadable {
+		published = h.pageDate(i.Link)
 	}
-}
 
-func formatTime(t *time.Time) string {
-	if t == nil {
-		return ""
+	item := Item{
+		Title:           title,
+		Link:            i.Link,
+		GUID:            extractors.GenerateGUIDFromURL(i.Link),
+		Published:       formatTime(published),
+		UpdatedAt:       formatTime(i.UpdatedParsed),
+		Description:     cleanDescription,
+		Content:         cleanContent,
+		Image:           imageURL,
+		Images:          itemImages(imageURL, images, captions),
+		Category:        category,
+		Author:          itemAuthor(i),
+		Categories:      i.Categories,
+		CategoryDomains: itemCategoryDomains(i),
+		DublinCore:      itemDublinCore(i),
+		Enclosures:      itemEnclosures(i),
+		ITunes:          itemITunes(i),
+		Location:        h.itemLocation(i, cleanContent, i.Link != "" && !skipExtraction && !deleted),
+		CommentsURL:     commentsURL,
+		ArchivedURL:     archivedURL,
+		ArchivedAt:      archivedAt,
+		Extraction:      extraction,
+		partial:         skipExtraction && i.Link != "",
+		source:          source,
+		trace:           trace,
+		err:             extractErr,
 	}
-	return t.Format(time.RFC3339)
+	if gallery != nil {
+		item.Images, item.Gallery = gallery, true
+	}
+	item.Video = video
+	if !deleted {
+		item.ItemType = classifyItem(item)
+	}
+	if title != i.Title {
+		item.OriginalTitle = i.Title
+	}
+	if deleted {
+		item.Deleted, item.DeletedAt = true, h.now().Format(time.RFC3339)
+	}
+	if h.OEmbed && i.Link != "" && !skipExtraction && !deleted {
+		if embed := h.fetchOEmbed(i.Link); embed != nil {
+			embed.HTML = h.LinkRules.Apply(embed.HTML)
+			item.mergeEmbed(embed)
+		}
+	}
+	return h.analyze(item)
 }
 
-// cleanHTMLContent cleans and normalizes HTML content by removing unwanted elements
-// and ensuring proper UTF-8 encoding
-func cleanHTMLContent(htmlContent string) string {
-	// Return early if content is empty or whitespace only
-	if strings.TrimSpace(htmlContent) == "" {
-		return ""
+// cleanArticle applies the rewrite rules to extracted content, removes
+// agency credits and boilerplate from it and rewrites its links.
+func (h *FeedHandler) cleanArticle(link, content string) string {
+	// Rewrite rules run before agency credits like "(Haber Merkezi)" are
+	// stripped, so they can replace them
+	content = h.TextRules.Apply(link, extractors.FieldContent, content)
+	content = removeHaberMerkezi(strings.TrimSpace(content))
+	content = h.Boilerplate.Strip(link, content)
+	content = h.LinkRules.Apply(content)
+	if h.NormalizeTurkish {
+		content = normalizeTurkishHTML(content)
 	}
+	return content
+}
 
-	// Remove <html><body> and </body></html> tags
-	htmlContent = strings.ReplaceAll(htmlContent, "<html><body>", "")
-	htmlContent = strings.ReplaceAll(htmlContent, "</body></html>", "")
+// analyze sets the tags, companies, tickers and sentiment read from item's
+// title and content.
+func (h *FeedHandler) analyze(item Item) Item {
+	item.Tags, item.Companies, item.Tickers = nil, nil, nil
+	if h.ExtractTags && item.Content != "" {
+		item.Tags = extractTags(item.Title, item.Content)
+	}
+	if h.Entities != nil && item.Content != "" && hostInDomains(item.Link, h.EntityDomains) {
+		found := h.Entities.Recognize(item.Title + "\n" + cleanHTMLTags(item.Content))
+		item.Companies, item.Tickers = found.Companies, found.Tickers
+	}
+	item.Sentiment = h.scoreSentiment(item.Title, item.Content)
+	return item
+}
 
-	// Create a new document from the HTML content
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+// hostInDomains reports whether link's host is one of domains or a
+// subdomain of one.
+func hostInDomains(link string, domains []string) bool {
+	u, err := url.Parse(link)
 	if err != nil {
-		return htmlContent
-	}
-
-	// Remove unwanted elements (scripts, styles, ads, etc.)
-	unwantedSelectors := []string{
-		// Basic elements
-		"script", "style", "iframe", "noscript", "object", "embed", "video", "audio",
-		"form", "input", "button", "select", "textarea", "label", "fieldset",
-		"header", "footer", "nav", "aside", "menu", "dialog", "figure", "figcaption",
-		
-		// Ads and tracking
-		".ad", ".advertisement", ".ad-container", ".ad-wrapper", ".ad-banner", 
-		".ad-header", ".ad-sidebar", ".ad-slot", ".ad-unit", ".advert",
-		".social-share", ".social-likes", ".sharing", ".share-buttons",
-		".related-news", ".related-posts", ".related-articles", ".recommended",
-		".popular-posts", ".trending", ".newsletter", ".subscribe",
-		".tags", ".tag-cloud", ".post-tags", ".post-meta", ".post-footer",
-		".author", ".byline", ".post-date", ".timestamp", ".comments",
-	}
-
-	// Add dynamic selectors for common ad patterns
-	for _, sel := range unwantedSelectors {
-		doc.Find(sel).Remove()
-	}
-
-	// Clean up divs and other elements
-	doc.Find("div, section, article, main").Each(func(i int, s *goquery.Selection) {
-		// Get element attributes
-		class, _ := s.Attr("class")
-		id, _ := s.Attr("id")
-		role, _ := s.Attr("role")
-		
-		// Check for unwanted elements
-		isUnwanted := false
-		
-		// Check class and ID patterns
-		lowerClass := strings.ToLower(class)
-		lowerId := strings.ToLower(id)
-		
-		unwantedPatterns := []string{
-			"ad", "banner", "sponsor", "recommend", "related", "popular",
-			"widget", "sidebar", "sticky", "modal", "popup", "newsletter",
-			"subscribe", "social", "share", "comment", "cookie", "consent",
-			"notification", "alert", "promo", "teaser", "recommendation",
-			"trending", "most-viewed", "most-read", "signup",
-		}
-		
-		for _, pattern := range unwantedPatterns {
-			if strings.Contains(lowerClass, pattern) || strings.Contains(lowerId, pattern) {
-				isUnwanted = true
-				break
-			}
-		}
-		
-		// Check for empty or nearly empty elements
-		text := strings.TrimSpace(s.Text())
-		if !isUnwanted && text == "" && s.Children().Length() == 0 {
-			isUnwanted = true
-		}
-		
-		// Check for common ad roles
-		if role == "banner" || role == "complementary" || role == "contentinfo" {
-			isUnwanted = true
-		}
-		
-		// Remove if unwanted
-		if isUnwanted {
-			s.Remove()
-		}
-	})
-
-	// Clean up empty elements
-	doc.Find("p, span, div, section, article, header, footer, aside").Each(func(i int, s *goquery.Selection) {
-		text := strings.TrimSpace(s.Text())
-		if text == "" && s.Children().Length() == 0 {
-			s.Remove()
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
 		}
-	})
-
-	// Normalize whitespace in text nodes
-	doc.Find("body").Each(func(i int, s *goquery.Selection) {
-		s.Contents().Each(func(i int, node *goquery.Selection) {
-			if node.Nodes[0].Type == 1 { // ElementNode
-				// Skip non-text nodes
-				return
-			}
-			if node.Nodes[0].Type == 3 { // TextNode
-				node.ReplaceWithHtml(strings.Join(strings.Fields(node.Text()), " "))
-			}
-		})
-	})
-
-	// Get the cleaned HTML
-	htmlStr, err := doc.Html()
-	if err != nil {
-		return htmlContent
 	}
+	return false
+}
 
-	// Decode HTML entities and normalize
-	htmlStr = html.UnescapeString(htmlStr)
-	
-	// Clean up whitespace
-	htmlStr = regexp.MustCompile(`\s+`).ReplaceAllString(htmlStr, " ")
-	htmlStr = strings.TrimSpace(htmlStr)
-	
-	// Remove any remaining empty HTML tags
-	htmlStr = regexp.MustCompile(`<\w+\s*(?:[^>]*)>\s*<\/\w+>`).ReplaceAllString(htmlStr, "")
-	htmlStr = regexp.MustCompile(`<\w+\s*(?:[^>]*)>\s*<\/\w+\s*>`).ReplaceAllString(htmlStr, "")
-
-	return htmlStr
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
 }
 
 // cleanHTMLTags removes HTML tags from text and decodes HTML entities
 func cleanHTMLTags(text string) string {
 	// Remove HTML tags using regex
-	re := regexp.MustCompile(`<[^>]*>`)
-	text = re.ReplaceAllString(text, "")
+	text = htmlTagRegex.ReplaceAllString(text, "")
 
 	// Decode HTML entities
 	text = html.UnescapeString(text)
@@ -459,12 +1642,10 @@ func cleanHTMLTags(text string) string {
 // removeHaberMerkezi removes "(Haber Merkezi)" from the end of content
 func removeHaberMerkezi(content string) string {
 	// Remove "(Haber Merkezi)" with various whitespace patterns
-	re := regexp.MustCompile(`\s*\(\s*Haber\s+Merkezi\s*\)\s*$`)
-	content = re.ReplaceAllString(content, "")
+	content = haberMerkeziParenRegex.ReplaceAllString(content, "")
 
 	// Also remove without parentheses
-	re2 := regexp.MustCompile(`\s*Haber\s+Merkezi\s*$`)
-	content = re2.ReplaceAllString(content, "")
+	content = haberMerkeziRegex.ReplaceAllString(content, "")
 
 	return strings.TrimSpace(content)
 }
\ No newline at end of file
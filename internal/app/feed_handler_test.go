@@ -0,0 +1,264 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+// doerFunc adapts a function to fetch.Doer.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+// respond builds a response with the given status and body.
+func respond(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+const testFeedURL = "https://news.example.com/rss"
+
+const testFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Example News</title><link>https://news.example.com/</link><description>Test feed</description>
+<item><title>First</title><link>https://news.example.com/a/1</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+<item><title>Second</title><link>https://news.example.com/a/2</link><pubDate>Mon, 01 Jan 2024 11:00:00 +0000</pubDate></item>
+</channel></rss>`
+
+// newTestFeedHandler returns a handler whose items are already archived, so
+// rendering never runs extraction against the network.
+func newTestFeedHandler(doer doerFunc, clock Clock, stale time.Duration) *FeedHandler {
+	cache := NewCache(time.Minute, stale)
+	cache.SetClock(clock)
+	archive := NewArchive(0)
+	for i := 1; i <= 2; i++ {
+		link := fmt.Sprintf("https://news.example.com/a/%d", i)
+		archive.Put(testFeedURL, Item{
+			Title:   fmt.Sprintf("Item %d", i),
+			Link:    link,
+			GUID:    extractors.GenerateGUIDFromURL(link),
+			Content: "<p>archived</p>",
+		})
+	}
+	h := NewFeedHandler(cache, doer, extractors.NewRegistry(), filters.NewFilterRegistry(), archive, nil)
+	h.Clock = clock
+	return h
+}
+
+func TestFetchFeedClassifiesUpstreamFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		doer   doerFunc
+		status int
+		code   string
+	}{
+		{
+			name:   "not found",
+			doer:   func(*http.Request) (*http.Response, error) { return respond(http.StatusNotFound, "missing"), nil },
+			status: http.StatusBadGateway,
+			code:   CodeUpstreamClientError,
+		},
+		{
+			name: "server error",
+			doer: func(*http.Request) (*http.Response, error) {
+				return respond(http.StatusServiceUnavailable, "down"), nil
+			},
+			status: http.StatusBadGateway,
+			code:   CodeUpstreamServerError,
+		},
+		{
+			name: "timeout",
+			doer: func(req *http.Request) (*http.Response, error) {
+				return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: context.DeadlineExceeded}
+			},
+			status: http.StatusGatewayTimeout,
+			code:   CodeUpstreamTimeout,
+		},
+		{
+			name:   "connection refused",
+			doer:   func(*http.Request) (*http.Response, error) { return nil, errors.New("connection refused") },
+			status: http.StatusBadGateway,
+			code:   CodeUpstreamUnreachable,
+		},
+		{
+			name:   "not a feed",
+			doer:   func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, "<html>hi</html>"), nil },
+			status: http.StatusBadGateway,
+			code:   CodeInvalidFeed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestFeedHandler(tt.doer, newFakeClock(), 0)
+			_, err := h.fetchFeed(testFeedURL, false)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("fetchFeed() error = %v; want *APIError", err)
+			}
+			if apiErr.Status != tt.status || apiErr.Code != tt.code {
+				t.Errorf("fetchFeed() = %d %s; want %d %s", apiErr.Status, apiErr.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestFeedHandlerCachesUntilExpiry(t *testing.T) {
+	var calls atomic.Int32
+	doer := func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return respond(http.StatusOK, testFeed), nil
+	}
+	clock := newFakeClock()
+	h := newTestFeedHandler(doer, clock, 0)
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?url="+url.QueryEscape(testFeedURL), nil))
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first request: %d %s", rec.Code, rec.Header().Get("X-Cache"))
+	}
+	clock.Advance(30 * time.Second)
+	rec := get()
+	if rec.Header().Get("X-Cache") != "HIT" || rec.Header().Get("Age") != "30" {
+		t.Errorf("second request: X-Cache %s, Age %s; want HIT aged 30", rec.Header().Get("X-Cache"), rec.Header().Get("Age"))
+	}
+	if !strings.Contains(rec.Body.String(), `"items_reused": 2`) {
+		t.Errorf("items were not served from the archive: %s", rec.Body.String())
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream fetched %d times before expiry; want 1", n)
+	}
+
+	clock.Advance(time.Minute)
+	if rec := get(); rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("request after expiry: X-Cache %s; want MISS", rec.Header().Get("X-Cache"))
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("upstream fetched %d times; want 2", n)
+	}
+}
+
+func TestFeedHandlerServesStaleAndRevalidates(t *testing.T) {
+	var calls atomic.Int32
+	refreshed := make(chan struct{}, 1)
+	doer := func(*http.Request) (*http.Response, error) {
+		if calls.Add(1) == 2 {
+			defer func() { refreshed <- struct{}{} }()
+		}
+		return respond(http.StatusOK, testFeed), nil
+	}
+	clock := newFakeClock()
+	h := newTestFeedHandler(doer, clock, time.Hour)
+	target := "/feed?format=rss&url=" + url.QueryEscape(testFeedURL)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	clock.Advance(2 * time.Minute)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	if rec.Header().Get("X-Cache") != "STALE" {
+		t.Fatalf("X-Cache = %s; want STALE", rec.Header().Get("X-Cache"))
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale entry was not revalidated")
+	}
+	// The refreshed entry is stamped with the fake clock's time
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, age, fresh, ok := h.Cache.GetStale(feedCacheKey(testFeedURL, 10, formatRSS)); ok && fresh && age == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("revalidated entry was not stored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFeedHandlerRSSUsesClock(t *testing.T) {
+	clock := newFakeClock()
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, testFeed), nil
+	}, clock, 0)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?format=rss&url="+url.QueryEscape(testFeedURL), nil))
+
+	want := "<lastBuildDate>" + clock.Now().Format(time.RFC1123Z) + "</lastBuildDate>"
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("RSS output lacks %s:\n%s", want, rec.Body.String())
+	}
+}
+
+func TestFeedCacheKeyCanonical(t *testing.T) {
+	base := feedParams{URL: "https://news.example.com/rss?b=2&a=1", Limit: 10, Format: formatJSON}
+	same := base
+	same.URL = "HTTPS://News.Example.com:443/rss?a=1&b=2#top"
+	same.Deadline = time.Now()
+	if base.cacheKey() != same.cacheKey() {
+		t.Errorf("equivalent requests got different keys:\n%s\n%s", base.cacheKey(), same.cacheKey())
+	}
+
+	variants := []feedParams{base, base, base, base, base}
+	variants[0].Limit = 5
+	variants[1].Format = formatRSS
+	variants[2].Sentiment = "negative"
+	variants[3].Cluster = clusterGrouped
+	variants[4].Reemit = true
+	seen := map[string]bool{base.cacheKey(): true}
+	for _, v := range variants {
+		key := v.cacheKey()
+		if seen[key] {
+			t.Errorf("key %s collides", key)
+		}
+		seen[key] = true
+	}
+	if !strings.HasPrefix(base.cacheKey(), fmt.Sprintf("v%d|", cacheKeyVersion)) {
+		t.Errorf("key %s lacks the version", base.cacheKey())
+	}
+}
+
+func TestFeedHandlerNegotiatesFormat(t *testing.T) {
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, testFeed), nil
+	}, newFakeClock(), 0)
+	// The second request is served from the cache
+	for range 2 {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL), nil)
+		r.Header.Set("Accept", "application/atom+xml")
+		h.ServeHTTP(rec, r)
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+			t.Errorf("Content-Type = %q (X-Cache %s)", ct, rec.Header().Get("X-Cache"))
+		}
+		if !strings.Contains(strings.Join(rec.Header().Values("Vary"), ","), "Accept") {
+			t.Errorf("Vary = %q", rec.Header().Values("Vary"))
+		}
+		if !strings.Contains(rec.Body.String(), "<feed xmlns=\"http://www.w3.org/2005/Atom\"") {
+			t.Errorf("body is not Atom:\n%s", rec.Body)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// internal/app/feed_post.go
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// maxFeedBodySize bounds the JSON body of POST /feed.
+const maxFeedBodySize = 64 << 10
+
+// maxMergedFeeds is the most feeds one /feed request may merge.
+const maxMergedFeeds = 20
+
+// postedQuery reads the JSON body of POST /feed, an object of the query
+// parameters of GET /feed, into query values. Lists give a parameter several
+// values, as url does to merge feeds. Parameters of the body override those
+// of the URL.
+func postedQuery(w http.ResponseWriter, r *http.Request) (url.Values, *APIError) {
+	var body map[string]any
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFeedBodySize))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, CodeInvalidBody, "request body must be a JSON object of /feed parameters").with("error", err.Error())
+	}
+	query := r.URL.Query()
+	for param, value := range body {
+		values, ok := queryValues(value)
+		if !ok {
+			return nil, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"parameters must be strings, numbers, booleans or lists of them").with("parameter", param)
+		}
+		query[param] = values
+	}
+	return query, nil
+}
+
+// queryValues returns the query values of a JSON value, false for objects,
+// nested lists and null.
+func queryValues(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case json.Number:
+		return []string{v.String()}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			if _, nested := elem.([]any); nested {
+				return nil, false
+			}
+			value, ok := queryValues(elem)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value...)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// collectFeedWriter keeps the items of a rendered feed for merging.
+type collectFeedWriter struct {
+	items   []Item
+	summary feedSummary
+}
+
+func (c *collectFeedWriter) Begin(feedMeta) error { return nil }
+
+func (c *collectFeedWriter) WriteItem(item Item) error {
+	c.items = append(c.items, item)
+	return nil
+}
+
+func (c *collectFeedWriter) End(summary feedSummary) error {
+	c.summary = summary
+	return nil
+}
+
+// serveMerged renders several feeds as one: each feed is rendered with
+// params, then their items are merged newest first up to the limit.
+// Clustering applies across feeds so a story covered by several sources is
+// told once. Feeds failing upstream are left out unless all of them fail.
+// Merged feeds are cached while fresh but not refreshed in the background,
+// and are always rendered synchronously.
+func (h *FeedHandler) serveMerged(w http.ResponseWriter, r *http.Request, urls []string, params feedParams) {
+	start := h.now()
+	keys := make([]string, len(urls))
+	for i, feedURL := range urls {
+		p := params
+		p.URL = feedURL
+		keys[i] = p.cacheKey()
+	}
+	cacheKey := strings.Join(keys, "\n")
+
+	if cached, age, fresh, ok := h.Cache.GetStale(cacheKey); ok && fresh {
+		w.Header().Set("Content-Type", contentTypeFor(params.Format))
+		w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+		w.Header().Set("X-Cache", "HIT")
+		h.setBuildHeaders(w, cacheKey)
+		writeCachedBody(w, r, h.Cache, cacheKey, []byte(cached))
+		return
+	}
+	if h.shed(w, r) {
+		return
+	}
+
+	feeds := make([]*gofeed.Feed, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, feedURL := range urls {
+		wg.Go(func() { feeds[i], errs[i] = h.cachedFeed(feedURL, params.Strict) })
+	}
+	wg.Wait()
+
+	// Each feed is rendered without clustering, rollup or offset, which are
+	// applied on the merge
+	perFeed := params
+	perFeed.Cluster, perFeed.Rollup = "", ""
+	perFeed.Limit, perFeed.Offset = params.Limit+params.Offset, 0
+	if h.Deadline > 0 {
+		perFeed.Deadline = start.Add(h.Deadline)
+	}
+	var (
+		items   []Item
+		titles  []string
+		summary feedSummary
+		debug   *feedDebug
+	)
+	if params.Debug {
+		debug = &feedDebug{Skipped: []debugSkip{}, Items: []debugItem{}}
+	}
+	for i, feed := range feeds {
+		if errs[i] != nil {
+			log.Printf("⚠️  Leaving %s out of the merged feed: %v", urls[i], errs[i])
+			continue
+		}
+		perFeed.URL = urls[i]
+		var fc collectFeedWriter
+		if err := h.render(feed, perFeed, &fc); err != nil {
+			log.Printf("⚠️  Failed to render %s for the merged feed: %v", urls[i], err)
+			continue
+		}
+		items = append(items, fc.items...)
+		titles = append(titles, feed.Title)
+		summary.Skipped += fc.summary.Skipped
+		summary.Reused += fc.summary.Reused
+		if debug != nil && fc.summary.Debug != nil {
+			debug.Skipped = append(debug.Skipped, fc.summary.Debug.Skipped...)
+			debug.GUIDs = append(debug.GUIDs, fc.summary.Debug.GUIDs...)
+		}
+	}
+	if len(titles) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+	}
+	slices.SortStableFunc(items, func(x, y Item) int {
+		return publishedTime(y).Compare(publishedTime(x))
+	})
+
+	w.Header().Set("Content-Type", contentTypeFor(params.Format))
+	w.Header().Set("X-Cache", "MISS")
+	flush := func() {}
+	if f, ok := w.(http.Flusher); ok {
+		flush = f.Flush
+	}
+	var buf bytes.Buffer
+	fw := newFeedWriter(params.Format, io.MultiWriter(w, &buf), flush)
+	if err := h.writeMerged(fw, feedMeta{Title: strings.Join(titles, ", "), Updated: h.now(), SchemaVersion: params.Version}, items, params, &summary, debug); err != nil {
+		log.Printf("⚠️  Failed to stream merged feed: %v", err)
+		return
+	}
+	if summary.Partial > 0 {
+		log.Printf("⏱️  Not caching merged feed: %d items without extraction", summary.Partial)
+		return
+	}
+	h.cacheFeed(cacheKey, buf.String(), summary)
+}
+
+// writeMerged writes the merged items through fw up to the limit, past the
+// offset, clustering and rolling them up when asked, and accounts them in
+// summary.
+// Tombstones do not count toward the limit or the offset.
+func (h *FeedHandler) writeMerged(fw feedWriter, meta feedMeta, items []Item, params feedParams, summary *feedSummary, debug *feedDebug) error {
+	if err := fw.Begin(meta); err != nil {
+		return err
+	}
+	var stories *storyGroups
+	if params.Cluster != "" {
+		stories = &storyGroups{}
+	}
+	var rollup *rollup
+	if params.Rollup != "" {
+		rollup = h.newRollup(params.Rollup, params.URL, meta)
+	}
+	skip := params.Offset
+	for _, item := range items {
+		if !item.Deleted {
+			if summary.Returned >= params.Limit {
+				continue
+			}
+			if stories != nil && !stories.add(item) {
+				summary.Clustered++
+				debug.skip(item.Link, "clustered", "")
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			summary.Returned++
+			if item.partial {
+				summary.Partial++
+			}
+			debug.item(item.Link, item.source)
+			if params.Cluster == clusterGrouped {
+				continue
+			}
+			if rollup != nil {
+				rollup.add(item)
+				continue
+			}
+		} else {
+			summary.Deleted++
+		}
+		if err := fw.WriteItem(item); err != nil {
+			return err
+		}
+	}
+	if params.Cluster == clusterGrouped {
+		for _, item := range stories.items() {
+			if rollup != nil {
+				rollup.add(item)
+				continue
+			}
+			if err := fw.WriteItem(item); err != nil {
+				return err
+			}
+		}
+	}
+	if rollup != nil {
+		for _, item := range rollup.items() {
+			if err := fw.WriteItem(item); err != nil {
+				return err
+			}
+		}
+	}
+	summary.Debug = debug
+	return fw.End(*summary)
+}
+
+// publishedTime returns when item was published, the zero time when unknown
+// so undated items sort last.
+func publishedTime(item Item) time.Time {
+	t, err := time.Parse(time.RFC3339, item.Published)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// feedURLs returns the trimmed url parameters of a /feed request, at least
+// one and at most maxMergedFeeds.
+func feedURLs(query url.Values) ([]string, *APIError) {
+	urls := query["url"]
+	if len(urls) == 0 {
+		urls = []string{""}
+	}
+	if len(urls) > maxMergedFeeds {
+		return nil, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("at most %d feeds can be merged", maxMergedFeeds)).with("parameter", "url")
+	}
+	trimmed := make([]string, len(urls))
+	for i, u := range urls {
+		trimmed[i] = strings.TrimSpace(u)
+		if _, apiErr := validateTargetURL("url", trimmed[i]); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+	return trimmed, nil
+}
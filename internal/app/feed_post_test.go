@@ -0,0 +1,106 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gofull/internal/extractors"
+)
+
+const otherFeedURL = "https://other.example.com/rss"
+
+const otherFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Other News</title><link>https://other.example.com/</link>
+<item><title>Middle</title><link>https://other.example.com/b/1</link><pubDate>Mon, 01 Jan 2024 10:30:00 +0000</pubDate></item>
+</channel></rss>`
+
+// newMergeTestHandler serves testFeed and otherFeed with their items
+// archived with publication dates.
+func newMergeTestHandler() *FeedHandler {
+	h := newTestFeedHandler(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == otherFeedURL {
+			return respond(http.StatusOK, otherFeed), nil
+		}
+		return respond(http.StatusOK, testFeed), nil
+	}, newFakeClock(), 0)
+	for _, item := range []Item{
+		{Title: "First", Link: "https://news.example.com/a/1", Published: "2024-01-01T10:00:00Z"},
+		{Title: "Second", Link: "https://news.example.com/a/2", Published: "2024-01-01T11:00:00Z"},
+	} {
+		item.GUID = extractors.GenerateGUIDFromURL(item.Link)
+		item.Content = "<p>archived</p>"
+		h.Archive.Put(testFeedURL, item)
+	}
+	h.Archive.Put(otherFeedURL, Item{Title: "Middle", Link: "https://other.example.com/b/1", GUID: extractors.GenerateGUIDFromURL("https://other.example.com/b/1"),
+		Published: "2024-01-01T10:30:00Z", Content: "<p>archived</p>"})
+	return h
+}
+
+func feedTitles(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct{ Items []Item }
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, item := range resp.Items {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func TestPostFeedMatchesGet(t *testing.T) {
+	h := newMergeTestHandler()
+	get := httptest.NewRecorder()
+	h.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/feed?format=json&limit=1&url="+testFeedURL, nil))
+
+	post := httptest.NewRecorder()
+	body := `{"url": "` + testFeedURL + `", "limit": 1, "format": "json", "debug": false}`
+	h.ServeHTTP(post, httptest.NewRequest(http.MethodPost, "/feed", strings.NewReader(body)))
+
+	if g, p := feedTitles(t, get), feedTitles(t, post); strings.Join(g, ",") != strings.Join(p, ",") {
+		t.Errorf("POST returned %v; GET returned %v", p, g)
+	}
+}
+
+func TestPostFeedMerge(t *testing.T) {
+	h := newMergeTestHandler()
+	rec := httptest.NewRecorder()
+	body := `{"url": ["` + testFeedURL + `", "` + otherFeedURL + `"], "format": "json"}`
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/feed", strings.NewReader(body)))
+
+	if got := strings.Join(feedTitles(t, rec), ","); got != "Second,Middle,First" {
+		t.Errorf("merged items %s; want Second,Middle,First", got)
+	}
+
+	// The same merge over GET is served from the cache
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+"&url="+otherFeedURL, nil))
+	if rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("X-Cache = %q; want HIT", rec.Header().Get("X-Cache"))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&limit=2&url="+testFeedURL+"&url="+otherFeedURL, nil))
+	if got := strings.Join(feedTitles(t, rec), ","); got != "Second,Middle" {
+		t.Errorf("merged items %s; want Second,Middle", got)
+	}
+}
+
+func TestPostFeedInvalidBody(t *testing.T) {
+	h := newMergeTestHandler()
+	for _, body := range []string{`not json`, `{"url": {"nested": true}}`, `{"url": [["` + testFeedURL + `"]]}`, `{"url": null}`} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/feed", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d; want 400", body, rec.Code)
+		}
+	}
+}
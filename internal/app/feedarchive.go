@@ -0,0 +1,132 @@
+// internal/app/feedarchive.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// historyNamespace is the XML namespace of RFC 5005 feed history, marking
+// archive pages.
+const historyNamespace = "http://purl.org/syndication/history/1.0"
+
+// archivePageSize is the number of archived items per archive page.
+const archivePageSize = 20
+
+// archivePages returns the number of complete archive pages of feedURL and
+// the first one whose items were not all dropped from the archive since.
+func (h *FeedHandler) archivePages(feedURL string) (pages, first int) {
+	if h.Archive == nil {
+		return 0, 0
+	}
+	stored, dropped := h.Archive.Stored(feedURL)
+	return stored / archivePageSize, dropped/archivePageSize + 1
+}
+
+// archiveLinks returns the RFC 5005 links of the feed at feedURL rendered in
+// format: prev-archive to its newest archive page, none until a page is
+// complete.
+func (h *FeedHandler) archiveLinks(feedURL, format string) []feedLink {
+	pages, first := h.archivePages(feedURL)
+	if pages < first {
+		return nil
+	}
+	return []feedLink{{Rel: "prev-archive", Href: h.archivePageURL(feedURL, format, pages)}}
+}
+
+// archivePageURL returns the link to page of the archive of feedURL, on
+// PublicURL or relative when it is not set.
+func (h *FeedHandler) archivePageURL(feedURL, format string, page int) string {
+	query := url.Values{"url": {feedURL}, "page": {strconv.Itoa(page)}}
+	if format != formatJSON {
+		query.Set("format", format)
+	}
+	return strings.TrimSuffix(h.PublicURL, "/") + "/feed/archive?" + query.Encode()
+}
+
+// feedPageURL returns the link to the feed at feedURL rendered in format,
+// the subscription document of its archive.
+func (h *FeedHandler) feedPageURL(feedURL, format string) string {
+	query := url.Values{"url": {feedURL}}
+	if format != formatJSON {
+		query.Set("format", format)
+	}
+	return strings.TrimSuffix(h.PublicURL, "/") + "/feed?" + query.Encode()
+}
+
+// handleFeedArchive serves GET /feed/archive, the RFC 5005 archive pages of
+// a feed: the items archived for it, archivePageSize per page numbered from
+// the oldest. Only complete pages are served, so a page never changes and
+// readers can walk back through the history past the upstream feed's items.
+func (s *Server) handleFeedArchive(w http.ResponseWriter, r *http.Request) {
+	h := s.feedHandler
+	urls, apiErr := feedURLs(r.URL.Query())
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if len(urls) > 1 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"archives are kept per feed, pass a single url").with("parameter", "url"))
+		return
+	}
+	feedURL := urls[0]
+
+	w.Header().Add("Vary", "Accept")
+	format, apiErr := outputFormat(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	pageStr := r.URL.Query().Get("page")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page <= 0 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"'page' must be a positive integer").with("parameter", "page").with("value", pageStr))
+		return
+	}
+	pages, first := h.archivePages(feedURL)
+	if page < first || page > pages {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound,
+			fmt.Sprintf("archive page %d of this feed is not available", page)).with("page", page).with("pages", pages))
+		return
+	}
+
+	links := []feedLink{{Rel: "current", Href: h.feedPageURL(feedURL, format)}}
+	if page > first {
+		links = append(links, feedLink{Rel: "prev-archive", Href: h.archivePageURL(feedURL, format, page-1)})
+	}
+	if page < pages {
+		links = append(links, feedLink{Rel: "next-archive", Href: h.archivePageURL(feedURL, format, page+1)})
+	}
+	items := h.Archive.Span(feedURL, (page-1)*archivePageSize, page*archivePageSize)
+
+	w.Header().Set("Content-Type", contentTypeFor(format))
+	// The page does not change, but items may still be dropped from it
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	fw := newFeedWriter(format, w, nil)
+	title := feedURL
+	if len(items) > 0 && items[0].SourceName != "" {
+		title = items[0].SourceName
+	}
+	meta := feedMeta{
+		Title:       title,
+		Description: fmt.Sprintf("Archive page %d of %d", page, pages),
+		Updated:     h.now(),
+		Links:       links,
+		Archive:     true,
+	}
+	if err := fw.Begin(meta); err != nil {
+		return
+	}
+	for _, item := range items {
+		if err := fw.WriteItem(item); err != nil {
+			return
+		}
+	}
+	fw.End(feedSummary{Returned: len(items)})
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// archiveTestItems archives n items for testFeedURL, numbered from 1.
+func archiveTestItems(archive *Archive, n int) {
+	for i := 1; i <= n; i++ {
+		archive.Put(testFeedURL, Item{Title: fmt.Sprintf("Item %d", i), Link: fmt.Sprintf("https://news.example.com/a/%d", i), GUID: fmt.Sprintf("guid-%d", i), SourceName: "Example News"})
+	}
+}
+
+func TestArchiveSpan(t *testing.T) {
+	archive := NewArchive(3)
+	archiveTestItems(archive, 5)
+	if stored, dropped := archive.Stored(testFeedURL); stored != 5 || dropped != 2 {
+		t.Errorf("Stored = %d, %d; want 5, 2", stored, dropped)
+	}
+	var titles []string
+	for _, item := range archive.Span(testFeedURL, 1, 4) {
+		titles = append(titles, item.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "Item 4, Item 3" {
+		t.Errorf("Span(1, 4) = %s; want the kept items, most recent first", got)
+	}
+	if items := archive.Span(testFeedURL, 0, 2); len(items) != 0 {
+		t.Errorf("Span(0, 2) = %v; want the dropped items missing", items)
+	}
+}
+
+func TestFeedArchivePages(t *testing.T) {
+	archive := NewArchive(0)
+	archiveTestItems(archive, 2*archivePageSize+5)
+	h := &FeedHandler{Archive: archive, PublicURL: "https://full.example.com/"}
+	s := &Server{cfg: &Config{}, feedHandler: h}
+
+	if links := h.archiveLinks(testFeedURL, formatRSS); len(links) != 1 || links[0].Rel != "prev-archive" ||
+		links[0].Href != "https://full.example.com/feed/archive?format=rss&page=2&url=https%3A%2F%2Fnews.example.com%2Frss" {
+		t.Errorf("archiveLinks = %+v; want prev-archive to page 2", links)
+	}
+	if links := h.archiveLinks("https://other.example.com/rss", formatJSON); links != nil {
+		t.Errorf("archiveLinks of a feed without a complete page = %+v; want none", links)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleFeedArchive(rec, httptest.NewRequest(http.MethodGet, "/feed/archive?url="+testFeedURL+query, nil))
+		return rec
+	}
+	for _, query := range []string{"&page=0", "&page=x"} {
+		if rec := get(query); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d; want 400", query, rec.Code)
+		}
+	}
+	if rec := get("&page=3"); rec.Code != http.StatusNotFound {
+		t.Errorf("incomplete page: status %d; want 404", rec.Code)
+	}
+
+	rec := get("&page=2")
+	var resp struct {
+		Items   []Item
+		Links   []feedLink `json:"feed_links"`
+		Archive bool       `json:"feed_archive"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != archivePageSize || resp.Items[0].Title != fmt.Sprintf("Item %d", 2*archivePageSize) || !resp.Archive {
+		t.Errorf("page 2 = %d items from %q (archive %v); want items %d to %d", len(resp.Items), resp.Items[0].Title, resp.Archive, archivePageSize+1, 2*archivePageSize)
+	}
+	rels := map[string]string{}
+	for _, link := range resp.Links {
+		rels[link.Rel] = link.Href
+	}
+	if !strings.Contains(rels["prev-archive"], "page=1") || rels["current"] == "" || rels["next-archive"] != "" {
+		t.Errorf("links = %v; want current and prev-archive only", rels)
+	}
+
+	// Atom pages carry the links and the archive marker
+	body := get("&page=1&format=atom").Body.String()
+	for _, want := range []string{`<fh:archive/>`, `<link rel="next-archive" href="https://full.example.com/feed/archive?format=atom&amp;page=2&amp;url=`, `<link rel="current" href="https://full.example.com/feed?format=atom&amp;url=`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("atom page lacks %s:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// internal/app/feeddoc.go
+package app
+
+import (
+	"strconv"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// feedDocVersion is part of the cache keys of upstream feed documents.
+const feedDocVersion = 1
+
+// feedDocKey returns the key the upstream document of the feed at urlParam
+// is cached under, apart from the rendered responses.
+func feedDocKey(urlParam string) string {
+	return "doc|v" + strconv.Itoa(feedDocVersion) + "|" + canonicalURL(urlParam)
+}
+
+// cachedFeed returns the upstream feed at urlParam, parsed from the document
+// cached by the last fetch while it is fresh. Responses are assembled from
+// the feed and the items archived when they were extracted, so a request
+// for another limit, offset or format than the cached responses fetches
+// nothing upstream and extracts only the items not archived yet.
+func (h *FeedHandler) cachedFeed(urlParam string, strict bool) (*gofeed.Feed, error) {
+	if body, ok := h.Cache.Get(feedDocKey(urlParam)); ok {
+		if feed, err := h.parseUpstream(urlParam, []byte(body), strict); err == nil {
+			return feed, nil
+		}
+	}
+	return h.fetchFeed(urlParam, strict)
+}
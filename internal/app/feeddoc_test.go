@@ -0,0 +1,71 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeedAssembledFromCachedDocument(t *testing.T) {
+	var calls atomic.Int32
+	clock := newFakeClock()
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return respond(http.StatusOK, testFeed), nil
+	}, clock, 0)
+
+	get := func(query string) (*httptest.ResponseRecorder, []Item) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+testFeedURL+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status %d: %s", query, rec.Code, rec.Body)
+		}
+		var resp struct{ Items []Item }
+		if rec.Header().Get("Content-Type") == contentTypeFor("json") {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec, resp.Items
+	}
+
+	get("&format=json&limit=2")
+	// Other limits, offsets and formats are assembled without refetching
+	if _, items := get("&format=json&limit=1"); len(items) != 1 || items[0].Link != "https://news.example.com/a/1" {
+		t.Errorf("limit=1 items = %+v; want the first", items)
+	}
+	if rec, items := get("&format=json&offset=1"); len(items) != 1 || items[0].Link != "https://news.example.com/a/2" || rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("offset=1 items = %+v; want the second", items)
+	}
+	if _, items := get("&format=json&offset=5"); len(items) != 0 {
+		t.Errorf("offset past the end items = %+v; want none", items)
+	}
+	get("&format=rss")
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream fetched %d times; want once", n)
+	}
+
+	// Once the document expires, the next new response fetches it again
+	clock.Advance(2 * time.Minute)
+	get("&format=json&limit=1")
+	if n := calls.Load(); n != 2 {
+		t.Errorf("upstream fetched %d times after expiry; want twice", n)
+	}
+}
+
+func TestFeedRejectsInvalidOffset(t *testing.T) {
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, testFeed), nil
+	}, newFakeClock(), 0)
+	for _, offset := range []string{"-1", "abc"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?offset="+offset+"&url="+testFeedURL, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("offset=%s: status %d; want 400", offset, rec.Code)
+		}
+	}
+}
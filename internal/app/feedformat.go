@@ -0,0 +1,270 @@
+// internal/app/feedformat.go
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Input formats told apart by detectFeedFormat.
+const (
+	inputRSS     = "rss"
+	inputAtom    = "atom"
+	inputRDF     = "rdf"
+	inputJSON    = "json"
+	inputHTML    = "html"
+	inputXML     = "xml"
+	inputUnknown = "unknown"
+)
+
+// feedParseError is returned for a document that could not be parsed as a
+// feed, naming the format it looked like.
+type feedParseError struct {
+	Format string
+	Err    error
+}
+
+func (e *feedParseError) Error() string {
+	return fmt.Sprintf("document detected as %s: %v", e.Format, e.Err)
+}
+
+func (e *feedParseError) Unwrap() error { return e.Err }
+
+// detectFeedFormat sniffs the format of a feed document: JSON Feed from
+// its opening brace, the XML formats from their root element, skipping
+// the prolog, comments and doctype.
+func detectFeedFormat(body []byte) string {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) == 0 {
+		return inputUnknown
+	}
+	if trimmed[0] == '{' {
+		return inputJSON
+	}
+	if trimmed[0] != '<' {
+		return inputUnknown
+	}
+	dec := xml.NewDecoder(bytes.NewReader(trimmed))
+	dec.Strict = false
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(start.Name.Local) {
+		case "rss":
+			return inputRSS
+		case "feed":
+			return inputAtom
+		case "rdf":
+			return inputRDF
+		case "html":
+			return inputHTML
+		}
+		return inputXML
+	}
+	if looksLikeHTML(trimmed) {
+		return inputHTML
+	}
+	return inputUnknown
+}
+
+// parseFeed parses an RSS, RDF (RSS 1.0), Atom or JSON Feed document.
+// Failures are *feedParseError; documents of no feed format wrap
+// gofeed.ErrFeedTypeNotDetected.
+func parseFeed(body []byte) (*gofeed.Feed, error) {
+	format := detectFeedFormat(body)
+	var feed *gofeed.Feed
+	var err error
+	switch format {
+	case inputJSON:
+		feed, err = parseJSONFeed(body)
+	case inputRSS, inputAtom, inputRDF:
+		// The parser sniffs the document itself; a byte order mark or
+		// leading blank lines throw it off
+		body = bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+		feed, err = newFeedParser().Parse(bytes.NewReader(body))
+	default:
+		err = gofeed.ErrFeedTypeNotDetected
+	}
+	if err != nil {
+		return nil, &feedParseError{Format: format, Err: err}
+	}
+	fixItemDates(feed)
+	return feed, nil
+}
+
+// formatName returns the name of an input format for messages.
+func formatName(format string) string {
+	switch format {
+	case inputRSS:
+		return "RSS"
+	case inputAtom:
+		return "Atom"
+	case inputRDF:
+		return "RDF (RSS 1.0)"
+	case inputJSON:
+		return "JSON Feed"
+	case inputHTML:
+		return "HTML"
+	case inputXML:
+		return "XML"
+	}
+	return "unrecognized"
+}
+
+// invalidFeedError returns the error for an upstream feed parseFeed
+// rejected, naming the format the document was detected as.
+func invalidFeedError(target string, err error) *APIError {
+	format := inputUnknown
+	var parseErr *feedParseError
+	if errors.As(err, &parseErr) {
+		format, err = parseErr.Format, parseErr.Err
+	}
+	msg := "upstream response is not a valid feed"
+	switch format {
+	case inputRSS, inputAtom, inputRDF, inputJSON:
+		msg = fmt.Sprintf("upstream %s feed could not be parsed", formatName(format))
+	case inputHTML:
+		msg = "upstream response is an HTML page, not a feed"
+	}
+	return newAPIError(http.StatusBadGateway, CodeInvalidFeed, msg).
+		with("url", target).with("format", format).with("error", err.Error())
+}
+
+// jsonFeed is a JSON Feed document, version 1 or 1.1.
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Language    string           `json:"language"`
+	Author      *jsonFeedAuthor  `json:"author"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage      `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	Image         string               `json:"image"`
+	BannerImage   string               `json:"banner_image"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *jsonFeedAuthor      `json:"author"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size_in_bytes"`
+}
+
+// parseJSONFeed converts a JSON Feed document to the universal feed model.
+// Dates are left raw for fixItemDates.
+func parseJSONFeed(body []byte) (*gofeed.Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a JSON Feed: version %q", doc.Version)
+	}
+	feed := &gofeed.Feed{
+		Title:       doc.Title,
+		Link:        doc.HomePageURL,
+		FeedLink:    doc.FeedURL,
+		Description: doc.Description,
+		Language:    doc.Language,
+		Authors:     jsonFeedPeople(doc.Author, doc.Authors),
+		FeedType:    "json",
+		FeedVersion: strings.TrimPrefix(doc.Version, "https://jsonfeed.org/version/"),
+	}
+	if doc.Icon != "" {
+		feed.Image = &gofeed.Image{URL: doc.Icon}
+	}
+	for _, it := range doc.Items {
+		item := &gofeed.Item{
+			Title:       it.Title,
+			Link:        firstNonEmpty(it.URL, it.ExternalURL),
+			GUID:        jsonFeedID(it.ID),
+			Description: it.Summary,
+			Content:     it.ContentHTML,
+			Published:   it.DatePublished,
+			Updated:     it.DateModified,
+			Authors:     jsonFeedPeople(it.Author, it.Authors),
+			Categories:  it.Tags,
+		}
+		if item.Content == "" && it.ContentText != "" {
+			item.Content = "<p>" + strings.ReplaceAll(html.EscapeString(it.ContentText), "\n\n", "</p><p>") + "</p>"
+		}
+		if len(item.Authors) > 0 {
+			item.Author = item.Authors[0]
+		}
+		if img := firstNonEmpty(it.Image, it.BannerImage); img != "" {
+			item.Image = &gofeed.Image{URL: img}
+		}
+		for _, a := range it.Attachments {
+			if a.URL != "" {
+				item.Enclosures = append(item.Enclosures, &gofeed.Enclosure{URL: a.URL, Type: a.MimeType, Length: strconv.FormatInt(a.Size, 10)})
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// jsonFeedPeople returns the authors of a JSON Feed object, version 1.1
+// authors or the version 1 author.
+func jsonFeedPeople(author *jsonFeedAuthor, authors []jsonFeedAuthor) []*gofeed.Person {
+	if len(authors) == 0 && author != nil {
+		authors = []jsonFeedAuthor{*author}
+	}
+	var people []*gofeed.Person
+	for _, a := range authors {
+		if a.Name != "" {
+			people = append(people, &gofeed.Person{Name: a.Name})
+		}
+	}
+	return people
+}
+
+// jsonFeedID reads an item ID, which should be a string but is sometimes
+// a number.
+func jsonFeedID(raw json.RawMessage) string {
+	var id string
+	if json.Unmarshal(raw, &id) == nil {
+		return id
+	}
+	return strings.TrimSpace(string(raw))
+}
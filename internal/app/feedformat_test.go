@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestDetectFeedFormat(t *testing.T) {
+	tests := []struct {
+		body, format string
+	}{
+		{`<?xml version="1.0"?><rss version="2.0"><channel/></rss>`, inputRSS},
+		{"\xef\xbb\xbf\n  <?xml version=\"1.0\"?>\n<!-- generated --><feed xmlns=\"http://www.w3.org/2005/Atom\"/>", inputAtom},
+		{`<?xml version="1.0" encoding="ISO-8859-9"?><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/>`, inputRDF},
+		{` {"version": "https://jsonfeed.org/version/1.1"}`, inputJSON},
+		{`<!DOCTYPE html><html><body>hi</body></html>`, inputHTML},
+		{`<sitemap/>`, inputXML},
+		{`not a feed`, inputUnknown},
+		{``, inputUnknown},
+	}
+	for _, tt := range tests {
+		if got := detectFeedFormat([]byte(tt.body)); got != tt.format {
+			t.Errorf("detectFeedFormat(%q) = %s; want %s", tt.body, got, tt.format)
+		}
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	const doc = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Haberler",
+  "home_page_url": "https://news.example.com/",
+  "feed_url": "https://news.example.com/feed.json",
+  "authors": [{"name": "Masa"}],
+  "items": [
+    {"id": 42, "url": "https://news.example.com/a/1", "title": "First",
+     "content_text": "Bir.\n\nİki & üç.", "date_published": "2026-03-01T10:00:00+03:00",
+     "tags": ["gündem"], "authors": [{"name": "Ayşe"}],
+     "attachments": [{"url": "https://news.example.com/a.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1024}]},
+    {"id": "b", "external_url": "https://elsewhere.example.com/b", "title": "Second",
+     "content_html": "<p>Two</p>", "summary": "Two in short"}
+  ]
+}`
+	feed, err := parseFeed([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.FeedType != "json" || feed.Title != "Haberler" || feed.Link != "https://news.example.com/" || len(feed.Items) != 2 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	first, second := feed.Items[0], feed.Items[1]
+	if first.GUID != "42" || first.Link != "https://news.example.com/a/1" || first.Content != "<p>Bir.</p><p>İki &amp; üç.</p>" {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.PublishedParsed == nil || first.PublishedParsed.Unix() != 1772348400 {
+		t.Errorf("first published = %v; want 2026-03-01T07:00:00Z", first.PublishedParsed)
+	}
+	if first.Author == nil || first.Author.Name != "Ayşe" || len(first.Categories) != 1 || len(first.Enclosures) != 1 || first.Enclosures[0].Length != "1024" {
+		t.Errorf("first item metadata = %+v", first)
+	}
+	if second.Link != "https://elsewhere.example.com/b" || second.Content != "<p>Two</p>" || second.Description != "Two in short" {
+		t.Errorf("second item = %+v", second)
+	}
+}
+
+func TestParseFeedErrorsNameFormat(t *testing.T) {
+	tests := []struct {
+		body, format string
+	}{
+		{`{"version": "https://jsonfeed.org/version/1", "items": [`, inputJSON},
+		{`{"title": "not a JSON Feed"}`, inputJSON},
+		{`<html><body>hi</body></html>`, inputHTML},
+		{`plain text`, inputUnknown},
+	}
+	for _, tt := range tests {
+		_, err := parseFeed([]byte(tt.body))
+		var parseErr *feedParseError
+		if !errors.As(err, &parseErr) || parseErr.Format != tt.format {
+			t.Errorf("parseFeed(%q) error = %v; want a %s parse error", tt.body, err, tt.format)
+		}
+	}
+}
+
+func TestFetchJSONFeed(t *testing.T) {
+	const doc = `{"version": "https://jsonfeed.org/version/1", "title": "JSON",
+"items": [{"id": "1", "url": "https://news.example.com/a/1", "title": "First"}]}`
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, doc), nil
+	}, newFakeClock(), 0)
+	feed, err := h.fetchFeed(testFeedURL, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "JSON" || len(feed.Items) != 1 || feed.Items[0].Title != "First" {
+		t.Errorf("feed = %+v", feed)
+	}
+
+	h = newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, `<rss version="2.0"><channel><item><title>broken`), nil
+	}, newFakeClock(), 0)
+	_, err = h.fetchFeed(testFeedURL, false)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidFeed || apiErr.Details["format"] != inputRSS {
+		t.Errorf("fetchFeed() error = %v; want %s naming the RSS format", err, CodeInvalidFeed)
+	}
+}
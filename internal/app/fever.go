@@ -0,0 +1,304 @@
+// internal/app/fever.go
+package app
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// feverItemsPerPage is how many items one Fever items request returns, as
+// the Fever API specifies.
+const feverItemsPerPage = 50
+
+// feverGroupID is the one group all archived feeds are listed in.
+const feverGroupID = 1
+
+// feverItem is an item as the Fever API describes it.
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+// feverFeed is a feed as the Fever API describes it.
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+// handleFever serves the Fever API at /fever/, so RSS clients such as
+// Reeder and FeedMe can sync with the archive: they list the archived feeds
+// and items, and the read and saved state is that of the account of the
+// API key. Clients log in with the username FeverUser and an API key as
+// password, and only get the items of sites fetched with the server's
+// credentials if the key may use them.
+func (s *Server) handleFever(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidBody, "request body could not be parsed").with("error", err.Error()))
+		return
+	}
+	resp := map[string]any{"api_version": 3, "auth": 0}
+	key, ok := s.feverKey(r.Form.Get("api_key"))
+	if !ok {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	resp["auth"] = 1
+
+	entries := slices.DeleteFunc(s.archive.All(), func(entry ArchivedItem) bool {
+		return !s.upstreamAuth.allows(key, entry.FeedURL, entry.Item.Link)
+	})
+	var lastRefreshed time.Time
+	for _, entry := range entries {
+		if entry.StoredAt.After(lastRefreshed) {
+			lastRefreshed = entry.StoredAt
+		}
+	}
+	resp["last_refreshed_on_time"] = unixOrZero(lastRefreshed)
+
+	if r.Form.Get("mark") != "" {
+		if err := s.feverMark(key, r.Form, entries); err != nil {
+			writeError(w, r, accountError(err))
+			return
+		}
+	}
+
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	if r.Form.Has("groups") || r.Form.Has("feeds") {
+		feeds := feverFeeds(entries)
+		ids := make([]string, len(feeds))
+		for i, feed := range feeds {
+			ids[i] = strconv.FormatInt(feed.ID, 10)
+		}
+		if r.Form.Has("groups") {
+			resp["groups"] = []map[string]any{{"id": feverGroupID, "title": "All"}}
+		}
+		if r.Form.Has("feeds") {
+			resp["feeds"] = feeds
+		}
+		resp["feeds_groups"] = []map[string]any{{"group_id": feverGroupID, "feed_ids": strings.Join(ids, ",")}}
+	}
+	if r.Form.Has("favicons") {
+		resp["favicons"] = []any{}
+	}
+	if r.Form.Has("links") {
+		resp["links"] = []any{}
+	}
+	if r.Form.Has("items") {
+		resp["total_items"] = len(entries)
+		resp["items"] = feverItems(feverPage(entries, r.Form), acc)
+	}
+	if r.Form.Has("unread_item_ids") {
+		var ids []string
+		for _, entry := range entries {
+			if _, read := acc.Read[entry.Item.GUID]; !read {
+				ids = append(ids, strconv.FormatInt(entry.ID, 10))
+			}
+		}
+		resp["unread_item_ids"] = strings.Join(ids, ",")
+	}
+	if r.Form.Has("saved_item_ids") {
+		byGUID := make(map[string]int64, len(entries))
+		for _, entry := range entries {
+			byGUID[entry.Item.GUID] = entry.ID
+		}
+		var ids []string
+		for _, star := range acc.Starred {
+			if id, ok := byGUID[star.Item.GUID]; ok {
+				ids = append(ids, strconv.FormatInt(id, 10))
+			}
+		}
+		resp["saved_item_ids"] = strings.Join(ids, ",")
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// feverKey returns the API key a Fever api_key, md5("user:key"), stands for.
+func (s *Server) feverKey(apiKey string) (string, bool) {
+	user := s.cfg.FeverUser
+	if user == "" {
+		user = "gofull"
+	}
+	apiKey = strings.ToLower(apiKey)
+	for _, key := range s.cfg.SaveKeys {
+		sum := md5.Sum([]byte(user + ":" + key))
+		if apiKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(hex.EncodeToString(sum[:]))) == 1 {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// feverMark applies a Fever mark request: an item marked read, unread,
+// saved or unsaved, or a feed or group marked read up to a time.
+func (s *Server) feverMark(key string, form url.Values, entries []ArchivedItem) error {
+	id, _ := strconv.ParseInt(form.Get("id"), 10, 64)
+	before, _ := strconv.ParseInt(form.Get("before"), 10, 64)
+	now := s.feedHandler.now().UTC()
+
+	return s.accounts.Update(key, func(acc *Account) error {
+		if acc.Read == nil {
+			acc.Read = make(map[string]time.Time)
+		}
+		for _, entry := range entries {
+			guid := entry.Item.GUID
+			switch form.Get("mark") {
+			case "item":
+				if entry.ID != id {
+					continue
+				}
+				switch form.Get("as") {
+				case "read":
+					acc.Read[guid] = now
+				case "unread":
+					delete(acc.Read, guid)
+				case "saved":
+					if acc.starredIndex(guid) < 0 {
+						acc.Starred = append([]StarredItem{{Item: entry.Item, StarredAt: now}}, acc.Starred...)
+					}
+				case "unsaved":
+					if i := acc.starredIndex(guid); i >= 0 {
+						acc.Starred = append(acc.Starred[:i], acc.Starred[i+1:]...)
+					}
+				}
+			case "feed", "group":
+				if form.Get("as") != "read" || entry.StoredAt.Unix() >= before {
+					continue
+				}
+				// Every group, Kindling (0) and Sparks (-1) included,
+				// holds all feeds
+				if form.Get("mark") == "feed" && feverFeedID(entry.FeedURL) != id {
+					continue
+				}
+				if _, read := acc.Read[guid]; !read {
+					acc.Read[guid] = now
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// feverPage returns the entries a Fever items request asks for: those of
+// with_ids, those after since_id, those before max_id or the first page.
+func feverPage(entries []ArchivedItem, form url.Values) []ArchivedItem {
+	var page []ArchivedItem
+	if form.Has("with_ids") {
+		want := make(map[int64]bool)
+		for _, field := range strings.Split(form.Get("with_ids"), ",") {
+			if n, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64); err == nil {
+				want[n] = true
+			}
+		}
+		for _, entry := range entries {
+			if want[entry.ID] && len(page) < feverItemsPerPage {
+				page = append(page, entry)
+			}
+		}
+		return page
+	}
+	if maxID, err := strconv.ParseInt(form.Get("max_id"), 10, 64); err == nil {
+		for i := len(entries) - 1; i >= 0 && len(page) < feverItemsPerPage; i-- {
+			if entries[i].ID < maxID {
+				page = append(page, entries[i])
+			}
+		}
+		return page
+	}
+	sinceID, _ := strconv.ParseInt(form.Get("since_id"), 10, 64)
+	for _, entry := range entries {
+		if entry.ID > sinceID && len(page) < feverItemsPerPage {
+			page = append(page, entry)
+		}
+	}
+	return page
+}
+
+// feverItems describes entries to Fever clients with the account's state.
+func feverItems(entries []ArchivedItem, acc *Account) []feverItem {
+	items := make([]feverItem, 0, len(entries))
+	for _, entry := range entries {
+		created := entry.StoredAt
+		if t, ok := parseDate(entry.Item.Published); ok {
+			created = t
+		}
+		item := feverItem{
+			ID:            entry.ID,
+			FeedID:        feverFeedID(entry.FeedURL),
+			Title:         entry.Item.Title,
+			Author:        entry.Item.Author,
+			HTML:          firstNonEmpty(entry.Item.Content, entry.Item.Description),
+			URL:           entry.Item.Link,
+			CreatedOnTime: unixOrZero(created),
+		}
+		if _, read := acc.Read[entry.Item.GUID]; read {
+			item.IsRead = 1
+		}
+		if acc.starredIndex(entry.Item.GUID) >= 0 {
+			item.IsSaved = 1
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// feverFeeds describes the feeds of the archived entries.
+func feverFeeds(entries []ArchivedItem) []feverFeed {
+	var feeds []feverFeed
+	byURL := make(map[string]int)
+	for _, entry := range entries {
+		i, ok := byURL[entry.FeedURL]
+		if !ok {
+			i = len(feeds)
+			byURL[entry.FeedURL] = i
+			feeds = append(feeds, feverFeed{
+				ID:      feverFeedID(entry.FeedURL),
+				Title:   firstNonEmpty(entry.Item.SourceName, entry.FeedURL),
+				URL:     entry.FeedURL,
+				SiteURL: entry.Item.SourceURL,
+			})
+		}
+		if t := entry.StoredAt.Unix(); t > feeds[i].LastUpdatedOnTime {
+			feeds[i].LastUpdatedOnTime = t
+		}
+	}
+	return feeds
+}
+
+// feverFeedID is the stable numeric ID of a feed URL.
+func feverFeedID(feedURL string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(feedURL))
+	return int64(h.Sum32())
+}
+
+// unixOrZero returns t as Unix time, 0 for the zero time.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
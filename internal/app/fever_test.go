@@ -0,0 +1,101 @@
+package app
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func fever(t *testing.T, s *Server, query string, form url.Values) map[string]any {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/fever/?api&"+query, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	s.handleFever(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func feverAPIKey(user, key string) url.Values {
+	sum := md5.Sum([]byte(user + ":" + key))
+	return url.Values{"api_key": {hex.EncodeToString(sum[:])}}
+}
+
+func TestFeverAuth(t *testing.T) {
+	s := newAccountsServer()
+	if resp := fever(t, s, "", feverAPIKey("gofull", "yanlis")); resp["auth"] != float64(0) {
+		t.Errorf("auth = %v with a wrong key", resp["auth"])
+	}
+	if resp := fever(t, s, "", feverAPIKey("gofull", "anahtar")); resp["auth"] != float64(1) || resp["api_version"] != float64(3) {
+		t.Errorf("response %v", resp)
+	}
+}
+
+func TestFeverSync(t *testing.T) {
+	s := newAccountsServer()
+	s.archive.Put("https://dunya.test/rss", Item{Title: "Borsa yükseldi", Link: "https://dunya.test/borsa", GUID: "guid-borsa", Published: "2024-01-01T10:00:00Z"})
+	auth := feverAPIKey("gofull", "anahtar")
+
+	resp := fever(t, s, "items&since_id=0", auth)
+	items := resp["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("items = %v", items)
+	}
+	second := items[1].(map[string]any)
+	if second["id"] != float64(2) || second["title"] != "Borsa yükseldi" || second["created_on_time"] != float64(1704103200) {
+		t.Errorf("second item %v", second)
+	}
+	if resp := fever(t, s, "items&since_id=1", auth); len(resp["items"].([]any)) != 1 {
+		t.Errorf("items after 1 = %v", resp["items"])
+	}
+
+	mark := url.Values{"mark": {"item"}, "as": {"read"}, "id": {"1"}}
+	mark.Set("api_key", auth.Get("api_key"))
+	fever(t, s, "", mark)
+	mark.Set("as", "saved")
+	mark.Set("id", "2")
+	fever(t, s, "", mark)
+
+	resp = fever(t, s, "unread_item_ids&saved_item_ids", auth)
+	if resp["unread_item_ids"] != "2" || resp["saved_item_ids"] != "2" {
+		t.Errorf("unread %v, saved %v", resp["unread_item_ids"], resp["saved_item_ids"])
+	}
+
+	resp = fever(t, s, "groups&feeds", auth)
+	if feeds := resp["feeds"].([]any); len(feeds) != 2 {
+		t.Errorf("feeds = %v", feeds)
+	}
+	groups := resp["feeds_groups"].([]any)
+	if ids := groups[0].(map[string]any)["feed_ids"].(string); strings.Count(ids, ",") != 1 {
+		t.Errorf("feeds_groups = %v", groups)
+	}
+}
+
+func TestFeverPrivateItems(t *testing.T) {
+	s := newAccountsServer()
+	s.upstreamAuth = NewUpstreamAuth(map[string]UpstreamCredential{"kapali.test": {Username: "okur", Password: "gizli", Keys: []string{"anahtar"}}}, nil)
+	s.archive.Put("https://kapali.test/rss", Item{Title: "Abonelere özel", Link: "https://kapali.test/analiz", GUID: "guid-analiz"})
+
+	// Items of sites fetched with credentials are only synced to keys
+	// allowed to use them
+	for key, want := range map[string]int{"anahtar": 2, "diger": 1} {
+		resp := fever(t, s, "items&feeds", feverAPIKey("gofull", key))
+		if items := resp["items"].([]any); len(items) != want || resp["total_items"] != float64(want) {
+			t.Errorf("%s: %d items; want %d", key, len(items), want)
+		}
+		if feeds := resp["feeds"].([]any); len(feeds) != want {
+			t.Errorf("%s: %d feeds; want %d", key, len(feeds), want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// internal/app/filtersets.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gofull/internal/extractors/filters"
+)
+
+// Filter sets requests pick with the filters parameter besides profiles.
+const (
+	// filtersOff applies no site filters
+	filtersOff = "off"
+	// filtersDefault applies the built-in site filters
+	filtersDefault = "default"
+)
+
+// newFilterProfiles builds the filter profiles of cfg and checks that its
+// default filter set exists.
+func newFilterProfiles(cfg *Config) (map[string]*filters.FilterRegistry, error) {
+	profiles := make(map[string]*filters.FilterRegistry, len(cfg.FilterProfiles))
+	for name, rules := range cfg.FilterProfiles {
+		if name == filtersOff || name == filtersDefault || !profileNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid filter profile name %q", name)
+		}
+		reg := filters.NewFilterRegistry()
+		for _, rule := range rules {
+			if rule.Domain == "" {
+				return nil, fmt.Errorf("filter profile %s: rule without a domain", name)
+			}
+			reg.Register(rule)
+		}
+		profiles[name] = reg
+	}
+	switch cfg.Filters {
+	case "", filtersOff, filtersDefault:
+	default:
+		if profiles[cfg.Filters] == nil {
+			return nil, fmt.Errorf("default filters %q are neither off, default nor a filter profile", cfg.Filters)
+		}
+	}
+	return profiles, nil
+}
+
+// filterSet returns the site filters named name, the handler's default ones
+// when name is empty. It is nil when filters are off.
+func (h *FeedHandler) filterSet(name string) *filters.FilterRegistry {
+	if name == "" {
+		name = h.DefaultFilters
+	}
+	switch name {
+	case "", filtersDefault:
+		return h.FilterReg
+	case filtersOff:
+		return nil
+	default:
+		return h.FilterProfiles[name]
+	}
+}
+
+// parseFilters returns the filters parameter of r, empty when it names the
+// handler's default set so such requests share cache entries with those
+// naming none.
+func (h *FeedHandler) parseFilters(r *http.Request) (string, *APIError) {
+	name := strings.TrimSpace(r.URL.Query().Get("filters"))
+	if _, ok := h.FilterProfiles[name]; !ok && name != "" && name != filtersOff && name != filtersDefault {
+		names := []string{filtersOff, filtersDefault}
+		for profile := range h.FilterProfiles {
+			names = append(names, profile)
+		}
+		sort.Strings(names[2:])
+		return "", newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("unknown filters '%s' (use %s)", name, strings.Join(names, ", "))).with("parameter", "filters").with("value", name)
+	}
+	if name == h.DefaultFilters || name == filtersDefault && h.DefaultFilters == "" {
+		return "", nil
+	}
+	return name, nil
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors/filters"
+)
+
+func TestFeedFilterSets(t *testing.T) {
+	doer := func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }
+	h := newTestFeedHandler(doer, newFakeClock(), 0)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/a/2"}})
+	profiles, err := newFilterProfiles(&Config{FilterProfiles: map[string][]filters.URLFilter{
+		"strict": {{Domain: "news.example.com", BlockedPaths: []string{"/a/"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.FilterProfiles = profiles
+
+	skipped := func(query string) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL)+query, nil))
+		var resp struct {
+			Skipped int `json:"items_skipped"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: invalid JSON: %v\n%s", query, err, rec.Body)
+		}
+		return resp.Skipped
+	}
+	tests := []struct {
+		defaults, query string
+		want            int
+	}{
+		{"", "", 1},
+		{"", "&filters=default", 1},
+		{"", "&filters=off", 0},
+		{"", "&filters=strict", 2},
+		{filtersOff, "", 0},
+		{filtersOff, "&filters=default", 1},
+		{"strict", "", 2},
+	}
+	for _, tt := range tests {
+		h.DefaultFilters = tt.defaults
+		h.Cache = NewCache(time.Minute, 0)
+		if got := skipped(tt.query); got != tt.want {
+			t.Errorf("defaults %q, query %q: %d items skipped; want %d", tt.defaults, tt.query, got, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?filters=lenient&url="+url.QueryEscape(testFeedURL), nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown filters: status %d; want 400", rec.Code)
+	}
+}
+
+func TestParseFiltersSharesDefaultCacheEntry(t *testing.T) {
+	h := &FeedHandler{DefaultFilters: filtersOff}
+	for _, query := range []string{"", "?filters=off"} {
+		if name, apiErr := h.parseFilters(httptest.NewRequest(http.MethodGet, "/feed"+query, nil)); apiErr != nil || name != "" {
+			t.Errorf("parseFilters(%q) = %q, %v; want the default", query, name, apiErr)
+		}
+	}
+	if name, _ := h.parseFilters(httptest.NewRequest(http.MethodGet, "/feed?filters=default", nil)); name != filtersDefault {
+		t.Errorf("parseFilters(default) = %q with filters off by default", name)
+	}
+}
+
+func TestNewFilterProfilesRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Filters: "missing"},
+		{FilterProfiles: map[string][]filters.URLFilter{"off": nil}},
+		{FilterProfiles: map[string][]filters.URLFilter{"finance": {{BlockedPaths: []string{"/spor/"}}}}},
+	} {
+		if _, err := newFilterProfiles(cfg); err == nil {
+			t.Errorf("newFilterProfiles(%+v) accepted", cfg)
+		}
+	}
+}
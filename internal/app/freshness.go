@@ -0,0 +1,110 @@
+// internal/app/freshness.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// Bounds of the max-age sent to clients.
+const (
+	minFeedMaxAge = time.Minute
+	maxFeedMaxAge = 6 * time.Hour
+)
+
+// syPeriods maps sy:updatePeriod values to durations.
+var syPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// feedMaxAge returns how long clients may cache a rendered feed: half the
+// feed's typical gap between posts, but never less than the update interval
+// the feed announces through <ttl> or sy:updatePeriod. fallback is used when
+// the feed gives no clue.
+func feedMaxAge(feed *gofeed.Feed, fallback time.Duration) time.Duration {
+	hint := feedUpdateHint(feed)
+	cadence := postingInterval(feed) / 2
+	maxAge := max(hint, cadence)
+	if maxAge == 0 {
+		maxAge = fallback
+	}
+	return min(max(maxAge, minFeedMaxAge), maxFeedMaxAge)
+}
+
+// feedUpdateHint returns the update interval announced by the feed.
+func feedUpdateHint(feed *gofeed.Feed) time.Duration {
+	var hint time.Duration
+	if minutes, err := strconv.Atoi(strings.TrimSpace(feed.Custom[ttlKey])); err == nil && minutes > 0 {
+		hint = time.Duration(minutes) * time.Minute
+	}
+	if sy, ok := feed.Extensions["sy"]; ok {
+		period := syPeriods[strings.ToLower(strings.TrimSpace(syValue(sy, "updatePeriod")))]
+		frequency, err := strconv.Atoi(strings.TrimSpace(syValue(sy, "updateFrequency")))
+		if err != nil || frequency < 1 {
+			frequency = 1
+		}
+		if period > 0 {
+			hint = max(hint, period/time.Duration(frequency))
+		}
+	}
+	return hint
+}
+
+func syValue(sy map[string][]ext.Extension, name string) string {
+	if values := sy[name]; len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+// postingInterval returns the median gap between the feed's items, or 0
+// when fewer than two items are dated.
+func postingInterval(feed *gofeed.Feed) time.Duration {
+	var times []time.Time
+	for _, item := range feed.Items {
+		switch {
+		case item.PublishedParsed != nil:
+			times = append(times, *item.PublishedParsed)
+		case item.UpdatedParsed != nil:
+			times = append(times, *item.UpdatedParsed)
+		}
+	}
+	if len(times) < 2 {
+		return 0
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return b.Compare(a) })
+	gaps := make([]time.Duration, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		gaps = append(gaps, times[i-1].Sub(times[i]))
+	}
+	slices.Sort(gaps)
+	return gaps[len(gaps)/2]
+}
+
+// rememberMaxAge records the max-age computed for a feed so cached
+// responses can be sent with the same freshness.
+func (h *FeedHandler) rememberMaxAge(feedURL string, maxAge time.Duration) {
+	h.maxAges.Store(feedURL, maxAge)
+}
+
+// setCacheControl sets Cache-Control for a response about feedURL that is
+// age old.
+func (h *FeedHandler) setCacheControl(w http.ResponseWriter, feedURL string, age time.Duration) {
+	maxAge := h.Cache.ttl
+	if v, ok := h.maxAges.Load(feedURL); ok {
+		maxAge = v.(time.Duration)
+	}
+	remaining := max(maxAge-age, 0)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(remaining.Seconds())))
+}
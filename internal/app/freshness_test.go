@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// datedFeed returns an RSS feed whose items are gap apart, with extra
+// channel elements.
+func datedFeed(gap time.Duration, channel string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/"><channel><title>T</title>` + channel)
+	start := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		fmt.Fprintf(&b, "<item><title>%d</title><link>https://example.com/%d</link><pubDate>%s</pubDate></item>",
+			i, i, start.Add(-time.Duration(i)*gap).Format(time.RFC1123Z))
+	}
+	b.WriteString("</channel></rss>")
+	return b.String()
+}
+
+func TestFeedMaxAge(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want time.Duration
+	}{
+		{"fast feed", datedFeed(10*time.Minute, ""), 5 * time.Minute},
+		{"very fast feed is capped", datedFeed(30*time.Second, ""), minFeedMaxAge},
+		{"slow blog is capped", datedFeed(7*24*time.Hour, ""), maxFeedMaxAge},
+		{"ttl is a floor", datedFeed(10*time.Minute, "<ttl>60</ttl>"), time.Hour},
+		{"sy:updatePeriod", datedFeed(10*time.Minute, "<sy:updatePeriod>hourly</sy:updatePeriod><sy:updateFrequency>2</sy:updateFrequency>"), 30 * time.Minute},
+		{"undated feed uses fallback", `<rss version="2.0"><channel><title>T</title><item><title>x</title></item></channel></rss>`, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		feed, err := newFeedParser().ParseString(tt.feed)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := feedMaxAge(feed, 5*time.Minute); got != tt.want {
+			t.Errorf("%s: feedMaxAge = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFeedHandlerCacheControl(t *testing.T) {
+	clock := newFakeClock()
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, datedFeed(20*time.Minute, "")), nil
+	}, clock, 0)
+	target := "/feed?url=" + url.QueryEscape(testFeedURL)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=600" {
+		t.Errorf("MISS Cache-Control = %q", got)
+	}
+
+	// Cached responses only get the remaining lifetime
+	clock.Advance(30 * time.Second)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=570" {
+		t.Errorf("HIT Cache-Control = %q", got)
+	}
+}
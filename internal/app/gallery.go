@@ -0,0 +1,208 @@
+// internal/app/gallery.go
+package app
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// galleryPaths mark the URLs of photo galleries and slideshows. Site filters
+// usually block them; galleries=true or media=include lets them through to
+// be extracted as galleries.
+var galleryPaths = []string{"/foto-galeri", "/fotogaleri", "/galeri/", "/foto-haber/", "/gallery/", "/slideshow/"}
+
+// maxGalleryImages caps the slides kept of a gallery.
+const maxGalleryImages = 100
+
+// gallerySlideSel selects the slides of a gallery page, tried in order until
+// one matches several slides.
+var gallerySlideSel = []string{
+	"[data-gallery-item]", ".gallery-item", ".galeri-item", ".gallery-slide", ".swiper-slide", ".slide", "figure",
+}
+
+// galleryCaptionSel selects the caption within a slide.
+const galleryCaptionSel = "figcaption, .caption, .description, .aciklama, .gallery-text, .slide-text, p"
+
+// isGalleryURL reports whether link is a photo gallery or slideshow.
+func isGalleryURL(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	path := strings.ToLower(u.Path)
+	for _, p := range galleryPaths {
+		if strings.Contains(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractGallery fetches a gallery page and returns its slides.
+func (h *FeedHandler) extractGallery(pageURL string) []ItemImage {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	doc, ok := h.getDocument(pageURL)
+	if !ok {
+		return nil
+	}
+	return findGallerySlides(doc, base)
+}
+
+// findGallerySlides returns the images of a gallery page with their
+// captions: the JSON-LD ImageGallery when the page has one, else the slides
+// of its markup. Pages with fewer than two images are not galleries.
+func findGallerySlides(doc *goquery.Document, base *url.URL) []ItemImage {
+	var slides []ItemImage
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) == nil {
+			slides = jsonLDGallery(data, base)
+		}
+		return len(slides) < 2
+	})
+	if len(slides) < 2 {
+		slides = markupGallery(doc, base)
+	}
+	if len(slides) < 2 {
+		return nil
+	}
+	return slides[:min(len(slides), maxGalleryImages)]
+}
+
+// jsonLDGallery looks for an ImageGallery in JSON-LD data, following @graph
+// and nested objects, and returns its images.
+func jsonLDGallery(data any, base *url.URL) []ItemImage {
+	switch v := data.(type) {
+	case []any:
+		for _, elem := range v {
+			if slides := jsonLDGallery(elem, base); len(slides) > 0 {
+				return slides
+			}
+		}
+	case map[string]any:
+		if jsonLDType(v["@type"]) == "ImageGallery" {
+			for _, key := range []string{"associatedMedia", "image", "hasPart"} {
+				if slides := jsonLDImages(v[key], base); len(slides) > 0 {
+					return slides
+				}
+			}
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if slides := jsonLDGallery(v[key], base); len(slides) > 0 {
+				return slides
+			}
+		}
+	}
+	return nil
+}
+
+// jsonLDType returns the schema.org type of a JSON-LD object, the first one
+// of several.
+func jsonLDType(t any) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			s, _ := v[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// jsonLDImages converts a list of schema.org ImageObjects or image URLs.
+func jsonLDImages(images any, base *url.URL) []ItemImage {
+	list, ok := images.([]any)
+	if !ok {
+		return nil
+	}
+	var slides []ItemImage
+	for _, elem := range list {
+		var slide ItemImage
+		switch img := elem.(type) {
+		case string:
+			slide.URL = resolveAgainst(base, img)
+		case map[string]any:
+			for _, key := range []string{"contentUrl", "url"} {
+				if s, ok := img[key].(string); ok && s != "" {
+					slide.URL = resolveAgainst(base, s)
+					break
+				}
+			}
+			for _, key := range []string{"caption", "description", "name"} {
+				if s, ok := img[key].(string); ok && strings.TrimSpace(s) != "" {
+					slide.Caption = strings.TrimSpace(html.UnescapeString(s))
+					break
+				}
+			}
+		}
+		if slide.URL != "" {
+			slides = append(slides, withCaptionCredit(slide))
+		}
+	}
+	return slides
+}
+
+// markupGallery returns the slides of a gallery page's markup, each an
+// image with the caption next to it.
+func markupGallery(doc *goquery.Document, base *url.URL) []ItemImage {
+	for _, sel := range gallerySlideSel {
+		var slides []ItemImage
+		seen := map[string]bool{}
+		doc.Find(sel).Each(func(_ int, s *goquery.Selection) {
+			img := s.Find("img").First()
+			src := ""
+			for _, attr := range []string{"data-src", "data-lazy-src", "data-original", "src"} {
+				if v, ok := img.Attr(attr); ok && strings.TrimSpace(v) != "" {
+					src = resolveAgainst(base, strings.TrimSpace(v))
+					break
+				}
+			}
+			if src == "" || seen[src] {
+				return
+			}
+			seen[src] = true
+			caption := strings.Join(strings.Fields(s.Find(galleryCaptionSel).First().Text()), " ")
+			if caption == "" {
+				caption = strings.TrimSpace(img.AttrOr("alt", ""))
+			}
+			slides = append(slides, withCaptionCredit(ItemImage{URL: src, Caption: caption, Credit: attrCredit(img.Get(0))}))
+		})
+		if len(slides) >= 2 {
+			return slides
+		}
+	}
+	return nil
+}
+
+// withCaptionCredit cuts a "Foto: AA" credit ending the slide's caption,
+// making it the slide's credit unless it has one.
+func withCaptionCredit(slide ItemImage) ItemImage {
+	if m := captionCreditRegex.FindStringSubmatchIndex(slide.Caption); m != nil {
+		if slide.Credit == "" {
+			slide.Credit = slide.Caption[m[2]:m[3]]
+		}
+		slide.Caption = strings.TrimSpace(slide.Caption[:m[0]])
+	}
+	return slide
+}
+
+// galleryContent concatenates the captions of a gallery's slides, one
+// paragraph each.
+func galleryContent(slides []ItemImage) string {
+	var b strings.Builder
+	for _, slide := range slides {
+		if slide.Caption != "" {
+			b.WriteString("<p>" + html.EscapeString(slide.Caption) + "</p>")
+		}
+	}
+	return b.String()
+}
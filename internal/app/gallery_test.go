@@ -0,0 +1,94 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gofull/internal/extractors/filters"
+)
+
+const testGalleryPage = `<html><body>
+<h1>Kar yağışı</h1>
+<div class="gallery-item"><img data-src="/img/1.jpg" src="/lazy.gif"><div class="caption">İstanbul beyaza büründü (Foto: AA)</div></div>
+<div class="gallery-item"><img src="/img/2.jpg" data-credit="DHA"><div class="caption">Ankara'da yollar kapandı</div></div>
+<div class="gallery-item"><img src="/img/3.jpg" alt="Çocuklar kartopu oynadı"></div>
+</body></html>`
+
+func TestFindGallerySlides(t *testing.T) {
+	base, _ := url.Parse("https://news.example.com/foto-galeri/kar-1")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(testGalleryPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ItemImage{
+		{URL: "https://news.example.com/img/1.jpg", Caption: "İstanbul beyaza büründü", Credit: "AA"},
+		{URL: "https://news.example.com/img/2.jpg", Caption: "Ankara'da yollar kapandı", Credit: "DHA"},
+		{URL: "https://news.example.com/img/3.jpg", Caption: "Çocuklar kartopu oynadı"},
+	}
+	got := findGallerySlides(doc, base)
+	if len(got) != len(want) {
+		t.Fatalf("slides = %+v; want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("slide %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+
+	// JSON-LD galleries win over the markup
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<script type="application/ld+json">{"@graph":[{"@type":"ImageGallery","associatedMedia":[
+		{"@type":"ImageObject","contentUrl":"https://cdn.example.com/a.jpg","caption":"Birinci"},
+		{"@type":"ImageObject","contentUrl":"https://cdn.example.com/b.jpg","description":"İkinci"}]}]}</script>` + testGalleryPage))
+	if got := findGallerySlides(doc, base); len(got) != 2 || got[0].URL != "https://cdn.example.com/a.jpg" || got[1].Caption != "İkinci" {
+		t.Errorf("JSON-LD slides = %+v; want the ImageGallery's", got)
+	}
+
+	// A single image is no gallery
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<figure><img src="/a.jpg"><figcaption>Tek</figcaption></figure>`))
+	if got := findGallerySlides(doc, base); got != nil {
+		t.Errorf("slides of a single image = %+v; want none", got)
+	}
+}
+
+func TestFeedGalleries(t *testing.T) {
+	const galleryLink = "https://news.example.com/foto-galeri/kar-1"
+	feed := `<?xml version="1.0"?><rss version="2.0"><channel><title>Example News</title>
+<item><title>Kar yağışı</title><link>` + galleryLink + `</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+	h := newTestFeedHandler(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() == galleryLink {
+			return respond(http.StatusOK, testGalleryPage), nil
+		}
+		return respond(http.StatusOK, feed), nil
+	}, newFakeClock(), 0)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/foto-galeri/"}})
+
+	get := func(query string) []Item {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var resp struct{ Items []Item }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Items
+	}
+	if items := get(""); len(items) != 0 {
+		t.Errorf("items without galleries = %+v; want the gallery filtered out", items)
+	}
+	items := get("&galleries=true")
+	if len(items) != 1 || !items[0].Gallery || len(items[0].Images) != 3 {
+		t.Fatalf("items = %+v; want the gallery with its 3 slides", items)
+	}
+	if want := "<p>İstanbul beyaza büründü</p><p>Ankara&#39;da yollar kapandı</p><p>Çocuklar kartopu oynadı</p>"; items[0].Content != want {
+		t.Errorf("content = %q; want %q", items[0].Content, want)
+	}
+}
@@ -0,0 +1,143 @@
+// internal/app/guids.go
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// maxTrackedGUIDs bounds the GUIDs remembered per feed; the oldest are
+// forgotten first.
+const maxTrackedGUIDs = 2000
+
+// GUID problems reported in debug output.
+const (
+	guidLinkChanged = "link_changed"
+	guidReused      = "guid_reused"
+	guidCollision   = "guid_collision"
+)
+
+// feedGUIDs keeps the GUIDs of a feed stable across renders. Item GUIDs are
+// derived from links, so an article whose link changes upstream would show
+// up again in readers under a new GUID, and two articles sharing a link
+// would be merged into one. feedGUIDs remembers which link each upstream
+// GUID went with, to keep the first GUID when only the link changed, and
+// which item first had each GUID, to give the others their own.
+type feedGUIDs struct {
+	mu sync.Mutex
+	// upstream holds the item seen under each upstream GUID
+	upstream      map[string]guidRecord
+	upstreamOrder []string
+	// owners holds the identity of the item each GUID was first given to
+	owners     map[string]string
+	ownerOrder []string
+}
+
+// guidRecord is the item seen under an upstream GUID.
+type guidRecord struct {
+	link, title, guid string
+}
+
+// guids returns the GUID tracker of feedURL.
+func (h *FeedHandler) guids(feedURL string) *feedGUIDs {
+	tracker, _ := h.guidTrackers.LoadOrStore(feedURL, &feedGUIDs{
+		upstream: make(map[string]guidRecord),
+		owners:   make(map[string]string),
+	})
+	return tracker.(*feedGUIDs)
+}
+
+// stableGUID returns the GUID item, built from feedItem, is published
+// under and the problem found with it, if any: the GUID first given to the
+// upstream GUID when only the link changed since, or the item's GUID
+// suffixed with a hash of the item when another item already has it.
+func (g *feedGUIDs) stableGUID(feedItem *gofeed.Item, item Item) (string, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	guid, problem := item.GUID, ""
+	key := strings.TrimSpace(feedItem.GUID)
+	if key != "" {
+		rec, ok := g.upstream[key]
+		switch {
+		case !ok:
+			g.remember(key, guidRecord{link: item.Link, title: feedItem.Title, guid: guid})
+		case rec.link == item.Link:
+			if guid != rec.guid {
+				guid, problem = rec.guid, guidLinkChanged
+			}
+		case sameTitle(rec.title, feedItem.Title):
+			guid, problem = rec.guid, guidLinkChanged
+			rec.link = item.Link
+			g.upstream[key] = rec
+		default:
+			problem = guidReused
+			g.upstream[key] = guidRecord{link: item.Link, title: feedItem.Title, guid: guid}
+		}
+	}
+
+	// The identity of an item is its upstream GUID, or its title without one
+	identity := key
+	if identity == "" {
+		identity = "title:" + titleIdentity(feedItem.Title)
+	}
+	owner, ok := g.owners[guid]
+	if !ok {
+		g.own(guid, identity)
+		return guid, problem
+	}
+	if owner == identity {
+		return guid, problem
+	}
+	sum := sha256.Sum256([]byte(identity))
+	return guid + "-" + hex.EncodeToString(sum[:4]), guidCollision
+}
+
+// remember records the item seen under an upstream GUID.
+func (g *feedGUIDs) remember(key string, rec guidRecord) {
+	g.upstream[key] = rec
+	g.upstreamOrder = append(g.upstreamOrder, key)
+	if len(g.upstreamOrder) > maxTrackedGUIDs {
+		delete(g.upstream, g.upstreamOrder[0])
+		g.upstreamOrder = g.upstreamOrder[1:]
+	}
+}
+
+// own records the item a GUID was first given to.
+func (g *feedGUIDs) own(guid, identity string) {
+	g.owners[guid] = identity
+	g.ownerOrder = append(g.ownerOrder, guid)
+	if len(g.ownerOrder) > maxTrackedGUIDs {
+		delete(g.owners, g.ownerOrder[0])
+		g.ownerOrder = g.ownerOrder[1:]
+	}
+}
+
+// stabilizeGUID gives item the stable GUID of feedURL's tracker, logging
+// and reporting in debug any problem found.
+func (h *FeedHandler) stabilizeGUID(feedURL string, feedItem *gofeed.Item, item *Item, debug *feedDebug) {
+	guid, problem := h.guids(feedURL).stableGUID(feedItem, *item)
+	if problem == "" {
+		return
+	}
+	log.Printf("🆔 GUID problem (%s) in %s: %s", problem, feedURL, item.Link)
+	debug.guid(item.Link, feedItem.GUID, item.GUID, guid, problem)
+	item.GUID = guid
+}
+
+// sameTitle reports whether two titles are the same up to entities, case
+// and spacing.
+func sameTitle(a, b string) bool {
+	return titleIdentity(a) == titleIdentity(b)
+}
+
+// titleIdentity returns title as compared by sameTitle.
+func titleIdentity(title string) string {
+	return normalizePhrase(html.UnescapeString(title))
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+)
+
+func TestStableGUIDs(t *testing.T) {
+	h := &FeedHandler{}
+	publish := func(upstream, link, title string, debug *feedDebug) string {
+		item := Item{Title: title, Link: link, GUID: extractors.GenerateGUIDFromURL(link)}
+		h.stabilizeGUID(testFeedURL, &gofeed.Item{GUID: upstream, Link: link, Title: title}, &item, debug)
+		return item.GUID
+	}
+	linkGUID := extractors.GenerateGUIDFromURL
+
+	first := publish("haber-1", "https://news.example.com/a/1", "Derbi sonuçlandı", nil)
+	if first != linkGUID("https://news.example.com/a/1") {
+		t.Fatalf("first GUID = %s; want the link's", first)
+	}
+
+	// The link changes, the title and upstream GUID stay: the GUID is kept
+	debug := &feedDebug{}
+	if got := publish("haber-1", "https://news.example.com/spor/a/1", "Derbi  SONUÇLANDI", debug); got != first {
+		t.Errorf("GUID after link change = %s; want %s", got, first)
+	}
+	if got := publish("haber-1", "https://news.example.com/spor/a/1", "Derbi sonuçlandı", debug); got != first {
+		t.Errorf("GUID on the next render = %s; want %s", got, first)
+	}
+	if len(debug.GUIDs) != 2 || debug.GUIDs[0].Problem != guidLinkChanged || debug.GUIDs[0].Published != first {
+		t.Errorf("debug GUIDs = %+v; want link_changed", debug.GUIDs)
+	}
+
+	// The upstream GUID is reused for another article: it keeps its own
+	debug = &feedDebug{}
+	if got := publish("haber-1", "https://news.example.com/a/2", "Seçim sonuçları", debug); got != linkGUID("https://news.example.com/a/2") {
+		t.Errorf("GUID of reused upstream GUID = %s; want the link's", got)
+	}
+	if len(debug.GUIDs) != 1 || debug.GUIDs[0].Problem != guidReused {
+		t.Errorf("debug GUIDs = %+v; want guid_reused", debug.GUIDs)
+	}
+
+	// Two articles share a link: the second gets a GUID of its own, the
+	// same on every render
+	shared := "https://news.example.com/canli"
+	if got := publish("canli-1", shared, "Canlı: sabah", nil); got != linkGUID(shared) {
+		t.Errorf("GUID of the first article = %s; want the link's", got)
+	}
+	debug = &feedDebug{}
+	second := publish("canli-2", shared, "Canlı: öğle", debug)
+	if second == linkGUID(shared) || !strings.HasPrefix(second, linkGUID(shared)+"-") {
+		t.Errorf("GUID of the second article = %s; want the link's, suffixed", second)
+	}
+	if again := publish("canli-2", shared, "Canlı: öğle", nil); again != second {
+		t.Errorf("GUID of the second article changed from %s to %s", second, again)
+	}
+	if len(debug.GUIDs) != 1 || debug.GUIDs[0].Problem != guidCollision {
+		t.Errorf("debug GUIDs = %+v; want guid_collision", debug.GUIDs)
+	}
+
+	// The same article listed twice is not a collision
+	if a, b := publish("", "https://news.example.com/a/3", "Aynı", nil), publish("", "https://news.example.com/a/3", "Aynı", nil); a != b {
+		t.Errorf("duplicate item GUIDs %s and %s differ", a, b)
+	}
+}
@@ -0,0 +1,117 @@
+// internal/app/head.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+)
+
+// feedBuild describes a cached feed to HEAD requests.
+type feedBuild struct {
+	Items int
+	Built time.Time
+}
+
+// cacheFeed caches a rendered feed, remembering its item count and build
+// time for HEAD requests.
+func (h *FeedHandler) cacheFeed(cacheKey, body string, summary feedSummary) {
+	h.Cache.Set(cacheKey, body)
+	h.builds.Store(cacheKey, feedBuild{Items: summary.Returned, Built: h.now()})
+}
+
+// setBuildHeaders reports the item count and build time of the feed cached
+// under cacheKey.
+func (h *FeedHandler) setBuildHeaders(w http.ResponseWriter, cacheKey string) {
+	v, ok := h.builds.Load(cacheKey)
+	if !ok {
+		return
+	}
+	build := v.(feedBuild)
+	w.Header().Set("X-Item-Count", strconv.Itoa(build.Items))
+	w.Header().Set("Last-Modified", build.Built.UTC().Format(http.TimeFormat))
+}
+
+// serveHead answers HEAD /feed with the headers of a GET without rendering
+// the feed, so monitoring can check freshness cheaply. A cached feed reports
+// its cache status, item count and build time; otherwise only the upstream
+// feed is fetched, for the number of items it lists and when it changed.
+func (h *FeedHandler) serveHead(w http.ResponseWriter, r *http.Request, params feedParams, cacheKey string) {
+	if _, age, fresh, ok := h.Cache.GetStale(cacheKey); ok {
+		w.Header().Set("Content-Type", contentTypeFor(params.Format))
+		w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+		h.setCacheControl(w, params.URL, age)
+		if fresh {
+			w.Header().Set("X-Cache", "HIT")
+		} else {
+			w.Header().Set("X-Cache", "STALE")
+		}
+		h.setBuildHeaders(w, cacheKey)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	feed, err := h.cachedFeed(params.URL, params.Strict)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeFor(params.Format))
+	w.Header().Set("X-Cache", "MISS")
+	h.setCacheControl(w, params.URL, 0)
+	w.Header().Set("X-Item-Count", strconv.Itoa(min(params.Limit, max(len(feed.Items)-params.Offset, 0))))
+	if updated := feedLastModified(feed); !updated.IsZero() {
+		w.Header().Set("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// feedLastModified returns when the feed last changed: its own update date
+// or that of its newest item.
+func feedLastModified(feed *gofeed.Feed) time.Time {
+	var latest time.Time
+	if feed.UpdatedParsed != nil {
+		latest = *feed.UpdatedParsed
+	}
+	for _, item := range feed.Items {
+		for _, t := range []*time.Time{item.UpdatedParsed, item.PublishedParsed} {
+			if t != nil && t.After(latest) {
+				latest = *t
+			}
+		}
+	}
+	return latest
+}
+
+// headArticle answers HEAD /extract by checking the article upstream rather
+// than extracting it: its status, Last-Modified and ETag are passed on.
+func (s *Server) headArticle(w http.ResponseWriter, r *http.Request, articleURL string, extractor extractors.Extractor) {
+	req, err := http.NewRequest(http.MethodHead, articleURL, nil)
+	if err != nil {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidURL, "invalid URL").with("url", articleURL))
+		return
+	}
+	resp, err := s.feedHandler.client().Do(req)
+	if err != nil {
+		writeError(w, r, upstreamError(articleURL, nil, err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		writeError(w, r, upstreamError(articleURL, resp, nil))
+		return
+	}
+	for _, name := range []string{"Last-Modified", "ETag"} {
+		if v := resp.Header.Get(name); v != "" {
+			w.Header().Set(name, v)
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Extractor", fmt.Sprintf("%T", extractor))
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFeedHandlerHead(t *testing.T) {
+	var calls atomic.Int32
+	h := newDeadlineTestHandler(newFakeClock(), func(any) (string, []string, error) {
+		calls.Add(1)
+		return "<p>full article</p>", nil, nil
+	})
+	head := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/feed?url="+url.QueryEscape(testFeedURL), nil))
+		return rec
+	}
+
+	rec := head()
+	if rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "MISS" || rec.Header().Get("X-Item-Count") != "2" {
+		t.Fatalf("uncached HEAD: %d, X-Cache %q, X-Item-Count %q", rec.Code, rec.Header().Get("X-Cache"), rec.Header().Get("X-Item-Count"))
+	}
+	if rec.Body.Len() != 0 || calls.Load() != 0 {
+		t.Fatalf("HEAD rendered the feed: %d bytes, %d extractions", rec.Body.Len(), calls.Load())
+	}
+
+	getTestFeed(t, h)
+	rec = head()
+	if rec.Header().Get("X-Cache") != "HIT" || rec.Header().Get("X-Item-Count") != "2" || rec.Header().Get("Last-Modified") == "" {
+		t.Errorf("cached HEAD headers = %v", rec.Header())
+	}
+	if calls.Load() != 2 {
+		t.Errorf("%d extractions, want 2", calls.Load())
+	}
+}
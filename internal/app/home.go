@@ -0,0 +1,83 @@
+// internal/app/home.go
+package app
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gofull/internal/extractors"
+)
+
+// webFS holds the home page template and the static files it uses.
+//
+//go:embed web
+var webFS embed.FS
+
+// homeTemplate renders the home page.
+var homeTemplate = template.Must(template.ParseFS(webFS, "web/templates/home.html"))
+
+// homePage is the data of the home page.
+type homePage struct {
+	DefaultLimit int
+	MaxLimit     int
+	// Sentiment is set when sentiment scoring is enabled
+	Sentiment bool
+	ItemTypes []string
+	Sites     []homeSite
+}
+
+// homeSite is a site with its own extractor.
+type homeSite struct {
+	Domain    string
+	Extractor string
+}
+
+// handleHome serves the home page: a request builder for /feed, usage and
+// example outputs, and the sites with their own extractors.
+func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	page := homePage{ItemTypes: itemTypes, Sentiment: s.sentiment != nil}
+	if s.cfg != nil {
+		page.DefaultLimit, page.MaxLimit = s.cfg.DefaultLimit, s.cfg.MaxLimit
+	}
+	if s.extractorReg != nil {
+		page.Sites = siteExtractors(s.extractorReg.DomainExtractors())
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := homeTemplate.Execute(w, page); err != nil {
+		log.Printf("⚠️  Failed to render the home page: %v", err)
+	}
+}
+
+// siteExtractors lists the domains with their own extractor, sorted, once
+// each whether registered with www. or not.
+func siteExtractors(registered map[string]extractors.Extractor) []homeSite {
+	seen := map[string]bool{}
+	var sites []homeSite
+	for domain, ext := range registered {
+		domain = strings.TrimPrefix(domain, "www.")
+		if seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		name := fmt.Sprintf("%T", ext)
+		name = name[strings.LastIndexByte(name, '.')+1:]
+		sites = append(sites, homeSite{Domain: domain, Extractor: name})
+	}
+	slices.SortFunc(sites, func(a, b homeSite) int { return strings.Compare(a.Domain, b.Domain) })
+	return sites
+}
+
+// staticHandler serves the static files of the home page under /static/.
+func staticHandler() http.Handler {
+	static, err := fs.Sub(webFS, "web/static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/static/", http.FileServerFS(static))
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gofull/internal/extractors"
+)
+
+type homeTestExtractor struct{}
+
+func (homeTestExtractor) Extract(input any) (string, []string, error) { return "", nil, nil }
+
+func TestHandleHome(t *testing.T) {
+	reg := extractors.NewRegistry()
+	reg.RegisterDomain("www.news.example.com", homeTestExtractor{})
+	reg.RegisterDomain("news.example.com", homeTestExtractor{})
+	s := &Server{cfg: &Config{DefaultLimit: 10, MaxLimit: 100}, extractorReg: reg}
+
+	rec := httptest.NewRecorder()
+	s.handleHome(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<tr><td>news.example.com</td><td>homeTestExtractor</td></tr>`,
+		`placeholder="10" min="1" max="100"`,
+		`<select name="type:opinion">`,
+		`<script src="/static/home.js">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("home page lacks %s", want)
+		}
+	}
+	if strings.Count(body, "<td>news.example.com</td>") != 1 {
+		t.Error("www. and bare domains listed twice")
+	}
+	if strings.Contains(body, `name="sentiment"`) {
+		t.Error("sentiment offered while scoring is disabled")
+	}
+
+	rec = httptest.NewRecorder()
+	staticHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/home.js", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "include_types") {
+		t.Errorf("/static/home.js: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,139 @@
+// internal/app/hooks.go
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// FeedHook transforms the parsed upstream feed at feedURL before its items
+// are extracted, e.g. to drop or rewrite items.
+type FeedHook interface {
+	TransformFeed(feedURL string, feed *gofeed.Feed) error
+}
+
+// ItemHook transforms the items of the feed at feedURL after extraction,
+// before they are written, e.g. to add enrichments.
+type ItemHook interface {
+	TransformItem(feedURL string, item *Item) error
+}
+
+// FeedHookFunc adapts a function to FeedHook.
+type FeedHookFunc func(feedURL string, feed *gofeed.Feed) error
+
+func (f FeedHookFunc) TransformFeed(feedURL string, feed *gofeed.Feed) error { return f(feedURL, feed) }
+
+// ItemHookFunc adapts a function to ItemHook.
+type ItemHookFunc func(feedURL string, item *Item) error
+
+func (f ItemHookFunc) TransformItem(feedURL string, item *Item) error { return f(feedURL, item) }
+
+// transformRequest is what a WebhookTransformer posts: the feed, or the
+// item, to transform with the URL of its feed.
+type transformRequest struct {
+	Stage   string       `json:"stage"`
+	FeedURL string       `json:"feed_url"`
+	Feed    *gofeed.Feed `json:"feed,omitempty"`
+	Item    *Item        `json:"item,omitempty"`
+}
+
+// WebhookTransformer is a FeedHook and ItemHook handing feeds and items to
+// an external service. It posts {"stage": "feed" or "item", "feed_url",
+// "feed" or "item"} as JSON and reads back the transformed feed or item in
+// the same shape, which replaces the original; 204 No Content leaves it as
+// it is.
+type WebhookTransformer struct {
+	URL    string
+	Client *http.Client
+}
+
+func (t *WebhookTransformer) TransformFeed(feedURL string, feed *gofeed.Feed) error {
+	answer, err := t.transform(transformRequest{Stage: "feed", FeedURL: feedURL, Feed: feed})
+	if err == nil && answer.Feed != nil {
+		*feed = *answer.Feed
+	}
+	return err
+}
+
+func (t *WebhookTransformer) TransformItem(feedURL string, item *Item) error {
+	answer, err := t.transform(transformRequest{Stage: "item", FeedURL: feedURL, Item: item})
+	if err == nil && answer.Item != nil {
+		// What the item was extracted with is not the transformer's to change
+		answer.Item.partial, answer.Item.source, answer.Item.trace, answer.Item.err = item.partial, item.source, item.trace, item.err
+		*item = *answer.Item
+	}
+	return err
+}
+
+// transform posts req and returns the answer, empty for 204 No Content.
+func (t *WebhookTransformer) transform(req transformRequest) (transformRequest, error) {
+	var answer transformRequest
+	client := t.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return answer, err
+	}
+	resp, err := client.Post(t.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return answer, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return answer, nil
+	}
+	if resp.StatusCode >= 300 {
+		return answer, fmt.Errorf("%s answered %s", t.URL, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTransformSize)).Decode(&answer); err != nil {
+		return transformRequest{}, fmt.Errorf("%s answered invalid JSON: %w", t.URL, err)
+	}
+	return answer, nil
+}
+
+// maxTransformSize bounds the answers of webhook transformers.
+const maxTransformSize = 10 << 20
+
+// runFeedHooks runs the handler's feed hooks on feed. A failing hook is
+// logged and the feed goes on to the next as the failing one left it.
+func (h *FeedHandler) runFeedHooks(feedURL string, feed *gofeed.Feed) {
+	for _, hook := range h.FeedHooks {
+		if err := hook.TransformFeed(feedURL, feed); err != nil {
+			log.Printf("⚠️  Feed hook %T failed for %s: %v", hook, feedURL, err)
+		}
+	}
+}
+
+// runItemHooks runs the handler's item hooks on item, logging those that
+// fail.
+func (h *FeedHandler) runItemHooks(feedURL string, item Item) Item {
+	for _, hook := range h.ItemHooks {
+		if err := hook.TransformItem(feedURL, &item); err != nil {
+			log.Printf("⚠️  Item hook %T failed for %s: %v", hook, item.Link, err)
+		}
+	}
+	return item
+}
+
+// configuredHooks returns the hooks of cfg, its Go hooks followed by its
+// webhook transformers.
+func configuredHooks(cfg *Config) ([]FeedHook, []ItemHook) {
+	feedHooks := append([]FeedHook(nil), cfg.FeedHooks...)
+	for _, target := range cfg.FeedTransformers {
+		feedHooks = append(feedHooks, &WebhookTransformer{URL: target})
+	}
+	itemHooks := append([]ItemHook(nil), cfg.ItemHooks...)
+	for _, target := range cfg.ItemTransformers {
+		itemHooks = append(itemHooks, &WebhookTransformer{URL: target})
+	}
+	return feedHooks, itemHooks
+}
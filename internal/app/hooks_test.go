@@ -0,0 +1,65 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestFeedHooks(t *testing.T) {
+	doer := func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }
+	h := newTestFeedHandler(doer, newFakeClock(), 0)
+
+	// The feed hook drops the second item, the webhook tags the others
+	h.FeedHooks = []FeedHook{FeedHookFunc(func(feedURL string, feed *gofeed.Feed) error {
+		feed.Items = feed.Items[:1]
+		return nil
+	})}
+	enricher := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req transformRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Stage != "item" || req.FeedURL != testFeedURL || req.Item == nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		req.Item.Tags = append(req.Item.Tags, "enriched")
+		json.NewEncoder(w).Encode(req)
+	}))
+	defer enricher.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	h.ItemHooks = []ItemHook{&WebhookTransformer{URL: failing.URL}, &WebhookTransformer{URL: enricher.URL}}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL), nil))
+	var resp struct {
+		Items []Item `json:"items"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body)
+	}
+	if len(resp.Items) != 1 || !strings.HasSuffix(resp.Items[0].Link, "/a/1") {
+		t.Fatalf("items = %+v; want only the first", resp.Items)
+	}
+	if tags := resp.Items[0].Tags; len(tags) != 1 || tags[0] != "enriched" {
+		t.Errorf("tags = %v; want the webhook's", tags)
+	}
+}
+
+func TestWebhookTransformerNoContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	item := Item{Title: "Title", source: "extractor"}
+	if err := (&WebhookTransformer{URL: srv.URL}).TransformItem(testFeedURL, &item); err != nil || item.Title != "Title" || item.source != "extractor" {
+		t.Errorf("item = %+v, %v; want it unchanged", item, err)
+	}
+}
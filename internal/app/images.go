@@ -0,0 +1,210 @@
+// internal/app/images.go
+package app
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxItemImages caps the images listed for an item.
+const maxItemImages = 10
+
+// ItemImage is an image of an article with its caption and photo credit.
+type ItemImage struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption,omitempty"`
+	Credit  string `json:"credit,omitempty"`
+}
+
+// creditAttrs are attributes of images and figures naming the photographer
+// or agency.
+var creditAttrs = []string{"data-credit", "data-copyright", "data-photographer", "data-author", "data-source"}
+
+// creditClassHints mark the elements of a caption holding the credit.
+var creditClassHints = []string{"credit", "copyright", "photographer", "source", "kaynak"}
+
+// captionCreditRegex matches a credit written at the end of a caption:
+// "(Foto: AA)", "Photo: Reuters", "© AFP".
+var captionCreditRegex = regexp.MustCompile(`(?i)\s*[(\[]?\s*(?:(?:foto(?:ğraf)?|photo(?:graph)?|image|görsel|credit|kaynak)\s*:|©)\s*([^()\[\]]+?)\s*[)\]]?\s*$`)
+
+// captionedImages returns the images of extracted article HTML that carry a
+// caption or credit, by absolute URL. Captions come from the figcaption of
+// the figure holding the image; credits from credit attributes, credit
+// elements inside the caption or a "Photo: ..." suffix.
+func captionedImages(content, base string) map[string]ItemImage {
+	if !strings.Contains(content, "<img") {
+		return nil
+	}
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+	baseURL, _ := url.Parse(base)
+
+	found := make(map[string]ItemImage)
+	var walk func(n *html.Node, figure *html.Node)
+	walk = func(n *html.Node, figure *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Figure:
+				walk(c, c)
+				continue
+			case atom.Img:
+				src := resolveAgainst(baseURL, firstNonEmpty(nodeAttr(c, "data-src"), nodeAttr(c, "src")))
+				if src == "" {
+					continue
+				}
+				img := ItemImage{URL: src, Credit: attrCredit(c)}
+				if figure != nil {
+					caption, credit := figureCaption(figure)
+					img.Caption = caption
+					img.Credit = firstNonEmpty(img.Credit, attrCredit(figure), credit)
+				}
+				if img.Caption != "" || img.Credit != "" {
+					if _, ok := found[src]; !ok {
+						found[src] = img
+					}
+				}
+				continue
+			}
+			walk(c, figure)
+		}
+	}
+	walk(root, nil)
+	return found
+}
+
+// figureCaption returns the caption text of a figure and the credit it names.
+func figureCaption(figure *html.Node) (caption, credit string) {
+	var figcaption *html.Node
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && figcaption == nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.DataAtom == atom.Figcaption {
+				figcaption = c
+				return
+			}
+			find(c)
+		}
+	}
+	find(figure)
+	if figcaption == nil {
+		return "", ""
+	}
+
+	var text strings.Builder
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				text.WriteString(c.Data)
+			case c.Type == html.ElementNode && isCreditElement(c):
+				if credit == "" {
+					credit = strings.TrimSpace(collapseWhitespace(nodeText(c)))
+				}
+			case c.Type == html.ElementNode:
+				collect(c)
+			}
+		}
+	}
+	collect(figcaption)
+
+	caption = strings.TrimSpace(collapseWhitespace(text.String()))
+	if m := captionCreditRegex.FindStringSubmatchIndex(caption); m != nil {
+		if credit == "" {
+			credit = caption[m[2]:m[3]]
+		}
+		caption = strings.TrimSpace(caption[:m[0]])
+	}
+	credit = strings.Trim(captionCreditRegex.ReplaceAllString(credit, "$1"), " ()[]")
+	return caption, credit
+}
+
+// isCreditElement reports whether a caption element holds the photo credit.
+func isCreditElement(n *html.Node) bool {
+	if n.DataAtom == atom.Cite {
+		return true
+	}
+	class := strings.ToLower(nodeAttr(n, "class"))
+	for _, hint := range creditClassHints {
+		if strings.Contains(class, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// attrCredit returns the credit named by n's attributes.
+func attrCredit(n *html.Node) string {
+	for _, name := range creditAttrs {
+		if v := nodeAttr(n, name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// nodeText returns the text below n.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				b.WriteString(c.Data)
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// resolveAgainst makes ref absolute against base, returning "" for data URIs
+// and unparsable references.
+func resolveAgainst(base *url.URL, ref string) string {
+	if ref == "" || strings.HasPrefix(ref, "data:") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// itemImages lists an item's images, the lead image first, with the captions
+// and credits found in its content.
+func itemImages(lead string, images []string, captions map[string]ItemImage) []ItemImage {
+	if lead == "" {
+		return nil
+	}
+	out := []ItemImage{{URL: lead}}
+	seen := map[string]bool{lead: true}
+	for _, u := range images {
+		if len(out) == maxItemImages {
+			break
+		}
+		if u != "" && !seen[u] {
+			seen[u] = true
+			out = append(out, ItemImage{URL: u})
+		}
+	}
+	for i := range out {
+		if c, ok := captions[out[i].URL]; ok {
+			out[i].Caption, out[i].Credit = c.Caption, c.Credit
+		}
+	}
+	return out
+}
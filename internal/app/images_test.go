@@ -0,0 +1,27 @@
+package app
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestCaptionedImages(t *testing.T) {
+	content := `<article>
+		<figure data-credit="Reuters"><img src="/a.jpg"><figcaption>Protesters in the square</figcaption></figure>
+		<figure><img src="https://cdn.test/b.jpg"><figcaption>Harbour at dawn (Foto: Ali Veli)</figcaption></figure>
+		<figure><picture><img data-src="/c.jpg" src="data:image/gif;base64,R0lGOD"></picture><figcaption>Bridge <cite>© AFP</cite></figcaption></figure>
+		<figure><img src="/d.jpg"></figure>
+		<p><img src="/e.jpg" data-photographer="Ayşe Kaya"></p>
+	</article>`
+
+	got := captionedImages(content, "https://news.test/2024/story")
+	want := map[string]ItemImage{
+		"https://news.test/a.jpg": {URL: "https://news.test/a.jpg", Caption: "Protesters in the square", Credit: "Reuters"},
+		"https://cdn.test/b.jpg":  {URL: "https://cdn.test/b.jpg", Caption: "Harbour at dawn", Credit: "Ali Veli"},
+		"https://news.test/c.jpg": {URL: "https://news.test/c.jpg", Caption: "Bridge", Credit: "AFP"},
+		"https://news.test/e.jpg": {URL: "https://news.test/e.jpg", Credit: "Ayşe Kaya"},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("captionedImages =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+// internal/app/isolate.go
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"gofull/internal/extractors"
+)
+
+// errExtractorPanic is wrapped by the errors of extractors that panicked.
+var errExtractorPanic = errors.New("extractor panicked")
+
+// safeExtract runs e on input, turning a panic into an error so one
+// malformed page costs its own item rather than the whole feed.
+func safeExtract(e extractors.Extractor, input any) (content string, images []string, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			log.Printf("💥 %T panicked on %s: %v\n%s", e, extractInputURL(input), v, debug.Stack())
+			content, images, err = "", nil, fmt.Errorf("%w: %v", errExtractorPanic, v)
+		}
+	}()
+	return e.Extract(input)
+}
+
+// extractInputURL returns the URL an extractor input refers to, for logging.
+func extractInputURL(input any) string {
+	switch v := input.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if link, ok := v["link"].(string); ok {
+			return link
+		}
+	}
+	return "?"
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSafeExtractRecoversPanics(t *testing.T) {
+	var extractor extractorFunc = func(input any) (string, []string, error) {
+		var doc *struct{ body string }
+		return doc.body, nil, nil
+	}
+	content, images, err := safeExtract(extractor, map[string]interface{}{"link": "https://news.example.com/a/1"})
+	if !errors.Is(err, errExtractorPanic) {
+		t.Fatalf("err = %v, want an extractor panic", err)
+	}
+	if content != "" || images != nil {
+		t.Errorf("got content %q and images %v from a panicking extractor", content, images)
+	}
+}
+
+func TestSafeExtractPassesResults(t *testing.T) {
+	var extractor extractorFunc = func(input any) (string, []string, error) {
+		return "<p>" + input.(string) + "</p>", []string{"https://img.example.com/1.jpg"}, nil
+	}
+	content, images, err := safeExtract(extractor, "article")
+	if err != nil || content != "<p>article</p>" || len(images) != 1 {
+		t.Errorf("got %q, %v, %v", content, images, err)
+	}
+}
@@ -0,0 +1,148 @@
+// internal/app/itemtypes.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Item types, reported as item_type.
+const (
+	itemNews    = "news"
+	itemOpinion = "opinion"
+	itemGallery = "gallery"
+	itemVideo   = "video"
+)
+
+// itemTypes are the item types in the order they are documented.
+var itemTypes = []string{itemNews, itemOpinion, itemGallery, itemVideo}
+
+// opinionPaths mark the URLs of columns and opinion pieces. Site filters
+// usually block them; include_types=opinion lets them through, labeled.
+var opinionPaths = []string{"/yazar/", "/yazarlar/", "/kose-yazisi/", "/kose-yazilari/", "/kose/", "/gorus/", "/gorusler/", "/opinion/", "/columnists/", "/column/"}
+
+// opinionCategories are the feed categories of opinion pieces, normalized.
+var opinionCategories = []string{"yazarlar", "yazar", "köşe yazıları", "köşe yazısı", "köşe", "görüş", "görüşler", "yorum", "opinion", "columnists", "column"}
+
+// maxBylineText is the length of text under which a block opening an
+// article with its author's name is a columnist's byline.
+const maxBylineText = 80
+
+// isOpinionURL reports whether link is a column or opinion piece.
+func isOpinionURL(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	path := strings.ToLower(u.Path)
+	for _, p := range opinionPaths {
+		if strings.Contains(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// urlItemType returns the type link's URL reveals, "" when it reveals none.
+func urlItemType(link string) string {
+	switch {
+	case isGalleryURL(link):
+		return itemGallery
+	case isVideoURL(link):
+		return itemVideo
+	case isOpinionURL(link):
+		return itemOpinion
+	}
+	return ""
+}
+
+// classifyItem returns the type of item: galleries and videos as they were
+// extracted, opinion pieces by their URL, their feed category or a byline
+// heading the article, news otherwise.
+func classifyItem(item Item) string {
+	switch {
+	case item.Gallery:
+		return itemGallery
+	case item.Video != nil:
+		return itemVideo
+	case isOpinionURL(item.Link), hasOpinionCategory(item.Categories), hasByline(item):
+		return itemOpinion
+	}
+	return itemNews
+}
+
+// hasOpinionCategory reports whether one of an item's feed categories is
+// that of opinion pieces.
+func hasOpinionCategory(categories []string) bool {
+	for _, c := range categories {
+		if slices.Contains(opinionCategories, normalizePhrase(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasByline reports whether item is laid out as a column: its author named
+// at the start of the title ("Ahmet Yılmaz: ..." or "Ahmet Yılmaz yazdı")
+// or in a short block opening the article.
+func hasByline(item Item) bool {
+	author := normalizePhrase(item.Author)
+	if author == "" {
+		return false
+	}
+	if title := normalizePhrase(item.Title); strings.HasPrefix(title, author+":") || strings.HasPrefix(title, author+" yazdı") {
+		return true
+	}
+	first, _, _ := strings.Cut(item.Content, "</")
+	text := normalizePhrase(cleanHTMLTags(first))
+	return strings.HasPrefix(text, author) && len([]rune(text)) <= maxBylineText
+}
+
+// parseItemTypes returns the item types listed by the query parameter
+// param of r, comma-separated or repeated.
+func parseItemTypes(r *http.Request, param string) ([]string, *APIError) {
+	var types []string
+	for _, value := range r.URL.Query()[param] {
+		for t := range strings.SplitSeq(value, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if t == "" {
+				continue
+			}
+			if !slices.Contains(itemTypes, t) {
+				return nil, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+					fmt.Sprintf("unsupported item type '%s' (use %s)", t, strings.Join(itemTypes, ", "))).with("parameter", param).with("value", t)
+			}
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+	slices.Sort(types)
+	return types, nil
+}
+
+// letThrough reports whether link is a gallery, video or opinion piece p
+// asks for, which passes the site filters.
+func (p feedParams) letThrough(link string) bool {
+	switch urlItemType(link) {
+	case itemGallery:
+		return p.Galleries || p.Media || slices.Contains(p.IncludeTypes, itemGallery)
+	case itemVideo:
+		return p.Media || slices.Contains(p.IncludeTypes, itemVideo)
+	case itemOpinion:
+		return slices.Contains(p.IncludeTypes, itemOpinion)
+	}
+	return false
+}
+
+// wantsType reports whether items of type t are returned with p: listed in
+// IncludeTypes when it is set, and not in ExcludeTypes.
+func (p feedParams) wantsType(t string) bool {
+	if len(p.IncludeTypes) > 0 && !slices.Contains(p.IncludeTypes, t) {
+		return false
+	}
+	return !slices.Contains(p.ExcludeTypes, t)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gofull/internal/extractors/filters"
+)
+
+func TestClassifyItem(t *testing.T) {
+	cases := []struct {
+		name string
+		item Item
+		want string
+	}{
+		{"news", Item{Link: "https://news.example.com/ekonomi/faiz-karari", Author: "Ahmet Yılmaz", Content: "<p>Merkez Bankası faizi sabit tuttu ve piyasalar karara olumlu tepki verdi.</p>"}, itemNews},
+		{"gallery", Item{Link: "https://news.example.com/galeri/derbi", Gallery: true}, itemGallery},
+		{"video", Item{Link: "https://news.example.com/video/derbi", Video: &ItemVideo{PlayerURL: "https://player.example.com/1"}}, itemVideo},
+		{"opinion URL", Item{Link: "https://news.example.com/yazarlar/ahmet-yilmaz/ekonomide-yeni-donem"}, itemOpinion},
+		{"opinion category", Item{Link: "https://news.example.com/haber/1", Categories: []string{"Köşe Yazıları"}}, itemOpinion},
+		{"byline title", Item{Link: "https://news.example.com/haber/2", Author: "Ahmet Yılmaz", Title: "Ahmet Yılmaz: Ekonomide yeni dönem"}, itemOpinion},
+		{"byline block", Item{Link: "https://news.example.com/haber/3", Author: "Ahmet Yılmaz", Content: "<p><strong>Ahmet Yılmaz</strong> - Ekonomi yazarı</p><p>Bu hafta faiz kararını konuşalım.</p>"}, itemOpinion},
+	}
+	for _, tc := range cases {
+		if got := classifyItem(tc.item); got != tc.want {
+			t.Errorf("%s: classifyItem = %q; want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFeedItemTypes(t *testing.T) {
+	const (
+		newsLink    = "https://news.example.com/ekonomi/faiz-karari"
+		opinionLink = "https://news.example.com/yazar/ahmet-yilmaz/ekonomide-yeni-donem"
+	)
+	feed := `<?xml version="1.0"?><rss version="2.0"><channel><title>Example News</title>
+<item><title>Faiz kararı açıklandı</title><link>` + newsLink + `</link><description>Merkez Bankası faizi sabit tuttu.</description><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+<item><title>Ekonomide yeni dönem</title><link>` + opinionLink + `</link><description>Bu hafta faiz kararını konuşalım.</description><pubDate>Mon, 01 Jan 2024 09:00:00 +0000</pubDate></item>
+</channel></rss>`
+	h := newTestFeedHandler(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host == "news.example.com" && r.URL.Path != "/rss" {
+			return respond(http.StatusOK, `<html><body><article><p>Makalenin metni.</p></article></body></html>`), nil
+		}
+		return respond(http.StatusOK, feed), nil
+	}, newFakeClock(), 0)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/yazar/"}})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+query, nil))
+		return rec
+	}
+	types := func(query string) map[string]string {
+		t.Helper()
+		rec := get(query)
+		var resp struct{ Items []Item }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v (status %d)", query, err, rec.Code)
+		}
+		got := map[string]string{}
+		for _, item := range resp.Items {
+			got[item.Link] = item.ItemType
+		}
+		return got
+	}
+
+	if rec := get("&include_types=column"); rec.Code != http.StatusBadRequest {
+		t.Errorf("include_types=column: status %d; want 400", rec.Code)
+	}
+	if got := types(""); len(got) != 1 || got[newsLink] != itemNews {
+		t.Errorf("default items = %v; want the news item only, labeled", got)
+	}
+	if got := types("&include_types=opinion"); len(got) != 1 || got[opinionLink] != itemOpinion {
+		t.Errorf("include_types=opinion items = %v; want the column only, labeled", got)
+	}
+	if got := types("&include_types=news,opinion"); len(got) != 2 {
+		t.Errorf("include_types=news,opinion items = %v; want both", got)
+	}
+	if got := types("&include_types=news&include_types=opinion&exclude_types=news"); len(got) != 1 || got[opinionLink] != itemOpinion {
+		t.Errorf("excluding news items = %v; want the column only", got)
+	}
+}
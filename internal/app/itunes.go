@@ -0,0 +1,160 @@
+// internal/app/itunes.go
+package app
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// itunesNamespace is the namespace of the itunes: podcast elements.
+const itunesNamespace = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+
+// ItemITunes is the iTunes podcast metadata of the upstream item, passed
+// through to RSS output so podcast apps keep working behind the proxy.
+type ItemITunes struct {
+	Image       string `json:"image,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	Episode     string `json:"episode,omitempty"`
+	Season      string `json:"season,omitempty"`
+	EpisodeType string `json:"episode_type,omitempty"`
+	Explicit    string `json:"explicit,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Subtitle    string `json:"subtitle,omitempty"`
+}
+
+// itemITunes returns the iTunes metadata of the feed item, nil when it has
+// none.
+func itemITunes(i *gofeed.Item) *ItemITunes {
+	it := i.ITunesExt
+	if it == nil {
+		return nil
+	}
+	out := ItemITunes{
+		Image:       strings.TrimSpace(it.Image),
+		Duration:    strings.TrimSpace(it.Duration),
+		Episode:     strings.TrimSpace(it.Episode),
+		Season:      strings.TrimSpace(it.Season),
+		EpisodeType: strings.TrimSpace(it.EpisodeType),
+		Explicit:    strings.TrimSpace(it.Explicit),
+		Author:      strings.TrimSpace(it.Author),
+		Subtitle:    strings.TrimSpace(it.Subtitle),
+	}
+	if out == (ItemITunes{}) {
+		return nil
+	}
+	return &out
+}
+
+// feedITunes is the iTunes podcast metadata of the upstream channel. The
+// itunes:new-feed-url is left out: it would move subscribers off the proxy.
+type feedITunes struct {
+	Author     string
+	Subtitle   string
+	Summary    string
+	Image      string
+	Explicit   string
+	Type       string
+	Block      string
+	Complete   string
+	OwnerName  string
+	OwnerEmail string
+	Categories []*ext.ITunesCategory
+}
+
+// channelITunes returns the iTunes metadata of the feed, nil when it has
+// none.
+func channelITunes(feed *gofeed.Feed) *feedITunes {
+	it := feed.ITunesExt
+	if it == nil {
+		return nil
+	}
+	out := &feedITunes{
+		Author:     it.Author,
+		Subtitle:   it.Subtitle,
+		Summary:    it.Summary,
+		Image:      it.Image,
+		Explicit:   it.Explicit,
+		Type:       it.Type,
+		Block:      it.Block,
+		Complete:   it.Complete,
+		Categories: it.Categories,
+	}
+	if it.Owner != nil {
+		out.OwnerName, out.OwnerEmail = it.Owner.Name, it.Owner.Email
+	}
+	return out
+}
+
+// rssITunesImage is an itunes:image element.
+type rssITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type rssITunesOwner struct {
+	Name  string `xml:"itunes:name,omitempty"`
+	Email string `xml:"itunes:email,omitempty"`
+}
+
+type rssITunesCategory struct {
+	Text        string             `xml:"text,attr"`
+	Subcategory *rssITunesCategory `xml:"itunes:category,omitempty"`
+}
+
+// newRSSITunesCategory converts a category with its subcategories.
+func newRSSITunesCategory(c *ext.ITunesCategory) *rssITunesCategory {
+	if c == nil || c.Text == "" {
+		return nil
+	}
+	return &rssITunesCategory{Text: c.Text, Subcategory: newRSSITunesCategory(c.Subcategory)}
+}
+
+// writeITunesChannel writes the itunes: elements of the channel to w.
+func writeITunesChannel(w io.Writer, it *feedITunes) error {
+	type element struct {
+		name  string
+		value any
+	}
+	var elements []element
+	for _, el := range []element{
+		{"author", it.Author},
+		{"subtitle", it.Subtitle},
+		{"summary", it.Summary},
+		{"explicit", it.Explicit},
+		{"type", it.Type},
+		{"block", it.Block},
+		{"complete", it.Complete},
+	} {
+		if el.value != "" {
+			elements = append(elements, el)
+		}
+	}
+	if it.Image != "" {
+		elements = append(elements, element{"image", rssITunesImage{Href: it.Image}})
+	}
+	if it.OwnerName != "" || it.OwnerEmail != "" {
+		elements = append(elements, element{"owner", rssITunesOwner{Name: it.OwnerName, Email: it.OwnerEmail}})
+	}
+	for _, c := range it.Categories {
+		if category := newRSSITunesCategory(c); category != nil {
+			elements = append(elements, element{"category", category})
+		}
+	}
+
+	enc := xml.NewEncoder(w)
+	for _, el := range elements {
+		if err := enc.EncodeElement(el.value, xml.StartElement{Name: xml.Name{Local: "itunes:" + el.name}}); err != nil {
+			return err
+		}
+		if err := enc.Flush(); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+// internal/app/jobs.go
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/storage"
+)
+
+// Job statuses.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Key prefixes used for jobs in the storage backend.
+const (
+	jobKeyPrefix    = "jobs/"
+	resultKeyPrefix = "job-results/"
+)
+
+// Job tracks the progress of a long-running background operation.
+type Job struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	Total      int       `json:"total"`
+	Done       int       `json:"done"`
+	Failed     int       `json:"failed"`
+	Error      string    `json:"error,omitempty"`
+	ResultType string    `json:"result_type,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// JobStore keeps track of background jobs, persisting them in a storage
+// backend so status and results survive restarts with the file backend.
+type JobStore struct {
+	mu    sync.Mutex
+	store storage.Store
+}
+
+// NewJobStore creates a JobStore backed by store.
+func NewJobStore(store storage.Store) *JobStore {
+	return &JobStore{store: store}
+}
+
+// Create registers a new queued job of the given kind.
+func (s *JobStore) Create(kind string) Job {
+	now := time.Now()
+	job := Job{
+		ID:        extractors.GenerateUniqueID(),
+		Kind:      kind,
+		Status:    JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.mu.Lock()
+	s.save(job)
+	s.mu.Unlock()
+	return job
+}
+
+// Get returns a snapshot of the job with the given ID.
+func (s *JobStore) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// Update applies fn to the job with the given ID under the store's lock.
+func (s *JobStore) Update(id string, fn func(job *Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job, ok := s.load(id); ok {
+		fn(&job)
+		job.UpdatedAt = time.Now()
+		s.save(job)
+	}
+}
+
+// SetResult stores the output of a finished job and marks it as done.
+func (s *JobStore) SetResult(id, contentType string, body []byte) {
+	if err := s.store.Put(resultKeyPrefix+id, body); err != nil {
+		log.Printf("❌ Failed to store result of job %s: %v", id, err)
+		s.Update(id, func(job *Job) {
+			job.Status = JobFailed
+			job.Error = "failed to store result"
+		})
+		return
+	}
+	s.Update(id, func(job *Job) {
+		job.Status = JobDone
+		job.ResultType = contentType
+	})
+}
+
+// Result returns the stored output of a job, if any.
+func (s *JobStore) Result(id string) ([]byte, bool) {
+	body, err := s.store.Get(resultKeyPrefix + id)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// Prune removes finished jobs and their results last updated before cutoff.
+func (s *JobStore) Prune(cutoff time.Time) int {
+	keys, err := s.store.Keys(jobKeyPrefix)
+	if err != nil {
+		log.Printf("⚠️  Could not list jobs: %v", err)
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for _, key := range keys {
+		id := strings.TrimPrefix(key, jobKeyPrefix)
+		job, ok := s.load(id)
+		if !ok || (job.Status != JobDone && job.Status != JobFailed) || job.UpdatedAt.After(cutoff) {
+			continue
+		}
+		s.store.Delete(jobKeyPrefix + id)
+		s.store.Delete(resultKeyPrefix + id)
+		removed++
+	}
+	return removed
+}
+
+// load reads a job from the backend. Callers must hold s.mu.
+func (s *JobStore) load(id string) (Job, bool) {
+	if id == "" || strings.ContainsAny(id, "/\\.") {
+		return Job{}, false
+	}
+	data, err := s.store.Get(jobKeyPrefix + id)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("⚠️  Could not load job %s: %v", id, err)
+		}
+		return Job{}, false
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		log.Printf("⚠️  Corrupt job %s: %v", id, err)
+		return Job{}, false
+	}
+	return job, true
+}
+
+// save writes a job to the backend. Callers must hold s.mu.
+func (s *JobStore) save(job Job) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("❌ Failed to encode job %s: %v", job.ID, err)
+		return
+	}
+	if err := s.store.Put(jobKeyPrefix+job.ID, data); err != nil {
+		log.Printf("❌ Failed to store job %s: %v", job.ID, err)
+	}
+}
+
+// handleJobStatus reports the progress of any background job.
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// handleJobResult serves the output of a finished job.
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, ok := s.jobs.Get(id)
+	if !ok {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "job not found"))
+		return
+	}
+
+	switch job.Status {
+	case JobFailed:
+		writeError(w, r, newAPIError(http.StatusBadGateway, CodeJobFailed, "job failed").with("error", job.Error))
+		return
+	case JobDone:
+	default:
+		// Not finished yet: tell the client to keep polling
+		w.Header().Set("Retry-After", "2")
+		writeJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	body, ok := s.jobs.Result(id)
+	if !ok {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "job has no result"))
+		return
+	}
+	if job.ResultType != "" {
+		w.Header().Set("Content-Type", job.ResultType)
+	}
+	w.Write(body)
+}
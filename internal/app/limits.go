@@ -0,0 +1,196 @@
+// internal/app/limits.go
+package app
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+// lookahead extracts the items of a feed ahead of the one being written,
+// so up to size more extractions run alongside it.
+type lookahead struct {
+	h         *FeedHandler
+	feedURL   string
+	items     []*gofeed.Item
+	filterReg *filters.FilterRegistry
+	scope     string
+	size      int
+	flow      *extractionFlow
+	started   map[*gofeed.Item]<-chan Item
+}
+
+// newLookahead returns a lookahead over the items of feedURL that
+// filterReg lets through and scope covers for the handler's
+// ItemConcurrency, extracting them in flow, nil when items are extracted one
+// at a time.
+func (h *FeedHandler) newLookahead(feedURL string, items []*gofeed.Item, filterReg *filters.FilterRegistry, scope string, flow *extractionFlow) *lookahead {
+	if h.ItemConcurrency <= 1 {
+		return nil
+	}
+	return &lookahead{h: h, feedURL: feedURL, items: items, filterReg: filterReg, scope: scope, size: h.ItemConcurrency - 1, flow: flow, started: make(map[*gofeed.Item]<-chan Item)}
+}
+
+// fill starts extracting the items from index i on that the feed will
+// need, until size are in flight or want are.
+func (l *lookahead) fill(i, want int) {
+	if l == nil {
+		return
+	}
+	for ; i < len(l.items) && len(l.started) < min(l.size, want); i++ {
+		feedItem := l.items[i]
+		if _, ok := l.started[feedItem]; ok || !l.h.pending(feedItem, l.filterReg) || !l.h.Upstream.Covers(l.scope, feedItem.Link) {
+			continue
+		}
+		l.h.Requests.Attribute(feedItem.Link, l.feedURL, feedItem.Link)
+		l.started[feedItem] = l.h.extractAsync(feedItem, l.flow)
+	}
+}
+
+// take returns the extraction started for feedItem, nil if none was.
+func (l *lookahead) take(feedItem *gofeed.Item) <-chan Item {
+	if l == nil {
+		return nil
+	}
+	done := l.started[feedItem]
+	delete(l.started, feedItem)
+	return done
+}
+
+// pending reports whether rendering the feed extracts feedItem, rather than
+// filterReg filtering it out or serving it from the archive.
+func (h *FeedHandler) pending(feedItem *gofeed.Item, filterReg *filters.FilterRegistry) bool {
+	if feedItem.Link == "" {
+		return true
+	}
+	if ok, _ := filterReg.Explain(feedItem.Link); !ok {
+		return false
+	}
+	if h.Archive != nil {
+		if stored, ok := h.Archive.Get(extractors.GenerateGUIDFromURL(feedItem.Link)); ok && !revisedUpstream(feedItem, stored) && !stored.Deleted {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLimit returns the limit parameter of r, DefaultLimit (10 when zero)
+// without one. Limits that are not positive or exceed the caller's maximum
+// are rejected with an error stating the allowed range.
+func (h *FeedHandler) parseLimit(r *http.Request) (int, *APIError) {
+	limit := 10
+	if h.DefaultLimit > 0 {
+		limit = h.DefaultLimit
+	}
+	maxLimit := h.maxLimit(r)
+	if maxLimit > 0 {
+		limit = min(limit, maxLimit)
+	}
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return limit, nil
+	}
+	n, err := strconv.Atoi(limitStr)
+	switch {
+	case maxLimit > 0 && (err != nil || n <= 0 || n > maxLimit):
+		return 0, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			fmt.Sprintf("'limit' must be an integer between 1 and %d", maxLimit)).
+			with("parameter", "limit").with("value", limitStr).with("min", 1).with("max", maxLimit)
+	case err != nil || n <= 0:
+		return 0, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"'limit' must be a positive integer").with("parameter", "limit").with("value", limitStr).with("min", 1)
+	}
+	return n, nil
+}
+
+// parseOffset returns the offset parameter of r, 0 without one.
+func parseOffset(r *http.Request) (int, *APIError) {
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(offsetStr)
+	if err != nil || n < 0 {
+		return 0, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+			"'offset' must be a non-negative integer").with("parameter", "offset").with("value", offsetStr).with("min", 0)
+	}
+	return n, nil
+}
+
+// maxLimit returns the largest limit the caller may ask for, 0 for no
+// maximum: that of the API key the request carries, as a bearer token or a
+// key parameter, else MaxLimit.
+func (h *FeedHandler) maxLimit(r *http.Request) int {
+	if len(h.KeyMaxLimits) > 0 {
+		key := requestKey(r)
+		for allowed, limit := range h.KeyMaxLimits {
+			if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+				return limit
+			}
+		}
+	}
+	return h.MaxLimit
+}
+
+// errResponseTooLarge is returned reading an upstream response past the
+// size limit.
+var errResponseTooLarge = errors.New("upstream response too large")
+
+// limitTransport sets the User-Agent of upstream requests that have none
+// and stops reading responses past maxSize bytes.
+type limitTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	maxSize   int64
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || t.maxSize <= 0 {
+		return resp, err
+	}
+	if resp.ContentLength > t.maxSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s is %d bytes", errResponseTooLarge, req.URL, resp.ContentLength)
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, left: t.maxSize}
+	return resp, nil
+}
+
+// limitedBody fails reads past its limit, so truncated pages are not taken
+// for whole ones.
+type limitedBody struct {
+	io.ReadCloser
+	left int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		// Reading one more byte tells a body of exactly the limit from a
+		// longer one
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, errResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.left -= int64(n)
+	return n, err
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+// slowExtractor takes a while over each article and records how many
+// extractions ran at once.
+type slowExtractor struct {
+	mu             sync.Mutex
+	active, maxRun int
+}
+
+func (e *slowExtractor) Extract(input any) (string, []string, error) {
+	e.mu.Lock()
+	e.active++
+	e.maxRun = max(e.maxRun, e.active)
+	e.mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	e.mu.Lock()
+	e.active--
+	e.mu.Unlock()
+	return fmt.Sprintf("<p>Article at %v</p>", input), nil, nil
+}
+
+func TestFeedItemConcurrency(t *testing.T) {
+	var feed strings.Builder
+	feed.WriteString(`<?xml version="1.0"?><rss version="2.0"><channel><title>Example News</title>`)
+	for i := 1; i <= 6; i++ {
+		fmt.Fprintf(&feed, `<item><title>Item %d</title><link>https://news.example.com/a/%d</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>`, i, i)
+	}
+	feed.WriteString(`</channel></rss>`)
+
+	ext := &slowExtractor{}
+	reg := extractors.NewRegistry()
+	reg.RegisterDefault(ext)
+	doer := doerFunc(func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, feed.String()), nil })
+	h := NewFeedHandler(NewCache(time.Minute, 0), doer, reg, filters.NewFilterRegistry(), NewArchive(0), nil)
+	h.ItemConcurrency = 4
+	h.MaxExtractions = 3
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&limit=5&url="+testFeedURL, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct{ Items []Item }
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 5 {
+		t.Fatalf("%d items, want 5", len(resp.Items))
+	}
+	for i, item := range resp.Items {
+		if want := fmt.Sprintf("Item %d", i+1); item.Title != want {
+			t.Errorf("item %d is %q; want %q", i, item.Title, want)
+		}
+	}
+	if ext.maxRun < 2 || ext.maxRun > 3 {
+		t.Errorf("%d extractions ran at once; want 2 or 3 (MaxExtractions)", ext.maxRun)
+	}
+}
+
+func TestFeedLimitDefaults(t *testing.T) {
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }, newFakeClock(), 0)
+	h.DefaultLimit = 5
+	h.MaxLimit = 1
+	h.KeyMaxLimits = map[string]int{"partner": 2}
+	tests := []struct {
+		query  string
+		header string
+		items  int
+		max    int
+	}{
+		// The default is cut to the maximum
+		{query: "", items: 1},
+		{query: "&limit=1", items: 1},
+		{query: "&limit=50", max: 1},
+		{query: "&limit=0", max: 1},
+		{query: "&limit=2&key=partner", items: 2},
+		{query: "&limit=2", header: "Bearer partner", items: 2},
+		{query: "&limit=3&key=partner", max: 2},
+		{query: "&limit=2&key=unknown", max: 1},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		h.ServeHTTP(rec, req)
+		if tt.max > 0 {
+			var apiErr APIError
+			if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusBadRequest || apiErr.Details["max"] != float64(tt.max) {
+				t.Errorf("%q: %d %s; want 400 stating the maximum %d", tt.query, rec.Code, rec.Body, tt.max)
+			}
+			continue
+		}
+		var resp struct{ Items []Item }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Items) != tt.items {
+			t.Errorf("%q: %d items, want %d", tt.query, len(resp.Items), tt.items)
+		}
+	}
+}
+
+func TestLimitTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-User-Agent", r.Header.Get("User-Agent"))
+		if r.URL.Path == "/big" {
+			// Streamed, so no Content-Length gives the size away
+			w.Write([]byte(strings.Repeat("x", 64)))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("x", 64)))
+			return
+		}
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: &limitTransport{base: http.DefaultTransport, userAgent: "TestBot/1.0", maxSize: 100}}
+	resp, err := client.Get(upstream.URL + "/small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || len(body) != 100 {
+		t.Errorf("read %d bytes, err %v; want the whole 100 byte body", len(body), err)
+	}
+	if ua := resp.Header.Get("X-User-Agent"); ua != "TestBot/1.0" {
+		t.Errorf("User-Agent = %q", ua)
+	}
+
+	resp, err = client.Get(upstream.URL + "/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !errors.Is(err, errResponseTooLarge) {
+		t.Errorf("reading past the limit: err %v; want errResponseTooLarge", err)
+	}
+}
@@ -0,0 +1,139 @@
+// internal/app/loadshed.go
+package app
+
+import (
+	"log"
+	"net/http"
+	"runtime/metrics"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// loadRetryAfter is the Retry-After, in seconds, of requests shed while the
+// server is overloaded.
+const loadRetryAfter = 10
+
+// Reasons the server sheds load.
+const (
+	overloadMemory      = "memory"
+	overloadExtractions = "extractions"
+)
+
+// heapMetric is the runtime metric of the bytes held by live and not yet
+// swept heap objects, read without stopping the world.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// LoadGuard watches the heap and the extractions in flight. Above either
+// threshold, requests that would extract are shed with 503 instead of
+// growing the heap until a small container is OOM-killed; cached responses,
+// stale ones included, are still served.
+type LoadGuard struct {
+	maxHeap    int64
+	maxPending int
+	// extractions returns the state of the extraction slots
+	extractions func() ExtractionStats
+	// readHeap returns the heap in use; tests replace it
+	readHeap func() int64
+
+	heap       atomic.Int64
+	overloaded atomic.Bool
+	shed       atomic.Int64
+}
+
+// LoadStats is the state of the load guard, reported by /stats.
+type LoadStats struct {
+	HeapBytes    int64 `json:"heap_bytes"`
+	MaxHeapBytes int64 `json:"max_heap_bytes"`
+	// Pending is the number of extractions running or queued
+	Pending    int    `json:"pending_extractions"`
+	MaxPending int    `json:"max_pending_extractions"`
+	Overloaded string `json:"overloaded,omitempty"`
+	// Shed counts the requests rejected while overloaded
+	Shed int64 `json:"shed"`
+}
+
+// NewLoadGuard creates a guard shedding load past maxHeap bytes of heap or
+// maxPending extractions running or queued (0 disables either).
+func NewLoadGuard(maxHeap int64, maxPending int, extractions func() ExtractionStats) *LoadGuard {
+	return &LoadGuard{maxHeap: maxHeap, maxPending: maxPending, extractions: extractions, readHeap: readHeap}
+}
+
+// readHeap returns the bytes of heap in use.
+func readHeap() int64 {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(sample[0].Value.Uint64())
+}
+
+// Watch samples the heap every interval, logging when the server starts
+// and stops shedding load. It never returns.
+func (g *LoadGuard) Watch(interval time.Duration) {
+	g.sample()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		g.sample()
+	}
+}
+
+// sample reads the heap in use.
+func (g *LoadGuard) sample() {
+	g.heap.Store(g.readHeap())
+	reason, overloaded := g.Overloaded()
+	if g.overloaded.Swap(overloaded) != overloaded {
+		if overloaded {
+			log.Printf("🚨 Shedding load: %s over threshold (heap %d bytes)", reason, g.heap.Load())
+		} else {
+			log.Printf("✅ Load back under thresholds, no longer shedding")
+		}
+	}
+}
+
+// Overloaded reports whether new extractions should be refused and why. A
+// nil guard never is.
+func (g *LoadGuard) Overloaded() (string, bool) {
+	if g == nil {
+		return "", false
+	}
+	if g.maxHeap > 0 && g.heap.Load() > g.maxHeap {
+		return overloadMemory, true
+	}
+	if g.maxPending > 0 {
+		if st := g.extractions(); st.Running+st.Queued > g.maxPending {
+			return overloadExtractions, true
+		}
+	}
+	return "", false
+}
+
+// Stats returns the state of the guard.
+func (g *LoadGuard) Stats() LoadStats {
+	st := g.extractions()
+	reason, _ := g.Overloaded()
+	return LoadStats{
+		HeapBytes:    g.heap.Load(),
+		MaxHeapBytes: g.maxHeap,
+		Pending:      st.Running + st.Queued,
+		MaxPending:   g.maxPending,
+		Overloaded:   reason,
+		Shed:         g.shed.Load(),
+	}
+}
+
+// shed rejects with 503 and Retry-After a request that would extract while
+// the server is overloaded, and reports whether it did.
+func (h *FeedHandler) shed(w http.ResponseWriter, r *http.Request) bool {
+	reason, overloaded := h.Load.Overloaded()
+	if !overloaded {
+		return false
+	}
+	h.Load.shed.Add(1)
+	w.Header().Set("Retry-After", strconv.Itoa(loadRetryAfter))
+	writeError(w, r, newAPIError(http.StatusServiceUnavailable, CodeOverloaded,
+		"server is overloaded, retry later").with("reason", reason).with("retry_after", loadRetryAfter))
+	return true
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadShedding(t *testing.T) {
+	clock := newFakeClock()
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, testFeed), nil
+	}, clock, time.Hour)
+	heap := int64(100)
+	guard := NewLoadGuard(1000, 0, h.extractions().stats)
+	guard.readHeap = func() int64 { return heap }
+	guard.sample()
+	h.Load = guard
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+testFeedURL+query, nil))
+		return rec
+	}
+	if rec := get("&format=json"); rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	heap = 2000
+	guard.sample()
+	clock.Advance(2 * time.Minute)
+
+	// The stale response is still served
+	if rec := get("&format=json"); rec.Code != http.StatusOK || rec.Header().Get("X-Cache") != "STALE" {
+		t.Errorf("cached feed: %d %s; want the stale response", rec.Code, rec.Header().Get("X-Cache"))
+	}
+
+	// A response to generate is shed
+	rec := get("&format=rss")
+	var apiErr APIError
+	if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" || apiErr.Code != CodeOverloaded || apiErr.Details["reason"] != overloadMemory {
+		t.Errorf("uncached feed: %d %s; want 503 overloaded by memory with Retry-After", rec.Code, rec.Body)
+	}
+	if st := guard.Stats(); st.Shed != 1 || st.Overloaded != overloadMemory {
+		t.Errorf("stats = %+v; want 1 shed for memory", st)
+	}
+
+	heap = 100
+	guard.sample()
+	if rec := get("&format=rss"); rec.Code != http.StatusOK {
+		t.Errorf("status %d once back under the threshold; want 200", rec.Code)
+	}
+}
+
+func TestLoadGuardPendingExtractions(t *testing.T) {
+	s := newExtractionScheduler(1)
+	guard := NewLoadGuard(0, 1, s.stats)
+	flow := s.newFlow(foregroundWeight)
+	release := s.acquire(flow)
+	if _, overloaded := guard.Overloaded(); overloaded {
+		t.Fatal("overloaded with one extraction pending; want under the threshold")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.acquire(flow)()
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for s.stats().Queued == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if reason, overloaded := guard.Overloaded(); !overloaded || reason != overloadExtractions {
+		t.Errorf("Overloaded() = %q, %v with two extractions pending; want extractions", reason, overloaded)
+	}
+	release()
+	<-done
+}
@@ -0,0 +1,306 @@
+// internal/app/location.go
+package app
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+// georssNamespace is the namespace of the georss: elements.
+const georssNamespace = "http://www.georss.org/georss"
+
+// ItemLocation is where the news happened, for map-based consumers.
+type ItemLocation struct {
+	Name      string   `json:"name,omitempty"`
+	Latitude  *float64 `json:"lat,omitempty"`
+	Longitude *float64 `json:"lon,omitempty"`
+	// Source is where the location was found: "feed" (georss:point),
+	// "json-ld" (contentLocation), "dateline" or "meta" (geo meta tags)
+	Source string `json:"source"`
+}
+
+// hasPoint reports whether the location has coordinates.
+func (l *ItemLocation) hasPoint() bool {
+	return l != nil && l.Latitude != nil && l.Longitude != nil
+}
+
+// georssPoint returns the location as a georss:point, "lat lon".
+func (l *ItemLocation) georssPoint() string {
+	if !l.hasPoint() {
+		return ""
+	}
+	return strconv.FormatFloat(*l.Latitude, 'f', -1, 64) + " " + strconv.FormatFloat(*l.Longitude, 'f', -1, 64)
+}
+
+// newLocation returns a location at lat, lon, nil when they are out of
+// range.
+func newLocation(name string, lat, lon float64, source string) *ItemLocation {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil
+	}
+	return &ItemLocation{Name: name, Latitude: &lat, Longitude: &lon, Source: source}
+}
+
+// itemLocation returns where the item's news happened: the feed's own
+// georss:point or, with Locations set, the dateline opening the content
+// when it names a known city, else the JSON-LD contentLocation or geo meta
+// tags of the article page, fetched when fetchPage is set, else the
+// dateline city without coordinates.
+func (h *FeedHandler) itemLocation(i *gofeed.Item, content string, fetchPage bool) *ItemLocation {
+	if loc := feedLocation(i); loc != nil {
+		return loc
+	}
+	if !h.Locations {
+		return nil
+	}
+	dateline := datelineLocation(content)
+	if dateline.hasPoint() {
+		return dateline
+	}
+	if fetchPage && i.Link != "" {
+		if doc, ok := h.getDocument(i.Link); ok {
+			if loc := pageLocation(doc); loc != nil {
+				return loc
+			}
+		}
+	}
+	return dateline
+}
+
+// feedLocation returns the georss:point of the feed item.
+func feedLocation(i *gofeed.Item) *ItemLocation {
+	georss := i.Extensions["georss"]
+	points := georss["point"]
+	if len(points) == 0 {
+		return nil
+	}
+	lat, lon, ok := parsePoint(points[0].Value, " ")
+	if !ok {
+		return nil
+	}
+	name := ""
+	if names := georss["featurename"]; len(names) > 0 {
+		name = strings.TrimSpace(names[0].Value)
+	}
+	return newLocation(name, lat, lon, "feed")
+}
+
+// parsePoint parses coordinates written "lat<sep>lon".
+func parsePoint(point, sep string) (lat, lon float64, ok bool) {
+	latStr, lonStr, found := strings.Cut(strings.TrimSpace(point), sep)
+	if !found {
+		return 0, 0, false
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	return lat, lon, err1 == nil && err2 == nil
+}
+
+// datelineRegex matches a dateline opening an article, an upper-case city
+// optionally followed by the agency: "İSTANBUL (AA) -".
+var datelineRegex = regexp.MustCompile(`^\s*([A-ZÇĞİÖŞÜÂÎÛ][A-ZÇĞİÖŞÜÂÎÛ' .]{1,30}?)\s*(\([^)]{1,40}\))?\s*[-–—]`)
+
+// datelineLocation returns the city of the dateline opening content, with
+// its coordinates when it is in the gazetteer, nil without a dateline.
+// Cities missing from the gazetteer are only taken from datelines naming
+// the agency, as upper-case openings such as "SON DAKİKA -" are not places.
+func datelineLocation(content string) *ItemLocation {
+	m := datelineRegex.FindStringSubmatch(cleanHTMLTags(content))
+	if m == nil {
+		return nil
+	}
+	city := strings.TrimSpace(m[1])
+	if place, ok := gazetteer[city]; ok {
+		return newLocation(place.name, place.lat, place.lon, "dateline")
+	}
+	if m[2] == "" {
+		return nil
+	}
+	return &ItemLocation{Name: turkishTitle(city), Source: "dateline"}
+}
+
+// turkishTitle capitalizes only the first letter of each word, with the
+// Turkish dotted and dotless i.
+func turkishTitle(s string) string {
+	words := strings.Fields(s)
+	for k, w := range words {
+		r := []rune(strings.ToLowerSpecial(unicode.TurkishCase, w))
+		if len(r) > 0 {
+			r[0] = []rune(strings.ToUpperSpecial(unicode.TurkishCase, string(r[0])))[0]
+		}
+		words[k] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+// pageLocation returns the location an article page marks up: the JSON-LD
+// contentLocation, else the geo meta tags.
+func pageLocation(doc *goquery.Document) *ItemLocation {
+	var loc *ItemLocation
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) == nil {
+			loc = jsonLDLocation(data)
+		}
+		return loc == nil
+	})
+	if loc != nil {
+		return loc
+	}
+	return metaLocation(doc)
+}
+
+// jsonLDLocation looks for a contentLocation in JSON-LD data, following
+// @graph and nested objects.
+func jsonLDLocation(data any) *ItemLocation {
+	switch v := data.(type) {
+	case []any:
+		for _, elem := range v {
+			if loc := jsonLDLocation(elem); loc != nil {
+				return loc
+			}
+		}
+	case map[string]any:
+		if place, ok := v["contentLocation"]; ok {
+			if loc := jsonLDPlace(place); loc != nil {
+				return loc
+			}
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if loc := jsonLDLocation(v[key]); loc != nil {
+				return loc
+			}
+		}
+	}
+	return nil
+}
+
+// jsonLDPlace converts a schema.org Place, or a list of them, or a plain
+// place name.
+func jsonLDPlace(place any) *ItemLocation {
+	switch p := place.(type) {
+	case string:
+		if name := strings.TrimSpace(p); name != "" {
+			return &ItemLocation{Name: name, Source: "json-ld"}
+		}
+	case []any:
+		if len(p) > 0 {
+			return jsonLDPlace(p[0])
+		}
+	case map[string]any:
+		name, _ := p["name"].(string)
+		name = strings.TrimSpace(name)
+		if geo, ok := p["geo"].(map[string]any); ok {
+			lat, latOK := jsonLDNumber(geo["latitude"])
+			lon, lonOK := jsonLDNumber(geo["longitude"])
+			if latOK && lonOK {
+				if loc := newLocation(name, lat, lon, "json-ld"); loc != nil {
+					return loc
+				}
+			}
+		}
+		if name != "" {
+			return &ItemLocation{Name: name, Source: "json-ld"}
+		}
+	}
+	return nil
+}
+
+// jsonLDNumber reads a number JSON-LD may write as a string.
+func jsonLDNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// metaLocation returns the location of the geo meta tags: geo.position,
+// ICBM or place:location, named by geo.placename.
+func metaLocation(doc *goquery.Document) *ItemLocation {
+	meta := func(selector string) string {
+		content, _ := doc.Find(selector).First().Attr("content")
+		return strings.TrimSpace(content)
+	}
+	name := meta(`meta[name="geo.placename"]`)
+	if lat, lon, ok := parsePoint(meta(`meta[name="geo.position"]`), ";"); ok {
+		return newLocation(name, lat, lon, "meta")
+	}
+	if lat, lon, ok := parsePoint(meta(`meta[name="ICBM"]`), ","); ok {
+		return newLocation(name, lat, lon, "meta")
+	}
+	latStr, lonStr := meta(`meta[property="place:location:latitude"]`), meta(`meta[property="place:location:longitude"]`)
+	if lat, lon, ok := parsePoint(latStr+","+lonStr, ","); ok {
+		return newLocation(name, lat, lon, "meta")
+	}
+	if name != "" {
+		return &ItemLocation{Name: name, Source: "meta"}
+	}
+	return nil
+}
+
+// gazetteerPlace is a gazetteer entry.
+type gazetteerPlace struct {
+	name     string
+	lat, lon float64
+}
+
+// gazetteer holds the coordinates of the cities datelines name most, by
+// their upper-case spelling.
+var gazetteer = map[string]gazetteerPlace{
+	"ADANA":         {"Adana", 37.0, 35.3213},
+	"ANKARA":        {"Ankara", 39.9334, 32.8597},
+	"ANTALYA":       {"Antalya", 36.8969, 30.7133},
+	"BURSA":         {"Bursa", 40.1885, 29.061},
+	"DENİZLİ":       {"Denizli", 37.7765, 29.0864},
+	"DİYARBAKIR":    {"Diyarbakır", 37.9144, 40.2306},
+	"EDİRNE":        {"Edirne", 41.6771, 26.5557},
+	"ERZURUM":       {"Erzurum", 39.9043, 41.2679},
+	"ESKİŞEHİR":     {"Eskişehir", 39.7767, 30.5206},
+	"GAZİANTEP":     {"Gaziantep", 37.0662, 37.3833},
+	"HATAY":         {"Hatay", 36.2021, 36.1606},
+	"İSTANBUL":      {"İstanbul", 41.0082, 28.9784},
+	"İZMİR":         {"İzmir", 38.4237, 27.1428},
+	"KAHRAMANMARAŞ": {"Kahramanmaraş", 37.5858, 36.9371},
+	"KAYSERİ":       {"Kayseri", 38.7205, 35.4826},
+	"KOCAELİ":       {"Kocaeli", 40.8533, 29.8815},
+	"KONYA":         {"Konya", 37.8746, 32.4932},
+	"MALATYA":       {"Malatya", 38.3552, 38.3095},
+	"MARDİN":        {"Mardin", 37.3212, 40.7245},
+	"MERSİN":        {"Mersin", 36.8121, 34.6415},
+	"MUĞLA":         {"Muğla", 37.2153, 28.3636},
+	"SAKARYA":       {"Sakarya", 40.7569, 30.3781},
+	"SAMSUN":        {"Samsun", 41.2867, 36.33},
+	"ŞANLIURFA":     {"Şanlıurfa", 37.1591, 38.7969},
+	"TRABZON":       {"Trabzon", 41.0027, 39.7168},
+	"VAN":           {"Van", 38.5012, 43.373},
+	"ZONGULDAK":     {"Zonguldak", 41.4564, 31.7987},
+	"LEFKOŞA":       {"Lefkoşa", 35.1856, 33.3823},
+	"ATİNA":         {"Atina", 37.9838, 23.7275},
+	"BAKÜ":          {"Bakü", 40.4093, 49.8671},
+	"BERLİN":        {"Berlin", 52.52, 13.405},
+	"BRÜKSEL":       {"Brüksel", 50.8503, 4.3517},
+	"CENEVRE":       {"Cenevre", 46.2044, 6.1432},
+	"KAHİRE":        {"Kahire", 30.0444, 31.2357},
+	"KİEV":          {"Kiev", 50.4501, 30.5234},
+	"LONDRA":        {"Londra", 51.5074, -0.1278},
+	"MOSKOVA":       {"Moskova", 55.7558, 37.6173},
+	"NEW YORK":      {"New York", 40.7128, -74.006},
+	"PARİS":         {"Paris", 48.8566, 2.3522},
+	"PEKİN":         {"Pekin", 39.9042, 116.4074},
+	"ROMA":          {"Roma", 41.9028, 12.4964},
+	"ŞAM":           {"Şam", 33.5138, 36.2765},
+	"TAHRAN":        {"Tahran", 35.6892, 51.389},
+	"TOKYO":         {"Tokyo", 35.6762, 139.6503},
+	"VAŞİNGTON":     {"Vaşington", 38.9072, -77.0369},
+	"WASHINGTON":    {"Washington", 38.9072, -77.0369},
+}
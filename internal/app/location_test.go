@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+func TestDatelineLocation(t *testing.T) {
+	tests := []struct {
+		content, name, point string
+	}{
+		{"<p>İSTANBUL (AA) - Boğaz'da gemi trafiği durdu.</p>", "İstanbul", "41.0082 28.9784"},
+		{"<p>ANKARA – Meclis toplandı.</p>", "Ankara", "39.9334 32.8597"},
+		// Unknown cities are taken from datelines naming the agency
+		{"<p>ERZİNCAN (İHA) - Kar yağışı başladı.</p>", "Erzincan", ""},
+		{"<p>SON DAKİKA - Deprem oldu.</p>", "", ""},
+		{"<p>Ankara'da bugün toplantı var - dedi.</p>", "", ""},
+	}
+	for _, tt := range tests {
+		loc := datelineLocation(tt.content)
+		if tt.name == "" {
+			if loc != nil {
+				t.Errorf("%q: location %+v; want none", tt.content, loc)
+			}
+			continue
+		}
+		if loc == nil || loc.Name != tt.name || loc.georssPoint() != tt.point || loc.Source != "dateline" {
+			t.Errorf("%q: location %+v; want %s at %q", tt.content, loc, tt.name, tt.point)
+		}
+	}
+}
+
+func TestPageLocation(t *testing.T) {
+	tests := []struct {
+		page, name, point, source string
+	}{
+		{`<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"NewsArticle","contentLocation":{"@type":"Place","name":"Hatay","geo":{"latitude":"36.2021","longitude":36.1606}}}]}</script>`,
+			"Hatay", "36.2021 36.1606", "json-ld"},
+		{`<meta name="geo.placename" content="İzmir"><meta name="geo.position" content="38.4237;27.1428">`,
+			"İzmir", "38.4237 27.1428", "meta"},
+		{`<meta name="ICBM" content="41.0082, 28.9784">`, "", "41.0082 28.9784", "meta"},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.page + "</head></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		loc := pageLocation(doc)
+		if loc == nil || loc.Name != tt.name || loc.georssPoint() != tt.point || loc.Source != tt.source {
+			t.Errorf("%s: location %+v; want %q at %q from %s", tt.page, loc, tt.name, tt.point, tt.source)
+		}
+	}
+}
+
+func TestFeedLocation(t *testing.T) {
+	const upstream = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:georss="http://www.georss.org/georss"><channel>
+<title>Harita</title><link>https://harita.test/</link>
+<item><title>Van'da deprem</title><link>https://harita.test/a/1</link>
+<georss:point>38.5012 43.373</georss:point><georss:featurename>Van</georss:featurename></item>
+</channel></rss>`
+	feed, err := newFeedParser().ParseString(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The feed's own point is kept without Locations
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, extractors.NewRegistry(), filters.NewFilterRegistry(), nil, nil)
+	item := h.buildItem(feed.Items[0], true)
+	if item.Location == nil || item.Location.Source != "feed" || item.Location.Name != "Van" {
+		t.Fatalf("location %+v; want the feed's point", item.Location)
+	}
+
+	var buf bytes.Buffer
+	fw := newFeedWriter(formatRSS, &buf, nil)
+	fw.Begin(feedMeta{Title: feed.Title})
+	fw.WriteItem(item)
+	fw.End(feedSummary{})
+	for _, want := range []string{"<georss:point>38.5012 43.373</georss:point>", "<georss:featurename>Van</georss:featurename>"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RSS lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// internal/app/meta.go
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// metaCacheTTL is how long site metadata is cached; it rarely changes.
+const metaCacheTTL = 24 * time.Hour
+
+// maxMetaPageSize bounds how much of a page is read for its metadata.
+const maxMetaPageSize = 2 << 20
+
+// SiteMeta describes a site for rendering source branding.
+type SiteMeta struct {
+	URL         string     `json:"url"`
+	Title       string     `json:"title,omitempty"`
+	SiteName    string     `json:"site_name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Favicon     string     `json:"favicon,omitempty"`
+	Image       string     `json:"image,omitempty"`
+	Feeds       []SiteFeed `json:"feeds,omitempty"`
+}
+
+// SiteFeed is a feed advertised by a site.
+type SiteFeed struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+// feedLinkTypes are the link types pages advertise feeds with.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+// handleMeta serves GET /meta?url=..., the title, favicon, Open Graph
+// defaults and feeds of a site. url may also point at a feed, in which case
+// the site it links to is described.
+func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
+	target := strings.TrimSpace(r.URL.Query().Get("url"))
+	if _, apiErr := validateTargetURL("url", target); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	cacheKey := "meta|" + target
+	if cached, ok := s.metaCache.Get(cacheKey); ok {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("X-Cache", "HIT")
+		w.Write([]byte(cached))
+		return
+	}
+
+	meta, err := s.fetchSiteMeta(target)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	data, _ := json.MarshalIndent(meta, "", "  ")
+	s.metaCache.Set(cacheKey, string(data))
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Cache", "MISS")
+	w.Write(data)
+}
+
+// fetchSiteMeta fetches target and describes its site.
+func (s *Server) fetchSiteMeta(target string) (*SiteMeta, error) {
+	body, contentType, err := s.fetchPage(target)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &SiteMeta{URL: target}
+	pageURL := target
+
+	// For a feed, describe the site it belongs to
+	if !strings.Contains(contentType, "html") {
+		feed, err := parseFeed(body)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadGateway, CodeInvalidFeed, "URL is neither an HTML page nor a feed").
+				with("url", target).with("content_type", contentType).with("format", detectFeedFormat(body))
+		}
+		meta.Title = feed.Title
+		meta.Description = feed.Description
+		meta.Feeds = []SiteFeed{{URL: target, Title: feed.Title, Type: feedMIMEType(feed.FeedType)}}
+		if feed.Image != nil {
+			meta.Image = feed.Image.URL
+		}
+		if feed.Link == "" {
+			return meta, nil
+		}
+		if body, _, err = s.fetchPage(feed.Link); err != nil {
+			return meta, nil
+		}
+		meta.URL, pageURL = feed.Link, feed.Link
+	}
+
+	base, _ := url.Parse(pageURL)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, newAPIError(http.StatusBadGateway, CodeExtractionFailed, "could not parse page").
+			with("url", pageURL).with("error", err.Error())
+	}
+	describePage(meta, doc, base)
+	return meta, nil
+}
+
+// fetchPage downloads target through the feed client.
+func (s *Server) fetchPage(target string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", newAPIError(http.StatusBadRequest, CodeInvalidURL, "invalid URL").with("url", target)
+	}
+	resp, err := s.feedHandler.client().Do(req)
+	if err != nil {
+		return nil, "", upstreamError(target, nil, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, "", upstreamError(target, resp, nil)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetaPageSize))
+	if err != nil {
+		return nil, "", upstreamError(target, nil, err)
+	}
+	return body, strings.ToLower(resp.Header.Get("Content-Type")), nil
+}
+
+// describePage fills meta from an HTML page, keeping values already set
+// from a feed except where the page has better ones.
+func describePage(meta *SiteMeta, doc *goquery.Document, base *url.URL) {
+	attr := func(selector, name string) string {
+		v, _ := doc.Find(selector).First().Attr(name)
+		return strings.TrimSpace(v)
+	}
+	resolve := func(ref string) string {
+		if ref == "" || base == nil {
+			return ref
+		}
+		u, err := base.Parse(ref)
+		if err != nil {
+			return ""
+		}
+		return u.String()
+	}
+	meta.SiteName = attr(`meta[property="og:site_name"]`, "content")
+	meta.Title = firstNonEmpty(attr(`meta[property="og:title"]`, "content"), strings.TrimSpace(doc.Find("title").First().Text()), meta.Title)
+	meta.Description = firstNonEmpty(attr(`meta[property="og:description"]`, "content"), attr(`meta[name="description"]`, "content"), meta.Description)
+	meta.Image = firstNonEmpty(resolve(attr(`meta[property="og:image"]`, "content")), meta.Image)
+
+	meta.Favicon = resolve(firstNonEmpty(
+		attr(`link[rel="icon"]`, "href"),
+		attr(`link[rel="shortcut icon"]`, "href"),
+		attr(`link[rel="apple-touch-icon"]`, "href"),
+		"/favicon.ico",
+	))
+
+	doc.Find(`link[rel="alternate"]`).Each(func(_ int, sel *goquery.Selection) {
+		typ := strings.ToLower(strings.TrimSpace(sel.AttrOr("type", "")))
+		href := resolve(strings.TrimSpace(sel.AttrOr("href", "")))
+		if !feedLinkTypes[typ] || href == "" {
+			return
+		}
+		for _, f := range meta.Feeds {
+			if f.URL == href {
+				return
+			}
+		}
+		meta.Feeds = append(meta.Feeds, SiteFeed{URL: href, Title: strings.TrimSpace(sel.AttrOr("title", "")), Type: typ})
+	})
+}
+
+// feedMIMEType returns the MIME type of a gofeed feed type.
+func feedMIMEType(feedType string) string {
+	switch feedType {
+	case "rss":
+		return "application/rss+xml"
+	case "atom":
+		return "application/atom+xml"
+	case "json":
+		return "application/feed+json"
+	}
+	return ""
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+// internal/app/metadata.go
+package app
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// categoryDomainsKey is the Custom key holding the domains of an item's
+// RSS categories, as a JSON object by category.
+const categoryDomainsKey = "category_domains"
+
+// ItemDublinCore is the Dublin Core metadata of the upstream item, passed
+// through to RSS output so metadata-rich feeds survive the proxy.
+type ItemDublinCore struct {
+	Creators     []string `json:"creators,omitempty"`
+	Contributors []string `json:"contributors,omitempty"`
+	Subjects     []string `json:"subjects,omitempty"`
+	Publisher    string   `json:"publisher,omitempty"`
+	Rights       string   `json:"rights,omitempty"`
+	Language     string   `json:"language,omitempty"`
+	// Date is the item's dc:date as the feed wrote it
+	Date string `json:"date,omitempty"`
+}
+
+// itemDublinCore returns the Dublin Core metadata of the feed item, nil
+// when it has none.
+func itemDublinCore(i *gofeed.Item) *ItemDublinCore {
+	dc := i.DublinCoreExt
+	if dc == nil {
+		return nil
+	}
+	out := &ItemDublinCore{
+		Creators:     nonEmptyValues(dc.Creator),
+		Contributors: nonEmptyValues(dc.Contributor),
+		Subjects:     nonEmptyValues(dc.Subject),
+		Publisher:    firstValue(dc.Publisher),
+		Rights:       firstValue(dc.Rights),
+		Language:     firstValue(dc.Language),
+		Date:         firstValue(dc.Date),
+	}
+	if out.Creators == nil && out.Contributors == nil && out.Subjects == nil &&
+		out.Publisher == "" && out.Rights == "" && out.Language == "" && out.Date == "" {
+		return nil
+	}
+	return out
+}
+
+// nonEmptyValues returns the trimmed non-empty values, nil when there are none.
+func nonEmptyValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// firstValue returns the first non-empty value.
+func firstValue(values []string) string {
+	if out := nonEmptyValues(values); len(out) > 0 {
+		return out[0]
+	}
+	return ""
+}
+
+// setCategoryDomains keeps the domains of the RSS item's categories, which
+// the universal feed model drops.
+func setCategoryDomains(item *gofeed.Item, categories []*rss.Category) {
+	domains := make(map[string]string)
+	for _, c := range categories {
+		if c != nil && c.Domain != "" && strings.TrimSpace(c.Value) != "" {
+			domains[strings.TrimSpace(c.Value)] = c.Domain
+		}
+	}
+	if len(domains) == 0 {
+		return
+	}
+	data, err := json.Marshal(domains)
+	if err != nil {
+		return
+	}
+	if item.Custom == nil {
+		item.Custom = make(map[string]string)
+	}
+	item.Custom[categoryDomainsKey] = string(data)
+}
+
+// itemCategoryDomains returns the domains of the feed item's categories by
+// category, nil when none has one.
+func itemCategoryDomains(item *gofeed.Item) map[string]string {
+	var domains map[string]string
+	if data := item.Custom[categoryDomainsKey]; data != "" {
+		json.Unmarshal([]byte(data), &domains)
+	}
+	return domains
+}
+
+// ItemEnclosure is a file the upstream item attaches, such as a podcast
+// episode or a PDF.
+type ItemEnclosure struct {
+	URL string `json:"url"`
+	// Length is the size in bytes, 0 when the feed does not say
+	Length int64  `json:"length,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+// itemEnclosures returns the enclosures of the feed item in feed order.
+func itemEnclosures(i *gofeed.Item) []ItemEnclosure {
+	var out []ItemEnclosure
+	for _, enc := range i.Enclosures {
+		if enc == nil || strings.TrimSpace(enc.URL) == "" {
+			continue
+		}
+		length, _ := strconv.ParseInt(strings.TrimSpace(enc.Length), 10, 64)
+		out = append(out, ItemEnclosure{URL: strings.TrimSpace(enc.URL), Length: max(length, 0), Type: strings.TrimSpace(enc.Type)})
+	}
+	return out
+}
+
+// encloses reports whether link is one of the item's enclosures.
+func (it *Item) encloses(link string) bool {
+	for _, enc := range it.Enclosures {
+		if enc.URL == link {
+			return true
+		}
+	}
+	return false
+}
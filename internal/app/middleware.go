@@ -0,0 +1,357 @@
+// internal/app/middleware.go
+package app
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Middleware wraps a handler with behaviour shared by several routes.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middlewares into one; the first listed is the outermost.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		return h
+	}
+}
+
+// statusWriter records the status and size of a response. It passes
+// flushes through so streamed feeds keep streaming.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// wrapWriter returns w as a statusWriter, reusing one set up by an outer
+// middleware.
+func wrapWriter(w http.ResponseWriter) *statusWriter {
+	if sw, ok := w.(*statusWriter); ok {
+		return sw
+	}
+	return &statusWriter{ResponseWriter: w}
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(p)
+	sw.bytes += int64(n)
+	return n, err
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// Status returns the status written so far, 200 if the handler wrote none.
+func (sw *statusWriter) Status() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
+
+// AccessLog logs every request with its status, size and duration.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := wrapWriter(w)
+		next.ServeHTTP(sw, r)
+		log.Printf("📨 %s %s %d %dB %v [%s]", r.Method, r.URL.Path, sw.Status(), sw.bytes, time.Since(start).Round(time.Millisecond), requestID(r))
+	})
+}
+
+// Recover turns a panic in a handler into a 500 internal_error response and
+// logs its stack, rather than letting the connection drop with no answer.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := wrapWriter(w)
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			log.Printf("💥 Panic serving %s %s [%s]: %v\n%s", r.Method, r.URL.Path, requestID(r), v, debug.Stack())
+			// Once the response started there is nothing left to report on
+			if sw.status == 0 {
+				writeError(sw, r, newAPIError(http.StatusInternalServerError, CodeInternal, "internal error"))
+			}
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// corsExposedHeaders are the response headers browsers may read.
+const corsExposedHeaders = "X-Request-ID, X-Cache, X-Item-Count, Age, Last-Modified"
+
+// CORS lets pages from origins call the API from the browser. "*" allows
+// every origin.
+func CORS(origins []string) Middleware {
+	allowAll := slices.Contains(origins, "*")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || (!allowAll && !slices.Contains(origins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if allowAll {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			// Answer preflight requests here
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID")
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimiter allows each client a number of requests per minute, refilled
+// continuously, with bursts of up to that number.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // requests per second
+	burst   float64
+	clients map[string]*rateBucket
+	clock   Clock
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing perMinute requests per
+// client. clock may be nil.
+func NewRateLimiter(perMinute int, clock Clock) *RateLimiter {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(perMinute),
+		clients: make(map[string]*rateBucket),
+		clock:   clock,
+	}
+}
+
+// Allow takes a request from client's allowance. When none is left it
+// returns false and how long until the next request is allowed.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	b, ok := l.clients[client]
+	if !ok {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Prune forgets clients whose allowance is full again.
+func (l *RateLimiter) Prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	for client, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// Middleware rejects requests over the client's allowance with 429.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.Allow(clientIP(r))
+		if !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeError(w, r, newAPIError(http.StatusTooManyRequests, CodeRateLimited,
+				"too many requests, slow down").with("retry_after", seconds))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the address the request came from.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RouteStats counts the requests served by a route.
+type RouteStats struct {
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	AvgMillis    float64 `json:"avg_ms"`
+}
+
+// Metrics counts requests, errors and response times per route.
+type Metrics struct {
+	mu     sync.Mutex
+	routes map[string]*routeMetrics
+}
+
+type routeMetrics struct {
+	RouteStats
+	total time.Duration
+}
+
+// NewMetrics creates an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{routes: make(map[string]*routeMetrics)}
+}
+
+// Track returns middleware counting the requests of the route named route.
+func (m *Metrics) Track(route string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := wrapWriter(w)
+			next.ServeHTTP(sw, r)
+			m.record(route, sw.Status(), time.Since(start))
+		})
+	}
+}
+
+func (m *Metrics) record(route string, status int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rm, ok := m.routes[route]
+	if !ok {
+		rm = &routeMetrics{}
+		m.routes[route] = rm
+	}
+	rm.Requests++
+	switch {
+	case status >= 500:
+		rm.ServerErrors++
+	case status >= 400:
+		rm.ClientErrors++
+	}
+	rm.total += elapsed
+}
+
+// Routes returns the counters of every route served so far.
+func (m *Metrics) Routes() map[string]RouteStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	routes := make(map[string]RouteStats, len(m.routes))
+	for route, rm := range m.routes {
+		stats := rm.RouteStats
+		stats.AvgMillis = float64(rm.total.Microseconds()) / float64(rm.Requests) / 1000
+		routes[route] = stats
+	}
+	return routes
+}
+
+// adminOnly requires the admin token on the routes it wraps.
+func (s *Server) adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.requireAdmin(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// privateUpstreams requires an API key allowed to use the credentials of the
+// url parameters' domains, when they have some, on the routes it wraps.
+func (s *Server) privateUpstreams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiErr := s.upstreamAuth.Authorize(r, r.URL.Query()["url"]...); apiErr != nil {
+			writeError(w, r, apiErr)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle registers handler for pattern behind middlewares, first listed
+// outermost. Every route is counted in the server's metrics.
+func (s *Server) handle(pattern string, handler http.Handler, middlewares ...Middleware) {
+	route := strings.TrimSpace(pattern[strings.IndexByte(pattern, ' ')+1:])
+	chain := append([]Middleware{s.metrics.Track(route)}, middlewares...)
+	s.mux.Handle(pattern, Chain(chain...)(handler))
+}
+
+// handleFunc is handle for handler functions.
+func (s *Server) handleFunc(pattern string, handler http.HandlerFunc, middlewares ...Middleware) {
+	s.handle(pattern, handler, middlewares...)
+}
+
+// limited is the rate limiting middleware of expensive routes, a no-op when
+// no limit is configured.
+func (s *Server) limited(next http.Handler) http.Handler {
+	if s.limiter == nil {
+		return next
+	}
+	return s.limiter.Middleware(next)
+}
+
+// handler returns the server's handler: the routes behind the middleware
+// every request goes through.
+func (s *Server) handler() http.Handler {
+	global := []Middleware{RequestID, AccessLog, Recover}
+	if len(s.cfg.CORSOrigins) > 0 {
+		global = append(global, CORS(s.cfg.CORSOrigins))
+	}
+	// Signatures cover the compressed bytes sent
+	if s.signer != nil {
+		global = append(global, s.signer.Middleware)
+	}
+	global = append(global, Compress)
+	return Chain(global...)(s.mux)
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Chain(mark("outer"), mark("inner"))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		order = append(order, "handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := strings.Join(order, ","); got != "outer,inner,handler" {
+		t.Errorf("order = %s", got)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := Chain(RequestID, Recover)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("extractor exploded")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d", rec.Code)
+	}
+	var body APIError
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Code != CodeInternal || body.RequestID == "" {
+		t.Errorf("body = %s (%v)", rec.Body, err)
+	}
+}
+
+func TestRecoverKeepsFlusher(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("wrapped writer cannot flush")
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRateLimiter(t *testing.T) {
+	clock := newFakeClock()
+	l := NewRateLimiter(2, clock)
+	h := l.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	get := func(addr string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/feed", nil)
+		r.RemoteAddr = addr
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for range 2 {
+		if rec := get("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("allowed request got %d", rec.Code)
+		}
+	}
+	rec := get("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("over the limit: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := get("10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("other client got %d", rec.Code)
+	}
+
+	clock.Advance(30 * time.Second)
+	if rec := get("10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("after refill got %d", rec.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	h := CORS([]string{"https://app.example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/feed", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("preflight: %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/feed", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	h.ServeHTTP(rec, r)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Body.String() != "ok" {
+		t.Errorf("foreign origin allowed: %v", rec.Header())
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	h := m.Track("/feed")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	for _, target := range []string{"/feed", "/feed?fail=1", "/feed"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	if got := m.Routes()["/feed"]; got.Requests != 3 || got.ServerErrors != 1 || got.ClientErrors != 0 {
+		t.Errorf("stats = %+v", got)
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	s := &Server{cfg: &Config{}}
+	h := s.adminOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	call := func(auth string) int {
+		req := httptest.NewRequest(http.MethodPost, "/admin/warm", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Without a token the admin endpoints are disabled
+	if code := call(""); code != http.StatusNotFound {
+		t.Errorf("no token configured: status %d; want 404", code)
+	}
+
+	s.cfg.AdminToken = "secret"
+	tests := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		if code := call(tt.auth); code != tt.want {
+			t.Errorf("Authorization %q: status %d; want %d", tt.auth, code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// internal/app/monitor.go
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gofull/internal/storage"
+)
+
+// extractionWindow is the number of latest extractions of a domain its
+// success rate is computed over.
+const extractionWindow = 20
+
+// Monitor watches extraction, feed fetches and the storage backend and
+// fires alerts when they go wrong.
+type Monitor struct {
+	alerts *Alerter
+	// minRate is the extraction success rate below which a domain alerts
+	minRate float64
+	// feedFailures is the number of failed fetches in a row after which a
+	// feed alerts
+	feedFailures int
+
+	mu      sync.Mutex
+	domains map[string]*extractionOutcomes
+	feeds   map[string]int // feed URL -> failed fetches in a row
+}
+
+// extractionOutcomes is a ring of the latest extraction results of a domain.
+type extractionOutcomes struct {
+	ok   [extractionWindow]bool
+	next int
+	n    int
+}
+
+// NewMonitor creates a Monitor firing through alerts. A zero minRate or
+// feedFailures disables the corresponding alert.
+func NewMonitor(alerts *Alerter, minRate float64, feedFailures int) *Monitor {
+	return &Monitor{
+		alerts:       alerts,
+		minRate:      minRate,
+		feedFailures: feedFailures,
+		domains:      make(map[string]*extractionOutcomes),
+		feeds:        make(map[string]int),
+	}
+}
+
+// RecordExtraction records the outcome of extracting link, alerting when
+// the success rate of its site drops below the minimum.
+func (m *Monitor) RecordExtraction(link string, err error) {
+	if m == nil || m.minRate <= 0 {
+		return
+	}
+	domain := circuitDomain(link)
+	m.mu.Lock()
+	o, ok := m.domains[domain]
+	if !ok {
+		o = &extractionOutcomes{}
+		m.domains[domain] = o
+	}
+	o.ok[o.next] = err == nil
+	o.next = (o.next + 1) % extractionWindow
+	o.n = min(o.n+1, extractionWindow)
+	succeeded := 0
+	for _, ok := range o.ok[:o.n] {
+		if ok {
+			succeeded++
+		}
+	}
+	full := o.n == extractionWindow
+	m.mu.Unlock()
+
+	rate := float64(succeeded) / extractionWindow
+	if full && rate < m.minRate {
+		go m.alerts.Fire(Alert{
+			Kind:    AlertExtractionRate,
+			Subject: domain,
+			Message: fmt.Sprintf("only %d of the last %d extractions succeeded", succeeded, extractionWindow),
+			Details: map[string]any{"success_rate": rate, "threshold": m.minRate, "last_error": errorString(err)},
+		})
+	}
+}
+
+// RecordFeed records the outcome of fetching feedURL, alerting once it has
+// failed the configured number of times in a row.
+func (m *Monitor) RecordFeed(feedURL string, err error) {
+	if m == nil || m.feedFailures <= 0 {
+		return
+	}
+	m.mu.Lock()
+	if err == nil {
+		delete(m.feeds, feedURL)
+		m.mu.Unlock()
+		return
+	}
+	m.feeds[feedURL]++
+	failures := m.feeds[feedURL]
+	m.mu.Unlock()
+
+	if failures >= m.feedFailures {
+		go m.alerts.Fire(Alert{
+			Kind:    AlertFeedUnreachable,
+			Subject: feedURL,
+			Message: fmt.Sprintf("the last %d fetches of the feed failed", failures),
+			Details: map[string]any{"failures": failures, "last_error": err.Error()},
+		})
+	}
+}
+
+// CheckStorage writes a probe key to store, alerting when that fails.
+func (m *Monitor) CheckStorage(store storage.Store) {
+	if m == nil || store == nil {
+		return
+	}
+	if err := store.Put("health/probe", []byte(time.Now().UTC().Format(time.RFC3339))); err != nil {
+		m.alerts.Fire(Alert{
+			Kind:    AlertStorageDown,
+			Subject: fmt.Sprintf("%T", store),
+			Message: "the storage backend is not accepting writes",
+			Details: map[string]any{"error": err.Error()},
+		})
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,128 @@
+// internal/app/oembed.go
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxOEmbedSize bounds the oEmbed responses read.
+const maxOEmbedSize = 1 << 20
+
+// ItemEmbed is the oEmbed description of an article, for rich previews of
+// videos, podcasts and galleries.
+type ItemEmbed struct {
+	// Type is "video", "rich", "photo" or "link"
+	Type         string `json:"type"`
+	Title        string `json:"title,omitempty"`
+	AuthorName   string `json:"author_name,omitempty"`
+	ProviderName string `json:"provider_name,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// HTML is the player or widget markup to embed
+	HTML   string `json:"html,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// oembedResponse is an oEmbed provider's answer. Width and height are
+// numbers, though some providers send them as strings.
+type oembedResponse struct {
+	ItemEmbed
+	Width  json.Number `json:"width"`
+	Height json.Number `json:"height"`
+}
+
+// fetchOEmbed fetches the article page and, when it links an oEmbed
+// endpoint, the embed the endpoint describes. It costs up to two extra
+// requests per article, so it only runs when OEmbed is set.
+func (h *FeedHandler) fetchOEmbed(pageURL string) *ItemEmbed {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	doc, ok := h.getDocument(pageURL)
+	if !ok {
+		return nil
+	}
+	endpoint := findOEmbedEndpoint(doc, base)
+	if endpoint == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	var embed oembedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOEmbedSize)).Decode(&embed); err != nil || embed.Type == "" {
+		return nil
+	}
+	width, _ := embed.Width.Int64()
+	height, _ := embed.Height.Int64()
+	embed.ItemEmbed.Width, embed.ItemEmbed.Height = int(width), int(height)
+	return &embed.ItemEmbed
+}
+
+// getDocument fetches and parses an HTML page.
+func (h *FeedHandler) getDocument(pageURL string) (*goquery.Document, bool) {
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, false
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// findOEmbedEndpoint returns the JSON oEmbed endpoint an article page
+// advertises for itself.
+func findOEmbedEndpoint(doc *goquery.Document, base *url.URL) string {
+	href, ok := doc.Find(`link[rel="alternate"][type="application/json+oembed"], link[rel="alternate"][type="text/json+oembed"]`).First().Attr("href")
+	if !ok {
+		return ""
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	endpoint := base.ResolveReference(ref)
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return ""
+	}
+	return endpoint.String()
+}
+
+// mergeEmbed fills the item's image and author from its embed where the
+// feed and the page gave none.
+func (it *Item) mergeEmbed(embed *ItemEmbed) {
+	it.Embed = embed
+	if it.Image == "" && embed.ThumbnailURL != "" {
+		it.Image = embed.ThumbnailURL
+		it.Images = append([]ItemImage{{URL: embed.ThumbnailURL}}, it.Images...)
+	}
+	if it.Author == "" {
+		it.Author = embed.AuthorName
+	}
+}
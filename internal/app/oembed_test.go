@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchOEmbed(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/video/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><head><link rel="alternate" type="application/json+oembed" href="/oembed?url=%s/video/1"></head><body></body></html>`, srv.URL)
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		// Width as a string, as some providers send it
+		fmt.Fprint(w, `{"type":"video","version":"1.0","title":"Clip","author_name":"Newsroom","thumbnail_url":"https://cdn.test/clip.jpg","html":"<iframe src=\"https://player.test/1\"></iframe>","width":"640","height":360}`)
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head></head><body></body></html>`)
+	})
+
+	h := &FeedHandler{Client: srv.Client()}
+	embed := h.fetchOEmbed(srv.URL + "/video/1")
+	if embed == nil {
+		t.Fatal("no embed found")
+	}
+	if embed.Type != "video" || embed.Title != "Clip" || embed.Width != 640 || embed.Height != 360 {
+		t.Errorf("embed = %+v", embed)
+	}
+
+	item := Item{Link: srv.URL + "/video/1"}
+	item.mergeEmbed(embed)
+	if item.Image != "https://cdn.test/clip.jpg" || item.Author != "Newsroom" || len(item.Images) != 1 {
+		t.Errorf("merged item = %+v", item)
+	}
+
+	if embed := h.fetchOEmbed(srv.URL + "/plain"); embed != nil {
+		t.Errorf("embed of a page without endpoint = %+v", embed)
+	}
+}
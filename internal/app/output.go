@@ -0,0 +1,656 @@
+// internal/app/output.go
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats supported by the feed handler.
+const (
+	formatJSON     = "json"
+	formatRSS      = "rss"
+	formatAtom     = "atom"
+	formatMarkdown = "md"
+)
+
+// atomNamespace is the XML namespace of Atom, whose links RSS output uses
+// for paging.
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// formatContentTypes maps output formats to their content types.
+var formatContentTypes = map[string]string{
+	formatJSON:     "application/json; charset=utf-8",
+	formatRSS:      "application/rss+xml; charset=utf-8",
+	formatAtom:     "application/atom+xml; charset=utf-8",
+	formatMarkdown: "text/markdown; charset=utf-8",
+}
+
+// acceptFormats maps the media types clients ask for in Accept to output
+// formats. Wildcards get JSON.
+var acceptFormats = map[string]string{
+	"application/json":     formatJSON,
+	"application/*":        formatJSON,
+	"*/*":                  formatJSON,
+	"application/rss+xml":  formatRSS,
+	"application/xml":      formatRSS,
+	"text/xml":             formatRSS,
+	"application/atom+xml": formatAtom,
+	"text/markdown":        formatMarkdown,
+}
+
+// outputFormat returns the format r asks for: the format parameter, or else
+// the type its Accept header prefers. Without either the output is JSON.
+func outputFormat(r *http.Request) (string, *APIError) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" {
+		if _, ok := formatContentTypes[format]; !ok {
+			return "", newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				fmt.Sprintf("unsupported format '%s' (use json, rss, atom or md)", format)).with("parameter", "format").with("value", format)
+		}
+		return format, nil
+	}
+
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return formatJSON, nil
+	}
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, ok := acceptFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			q = v
+		}
+		// Ties go to the type listed first
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	if best == "" {
+		return "", newAPIError(http.StatusNotAcceptable, CodeNotAcceptable,
+			"none of the accepted types can be produced (use application/json, application/rss+xml, application/atom+xml or text/markdown)").with("accept", accept)
+	}
+	return best, nil
+}
+
+// feedMeta describes the feed being rendered.
+type feedMeta struct {
+	Title       string
+	Link        string
+	Description string
+	// Updated is when the feed was rendered
+	Updated time.Time
+	// ITunes is the upstream channel's podcast metadata, for RSS output
+	ITunes *feedITunes
+	// Links are the RFC 5005 links to the feed's archive pages
+	Links []feedLink
+	// Archive marks an RFC 5005 archive page, whose items do not change
+	Archive bool
+	// SchemaVersion is the version of the JSON response schema written,
+	// SchemaVersion when 0
+	SchemaVersion int
+}
+
+// feedLink links a feed to a related document by relation.
+type feedLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// feedSummary holds the counters reported once all items were written.
+type feedSummary struct {
+	Returned int
+	Skipped  int
+	Reused   int
+	// Clustered counts items dropped or grouped as repeats of a story
+	Clustered int
+	// Partial counts items served with the feed's own content because their
+	// extraction was skipped or ran out of time
+	Partial int
+	// Deleted counts tombstones of retracted items
+	Deleted int
+	// Debug is set when the feed was requested with debug=true
+	Debug *feedDebug
+}
+
+// feedWriter renders a feed incrementally so items can be sent to the client
+// as soon as they finish extraction.
+type feedWriter interface {
+	Begin(meta feedMeta) error
+	WriteItem(item Item) error
+	End(summary feedSummary) error
+}
+
+// contentTypeFor returns the response content type for an output format.
+func contentTypeFor(format string) string {
+	if t, ok := formatContentTypes[format]; ok {
+		return t
+	}
+	return formatContentTypes[formatJSON]
+}
+
+// newFeedWriter creates a writer for the given format. flush is called after
+// each chunk so the data reaches the client immediately; it may be nil.
+func newFeedWriter(format string, w io.Writer, flush func()) feedWriter {
+	if flush == nil {
+		flush = func() {}
+	}
+	switch format {
+	case formatRSS:
+		return &rssFeedWriter{w: w, flush: flush}
+	case formatAtom:
+		return &atomFeedWriter{w: w, flush: flush}
+	case formatMarkdown:
+		return &markdownFeedWriter{w: w, flush: flush}
+	}
+	return &jsonFeedWriter{w: w, flush: flush}
+}
+
+// summaryFeedWriter remembers the summary written at the end of a feed.
+type summaryFeedWriter struct {
+	feedWriter
+	summary feedSummary
+}
+
+func (s *summaryFeedWriter) End(summary feedSummary) error {
+	s.summary = summary
+	return s.feedWriter.End(summary)
+}
+
+// jsonFeedWriter streams the JSON response one item at a time.
+type jsonFeedWriter struct {
+	w     io.Writer
+	flush func()
+	count int
+}
+
+func (j *jsonFeedWriter) Begin(meta feedMeta) error {
+	title, _ := json.Marshal(meta.Title)
+	link, _ := json.Marshal(meta.Link)
+	paging := ""
+	if len(meta.Links) > 0 {
+		links, _ := json.Marshal(meta.Links)
+		paging += ",\n  \"feed_links\": " + string(links)
+	}
+	if meta.Archive {
+		paging += ",\n  \"feed_archive\": true"
+	}
+	version := meta.SchemaVersion
+	if version == 0 {
+		version = SchemaVersion
+	}
+	_, err := fmt.Fprintf(j.w, "{\n  \"schema_version\": %d,\n  \"feed_title\": %s,\n  \"feed_link\": %s%s,\n  \"items\": [", version, title, link, paging)
+	j.flush()
+	return err
+}
+
+func (j *jsonFeedWriter) WriteItem(item Item) error {
+	data, err := json.MarshalIndent(item, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := "\n    "
+	if j.count > 0 {
+		sep = ",\n    "
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	j.flush()
+	return err
+}
+
+func (j *jsonFeedWriter) End(summary feedSummary) error {
+	closing := "]"
+	if j.count > 0 {
+		closing = "\n  ]"
+	}
+	debug := ""
+	if summary.Debug != nil {
+		data, err := json.MarshalIndent(summary.Debug, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		debug = ",\n  \"debug\": " + string(data)
+	}
+	_, err := fmt.Fprintf(j.w, "%s,\n  \"items_returned\": %d,\n  \"items_skipped\": %d,\n  \"items_reused\": %d,\n  \"items_clustered\": %d,\n  \"items_partial\": %d,\n  \"items_deleted\": %d%s\n}",
+		closing, summary.Returned, summary.Skipped, summary.Reused, summary.Clustered, summary.Partial, summary.Deleted, debug)
+	j.flush()
+	return err
+}
+
+// rssFeedWriter streams an RSS 2.0 document one item at a time.
+type rssFeedWriter struct {
+	w     io.Writer
+	flush func()
+}
+
+// rssCDATA wraps text in a CDATA section.
+type rssCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssSource struct {
+	Name string `xml:",chardata"`
+	URL  string `xml:"url,attr"`
+}
+
+type rssCategory struct {
+	Value  string `xml:",chardata"`
+	Domain string `xml:"domain,attr,omitempty"`
+}
+
+type rssItem struct {
+	XMLName      xml.Name       `xml:"item"`
+	Title        string         `xml:"title"`
+	Link         string         `xml:"link"`
+	GUID         rssGUID        `xml:"guid"`
+	PubDate      string         `xml:"pubDate,omitempty"`
+	Creators     []string       `xml:"dc:creator"`
+	Contributors []string       `xml:"dc:contributor"`
+	Date         string         `xml:"dc:date,omitempty"`
+	Subjects     []string       `xml:"dc:subject"`
+	Publisher    string         `xml:"dc:publisher,omitempty"`
+	Rights       string         `xml:"dc:rights,omitempty"`
+	Language     string         `xml:"dc:language,omitempty"`
+	Comments     string         `xml:"comments,omitempty"`
+	Description  *rssCDATA      `xml:"description,omitempty"`
+	Content      *rssCDATA      `xml:"content:encoded,omitempty"`
+	Categories   []rssCategory  `xml:"category"`
+	Source       *rssSource     `xml:"source,omitempty"`
+	Enclosures   []rssEnclosure `xml:"enclosure"`
+	// Podcast metadata passed through from the upstream item
+	ITunesImage       *rssITunesImage `xml:"itunes:image,omitempty"`
+	ITunesDuration    string          `xml:"itunes:duration,omitempty"`
+	ITunesEpisode     string          `xml:"itunes:episode,omitempty"`
+	ITunesSeason      string          `xml:"itunes:season,omitempty"`
+	ITunesEpisodeType string          `xml:"itunes:episodeType,omitempty"`
+	ITunesExplicit    string          `xml:"itunes:explicit,omitempty"`
+	ITunesAuthor      string          `xml:"itunes:author,omitempty"`
+	ITunesSubtitle    string          `xml:"itunes:subtitle,omitempty"`
+	GeoPoint          string          `xml:"georss:point,omitempty"`
+	GeoName           string          `xml:"georss:featurename,omitempty"`
+}
+
+func (x *rssFeedWriter) Begin(meta feedMeta) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:at="http://purl.org/atompub/tombstones/1.0" xmlns:itunes="` + itunesNamespace + `" xmlns:georss="` + georssNamespace + `" xmlns:atom="` + atomNamespace + `" xmlns:fh="` + historyNamespace + `">` + "\n<channel>\n")
+	for _, el := range []struct{ name, value string }{
+		{"title", meta.Title},
+		{"link", meta.Link},
+		{"description", meta.Description},
+		{"lastBuildDate", meta.Updated.UTC().Format(time.RFC1123Z)},
+	} {
+		b.WriteString("<" + el.name + ">")
+		xml.EscapeText(&b, []byte(el.value))
+		b.WriteString("</" + el.name + ">\n")
+	}
+	for _, link := range meta.Links {
+		b.WriteString(`<atom:link rel="` + link.Rel + `" href="`)
+		xml.EscapeText(&b, []byte(link.Href))
+		b.WriteString("\"/>\n")
+	}
+	if meta.Archive {
+		b.WriteString("<fh:archive/>\n")
+	}
+	if meta.ITunes != nil {
+		if err := writeITunesChannel(&b, meta.ITunes); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(x.w, b.String())
+	x.flush()
+	return err
+}
+
+// rssTombstone is an RFC 6721 deleted entry standing for a retracted item.
+type rssTombstone struct {
+	XMLName xml.Name `xml:"at:deleted-entry"`
+	Ref     string   `xml:"ref,attr"`
+	When    string   `xml:"when,attr"`
+	Link    string   `xml:"link,omitempty"`
+}
+
+func (x *rssFeedWriter) WriteItem(item Item) error {
+	if item.Deleted {
+		return x.write(rssTombstone{Ref: item.GUID, When: item.DeletedAt, Link: item.Link})
+	}
+	out := rssItem{
+		Title:    item.Title,
+		Link:     item.Link,
+		GUID:     rssGUID{Value: item.GUID, IsPermaLink: "false"},
+		PubDate:  rssDate(item.Published),
+		Comments: item.CommentsURL,
+	}
+	// Upstream Dublin Core metadata is passed through, all creators included
+	if dc := item.DublinCore; dc != nil {
+		out.Creators, out.Contributors, out.Subjects = dc.Creators, dc.Contributors, dc.Subjects
+		out.Date, out.Publisher, out.Rights, out.Language = dc.Date, dc.Publisher, dc.Rights, dc.Language
+	}
+	if len(out.Creators) == 0 && item.Author != "" {
+		out.Creators = []string{item.Author}
+	}
+	// Our own category comes first, followed by the upstream ones with their
+	// domains and tags
+	categories := append([]string{item.Category}, item.Categories...)
+	seen := make(map[string]bool)
+	for _, c := range append(categories, item.Tags...) {
+		if c != "" && !seen[c] {
+			seen[c] = true
+			out.Categories = append(out.Categories, rssCategory{Value: c, Domain: item.CategoryDomains[c]})
+		}
+	}
+	if item.SourceURL != "" {
+		out.Source = &rssSource{Name: item.SourceName, URL: item.SourceURL}
+	}
+	if item.Description != "" {
+		out.Description = &rssCDATA{Text: item.Description}
+	}
+	if item.Content != "" {
+		out.Content = &rssCDATA{Text: item.Content}
+	}
+	// Upstream enclosures come first for podcast apps reading only one,
+	// followed by the lead image unless it is one of them
+	for _, enc := range item.Enclosures {
+		out.Enclosures = append(out.Enclosures, rssEnclosure{URL: enc.URL, Length: strconv.FormatInt(enc.Length, 10), Type: enc.Type})
+	}
+	if item.Image != "" && !item.encloses(item.Image) {
+		out.Enclosures = append(out.Enclosures, rssEnclosure{URL: item.Image, Length: "0", Type: imageMimeType(item.Image)})
+	}
+	if it := item.ITunes; it != nil {
+		if it.Image != "" {
+			out.ITunesImage = &rssITunesImage{Href: it.Image}
+		}
+		out.ITunesDuration, out.ITunesEpisode, out.ITunesSeason, out.ITunesEpisodeType = it.Duration, it.Episode, it.Season, it.EpisodeType
+		out.ITunesExplicit, out.ITunesAuthor, out.ITunesSubtitle = it.Explicit, it.Author, it.Subtitle
+	}
+	if item.Location != nil {
+		out.GeoPoint, out.GeoName = item.Location.georssPoint(), item.Location.Name
+	}
+	return x.write(out)
+}
+
+// write marshals an element of the channel.
+func (x *rssFeedWriter) write(v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = x.w.Write(data)
+	x.flush()
+	return err
+}
+
+func (x *rssFeedWriter) End(summary feedSummary) error {
+	_, err := io.WriteString(x.w, "</channel>\n</rss>\n")
+	x.flush()
+	return err
+}
+
+// atomFeedWriter streams an Atom document one entry at a time. Tombstones
+// are written as RFC 6721 deleted entries.
+type atomFeedWriter struct {
+	w       io.Writer
+	flush   func()
+	updated time.Time
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term   string `xml:"term,attr"`
+	Scheme string `xml:"scheme,attr,omitempty"`
+}
+
+type atomSource struct {
+	Title string   `xml:"title"`
+	Link  atomLink `xml:"link"`
+}
+
+type atomEntry struct {
+	XMLName    xml.Name       `xml:"entry"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Source     *atomSource    `xml:"source,omitempty"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	GeoPoint   string         `xml:"georss:point,omitempty"`
+	GeoName    string         `xml:"georss:featurename,omitempty"`
+}
+
+type atomTombstone struct {
+	XMLName xml.Name  `xml:"at:deleted-entry"`
+	Ref     string    `xml:"ref,attr"`
+	When    string    `xml:"when,attr"`
+	Link    *atomLink `xml:"link,omitempty"`
+}
+
+// atomID returns the Atom ID of the item with guid.
+func atomID(guid string) string {
+	return "tag:gofull,2024:" + guid
+}
+
+func (x *atomFeedWriter) Begin(meta feedMeta) error {
+	x.updated = meta.Updated
+	id := meta.Link
+	if id == "" {
+		id = atomID("feed")
+	}
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<feed xmlns="` + atomNamespace + `" xmlns:at="http://purl.org/atompub/tombstones/1.0" xmlns:georss="` + georssNamespace + `" xmlns:fh="` + historyNamespace + `">` + "\n")
+	for _, el := range []struct{ name, value string }{
+		{"title", meta.Title},
+		{"subtitle", meta.Description},
+		{"id", id},
+		{"updated", meta.Updated.UTC().Format(time.RFC3339)},
+	} {
+		if el.value == "" && el.name == "subtitle" {
+			continue
+		}
+		b.WriteString("<" + el.name + ">")
+		xml.EscapeText(&b, []byte(el.value))
+		b.WriteString("</" + el.name + ">\n")
+	}
+	if meta.Link != "" {
+		b.WriteString(`<link rel="alternate" href="`)
+		xml.EscapeText(&b, []byte(meta.Link))
+		b.WriteString("\"/>\n")
+	}
+	for _, link := range meta.Links {
+		b.WriteString(`<link rel="` + link.Rel + `" href="`)
+		xml.EscapeText(&b, []byte(link.Href))
+		b.WriteString("\"/>\n")
+	}
+	if meta.Archive {
+		b.WriteString("<fh:archive/>\n")
+	}
+	_, err := io.WriteString(x.w, b.String())
+	x.flush()
+	return err
+}
+
+func (x *atomFeedWriter) WriteItem(item Item) error {
+	if item.Deleted {
+		tomb := atomTombstone{Ref: atomID(item.GUID), When: item.DeletedAt}
+		if item.Link != "" {
+			tomb.Link = &atomLink{Href: item.Link}
+		}
+		return x.write(tomb)
+	}
+
+	// Atom requires an update date: the article's, else its publication
+	updated := item.UpdatedAt
+	if updated == "" {
+		updated = item.Published
+	}
+	if updated == "" {
+		updated = x.updated.UTC().Format(time.RFC3339)
+	}
+	out := atomEntry{
+		Title:     item.Title,
+		ID:        atomID(item.GUID),
+		Updated:   updated,
+		Published: item.Published,
+	}
+	if item.Link != "" {
+		out.Links = append(out.Links, atomLink{Href: item.Link, Rel: "alternate"})
+	}
+	if item.CommentsURL != "" {
+		out.Links = append(out.Links, atomLink{Href: item.CommentsURL, Rel: "replies", Type: "text/html"})
+	}
+	for _, enc := range item.Enclosures {
+		link := atomLink{Href: enc.URL, Rel: "enclosure", Type: enc.Type}
+		if enc.Length > 0 {
+			link.Length = strconv.FormatInt(enc.Length, 10)
+		}
+		out.Links = append(out.Links, link)
+	}
+	if item.Image != "" && !item.encloses(item.Image) {
+		out.Links = append(out.Links, atomLink{Href: item.Image, Rel: "enclosure", Type: imageMimeType(item.Image), Length: "0"})
+	}
+	if item.Author != "" {
+		out.Author = &atomPerson{Name: item.Author}
+	}
+	categories := append([]string{item.Category}, item.Categories...)
+	seen := make(map[string]bool)
+	for _, c := range append(categories, item.Tags...) {
+		if c != "" && !seen[c] {
+			seen[c] = true
+			out.Categories = append(out.Categories, atomCategory{Term: c, Scheme: item.CategoryDomains[c]})
+		}
+	}
+	if item.SourceURL != "" {
+		out.Source = &atomSource{Title: item.SourceName, Link: atomLink{Href: item.SourceURL, Rel: "self"}}
+	}
+	if item.Description != "" {
+		out.Summary = &atomText{Text: item.Description}
+	}
+	if item.Content != "" {
+		out.Content = &atomText{Type: "html", Text: item.Content}
+	}
+	if item.Location != nil {
+		out.GeoPoint, out.GeoName = item.Location.georssPoint(), item.Location.Name
+	}
+	return x.write(out)
+}
+
+func (x *atomFeedWriter) write(v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = x.w.Write(data)
+	x.flush()
+	return err
+}
+
+func (x *atomFeedWriter) End(summary feedSummary) error {
+	_, err := io.WriteString(x.w, "</feed>\n")
+	x.flush()
+	return err
+}
+
+// markdownFeedWriter renders a feed as a Markdown document, one section per
+// article. Tombstones are left out.
+type markdownFeedWriter struct {
+	w     io.Writer
+	flush func()
+}
+
+func (m *markdownFeedWriter) Begin(meta feedMeta) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscape(meta.Title))
+	if meta.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", markdownEscape(meta.Description))
+	}
+	b.WriteString("---\n\n")
+	_, err := io.WriteString(m.w, b.String())
+	m.flush()
+	return err
+}
+
+func (m *markdownFeedWriter) WriteItem(item Item) error {
+	if item.Deleted {
+		return nil
+	}
+	err := writeMarkdownArticle(m.w, item.SourceURL, ArchivedItem{Item: item})
+	m.flush()
+	return err
+}
+
+func (m *markdownFeedWriter) End(summary feedSummary) error {
+	return nil
+}
+
+// rssDate converts an RFC3339 timestamp to the RFC822 format used by RSS.
+func rssDate(published string) string {
+	if published == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, published)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// imageMimeType guesses an image MIME type from the URL's file extension.
+func imageMimeType(imageURL string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(imageURL, "?", 2)[0]))
+	if t := mime.TypeByExtension(ext); strings.HasPrefix(t, "image/") {
+		return t
+	}
+	return "image/jpeg"
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+func TestOutputFormat(t *testing.T) {
+	tests := []struct {
+		query  string
+		accept string
+		want   string
+		status int
+	}{
+		{"", "", formatJSON, 0},
+		{"?format=rss", "application/atom+xml", formatRSS, 0},
+		{"", "application/atom+xml", formatAtom, 0},
+		{"", "text/markdown", formatMarkdown, 0},
+		{"", "text/html,application/xhtml+xml,*/*;q=0.8", formatJSON, 0},
+		{"", "application/rss+xml, application/rdf+xml;q=0.8, application/atom+xml;q=0.6", formatRSS, 0},
+		{"", "application/rss+xml;q=0.5, application/atom+xml", formatAtom, 0},
+		{"", "image/png", "", http.StatusNotAcceptable},
+		{"?format=pdf", "", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/feed"+tt.query, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		got, err := outputFormat(r)
+		if tt.status != 0 {
+			if err == nil || err.Status != tt.status {
+				t.Errorf("%s %q: error %v, want status %d", tt.query, tt.accept, err, tt.status)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s %q = %q, %v; want %q", tt.query, tt.accept, got, err, tt.want)
+		}
+	}
+}
+
+func TestAtomFeedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	fw := newFeedWriter(formatAtom, &buf, nil)
+	fw.Begin(feedMeta{Title: "Haberler & Gündem", Link: "https://news.example.com/", Updated: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)})
+	fw.WriteItem(Item{Title: "Deprem", Link: "https://news.example.com/a/1", GUID: "abc", Published: "2024-05-01T10:00:00+03:00", Content: "<p>Metin</p>", Image: "https://news.example.com/a.png"})
+	fw.WriteItem(Item{Link: "https://news.example.com/a/2", GUID: "def", Deleted: true, DeletedAt: "2024-05-01T11:00:00Z"})
+	fw.End(feedSummary{})
+
+	var doc struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Updated string `xml:"updated"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+		Deleted []struct {
+			Ref string `xml:"ref,attr"`
+		} `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid Atom: %v\n%s", err, buf.String())
+	}
+	if doc.Title != "Haberler & Gündem" || len(doc.Entries) != 1 || len(doc.Deleted) != 1 {
+		t.Fatalf("unexpected document:\n%s", buf.String())
+	}
+	if e := doc.Entries[0]; e.ID != atomID("abc") || e.Updated != "2024-05-01T10:00:00+03:00" || e.Content != "<p>Metin</p>" {
+		t.Errorf("entry = %+v", e)
+	}
+	if doc.Deleted[0].Ref != atomID("def") {
+		t.Errorf("tombstone ref = %q", doc.Deleted[0].Ref)
+	}
+	if !strings.Contains(buf.String(), `rel="enclosure" type="image/png"`) {
+		t.Errorf("image enclosure missing:\n%s", buf.String())
+	}
+}
+
+func TestRSSMetadataRoundTrip(t *testing.T) {
+	const upstream = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel>
+<title>Haber</title><link>https://haber.test/</link>
+<item>
+<title>Bütçe kabul edildi</title><link>https://haber.test/a/1</link>
+<dc:creator>Ayşe Yılmaz</dc:creator><dc:creator>Mehmet Demir</dc:creator>
+<dc:date>2024-10-01T14:00:00+03:00</dc:date>
+<dc:rights>© Haber Test</dc:rights>
+<dc:subject>Ekonomi</dc:subject>
+<category domain="https://haber.test/kategori">Ekonomi</category>
+<content:encoded><![CDATA[<p>Bütçe Meclis'te kabul edildi.</p>]]></content:encoded>
+</item>
+</channel></rss>`
+	feed, err := newFeedParser().ParseString(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, extractors.NewRegistry(), filters.NewFilterRegistry(), nil, nil)
+	item := h.buildItem(feed.Items[0], true)
+
+	var buf bytes.Buffer
+	fw := newFeedWriter(formatRSS, &buf, nil)
+	fw.Begin(feedMeta{Title: feed.Title})
+	fw.WriteItem(item)
+	fw.End(feedSummary{})
+	for _, want := range []string{
+		"<dc:creator>Ayşe Yılmaz</dc:creator>",
+		"<dc:creator>Mehmet Demir</dc:creator>",
+		"<dc:date>2024-10-01T14:00:00+03:00</dc:date>",
+		"<dc:rights>© Haber Test</dc:rights>",
+		"<dc:subject>Ekonomi</dc:subject>",
+		`<category domain="https://haber.test/kategori">Ekonomi</category>`,
+		"<content:encoded><![CDATA[<p>Bütçe Meclis",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RSS lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestEnclosurePassthrough(t *testing.T) {
+	item := Item{
+		Title: "Bölüm 12", Link: "https://podcast.test/12", GUID: "p12",
+		Image: "https://podcast.test/kapak.jpg",
+		Enclosures: []ItemEnclosure{
+			{URL: "https://podcast.test/12.mp3", Length: 24986239, Type: "audio/mpeg"},
+			{URL: "https://podcast.test/kapak.jpg", Type: "image/jpeg"},
+		},
+	}
+
+	var buf bytes.Buffer
+	fw := newFeedWriter(formatRSS, &buf, nil)
+	fw.Begin(feedMeta{Title: "Podcast"})
+	fw.WriteItem(item)
+	fw.End(feedSummary{})
+	var doc struct {
+		Enclosures []struct {
+			URL    string `xml:"url,attr"`
+			Length string `xml:"length,attr"`
+			Type   string `xml:"type,attr"`
+		} `xml:"channel>item>enclosure"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid RSS: %v\n%s", err, buf.String())
+	}
+	// The lead image is one of the enclosures, so it is not repeated
+	if len(doc.Enclosures) != 2 {
+		t.Fatalf("%d enclosures, want 2:\n%s", len(doc.Enclosures), buf.String())
+	}
+	if e := doc.Enclosures[0]; e.URL != "https://podcast.test/12.mp3" || e.Length != "24986239" || e.Type != "audio/mpeg" {
+		t.Errorf("first enclosure = %+v; want the episode", e)
+	}
+
+	buf.Reset()
+	fw = newFeedWriter(formatJSON, &buf, nil)
+	fw.Begin(feedMeta{Title: "Podcast"})
+	fw.WriteItem(item)
+	fw.End(feedSummary{})
+	var resp struct{ Items []Item }
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 1 || !slices.Equal(resp.Items[0].Enclosures, item.Enclosures) {
+		t.Errorf("JSON enclosures differ:\n%s", buf.String())
+	}
+}
+
+func TestITunesPassthrough(t *testing.T) {
+	const upstream = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+<title>Gündem Podcast</title><link>https://podcast.test/</link>
+<itunes:author>Podcast Test</itunes:author>
+<itunes:image href="https://podcast.test/kapak.jpg"/>
+<itunes:explicit>false</itunes:explicit>
+<itunes:owner><itunes:name>Ayşe Yılmaz</itunes:name><itunes:email>ayse@podcast.test</itunes:email></itunes:owner>
+<itunes:category text="News"><itunes:category text="Daily News"/></itunes:category>
+<itunes:new-feed-url>https://elsewhere.test/rss</itunes:new-feed-url>
+<item>
+<title>Bölüm 12</title><link>https://podcast.test/12</link>
+<enclosure url="https://podcast.test/12.mp3" length="24986239" type="audio/mpeg"/>
+<itunes:duration>00:32:16</itunes:duration>
+<itunes:episode>12</itunes:episode>
+<itunes:season>2</itunes:season>
+<itunes:episodeType>full</itunes:episodeType>
+<itunes:explicit>true</itunes:explicit>
+<itunes:image href="https://podcast.test/12.jpg"/>
+</item>
+</channel></rss>`
+	feed, err := newFeedParser().ParseString(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, extractors.NewRegistry(), filters.NewFilterRegistry(), nil, nil)
+	item := h.buildItem(feed.Items[0], true)
+
+	var buf bytes.Buffer
+	fw := newFeedWriter(formatRSS, &buf, nil)
+	fw.Begin(feedMeta{Title: feed.Title, ITunes: channelITunes(feed)})
+	fw.WriteItem(item)
+	fw.End(feedSummary{})
+	out := buf.String()
+	for _, want := range []string{
+		`xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"`,
+		"<itunes:author>Podcast Test</itunes:author>",
+		`<itunes:image href="https://podcast.test/kapak.jpg"></itunes:image>`,
+		"<itunes:owner><itunes:name>Ayşe Yılmaz</itunes:name><itunes:email>ayse@podcast.test</itunes:email></itunes:owner>",
+		`<itunes:category text="News"><itunes:category text="Daily News"></itunes:category></itunes:category>`,
+		"<itunes:duration>00:32:16</itunes:duration>",
+		"<itunes:episode>12</itunes:episode>",
+		"<itunes:season>2</itunes:season>",
+		"<itunes:episodeType>full</itunes:episodeType>",
+		"<itunes:explicit>true</itunes:explicit>",
+		`<itunes:image href="https://podcast.test/12.jpg"></itunes:image>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RSS lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "new-feed-url") {
+		t.Errorf("RSS moves subscribers off the proxy:\n%s", out)
+	}
+	var doc struct{}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Errorf("invalid RSS: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+func TestHandlePlaygroundExtract(t *testing.T) {
+	reg := extractors.NewRegistry()
+	reg.RegisterDefault(extractorFunc(func(any) (string, []string, error) {
+		return "<p>" + strings.Repeat("kelime ", 300) + "</p>", nil, nil
+	}))
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, reg, filters.NewFilterRegistry(), nil, nil)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/yazar/"}})
+	s := &Server{cfg: &Config{}, feedHandler: h}
+
+	rec := httptest.NewRecorder()
+	s.handlePlaygroundExtract(rec, httptest.NewRequest(http.MethodGet, "/playground/extract?url=https://news.example.com/yazar/1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var result PlaygroundResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Extractor != "app.extractorFunc" {
+		t.Errorf("extractor %q", result.Extractor)
+	}
+	if !strings.Contains(result.Filter, "/yazar/") {
+		t.Errorf("filter %q; want the blocked path rule", result.Filter)
+	}
+	if !strings.Contains(result.Item.Content, "kelime") {
+		t.Error("extracted content missing")
+	}
+	if len(result.Trace) < 2 || !strings.HasPrefix(result.Trace[0], "extractor app.extractorFunc") {
+		t.Errorf("trace %q", result.Trace)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handlePlaygroundExtract(rec, httptest.NewRequest(http.MethodGet, "/playground/extract?url=ftp://news.example.com/", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("ftp URL: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handlePlayground(rec, httptest.NewRequest(http.MethodGet, "/playground", nil))
+	if !strings.Contains(rec.Body.String(), `<script src="/static/playground.js">`) {
+		t.Error("playground page lacks its script")
+	}
+}
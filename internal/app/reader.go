@@ -0,0 +1,185 @@
+// internal/app/reader.go
+package app
+
+import (
+	"bytes"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+)
+
+// readerPage is the data the reader template renders.
+type readerPage struct {
+	Title     string
+	Link      string
+	Site      string
+	Author    string
+	Published string
+	Image     string
+	Caption   string
+	Credit    string
+	Content   template.HTML
+}
+
+// readerTemplate renders an article as a self-contained reading page: all
+// styling is inline and nothing but the article's own images is loaded.
+var readerTemplate = template.Must(template.New("reader").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="referrer" content="no-referrer">
+    <title>{{.Title}}</title>
+    <style>
+        body {
+            margin: 0;
+            padding: 40px 20px;
+            background: #fbfaf7;
+            color: #222;
+            font-family: Georgia, 'Times New Roman', serif;
+            font-size: 1.15em;
+            line-height: 1.7;
+        }
+        article { max-width: 680px; margin: 0 auto; }
+        h1 { font-size: 2em; line-height: 1.25; margin: 0 0 10px; }
+        .byline {
+            color: #777;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif;
+            font-size: 0.8em;
+            margin-bottom: 30px;
+        }
+        .byline a { color: inherit; }
+        img, figure, video { max-width: 100%; height: auto; }
+        figure { margin: 1.5em 0; }
+        figcaption {
+            color: #777;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif;
+            font-size: 0.75em;
+            margin-top: 6px;
+        }
+        figcaption .credit { font-style: italic; }
+        blockquote { margin: 1.5em 0; padding-left: 1em; border-left: 3px solid #ddd; color: #555; }
+        a { color: #3b5bdb; }
+        @media (prefers-color-scheme: dark) {
+            body { background: #1c1c1e; color: #ddd; }
+            .byline, figcaption { color: #999; }
+            a { color: #8ea6ff; }
+        }
+    </style>
+</head>
+<body>
+    <article>
+        <h1>{{.Title}}</h1>
+        <div class="byline">
+            {{- if .Site}}{{.Site}} · {{end -}}
+            {{- if .Author}}{{.Author}} · {{end -}}
+            {{- if .Published}}{{.Published}} · {{end -}}
+            <a href="{{.Link}}">Original article</a>
+        </div>
+        {{- if .Image}}
+        <figure>
+            <img src="{{.Image}}" alt="{{.Caption}}">
+            {{- if or .Caption .Credit}}
+            <figcaption>{{.Caption}}{{if and .Caption .Credit}} {{end}}{{if .Credit}}<span class="credit">{{.Credit}}</span>{{end}}</figcaption>
+            {{- end}}
+        </figure>
+        {{- end}}
+        {{.Content}}
+    </article>
+</body>
+</html>
+`))
+
+// handleRead serves GET /read?url=..., the article at url as a clean reading
+// page. Articles already extracted for a feed are served from the archive;
+// others are extracted on the spot. Rendered pages are cached like feeds.
+func (s *Server) handleRead(w http.ResponseWriter, r *http.Request) {
+	target := strings.TrimSpace(r.URL.Query().Get("url"))
+	if _, apiErr := validateTargetURL("url", target); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	filterSet, apiErr := s.feedHandler.parseFilters(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if !s.feedHandler.filterSet(filterSet).ShouldProcess(target) {
+		writeError(w, r, newAPIError(http.StatusUnprocessableEntity, CodeFilteredURL,
+			"URL is excluded by the site's filter rules").with("url", target))
+		return
+	}
+
+	cacheKey := "read|" + target
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Cache", "HIT")
+		w.Write([]byte(cached))
+		return
+	}
+
+	item, ok := s.archive.Get(extractors.GenerateGUIDFromURL(target))
+	if !ok || item.Content == "" {
+		if s.feedHandler.shed(w, r) {
+			return
+		}
+		item = s.feedHandler.extract(&gofeed.Item{Link: target}, nil)
+		if strings.TrimSpace(item.Content) == "" {
+			writeError(w, r, extractionError(target, item.err))
+			return
+		}
+	}
+
+	page := readerPage{
+		Title:     item.Title,
+		Link:      target,
+		Site:      item.SourceName,
+		Author:    item.Author,
+		Published: readerDate(item.Published),
+		Content:   template.HTML(item.Content),
+	}
+	// The article image is shown unless the content already contains it
+	if item.Image != "" && !strings.Contains(item.Content, item.Image) {
+		page.Image = item.Image
+		if len(item.Images) > 0 && item.Images[0].URL == item.Image {
+			page.Caption, page.Credit = item.Images[0].Caption, item.Images[0].Credit
+		}
+	}
+	// Pages extracted on the spot have no feed to name them
+	if page.Title == "" || page.Site == "" {
+		if meta, err := s.fetchSiteMeta(target); err == nil {
+			page.Title = firstNonEmpty(page.Title, meta.Title)
+			page.Site = firstNonEmpty(page.Site, meta.SiteName)
+		}
+	}
+	if page.Title == "" {
+		page.Title = target
+	}
+
+	var buf bytes.Buffer
+	if err := readerTemplate.Execute(&buf, page); err != nil {
+		log.Printf("❌ Error rendering reader page for %s: %v", target, err)
+		writeError(w, r, newAPIError(http.StatusInternalServerError, CodeInternal, "could not render page"))
+		return
+	}
+	s.cache.Set(cacheKey, buf.String())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Cache", "MISS")
+	w.Write(buf.Bytes())
+}
+
+// readerDate formats an item's RFC 3339 publication time for display.
+func readerDate(published string) string {
+	t, err := time.Parse(time.RFC3339, published)
+	if err != nil {
+		return published
+	}
+	return t.Format("2 January 2006 15:04")
+}
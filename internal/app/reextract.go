@@ -0,0 +1,144 @@
+// internal/app/reextract.go
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gofull/internal/extractors"
+)
+
+// ReextractJobAccepted is returned by POST /admin/reextract.
+type ReextractJobAccepted struct {
+	JobID     string `json:"job_id"`
+	StatusURL string `json:"status_url"`
+	Items     int    `json:"items"`
+}
+
+// reextractTarget is an archived item with the snapshot it is replayed from.
+type reextractTarget struct {
+	feedURL  string
+	item     Item
+	snapshot Snapshot
+}
+
+// handleReextract starts a background job running the stored snapshots of
+// archived articles through the current extractors and archiving the
+// results, so fixes to an extractor reach articles extracted before them.
+func (s *Server) handleReextract(w http.ResponseWriter, r *http.Request) {
+	if s.snapshots == nil {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "snapshots are disabled"))
+		return
+	}
+	q := r.URL.Query()
+	var since time.Time
+	if v := strings.TrimSpace(q.Get("since")); v != "" {
+		t, err := parseSince(v, time.Now())
+		if err != nil {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"since must be an RFC 3339 time, a date or a duration such as 24h").with("parameter", "since"))
+			return
+		}
+		since = t
+	}
+	feeds := q["feed"]
+	if len(feeds) == 0 {
+		feeds = s.archive.Feeds()
+	}
+
+	var targets []reextractTarget
+	for _, feedURL := range feeds {
+		for _, entry := range s.archive.Entries(feedURL) {
+			if entry.StoredAt.Before(since) || entry.Item.Link == "" || entry.Item.Deleted {
+				continue
+			}
+			if snap, ok := s.snapshots.Latest(entry.Item.Link); ok {
+				targets = append(targets, reextractTarget{feedURL: feedURL, item: entry.Item, snapshot: snap})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no archived articles with snapshots to re-extract"))
+		return
+	}
+
+	job := s.jobs.Create("reextract")
+	s.jobs.Update(job.ID, func(job *Job) { job.Total = len(targets) })
+	go s.runReextractJob(job.ID, targets)
+
+	log.Printf("♻️  Started re-extraction job %s for %d articles", job.ID, len(targets))
+	writeJSON(w, http.StatusAccepted, ReextractJobAccepted{
+		JobID:     job.ID,
+		StatusURL: "/jobs/" + job.ID,
+		Items:     len(targets),
+	})
+}
+
+// parseSince reads since as an RFC 3339 time, a date or a duration back from
+// now.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, since); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(since)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q", since)
+	}
+	return now.Add(-d), nil
+}
+
+// runReextractJob replays each target's snapshot and archives the result.
+func (s *Server) runReextractJob(jobID string, targets []reextractTarget) {
+	s.jobs.Update(jobID, func(job *Job) { job.Status = JobRunning })
+	for _, t := range targets {
+		item, err := s.feedHandler.replaySnapshot(t.item, t.snapshot)
+		if err != nil {
+			log.Printf("⚠️  Could not re-extract %s: %v", t.item.Link, err)
+			s.jobs.Update(jobID, func(job *Job) { job.Failed++ })
+			continue
+		}
+		s.archive.Put(t.feedURL, item)
+		s.jobs.Update(jobID, func(job *Job) { job.Done++ })
+	}
+	s.jobs.Update(jobID, func(job *Job) { job.Status = JobDone })
+	log.Printf("♻️  Re-extraction job %s finished", jobID)
+}
+
+// replaySnapshot extracts stored, an archived item, again from snap, the
+// article page as fetched before, cleaning and analyzing the result the way
+// fresh extractions are. Nothing is fetched.
+func (h *FeedHandler) replaySnapshot(stored Item, snap Snapshot) (Item, error) {
+	input := map[string]interface{}{"html": snap.HTML, "link": stored.Link}
+	content, images, err := safeExtract(h.Registry.ForURL(stored.Link), input)
+	if (err != nil || textLength(content) == 0) && h.Registry.Default() != nil {
+		content, images, err = safeExtract(h.Registry.Default(), input)
+	}
+	if err != nil {
+		return stored, err
+	}
+	if textLength(content) == 0 {
+		return stored, errors.New("no article found in the snapshot")
+	}
+
+	item := stored
+	captions := captionedImages(content, stored.Link)
+	item.Content = h.cleanArticle(stored.Link, cleanHTMLContent(content))
+	images = extractors.CollapseImageVariants(images)
+	if h.ImageRules != nil {
+		for k, u := range images {
+			images[k] = h.ImageRules.Apply(stored.Link, u)
+		}
+	}
+	if len(images) > 0 {
+		item.Image = images[0]
+	}
+	item.Images = itemImages(item.Image, images, captions)
+	return h.analyze(item), nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+	"gofull/internal/storage"
+)
+
+func TestReextractReplaysSnapshots(t *testing.T) {
+	registry := extractors.NewRegistry()
+	registry.RegisterDefault(extractorFunc(func(input any) (string, []string, error) {
+		page := input.(map[string]interface{})["html"].(string)
+		return strings.ReplaceAll(page, "<div>", "<p>"), nil, nil
+	}))
+	archive := NewArchive(0)
+	h := NewFeedHandler(NewCache(time.Minute, 0), nil, registry, filters.NewFilterRegistry(), archive, nil)
+	s := &Server{
+		cfg:         &Config{},
+		archive:     archive,
+		jobs:        NewJobStore(storage.NewMemoryStore()),
+		feedHandler: h,
+		snapshots:   NewSnapshots(storage.NewMemoryStore(), 1, nil),
+	}
+
+	for _, n := range []string{"1", "2"} {
+		link := "https://news.example.com/a/" + n
+		archive.Put(testFeedURL, Item{Title: "Item " + n, Link: link, GUID: extractors.GenerateGUIDFromURL(link), Content: "<p>broken</p>"})
+	}
+	s.snapshots.Save("https://news.example.com/a/1", []byte("<div>fixed article</p>"))
+
+	rec := httptest.NewRecorder()
+	s.handleReextract(rec, httptest.NewRequest(http.MethodPost, "/admin/reextract?since=1h&feed="+url.QueryEscape(testFeedURL), nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var accepted ReextractJobAccepted
+	json.Unmarshal(rec.Body.Bytes(), &accepted)
+	if accepted.Items != 1 {
+		t.Errorf("items = %d, want only the one with a snapshot", accepted.Items)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if job, _ := s.jobs.Get(accepted.JobID); job.Status == JobDone {
+			if job.Done != 1 {
+				t.Errorf("job = %+v", job)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("re-extraction job did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if item, _ := archive.Get(extractors.GenerateGUIDFromURL("https://news.example.com/a/1")); !strings.Contains(item.Content, "fixed article") || item.Title != "Item 1" {
+		t.Errorf("re-extracted item = %+v", item)
+	}
+	if item, _ := archive.Get(extractors.GenerateGUIDFromURL("https://news.example.com/a/2")); item.Content != "<p>broken</p>" {
+		t.Errorf("item without snapshot changed: %+v", item)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 9, 3, 12, 0, 0, 0, time.UTC)
+	for since, want := range map[string]time.Time{
+		"2024-09-01T08:00:00Z": time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC),
+		"2024-09-01":           time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		"36h":                  time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, err := parseSince(since, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseSince(%q) = %v, %v; want %v", since, got, err, want)
+		}
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("parseSince accepted an invalid value")
+	}
+}
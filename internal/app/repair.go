@@ -0,0 +1,147 @@
+// internal/app/repair.go
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Repairs repairFeed makes, named in logs.
+const (
+	repairEncoding = "encoding"
+	repairControl  = "control_characters"
+	repairEntities = "entities"
+)
+
+// entityRegex matches what may follow an ampersand to form a reference.
+var entityRegex = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{0,31});`)
+
+// xmlEntities are the named references XML defines without a DTD.
+var xmlEntities = map[string]bool{"amp": true, "lt": true, "gt": true, "quot": true, "apos": true}
+
+// repairFeed fixes the mistakes that make feeds of careless publishers
+// invalid XML: bytes that are not UTF-8 in a document declared or defaulting
+// to UTF-8, taken as Windows-1254 as Turkish sites mostly mean; control
+// characters XML forbids; and bare ampersands and HTML entities outside
+// CDATA sections. It returns body itself and no repairs when there was
+// nothing to fix. JSON Feed documents are left alone.
+func repairFeed(body []byte) ([]byte, []string) {
+	if detectFeedFormat(body) == inputJSON {
+		return body, nil
+	}
+	var repairs []string
+	if declaresUTF8(body) && !utf8.Valid(body) {
+		body = decodeInvalidUTF8(body)
+		repairs = append(repairs, repairEncoding)
+	}
+	if stripped := stripControlBytes(body); len(stripped) != len(body) {
+		body = stripped
+		repairs = append(repairs, repairControl)
+	}
+	if escaped, changed := escapeEntities(body); changed {
+		body = escaped
+		repairs = append(repairs, repairEntities)
+	}
+	return body, repairs
+}
+
+// declaresUTF8 reports whether the XML document is UTF-8 by its
+// declaration, or by default without one.
+func declaresUTF8(body []byte) bool {
+	m := xmlEncodingDecl.FindSubmatch(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	return m == nil || sameCharset(string(m[1]), "utf-8")
+}
+
+// stripControlBytes removes the control characters XML forbids. It works on
+// bytes, which are the same in UTF-8 and the legacy encodings.
+func stripControlBytes(body []byte) []byte {
+	isControl := func(b byte) bool { return b < 0x20 && b != '\t' && b != '\n' && b != '\r' }
+	if !slices.ContainsFunc(body, isControl) {
+		return body
+	}
+	out := make([]byte, 0, len(body))
+	for _, b := range body {
+		if !isControl(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// decodeInvalidUTF8 keeps the valid UTF-8 sequences of body and decodes each
+// remaining byte as Windows-1254, as documents often mix both.
+func decodeInvalidUTF8(body []byte) []byte {
+	out := make([]byte, 0, len(body)+len(body)/8)
+	for len(body) > 0 {
+		r, size := utf8.DecodeRune(body)
+		if r == utf8.RuneError && size == 1 {
+			r = charmap.Windows1254.DecodeByte(body[0])
+		}
+		out = utf8.AppendRune(out, r)
+		body = body[size:]
+	}
+	return out
+}
+
+// escapeEntities escapes the ampersands of body that start no reference and
+// turns HTML entities, undefined in XML, into character references. CDATA
+// sections and comments are copied as they are.
+func escapeEntities(body []byte) ([]byte, bool) {
+	var out bytes.Buffer
+	changed := false
+	for len(body) > 0 {
+		if end := skipVerbatim(body); end > 0 {
+			out.Write(body[:end])
+			body = body[end:]
+			continue
+		}
+		if body[0] != '&' {
+			out.WriteByte(body[0])
+			body = body[1:]
+			continue
+		}
+		m := entityRegex.Find(body)
+		switch {
+		case m == nil:
+			out.WriteString("&amp;")
+			changed = true
+			body = body[1:]
+			continue
+		case m[1] == '#' || xmlEntities[string(m[1:len(m)-1])]:
+			out.Write(m)
+		default:
+			if text := html.UnescapeString(string(m)); text != string(m) {
+				for _, r := range text {
+					fmt.Fprintf(&out, "&#%d;", r)
+				}
+			} else {
+				out.WriteString("&amp;")
+				out.Write(m[1:])
+			}
+			changed = true
+		}
+		body = body[len(m):]
+	}
+	return out.Bytes(), changed
+}
+
+// skipVerbatim returns the length of the CDATA section or comment opening
+// body, 0 when body opens neither. An unterminated one runs to the end.
+func skipVerbatim(body []byte) int {
+	for _, delim := range [][2]string{{"<![CDATA[", "]]>"}, {"<!--", "-->"}} {
+		if !bytes.HasPrefix(body, []byte(delim[0])) {
+			continue
+		}
+		if end := bytes.Index(body[len(delim[0]):], []byte(delim[1])); end >= 0 {
+			return len(delim[0]) + end + len(delim[1])
+		}
+		return len(body)
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestRepairFeed(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+		repairs          []string
+	}{
+		{
+			name: "valid",
+			body: `<rss><channel><title>A &amp; B</title><description><![CDATA[<p>x & y</p>]]></description></channel></rss>`,
+			want: `<rss><channel><title>A &amp; B</title><description><![CDATA[<p>x & y</p>]]></description></channel></rss>`,
+		},
+		{
+			name:    "bare ampersands",
+			body:    `<item><title>Ekonomi & Finans</title><link>https://example.com/?a=1&b=2</link><!-- R&D --></item>`,
+			want:    `<item><title>Ekonomi &amp; Finans</title><link>https://example.com/?a=1&amp;b=2</link><!-- R&D --></item>`,
+			repairs: []string{repairEntities},
+		},
+		{
+			name:    "HTML entities",
+			body:    `<title>Haber&nbsp;&raquo; Son &bogus; &#246;</title>`,
+			want:    `<title>Haber&#160;&#187; Son &amp;bogus; &#246;</title>`,
+			repairs: []string{repairEntities},
+		},
+		{
+			name:    "control characters",
+			body:    "<title>Son\x0b dakika\x1f</title>",
+			want:    "<title>Son dakika</title>",
+			repairs: []string{repairControl},
+		},
+		{
+			name:    "Windows-1254 in UTF-8",
+			body:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?><title>Ye\xfeil \xe7ay ı</title>",
+			want:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?><title>Yeşil çay ı</title>",
+			repairs: []string{repairEncoding},
+		},
+		{
+			name: "declared legacy encoding",
+			body: "<?xml version=\"1.0\" encoding=\"windows-1254\"?><title>Ye\xfeil</title>",
+			want: "<?xml version=\"1.0\" encoding=\"windows-1254\"?><title>Ye\xfeil</title>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, repairs := repairFeed([]byte(tt.body))
+			if string(got) != tt.want || !slices.Equal(repairs, tt.repairs) {
+				t.Errorf("repairFeed() = %q, %v; want %q, %v", got, repairs, tt.want, tt.repairs)
+			}
+		})
+	}
+}
+
+func TestFetchFeedRepairsUnlessStrict(t *testing.T) {
+	const malformed = "<?xml version=\"1.0\"?>\n<rss version=\"2.0\"><channel><title>Spor & Ya\xfeam</title>\n" +
+		"<item><title>Derbi\x08 sonu&ccedil;land\xfd</title><link>https://news.example.com/a/1?ref=rss&utm=x</link></item>\n" +
+		"</channel></rss>"
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, malformed), nil
+	}, newFakeClock(), 0)
+
+	feed, err := h.fetchFeed(testFeedURL, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Spor & Yaşam" || len(feed.Items) != 1 || feed.Items[0].Title != "Derbi sonuçlandı" ||
+		feed.Items[0].Link != "https://news.example.com/a/1?ref=rss&utm=x" {
+		t.Errorf("repaired feed = %q, %+v", feed.Title, feed.Items)
+	}
+
+	_, err = h.fetchFeed(testFeedURL, true)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeInvalidFeed {
+		t.Errorf("strict fetchFeed() error = %v; want %s", err, CodeInvalidFeed)
+	}
+}
+
+func TestStrictChangesCacheKey(t *testing.T) {
+	p := feedParams{URL: testFeedURL, Limit: 10, Format: "json"}
+	strict := p
+	strict.Strict = true
+	if p.cacheKey() == strict.cacheKey() {
+		t.Errorf("strict and lenient renders share cache key %q", p.cacheKey())
+	}
+}
@@ -0,0 +1,217 @@
+// internal/app/requestlog.go
+package app
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRequestOrigins bounds the pages whose feed is remembered for
+// attributing requests.
+const maxRequestOrigins = 10000
+
+// OutboundRequest is a request the server made to an upstream site.
+type OutboundRequest struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URL    string    `json:"url"`
+	// Status is 0 when no response arrived
+	Status int   `json:"status,omitempty"`
+	Bytes  int64 `json:"bytes"`
+	// DurationMS runs until the body was read and closed
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	// Feed and Item are the feed and article the request was made for,
+	// when known
+	Feed string `json:"feed,omitempty"`
+	Item string `json:"item,omitempty"`
+}
+
+// requestOrigin is the feed and article a page is fetched for.
+type requestOrigin struct {
+	feed, item string
+}
+
+// RequestLog keeps the latest outbound requests in a ring buffer, so
+// operators can see what the server fetches on their behalf.
+type RequestLog struct {
+	mu      sync.Mutex
+	entries []OutboundRequest
+	next    int
+	full    bool
+	origins map[string]requestOrigin
+	order   []string // origin URLs, oldest first
+	clock   Clock
+}
+
+// NewRequestLog creates a RequestLog keeping the latest size requests.
+// clock may be nil.
+func NewRequestLog(size int, clock Clock) *RequestLog {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &RequestLog{entries: make([]OutboundRequest, size), origins: make(map[string]requestOrigin), clock: clock}
+}
+
+// Attribute records that requests for pageURL are made for the article
+// itemURL of feedURL. Feeds are attributed to themselves with no item.
+func (l *RequestLog) Attribute(pageURL, feedURL, itemURL string) {
+	if l == nil || pageURL == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.origins[pageURL]; !ok {
+		l.order = append(l.order, pageURL)
+	}
+	l.origins[pageURL] = requestOrigin{feed: feedURL, item: itemURL}
+	if len(l.order) > maxRequestOrigins {
+		delete(l.origins, l.order[0])
+		l.order = l.order[1:]
+	}
+}
+
+// add records a request.
+func (l *RequestLog) add(req OutboundRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = req
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns the logged requests, newest first.
+func (l *RequestLog) Recent() []OutboundRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.next
+	if l.full {
+		n = len(l.entries)
+	}
+	recent := make([]OutboundRequest, 0, n)
+	for i := 1; i <= n; i++ {
+		recent = append(recent, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return recent
+}
+
+// Transport returns a RoundTripper logging the requests sent through base
+// (http.DefaultTransport when nil). Each redirect and retry is a request of
+// its own, attributed like the first.
+func (l *RequestLog) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &loggingTransport{base: base, log: l}
+}
+
+// loggingTransport records each request in a RequestLog once its response
+// body is closed.
+type loggingTransport struct {
+	base http.RoundTripper
+	log  *RequestLog
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.log.clock.Now()
+	entry := OutboundRequest{Time: start.UTC(), Method: req.Method, URL: req.URL.String()}
+	// Redirected requests are attributed through the URL first asked for
+	first := req
+	for first.Response != nil && first.Response.Request != nil {
+		first = first.Response.Request
+	}
+	origin := first.URL.String()
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		entry.Error = err.Error()
+		entry.DurationMS = t.log.clock.Now().Sub(start).Milliseconds()
+		t.log.addFor(origin, entry)
+		return resp, err
+	}
+	entry.Status = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64) {
+		entry.Bytes = n
+		entry.DurationMS = t.log.clock.Now().Sub(start).Milliseconds()
+		t.log.addFor(origin, entry)
+	}}
+	return resp, nil
+}
+
+// addFor records a request attributed like the request for origin.
+func (l *RequestLog) addFor(origin string, req OutboundRequest) {
+	l.mu.Lock()
+	if o, ok := l.origins[origin]; ok {
+		req.Feed, req.Item = o.feed, o.item
+	}
+	l.mu.Unlock()
+	l.add(req)
+}
+
+// countingBody counts the bytes read from a response body and reports them
+// when it is closed.
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.n) })
+	return err
+}
+
+// handleRequests serves GET /admin/requests, the latest outbound requests,
+// newest first. They can be narrowed by domain, feed and status ("error"
+// for requests without a response, or a code such as 404), and limited.
+func (s *Server) handleRequests(w http.ResponseWriter, r *http.Request) {
+	if s.requestLog == nil {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "the request log is disabled"))
+		return
+	}
+	q := r.URL.Query()
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"'limit' must be a positive integer").with("parameter", "limit").with("value", v))
+			return
+		}
+		limit = n
+	}
+	domain := strings.TrimPrefix(strings.ToLower(q.Get("domain")), "www.")
+	feed, status := q.Get("feed"), q.Get("status")
+
+	requests := []OutboundRequest{}
+	for _, req := range s.requestLog.Recent() {
+		if len(requests) == limit {
+			break
+		}
+		if host := circuitDomain(req.URL); domain != "" && host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if feed != "" && req.Feed != feed {
+			continue
+		}
+		if (status == "error" && req.Error == "") || (status != "" && status != "error" && strconv.Itoa(req.Status) != status) {
+			continue
+		}
+		requests = append(requests, req)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"requests": requests})
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLogTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Write([]byte("<html>article</html>"))
+	}))
+	defer upstream.Close()
+
+	rl := NewRequestLog(10, newFakeClock())
+	rl.Attribute(upstream.URL+"/old", "https://feed.test/rss", upstream.URL+"/old")
+	client := &http.Client{Transport: rl.Transport(nil)}
+	resp, err := client.Get(upstream.URL + "/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	recent := rl.Recent()
+	if len(recent) != 2 {
+		t.Fatalf("logged %d requests, want the redirect and the page: %+v", len(recent), recent)
+	}
+	page, redirect := recent[0], recent[1]
+	if page.URL != upstream.URL+"/new" || page.Status != http.StatusOK || page.Bytes != int64(len("<html>article</html>")) {
+		t.Errorf("page request %+v", page)
+	}
+	if redirect.Status != http.StatusMovedPermanently {
+		t.Errorf("redirect request %+v", redirect)
+	}
+	for _, req := range recent {
+		if req.Feed != "https://feed.test/rss" || req.Item != upstream.URL+"/old" {
+			t.Errorf("request %s attributed to %q/%q", req.URL, req.Feed, req.Item)
+		}
+	}
+}
+
+func TestRequestLogRing(t *testing.T) {
+	rl := NewRequestLog(3, nil)
+	for _, u := range []string{"https://a.test/", "https://b.test/", "https://c.test/", "https://d.test/"} {
+		rl.add(OutboundRequest{URL: u})
+	}
+	recent := rl.Recent()
+	if len(recent) != 3 || recent[0].URL != "https://d.test/" || recent[2].URL != "https://b.test/" {
+		t.Errorf("recent = %+v", recent)
+	}
+}
+
+func TestHandleRequests(t *testing.T) {
+	rl := NewRequestLog(10, nil)
+	rl.add(OutboundRequest{URL: "https://www.haber.test/a", Status: 200, Feed: "https://haber.test/rss"})
+	rl.add(OutboundRequest{URL: "https://dunya.test/b", Status: 404})
+	rl.add(OutboundRequest{URL: "https://spor.haber.test/c", Error: "timeout"})
+	s := &Server{requestLog: rl}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"domain=haber.test", 2},
+		{"status=404", 1},
+		{"status=error", 1},
+		{"feed=https://haber.test/rss", 1},
+		{"limit=1", 1},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.handleRequests(rec, httptest.NewRequest(http.MethodGet, "/admin/requests?"+tt.query, nil))
+		var resp struct{ Requests []OutboundRequest }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Requests) != tt.want {
+			t.Errorf("%q: %d requests, want %d", tt.query, len(resp.Requests), tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// internal/app/rollup.go
+package app
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"gofull/internal/extractors"
+)
+
+// Rollup windows selected with the rollup query parameter: the items
+// published within one are combined into a single item.
+const (
+	rollupHourly = "hourly"
+	rollupDaily  = "daily"
+	rollupWeekly = "weekly"
+)
+
+// rollupLabels format the start of a window in the title of its rollup.
+var rollupLabels = map[string]string{
+	rollupHourly: "2 January 2006 15:00",
+	rollupDaily:  "2 January 2006",
+	rollupWeekly: "week of 2 January 2006",
+}
+
+// rollup combines the items of a feed published within the same window.
+type rollup struct {
+	window string
+	loc    *time.Location
+	// now is the window of undated items
+	now     time.Time
+	feedURL string
+	meta    feedMeta
+	groups  []*rollupGroup
+}
+
+type rollupGroup struct {
+	start time.Time
+	items []Item
+}
+
+// newRollup creates a rollup of the feed at feedURL described by meta, its
+// windows starting in RollupLocation, UTC when it is not set.
+func (h *FeedHandler) newRollup(window, feedURL string, meta feedMeta) *rollup {
+	loc := h.RollupLocation
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &rollup{window: window, loc: loc, now: h.now(), feedURL: feedURL, meta: meta}
+}
+
+// add files item under the window it was published in.
+func (r *rollup) add(item Item) {
+	t := publishedTime(item)
+	if t.IsZero() {
+		t = r.now
+	}
+	start := rollupStart(t.In(r.loc), r.window)
+	for _, g := range r.groups {
+		if g.start.Equal(start) {
+			g.items = append(g.items, item)
+			return
+		}
+	}
+	r.groups = append(r.groups, &rollupGroup{start: start, items: []Item{item}})
+}
+
+// rollupStart returns the start of the window t falls in: the hour, the
+// day or the week starting on Monday.
+func rollupStart(t time.Time, window string) time.Time {
+	switch window {
+	case rollupHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case rollupWeekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// items returns one item per window, in the order the windows were first
+// seen.
+func (r *rollup) items() []Item {
+	items := make([]Item, 0, len(r.groups))
+	for _, g := range r.groups {
+		items = append(items, r.item(g))
+	}
+	return items
+}
+
+// item combines the items of a window: its content is a table of contents
+// followed by the full text of each.
+func (r *rollup) item(g *rollupGroup) Item {
+	label := g.start.Format(rollupLabels[r.window])
+	title := label
+	if r.meta.Title != "" {
+		title = r.meta.Title + ": " + label
+	}
+	published := g.start
+	image := ""
+	for _, item := range g.items {
+		if t := publishedTime(item); t.After(published) {
+			published = t
+		}
+		image = firstNonEmpty(image, item.Image)
+	}
+	return Item{
+		Title:       title,
+		Link:        firstNonEmpty(r.meta.Link, r.feedURL),
+		GUID:        extractors.GenerateGUIDFromURL(r.feedURL + "#rollup-" + r.window + "-" + g.start.Format(time.RFC3339)),
+		Published:   published.Format(time.RFC3339),
+		Description: fmt.Sprintf("%d articles", len(g.items)),
+		Content:     rollupContent(g.items),
+		Image:       image,
+		SourceName:  g.items[0].SourceName,
+		SourceURL:   g.items[0].SourceURL,
+	}
+}
+
+// rollupContent lists the titles of items linking to their sections, then
+// the sections: each item's title, author and content, or description when
+// it has none.
+func rollupContent(items []Item) string {
+	var b strings.Builder
+	b.WriteString("<h2>Contents</h2><ol>")
+	for i, item := range items {
+		fmt.Fprintf(&b, `<li><a href="#rollup-%d">%s</a></li>`, i+1, html.EscapeString(item.Title))
+	}
+	b.WriteString("</ol>")
+	for i, item := range items {
+		fmt.Fprintf(&b, `<article id="rollup-%d"><h2><a href="%s">%s</a></h2>`, i+1, html.EscapeString(item.Link), html.EscapeString(item.Title))
+		if item.Author != "" {
+			b.WriteString("<p>" + html.EscapeString(item.Author) + "</p>")
+		}
+		if item.Content != "" {
+			b.WriteString(item.Content)
+		} else if item.Description != "" {
+			b.WriteString("<p>" + html.EscapeString(item.Description) + "</p>")
+		}
+		b.WriteString("</article>")
+	}
+	return b.String()
+}
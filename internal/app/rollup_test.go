@@ -0,0 +1,80 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollupStart(t *testing.T) {
+	at := time.Date(2024, 1, 3, 15, 42, 0, 0, time.UTC) // a Wednesday
+	cases := map[string]time.Time{
+		rollupHourly: time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC),
+		rollupDaily:  time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		rollupWeekly: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for window, want := range cases {
+		if got := rollupStart(at, window); !got.Equal(want) {
+			t.Errorf("rollupStart(%s) = %v; want %v", window, got, want)
+		}
+	}
+	// Sundays belong to the week started the Monday before
+	if got, want := rollupStart(time.Date(2024, 1, 7, 23, 0, 0, 0, time.UTC), rollupWeekly), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("rollupStart(Sunday) = %v; want %v", got, want)
+	}
+}
+
+func TestFeedRollupDaily(t *testing.T) {
+	feed := `<?xml version="1.0"?><rss version="2.0"><channel><title>Example News</title><link>https://news.example.com/</link>
+<item><title>Third</title><link>https://news.example.com/b/3</link><pubDate>Tue, 02 Jan 2024 09:00:00 +0000</pubDate></item>
+<item><title>Late second</title><link>https://news.example.com/b/2</link><pubDate>Mon, 01 Jan 2024 22:00:00 +0000</pubDate></item>
+<item><title>First</title><link>https://news.example.com/b/1</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+	h := newTestFeedHandler(func(r *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(r.URL.Path, "/b/") {
+			return respond(http.StatusOK, `<html><body><article><p>Text of `+r.URL.Path+`</p></article></body></html>`), nil
+		}
+		return respond(http.StatusOK, feed), nil
+	}, newFakeClock(), 0)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+query, nil))
+		return rec
+	}
+	if rec := get("&rollup=monthly"); rec.Code != http.StatusBadRequest {
+		t.Errorf("rollup=monthly: status %d; want 400", rec.Code)
+	}
+
+	var resp struct{ Items []Item }
+	if err := json.Unmarshal(get("&rollup=daily").Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("got %d items; want one per day", len(resp.Items))
+	}
+	day := resp.Items[1]
+	if day.Title != "Example News: 1 January 2024" || day.Description != "2 articles" || day.Published != "2024-01-01T22:00:00Z" {
+		t.Errorf("rollup = %q, %q, %q; want the day's two articles, published with the latest", day.Title, day.Description, day.Published)
+	}
+	toc := strings.Index(day.Content, `<a href="#rollup-1">Late second</a>`)
+	section := strings.Index(day.Content, `<article id="rollup-2"><h2><a href="https://news.example.com/b/1">First</a></h2>`)
+	if toc < 0 || section < toc {
+		t.Errorf("content = %q; want a table of contents followed by each article", day.Content)
+	}
+	if day.GUID == resp.Items[0].GUID {
+		t.Errorf("rollups share GUID %q", day.GUID)
+	}
+
+	// Days start in RollupLocation
+	h.RollupLocation = time.FixedZone("TRT", 3*60*60)
+	if err := json.Unmarshal(get("&rollup=daily&limit=20").Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].Description != "2 articles" || resp.Items[0].Title != "Example News: 2 January 2024" {
+		t.Errorf("items = %+v; want the late article in the next day", resp.Items)
+	}
+}
@@ -0,0 +1,211 @@
+// internal/app/saved.go
+package app
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+// maxSaveBodySize bounds POST /save bodies, which may carry a whole page.
+const maxSaveBodySize = 5 << 20
+
+// SaveRequest is the body of POST /save. It may also be sent as form fields,
+// which is what bookmarklets post.
+type SaveRequest struct {
+	URL string `json:"url"`
+	// HTML is the page as the browser rendered it; when set it is extracted
+	// instead of fetching URL, so pages behind logins can be saved
+	HTML string `json:"html,omitempty"`
+	// Title overrides the title found on the page
+	Title string `json:"title,omitempty"`
+}
+
+// handleSave serves POST /save: it extracts an article and adds it to the
+// reading list of the caller's API key. Posted pages are never archived, so
+// what a user posts cannot replace the articles others read.
+func (s *Server) handleSave(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+
+	req, apiErr := parseSaveRequest(w, r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if _, apiErr := validateTargetURL("url", req.URL); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if apiErr := s.upstreamAuth.Authorize(r, req.URL); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	item, err := s.extractSaved(req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	err = s.accounts.Update(key, func(acc *Account) error {
+		acc.save(item, s.feedHandler.now().UTC())
+		return nil
+	})
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	log.Printf("🔖 Saved %s", item.Link)
+
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// handleSaved serves GET /saved, the caller's reading list as a feed.
+func (s *Server) handleSaved(w http.ResponseWriter, r *http.Request) {
+	key, ok := s.requireSaveKey(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	format, apiErr := outputFormat(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	acc, err := s.accounts.Get(key)
+	if err != nil {
+		writeError(w, r, accountError(err))
+		return
+	}
+	items := make([]Item, len(acc.Saved))
+	for i, saved := range acc.Saved {
+		items[i] = saved.Item
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidParameter,
+				"'limit' must be a positive integer").with("parameter", "limit").with("value", limitStr))
+			return
+		}
+		items = items[:min(n, len(items))]
+	}
+
+	w.Header().Set("Content-Type", contentTypeFor(format))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	fw := newFeedWriter(format, w, nil)
+	if err := fw.Begin(feedMeta{Title: "Saved articles", Description: "Articles saved for later reading", Updated: s.feedHandler.now()}); err != nil {
+		return
+	}
+	for _, item := range items {
+		if err := fw.WriteItem(item); err != nil {
+			return
+		}
+	}
+	fw.End(feedSummary{Returned: len(items)})
+}
+
+// requireSaveKey returns the caller's API key, given as a bearer token or a
+// key parameter so bookmarklets and feed readers can pass it in the URL. It
+// writes an error response and returns false if the key is not accepted.
+func (s *Server) requireSaveKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if len(s.cfg.SaveKeys) == 0 {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "saving articles is not enabled on this server"))
+		return "", false
+	}
+	if key := requestKey(r); matchesKey(key, s.cfg.SaveKeys) {
+		return key, true
+	}
+	writeError(w, r, newAPIError(http.StatusUnauthorized, CodeUnauthorized, "missing or invalid API key"))
+	return "", false
+}
+
+// parseSaveRequest reads a JSON or form encoded save request.
+func parseSaveRequest(w http.ResponseWriter, r *http.Request) (SaveRequest, *APIError) {
+	var req SaveRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxSaveBodySize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, newAPIError(http.StatusBadRequest, CodeInvalidBody, "request body is not valid JSON").with("error", err.Error())
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return req, newAPIError(http.StatusBadRequest, CodeInvalidBody, "request body could not be parsed").with("error", err.Error())
+		}
+		req = SaveRequest{URL: r.Form.Get("url"), HTML: r.Form.Get("html"), Title: r.Form.Get("title")}
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	req.Title = strings.TrimSpace(req.Title)
+	return req, nil
+}
+
+// extractSaved extracts the article of a save request, from the posted HTML
+// when there is some and from the page itself otherwise. Both go through the
+// cleaning of feed items.
+func (s *Server) extractSaved(req SaveRequest) (Item, error) {
+	if req.HTML == "" {
+		item := s.feedHandler.extract(&gofeed.Item{Link: req.URL, Title: req.Title}, nil)
+		if strings.TrimSpace(item.Content) == "" {
+			return Item{}, extractionError(req.URL, item.err)
+		}
+		if item.Title == "" || item.SourceName == "" {
+			if meta, err := s.fetchSiteMeta(req.URL); err == nil {
+				item.Title = firstNonEmpty(item.Title, meta.Title)
+				item.SourceName = firstNonEmpty(item.SourceName, meta.SiteName)
+			}
+		}
+		item.Title = firstNonEmpty(item.Title, req.URL)
+		item.Published = s.feedHandler.now().UTC().Format(time.RFC3339)
+		return item, nil
+	}
+
+	// Site extractors understand raw HTML too; the default one is the
+	// fallback for those that only fetch pages themselves
+	input := map[string]interface{}{"html": req.HTML, "link": req.URL}
+	content, images, err := safeExtract(s.extractorReg.ForURL(req.URL), input)
+	if (err != nil || strings.TrimSpace(content) == "") && s.extractorReg.Default() != nil {
+		content, images, err = safeExtract(s.extractorReg.Default(), input)
+	}
+	if err != nil || strings.TrimSpace(content) == "" {
+		apiErr := newAPIError(http.StatusUnprocessableEntity, CodeExtractionFailed, "no article found in the posted HTML").with("url", req.URL)
+		if err != nil {
+			apiErr = apiErr.with("error", err.Error())
+		}
+		return Item{}, apiErr
+	}
+
+	meta := &SiteMeta{URL: req.URL}
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(req.HTML)); err == nil {
+		base, _ := url.Parse(req.URL)
+		describePage(meta, doc, base)
+	}
+	// The page stands in for the feed item, its article for the feed's
+	// content, which buildItem cleans without fetching anything
+	feedItem := &gofeed.Item{
+		Title:       firstNonEmpty(req.Title, meta.Title, req.URL),
+		Link:        req.URL,
+		Description: meta.Description,
+		Content:     cleanHTMLContent(content),
+	}
+	if image := firstNonEmpty(append(images, meta.Image)...); image != "" {
+		feedItem.Image = &gofeed.Image{URL: image}
+	}
+	item := s.feedHandler.buildItem(feedItem, true)
+	item.partial = false
+	item.SourceName = meta.SiteName
+	item.Published = s.feedHandler.now().UTC().Format(time.RFC3339)
+	return item, nil
+}
@@ -0,0 +1,156 @@
+// internal/app/scheduler.go
+package app
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// Weights of the flows sharing the extraction slots: a client waiting on a
+// feed gets twice the share of a background refresh, async job or warm-up.
+const (
+	foregroundWeight = 2
+	backgroundWeight = 1
+)
+
+// extractionScheduler shares the MaxExtractions extraction slots among the
+// requests rendering feeds at the same time with weighted fair queueing, so
+// a big feed cannot starve the requests arriving after it. Each request is
+// a flow whose extractions are tagged with virtual start and finish times,
+// the finish 1/weight after the start, which is the finish of the flow's
+// previous extraction or the virtual time, the start of the last extraction
+// let through, whichever is later. A freed slot goes to the waiting
+// extraction finishing earliest. A capacity of 0 means no cap.
+type extractionScheduler struct {
+	mu       sync.Mutex
+	capacity int
+	running  int
+	// vtime is the virtual time
+	vtime float64
+	seq   uint64
+	queue waiterQueue
+	// waitingFlows counts the flows with extractions in the queue
+	waitingFlows int
+}
+
+// extractionFlow is the extractions of one request.
+type extractionFlow struct {
+	weight float64
+	// finish is the finish tag of the flow's last extraction
+	finish  float64
+	waiting int
+}
+
+// extractionWaiter is an extraction waiting for a slot.
+type extractionWaiter struct {
+	start float64
+	tag   float64
+	seq   uint64
+	flow  *extractionFlow
+	ready chan struct{}
+}
+
+// ExtractionStats is the state of the extraction slots, reported by /stats.
+type ExtractionStats struct {
+	// Slots is MaxExtractions, 0 for no cap
+	Slots   int `json:"slots"`
+	Running int `json:"running"`
+	// Queued is the number of extractions waiting for a slot, from
+	// WaitingRequests requests
+	Queued          int `json:"queued"`
+	WaitingRequests int `json:"waiting_requests"`
+}
+
+func newExtractionScheduler(capacity int) *extractionScheduler {
+	return &extractionScheduler{capacity: capacity}
+}
+
+// newFlow returns a flow for the extractions of a request.
+func (s *extractionScheduler) newFlow(weight float64) *extractionFlow {
+	return &extractionFlow{weight: weight}
+}
+
+// acquire waits for a slot for an extraction of flow and returns the
+// function releasing it.
+func (s *extractionScheduler) acquire(flow *extractionFlow) func() {
+	s.mu.Lock()
+	start := max(flow.finish, s.vtime)
+	flow.finish = start + 1/flow.weight
+	if s.capacity <= 0 || (s.running < s.capacity && s.queue.Len() == 0) {
+		s.running++
+		s.vtime = start
+		s.mu.Unlock()
+		return s.release
+	}
+	s.seq++
+	w := &extractionWaiter{start: start, tag: flow.finish, seq: s.seq, flow: flow, ready: make(chan struct{})}
+	heap.Push(&s.queue, w)
+	if flow.waiting++; flow.waiting == 1 {
+		s.waitingFlows++
+	}
+	s.mu.Unlock()
+
+	<-w.ready
+	return s.release
+}
+
+// release frees a slot, handing it to the queued extraction with the
+// earliest finish tag.
+func (s *extractionScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queue.Len() == 0 {
+		s.running--
+		return
+	}
+	w := heap.Pop(&s.queue).(*extractionWaiter)
+	s.vtime = max(s.vtime, w.start)
+	if w.flow.waiting--; w.flow.waiting == 0 {
+		s.waitingFlows--
+	}
+	close(w.ready)
+}
+
+// stats returns the state of the slots.
+func (s *extractionScheduler) stats() ExtractionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ExtractionStats{Slots: s.capacity, Running: s.running, Queued: s.queue.Len(), WaitingRequests: s.waitingFlows}
+}
+
+// waiterQueue is a heap of waiters by finish tag, then arrival.
+type waiterQueue []*extractionWaiter
+
+func (q waiterQueue) Len() int { return len(q) }
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].tag != q[j].tag {
+		return q[i].tag < q[j].tag
+	}
+	return q[i].seq < q[j].seq
+}
+func (q waiterQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *waiterQueue) Push(x any)   { *q = append(*q, x.(*extractionWaiter)) }
+func (q *waiterQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return w
+}
+
+// extractions returns the scheduler of the handler's extraction slots.
+func (h *FeedHandler) extractions() *extractionScheduler {
+	h.schedulerOnce.Do(func() { h.scheduler = newExtractionScheduler(h.MaxExtractions) })
+	return h.scheduler
+}
+
+// acquireExtraction waits for one of the MaxExtractions extraction slots
+// shared by all requests, in flow's turn, and returns the function
+// releasing it. A nil flow stands for a one-off extraction a client waits
+// on.
+func (h *FeedHandler) acquireExtraction(flow *extractionFlow) func() {
+	s := h.extractions()
+	if flow == nil {
+		flow = s.newFlow(foregroundWeight)
+	}
+	return s.acquire(flow)
+}
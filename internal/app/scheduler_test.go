@@ -0,0 +1,78 @@
+package app
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// queueOrder holds the only slot of a scheduler while the given
+// extractions queue up in order, then releases it and returns the order
+// the extractions got the slot in.
+func queueOrder(t *testing.T, s *extractionScheduler, names string, flows map[byte]*extractionFlow) string {
+	t.Helper()
+	release := s.acquire(s.newFlow(foregroundWeight))
+
+	var mu sync.Mutex
+	var order strings.Builder
+	var wg sync.WaitGroup
+	for i := range len(names) {
+		name := names[i]
+		wg.Go(func() {
+			done := s.acquire(flows[name])
+			mu.Lock()
+			order.WriteByte(name)
+			mu.Unlock()
+			done()
+		})
+		// Queue the extractions one at a time so they arrive in order
+		deadline := time.Now().Add(time.Second)
+		for s.stats().Queued < i+1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("extraction %d did not queue", i)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if st := s.stats(); st.Running != 1 || st.WaitingRequests != len(flows) {
+		t.Errorf("stats = %+v; want 1 running and %d waiting requests", st, len(flows))
+	}
+	release()
+	wg.Wait()
+	if st := s.stats(); st.Running != 0 || st.Queued != 0 || st.WaitingRequests != 0 {
+		t.Errorf("stats after = %+v; want all idle", st)
+	}
+	return order.String()
+}
+
+func TestExtractionSchedulerFairness(t *testing.T) {
+	// A request arriving after a big one gets every other slot
+	s := newExtractionScheduler(1)
+	flows := map[byte]*extractionFlow{'A': s.newFlow(foregroundWeight), 'B': s.newFlow(foregroundWeight)}
+	if got := queueOrder(t, s, "AAAABB", flows); got != "ABABAA" {
+		t.Errorf("order = %s; want ABABAA", got)
+	}
+
+	// A background refresh gets half the share of a client's request
+	s = newExtractionScheduler(1)
+	flows = map[byte]*extractionFlow{'F': s.newFlow(foregroundWeight), 'b': s.newFlow(backgroundWeight)}
+	if got := queueOrder(t, s, "bbbFFFF", flows); got != "FbFFbFb" {
+		t.Errorf("order = %s; want FbFFbFb", got)
+	}
+}
+
+func TestExtractionSchedulerUncapped(t *testing.T) {
+	s := newExtractionScheduler(0)
+	flow := s.newFlow(foregroundWeight)
+	var releases []func()
+	for range 5 {
+		releases = append(releases, s.acquire(flow))
+	}
+	if st := s.stats(); st.Running != 5 || st.Queued != 0 {
+		t.Errorf("stats = %+v; want 5 running", st)
+	}
+	for _, release := range releases {
+		release()
+	}
+}
@@ -0,0 +1,45 @@
+// internal/app/schema.go
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON API's response schema: the
+// fields of feed responses and items and what they mean. It is bumped,
+// with the older versions still served to clients asking for them, when a
+// change would break clients built on the current output.
+const SchemaVersion = 1
+
+// schemaVersions are the response schema versions clients may ask for.
+var schemaVersions = map[int]bool{1: true}
+
+// parseSchemaVersion returns the response schema version r asks for with
+// the v parameter, or the Accept-Version header without one. It is 0, for
+// SchemaVersion, when r asks for none or the current one, so such requests
+// share cache entries.
+func parseSchemaVersion(r *http.Request) (int, *APIError) {
+	raw := strings.TrimSpace(r.URL.Query().Get("v"))
+	fromHeader := raw == ""
+	if fromHeader {
+		raw = strings.TrimSpace(r.Header.Get("Accept-Version"))
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(raw), "v"))
+	if err != nil || !schemaVersions[version] {
+		msg := fmt.Sprintf("unsupported schema version '%s' (use %d)", raw, SchemaVersion)
+		if fromHeader {
+			return 0, newAPIError(http.StatusNotAcceptable, CodeNotAcceptable, msg).with("accept_version", raw)
+		}
+		return 0, newAPIError(http.StatusBadRequest, CodeInvalidParameter, msg).with("parameter", "v").with("value", raw)
+	}
+	if version == SchemaVersion {
+		return 0, nil
+	}
+	return version, nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFeedSchemaVersion(t *testing.T) {
+	doer := func(*http.Request) (*http.Response, error) { return respond(http.StatusOK, testFeed), nil }
+	h := newTestFeedHandler(doer, newFakeClock(), 0)
+
+	get := func(query, acceptVersion string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL)+query, nil)
+		if acceptVersion != "" {
+			req.Header.Set("Accept-Version", acceptVersion)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		query, acceptVersion string
+		status               int
+	}{
+		{"", "", http.StatusOK},
+		{"&v=1", "", http.StatusOK},
+		{"", "1", http.StatusOK},
+		{"&v=v1", "2", http.StatusOK},
+		{"&v=2", "", http.StatusBadRequest},
+		{"&v=latest", "", http.StatusBadRequest},
+		{"", "2", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		rec := get(tt.query, tt.acceptVersion)
+		if rec.Code != tt.status {
+			t.Errorf("%q, Accept-Version %q: status %d; want %d", tt.query, tt.acceptVersion, rec.Code, tt.status)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			SchemaVersion int `json:"schema_version"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.SchemaVersion != SchemaVersion {
+			t.Errorf("%q: schema_version %d (%v); want %d", tt.query, resp.SchemaVersion, err, SchemaVersion)
+		}
+	}
+
+	// Asking for the current version explicitly shares the cache entry
+	if rec := get("&v=1", ""); rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("v=1 X-Cache = %q; want HIT", rec.Header().Get("X-Cache"))
+	}
+}
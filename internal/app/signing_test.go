@@ -0,0 +1,82 @@
+package app
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignerMiddleware(t *testing.T) {
+	seed := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+	signer, err := NewSigner(seed, "test-key", newFakeClock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := signer.Middleware(Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(strings.Repeat(`{"items":[]}`, 200)))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/feed?url=https%3A%2F%2Fnews.test%2Frss", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	// The digest covers the compressed bytes sent
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q; want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	digest := sha256.Sum256(rec.Body.Bytes())
+	if want := "sha-256=:" + base64.StdEncoding.EncodeToString(digest[:]) + ":"; rec.Header().Get("Content-Digest") != want {
+		t.Errorf("Content-Digest = %q; want %q", rec.Header().Get("Content-Digest"), want)
+	}
+
+	input := strings.TrimPrefix(rec.Header().Get("Signature-Input"), "sig1=")
+	wantInput := `("@status" "content-type" "content-encoding" "content-digest" "@path";req "@query";req);created=1704164645;keyid="test-key";alg="ed25519"`
+	if input != wantInput {
+		t.Fatalf("Signature-Input = %q; want %q", input, wantInput)
+	}
+	base := strings.Join([]string{
+		`"@status": 200`,
+		`"content-type": application/json`,
+		`"content-encoding": gzip`,
+		`"content-digest": ` + rec.Header().Get("Content-Digest"),
+		`"@path";req: /feed`,
+		`"@query";req: ?url=https%3A%2F%2Fnews.test%2Frss`,
+		`"@signature-params": ` + input,
+	}, "\n")
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(rec.Header().Get("Signature"), "sig1=:"), ":"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(signer.PublicKey(), []byte(base), sig) {
+		t.Error("signature does not verify")
+	}
+
+	// Uncompressed responses do not cover the encoding they lack
+	req.Header.Del("Accept-Encoding")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if input := rec.Header().Get("Signature-Input"); strings.Contains(input, "content-encoding") {
+		t.Errorf("Signature-Input = %q covers a missing Content-Encoding", input)
+	}
+
+	// Other routes are left alone
+	rec = httptest.NewRecorder()
+	signer.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if rec.Header().Get("Signature") != "" {
+		t.Error("signed a response of /stats")
+	}
+}
+
+func TestNewSignerInvalidKey(t *testing.T) {
+	for _, seed := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewSigner(seed, "", nil); err == nil {
+			t.Errorf("NewSigner(%q) succeeded", seed)
+		}
+	}
+}
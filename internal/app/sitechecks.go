@@ -0,0 +1,140 @@
+// internal/app/sitechecks.go
+package app
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSiteCheckWords is the word count a sample article must reach when
+// its check sets none.
+const defaultSiteCheckWords = 150
+
+// SiteCheck lists sample articles of a site that extraction must keep
+// handling, and how many words each must yield.
+type SiteCheck struct {
+	Domain   string   `json:"domain"`
+	URLs     []string `json:"urls"`
+	MinWords int      `json:"min_words,omitempty"`
+}
+
+// SiteCheckResult is the outcome of extracting one sample article.
+type SiteCheckResult struct {
+	Domain   string `json:"domain"`
+	URL      string `json:"url"`
+	Words    int    `json:"words"`
+	MinWords int    `json:"min_words"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SiteCheckReport is the outcome of the latest run of the site checks.
+type SiteCheckReport struct {
+	RanAt   time.Time         `json:"ran_at"`
+	Failed  int               `json:"failed"`
+	Results []SiteCheckResult `json:"results"`
+}
+
+// siteChecker runs the configured site checks and keeps their last report.
+type siteChecker struct {
+	mu     sync.Mutex
+	last   *SiteCheckReport
+	checks []SiteCheck
+}
+
+// checkSite extracts each sample article of check.
+func (h *FeedHandler) checkSite(check SiteCheck) []SiteCheckResult {
+	minWords := check.MinWords
+	if minWords <= 0 {
+		minWords = defaultSiteCheckWords
+	}
+	results := make([]SiteCheckResult, 0, len(check.URLs))
+	for _, link := range check.URLs {
+		result := SiteCheckResult{Domain: check.Domain, URL: link, MinWords: minWords}
+		content, _, err := safeExtract(h.Registry.ForURL(link), link)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Words = len(strings.Fields(cleanHTMLTags(h.cleanArticle(link, cleanHTMLContent(content)))))
+			result.OK = result.Words >= minWords
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runSiteChecks checks every configured site, alerting for each site with
+// a sample article that failed, and returns the report.
+func (s *Server) runSiteChecks() SiteCheckReport {
+	report := SiteCheckReport{RanAt: time.Now().UTC(), Results: []SiteCheckResult{}}
+	for _, check := range s.siteChecks.checks {
+		results := s.feedHandler.checkSite(check)
+		report.Results = append(report.Results, results...)
+
+		var failed []string
+		for _, r := range results {
+			if !r.OK {
+				failed = append(failed, r.URL)
+			}
+		}
+		report.Failed += len(failed)
+		if len(failed) > 0 {
+			s.alerts.Fire(Alert{
+				Kind:    AlertSiteCheck,
+				Subject: check.Domain,
+				Message: fmt.Sprintf("%d of %d sample articles failed extraction", len(failed), len(results)),
+				Details: map[string]any{"failed_urls": failed, "results": results},
+			})
+		}
+	}
+	log.Printf("🩺 Site checks done: %d of %d sample articles failed", report.Failed, len(report.Results))
+
+	s.siteChecks.mu.Lock()
+	s.siteChecks.last = &report
+	s.siteChecks.mu.Unlock()
+	return report
+}
+
+// siteCheckLoop runs the site checks every interval. In distributed mode
+// only the instance holding the lease runs them.
+func (s *Server) siteCheckLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if s.cluster != nil {
+			release, ok := s.cluster.Lease("site-checks", interval/2)
+			if !ok {
+				continue
+			}
+			s.runSiteChecks()
+			release()
+			continue
+		}
+		s.runSiteChecks()
+	}
+}
+
+// handleSiteChecks reports the latest site check results; POST runs the
+// checks first.
+func (s *Server) handleSiteChecks(w http.ResponseWriter, r *http.Request) {
+	if len(s.siteChecks.checks) == 0 {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no site checks are configured"))
+		return
+	}
+	if r.Method == http.MethodPost {
+		writeJSON(w, http.StatusOK, s.runSiteChecks())
+		return
+	}
+	s.siteChecks.mu.Lock()
+	last := s.siteChecks.last
+	s.siteChecks.mu.Unlock()
+	if last == nil {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "the site checks have not run yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, last)
+}
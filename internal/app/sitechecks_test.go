@@ -0,0 +1,74 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+func TestSiteChecksAlertOnBrokenSites(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []Alert
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		mu.Lock()
+		alerts = append(alerts, a)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	registry := extractors.NewRegistry()
+	registry.RegisterDefault(extractorFunc(func(input any) (string, []string, error) {
+		switch link := input.(string); {
+		case strings.HasSuffix(link, "/long"):
+			return "<p>" + strings.Repeat("kelime ", 300) + "</p>", nil, nil
+		case strings.HasSuffix(link, "/short"):
+			return "<p>Abone olun</p>", nil, nil
+		default:
+			return "", nil, errors.New("unexpected HTTP status 404")
+		}
+	}))
+	s := &Server{
+		feedHandler: NewFeedHandler(NewCache(time.Minute, 0), nil, registry, filters.NewFilterRegistry(), NewArchive(0), nil),
+		alerts:      NewAlerter(time.Hour, &WebhookNotifier{URL: webhook.URL}),
+		siteChecks: siteChecker{checks: []SiteCheck{
+			{Domain: "good.example.com", URLs: []string{"https://good.example.com/long"}},
+			{Domain: "bad.example.com", URLs: []string{"https://bad.example.com/long", "https://bad.example.com/short", "https://bad.example.com/gone"}, MinWords: 50},
+		}},
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleSiteChecks(rec, httptest.NewRequest(http.MethodGet, "/admin/site-checks", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("report before the first run: %d", rec.Code)
+	}
+
+	report := s.runSiteChecks()
+	if len(report.Results) != 4 || report.Failed != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if r := report.Results[3]; r.OK || r.Error == "" {
+		t.Errorf("failed extraction result = %+v", r)
+	}
+	mu.Lock()
+	if len(alerts) != 1 || alerts[0].Kind != "site_check" || alerts[0].Subject != "bad.example.com" {
+		t.Errorf("alerts = %+v", alerts)
+	}
+	mu.Unlock()
+
+	rec = httptest.NewRecorder()
+	s.handleSiteChecks(rec, httptest.NewRequest(http.MethodGet, "/admin/site-checks", nil))
+	var got SiteCheckReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Failed != 2 {
+		t.Errorf("served report %s (%v)", rec.Body, err)
+	}
+}
@@ -0,0 +1,600 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/sentiment"
+	"gofull/internal/storage"
+)
+
+// siteHarness serves recorded pages from testdata/sites/<host>/ for any
+// host, so feeds and extractors run end to end without the internet. A page
+// for https://www.example.com/a/b is read from testdata/sites/example.com/a/b
+// or, failing that, from the same path with a .xml (feeds) or .html suffix,
+// or from index.html in the directory.
+type siteHarness struct {
+	server  *httptest.Server
+	client  *http.Client
+	handler *FeedHandler
+
+	mu   sync.Mutex
+	hits map[string]int // "host/path" -> requests served
+}
+
+func newSiteHarness(t *testing.T) *siteHarness {
+	t.Helper()
+	h := &siteHarness{hits: make(map[string]int)}
+	h.server = httptest.NewServer(http.HandlerFunc(h.serve))
+	t.Cleanup(h.server.Close)
+
+	// Route every request to the fixture server, keeping the original host
+	target, _ := url.Parse(h.server.URL)
+	h.client = &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Host = req.URL.Host
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+
+	h.handler = NewFeedHandler(NewCache(time.Minute, 0), h.client, newExtractorRegistry(h.client, ""), newFilterRegistry(), NewArchive(0), nil)
+	return h
+}
+
+func (h *siteHarness) serve(w http.ResponseWriter, r *http.Request) {
+	host := strings.TrimPrefix(strings.ToLower(r.Host), "www.")
+	base := filepath.Join("testdata", "sites", host, filepath.FromSlash(r.URL.Path))
+
+	for _, name := range []string{base, base + ".xml", base + ".html", filepath.Join(base, "index.html")} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		h.mu.Lock()
+		h.hits[host+r.URL.Path]++
+		h.mu.Unlock()
+
+		if strings.HasSuffix(name, ".xml") {
+			w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write(data)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// Hits returns how often host/path was served.
+func (h *siteHarness) Hits(hostPath string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hits[hostPath]
+}
+
+// getFeed requests /feed for feedURL and decodes the JSON response.
+func (h *siteHarness) getFeed(t *testing.T, feedURL string) (*httptest.ResponseRecorder, feedResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?url="+url.QueryEscape(feedURL), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /feed?url=%s: %d %s", feedURL, rec.Code, rec.Body.String())
+	}
+	var resp feedResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v\n%s", err, rec.Body.String())
+	}
+	return rec, resp
+}
+
+// feedResponse mirrors the JSON output of /feed.
+type feedResponse struct {
+	FeedTitle string `json:"feed_title"`
+	Items     []Item `json:"items"`
+	Returned  int    `json:"items_returned"`
+	Skipped   int    `json:"items_skipped"`
+	Reused    int    `json:"items_reused"`
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestSiteDunyaFeed(t *testing.T) {
+	h := newSiteHarness(t)
+	_, resp := h.getFeed(t, "https://www.dunya.com/rss")
+
+	if resp.FeedTitle != "Dünya Gazetesi" {
+		t.Errorf("feed_title = %q", resp.FeedTitle)
+	}
+	// The /spor/ item is blocked by the dunya.com filter
+	if resp.Returned != 2 || resp.Skipped != 1 {
+		t.Fatalf("returned %d, skipped %d; want 2 and 1", resp.Returned, resp.Skipped)
+	}
+	if h.Hits("dunya.com/spor/futbol/derbide-kazanan-cikmadi-haberi-700102") != 0 {
+		t.Error("filtered article was fetched")
+	}
+
+	borsa := resp.Items[0]
+	for _, want := range []string{"BIST 100 endeksi güne", "Bankacılık endeksi", "enflasyon verilerini"} {
+		if !strings.Contains(borsa.Content, want) {
+			t.Errorf("content lacks %q: %s", want, borsa.Content)
+		}
+	}
+	for _, unwanted := range []string{"Reklam", "googletag", "Paylaş", "Dünya Haber Merkezi", "İlgili haber", "Ana Sayfa"} {
+		if strings.Contains(borsa.Content, unwanted) {
+			t.Errorf("content still contains %q: %s", unwanted, borsa.Content)
+		}
+	}
+	if borsa.Image != "https://i.dunya.com/storage/files/images/2024/01/02/borsa-700101.jpg" {
+		t.Errorf("image = %q; want the og:image", borsa.Image)
+	}
+	if borsa.Category != "business" || borsa.Description != "BIST 100 endeksi güne yükselişle başladı." {
+		t.Errorf("category %q, description %q", borsa.Category, borsa.Description)
+	}
+
+	// Without og:image the first content image is used, skipping icons
+	yatirim := resp.Items[1]
+	if !strings.HasSuffix(yatirim.Image, "/storage/files/images/2024/01/02/fabrika-700103.jpg") {
+		t.Errorf("image = %q; want the content image", yatirim.Image)
+	}
+	want := []ItemImage{{URL: yatirim.Image, Caption: "Yeni fabrikanın kurulacağı alan", Credit: "AA"}}
+	if !slices.Equal(yatirim.Images, want) {
+		t.Errorf("images = %+v; want %+v", yatirim.Images, want)
+	}
+}
+
+func TestSiteDefaultExtractorUsesEnclosureImage(t *testing.T) {
+	h := newSiteHarness(t)
+	_, resp := h.getFeed(t, "https://haber.test/rss")
+
+	if resp.Returned != 1 {
+		t.Fatalf("returned %d items; want 1", resp.Returned)
+	}
+	item := resp.Items[0]
+	if !strings.Contains(item.Content, "yeni yasama yılına düzenlenen törenle") {
+		t.Errorf("content was not extracted: %s", item.Content)
+	}
+	if item.Image != "https://cdn.haber.test/images/meclis.jpg" {
+		t.Errorf("image = %q; want the enclosure image", item.Image)
+	}
+	if item.Category != "turkiye" {
+		t.Errorf("category = %q", item.Category)
+	}
+}
+
+func TestSiteFeedIsCached(t *testing.T) {
+	h := newSiteHarness(t)
+	const article = "dunya.com/ekonomi/piyasalar/borsa-gune-yukselisle-basladi-haberi-700101"
+
+	rec, _ := h.getFeed(t, "https://www.dunya.com/rss")
+	if rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("first request X-Cache = %s", rec.Header().Get("X-Cache"))
+	}
+	rec, resp := h.getFeed(t, "https://www.dunya.com/rss")
+	if rec.Header().Get("X-Cache") != "HIT" || resp.Returned != 2 {
+		t.Errorf("second request X-Cache = %s, returned %d", rec.Header().Get("X-Cache"), resp.Returned)
+	}
+	if h.Hits("dunya.com/rss") != 1 || h.Hits(article) != 1 {
+		t.Errorf("feed fetched %d times, article %d times; want once each", h.Hits("dunya.com/rss"), h.Hits(article))
+	}
+
+	// A different limit renders again, but archived articles are not re-extracted
+	rec = httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?limit=5&url="+url.QueryEscape("https://www.dunya.com/rss"), nil))
+	if !strings.Contains(rec.Body.String(), `"items_reused": 2`) || h.Hits(article) != 1 {
+		t.Errorf("archived items were re-extracted (article hits %d): %s", h.Hits(article), rec.Body.String())
+	}
+}
+
+func TestSiteT24UsesFeedItems(t *testing.T) {
+	h := newSiteHarness(t)
+	_, resp := h.getFeed(t, "https://t24.com.tr/rss")
+
+	if resp.Returned != 2 {
+		t.Fatalf("returned %d items; want 2", resp.Returned)
+	}
+	faiz := resp.Items[0]
+	for _, want := range []string{"<p>Türkiye Cumhuriyet Merkez Bankası", "<h3>Karar metninden</h3>", "sürdüğünü &amp; sıkı"} {
+		if !strings.Contains(faiz.Content, want) {
+			t.Errorf("content lacks %q: %s", want, faiz.Content)
+		}
+	}
+	if strings.Contains(faiz.Content, "# ") {
+		t.Errorf("content is markdown: %s", faiz.Content)
+	}
+	// The page has no usable image, so the enclosure is used
+	if faiz.Image != "https://cdn.t24.com.tr/uploads/images/2024/10/03/faiz.jpg" {
+		t.Errorf("image = %q; want the enclosure image", faiz.Image)
+	}
+
+	// A missing article falls back to the item description and enclosure
+	kayip := resp.Items[1]
+	if !strings.Contains(kayip.Content, "Sayfa bulunamadığında açıklama kullanılır.") {
+		t.Errorf("content = %q; want the item description", kayip.Content)
+	}
+	if kayip.Image != "https://cdn.t24.com.tr/uploads/images/2024/10/03/kayip.jpg" {
+		t.Errorf("image = %q; want the enclosure image", kayip.Image)
+	}
+}
+
+func TestSiteItemProvenance(t *testing.T) {
+	h := newSiteHarness(t)
+	_, resp := h.getFeed(t, "https://haber.test/rss")
+
+	item := resp.Items[0]
+	if item.SourceName != "Haber Test" || item.SourceURL != "https://haber.test/rss" {
+		t.Errorf("source = %q <%s>", item.SourceName, item.SourceURL)
+	}
+	if item.Author != "Ayşe Yılmaz" || !slices.Equal(item.Categories, []string{"Politika", "Meclis"}) {
+		t.Errorf("author %q, categories %q", item.Author, item.Categories)
+	}
+
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?format=rss&url="+url.QueryEscape("https://haber.test/rss"), nil))
+	for _, want := range []string{
+		"<dc:creator>Ayşe Yılmaz</dc:creator>",
+		"<category>turkiye</category>",
+		"<category>Politika</category>",
+		"<category>Meclis</category>",
+		`<source url="https://haber.test/rss">Haber Test</source>`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("RSS lacks %q:\n%s", want, rec.Body.String())
+		}
+	}
+}
+
+func TestSiteCommentsURL(t *testing.T) {
+	h := newSiteHarness(t)
+	_, resp := h.getFeed(t, "https://haber.test/rss")
+	if got := resp.Items[0].CommentsURL; got != "https://haber.test/gundem/meclis-yeni-yasama-yilina-basladi/yorumlar" {
+		t.Errorf("comments_url = %q; want the feed's comments link", got)
+	}
+
+	// Article pages are only inspected when detection is enabled
+	_, resp = h.getFeed(t, "https://www.dunya.com/rss")
+	if got := resp.Items[1].CommentsURL; got != "" {
+		t.Errorf("comments_url = %q without detection", got)
+	}
+
+	h = newSiteHarness(t)
+	h.handler.DetectComments = true
+	_, resp = h.getFeed(t, "https://www.dunya.com/rss")
+	if got := resp.Items[0].CommentsURL; got != "" {
+		t.Errorf("comments_url = %q for a page without comments", got)
+	}
+	want := "https://www.dunya.com/sirketler/otomotiv-devinden-yeni-yatirim-haberi-700103#disqus_thread"
+	if got := resp.Items[1].CommentsURL; got != want {
+		t.Errorf("comments_url = %q; want %q", got, want)
+	}
+}
+
+func TestSiteSentimentFilter(t *testing.T) {
+	h := newSiteHarness(t)
+
+	rec := httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?sentiment=negative&url="+url.QueryEscape("https://www.dunya.com/rss"), nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("sentiment filter without scoring: %d; want 400", rec.Code)
+	}
+
+	h.handler.Sentiment = sentiment.NewLexicon()
+	_, resp := h.getFeed(t, "https://www.dunya.com/rss")
+	if s := resp.Items[0].Sentiment; s == nil || s.Label != sentiment.Positive {
+		t.Errorf("sentiment = %+v; want positive", s)
+	}
+
+	// Archived items are filtered too; the rest count as skipped
+	rec = httptest.NewRecorder()
+	h.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/feed?sentiment=positive&url="+url.QueryEscape("https://www.dunya.com/rss"), nil))
+	resp = feedResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v\n%s", err, rec.Body.String())
+	}
+	if resp.Returned != 1 || resp.Skipped != 2 || resp.Items[0].Title != "Borsa güne yükselişle başladı" {
+		t.Errorf("returned %d, skipped %d: %+v", resp.Returned, resp.Skipped, resp.Items)
+	}
+}
+
+func TestSiteTextRules(t *testing.T) {
+	h := newSiteHarness(t)
+	rules, err := extractors.NewTextRewrites([]extractors.TextRule{
+		{Domain: "haber.test", Field: extractors.FieldContent, Find: `\(?Haber Merkezi\)?`, Replace: "Brief.tr", Regexp: true},
+		{Domain: "haber.test", Field: extractors.FieldTitle, Find: "Meclis", Replace: "TBMM"},
+		{Domain: "dunya.com", Find: "Brief", Replace: "never"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.handler.TextRules = rules
+	_, resp := h.getFeed(t, "https://haber.test/rss")
+
+	item := resp.Items[0]
+	if item.Title != "TBMM yeni yasama yılına başladı" {
+		t.Errorf("title = %q", item.Title)
+	}
+	// The credit is replaced before the "(Haber Merkezi)" suffix is stripped
+	if !strings.Contains(item.Content, "yargı paketleri yer alıyor. Brief.tr") {
+		t.Errorf("content was not rewritten: %s", item.Content)
+	}
+	// Title rules leave the content alone
+	if !strings.Contains(item.Content, "Açılış oturumunda Meclis Başkanı") {
+		t.Errorf("content rewritten by a title rule: %s", item.Content)
+	}
+}
+
+func TestSiteReaderVariant(t *testing.T) {
+	h := newSiteHarness(t)
+	h.handler.Variants = extractors.NewVariants(extractors.DefaultVariantPatterns)
+	_, resp := h.getFeed(t, "https://kisa.test/rss")
+
+	content := resp.Items[0].Content
+	if !strings.Contains(content, "rıhtım 600 metre uzatılacak") || strings.Contains(content, "Haberin devamı uygulamada") {
+		t.Errorf("content was not taken from the AMP page: %s", content)
+	}
+	if h.Hits("kisa.test/amp/haber/liman-genisletme-projesi-onaylandi") != 1 {
+		t.Error("AMP variant was not fetched")
+	}
+}
+
+func TestSiteWaybackFallback(t *testing.T) {
+	h := newSiteHarness(t)
+	h.handler.Wayback = true
+	_, resp := h.getFeed(t, "https://gone.test/rss")
+
+	item := resp.Items[0]
+	if !strings.Contains(item.Content, "arşivdeki kopyasından okunuyor") {
+		t.Errorf("content was not taken from the snapshot: %q", item.Content)
+	}
+	if item.ArchivedURL != "http://web.archive.org/web/20240101120000id_/gone.test/haber/kaldirilan-haber" || item.ArchivedAt != "2024-01-01T12:00:00Z" {
+		t.Errorf("archived_url %q, archived_at %q", item.ArchivedURL, item.ArchivedAt)
+	}
+	if h.Hits("archive.org/wayback/available") != 1 {
+		t.Error("availability API was not queried")
+	}
+
+	// Live articles never hit the Wayback Machine
+	h.getFeed(t, "https://haber.test/rss")
+	if h.Hits("archive.org/wayback/available") != 1 {
+		t.Error("availability API was queried for a live article")
+	}
+}
+
+func TestSiteMeta(t *testing.T) {
+	h := newSiteHarness(t)
+	s := &Server{feedHandler: h.handler, metaCache: NewCache(metaCacheTTL, 0)}
+
+	get := func() (*httptest.ResponseRecorder, SiteMeta) {
+		rec := httptest.NewRecorder()
+		s.handleMeta(rec, httptest.NewRequest(http.MethodGet, "/meta?url="+url.QueryEscape("https://marka.test/rss"), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var meta SiteMeta
+		if err := json.Unmarshal(rec.Body.Bytes(), &meta); err != nil {
+			t.Fatal(err)
+		}
+		return rec, meta
+	}
+
+	rec, meta := get()
+	if rec.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("X-Cache %q on first request", rec.Header().Get("X-Cache"))
+	}
+	if meta.URL != "https://marka.test/" || meta.Title != "Marka Haber" || meta.SiteName != "Marka Haber" {
+		t.Errorf("url %q, title %q, site name %q", meta.URL, meta.Title, meta.SiteName)
+	}
+	if meta.Favicon != "https://marka.test/static/favicon-32.png" || meta.Image != "https://marka.test/static/marka-paylasim.png" {
+		t.Errorf("favicon %q, image %q", meta.Favicon, meta.Image)
+	}
+	var feeds []string
+	for _, f := range meta.Feeds {
+		feeds = append(feeds, f.URL)
+	}
+	if want := []string{"https://marka.test/rss", "https://marka.test/rss/ekonomi"}; !slices.Equal(feeds, want) {
+		t.Errorf("feeds %v, want %v", feeds, want)
+	}
+
+	rec, _ = get()
+	if rec.Header().Get("X-Cache") != "HIT" || h.Hits("marka.test/") != 1 {
+		t.Errorf("second request was not cached: X-Cache %q, %d page fetches", rec.Header().Get("X-Cache"), h.Hits("marka.test/"))
+	}
+}
+
+func TestSiteReaderPage(t *testing.T) {
+	h := newSiteHarness(t)
+	h.getFeed(t, "https://haber.test/rss")
+	s := &Server{
+		feedHandler: h.handler,
+		cache:       NewCache(time.Minute, 0),
+		metaCache:   NewCache(metaCacheTTL, 0),
+		archive:     h.handler.Archive,
+		filterReg:   h.handler.FilterReg,
+	}
+
+	read := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleRead(rec, httptest.NewRequest(http.MethodGet, "/read?url="+url.QueryEscape("https://haber.test/gundem/meclis-yeni-yasama-yilina-basladi"), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		return rec
+	}
+
+	rec := read()
+	page := rec.Body.String()
+	for _, want := range []string{
+		"<title>Meclis yeni yasama yılına başladı</title>",
+		"Haber Test · Ayşe Yılmaz · 1 October 2024 11:00 ·",
+		`<a href="https://haber.test/gundem/meclis-yeni-yasama-yilina-basladi">Original article</a>`,
+		"<style>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script") || strings.Contains(page, `rel="stylesheet"`) {
+		t.Error("page loads external assets")
+	}
+	// The article was archived by the feed, so it is not fetched again
+	if h.Hits("haber.test/gundem/meclis-yeni-yasama-yilina-basladi") != 1 {
+		t.Errorf("article fetched %d times", h.Hits("haber.test/gundem/meclis-yeni-yasama-yilina-basladi"))
+	}
+	if rec := read(); rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("X-Cache %q on second request", rec.Header().Get("X-Cache"))
+	}
+}
+
+func TestSiteSaveForLater(t *testing.T) {
+	h := newSiteHarness(t)
+	rules, err := extractors.NewTextRewrites([]extractors.TextRule{
+		{Domain: "kapali.test", Field: extractors.FieldContent, Find: "Faiz kararının", Replace: "PPK kararının"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.handler.TextRules = rules
+	s := &Server{
+		cfg:          &Config{SaveKeys: []string{"anahtar-1", "anahtar-2"}},
+		feedHandler:  h.handler,
+		metaCache:    NewCache(metaCacheTTL, 0),
+		archive:      h.handler.Archive,
+		accounts:     NewAccounts(storage.NewMemoryStore()),
+		extractorReg: h.handler.Registry,
+	}
+	archived := Item{Title: "Arşivdeki analiz", Link: "https://kapali.test/analiz/faiz", GUID: extractors.GenerateGUIDFromURL("https://kapali.test/analiz/faiz"), Content: "<p>Arşivlenen metin.</p>"}
+	s.archive.Put("https://kapali.test/rss", archived)
+
+	save := func(key string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/save?key="+key, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		s.handleSave(rec, req)
+		return rec
+	}
+
+	// A page fetched by the proxy
+	rec := save("anahtar-1", url.Values{"url": {"https://haber.test/gundem/meclis-yeni-yasama-yilina-basladi"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	// A page posted by a bookmarklet is not fetched, and is rewritten and
+	// cleaned like fetched ones
+	posted := `<html><head><title>Abonelere özel analiz</title><meta property="og:site_name" content="Kapalı Gazete"></head>
+<body><article><p>Faiz kararının ardından piyasalarda beklentiler yeniden şekillendi.</p></article></body></html>`
+	rec = save("anahtar-1", url.Values{"url": {"https://kapali.test/analiz/faiz"}, "html": {posted}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var item Item
+	json.Unmarshal(rec.Body.Bytes(), &item)
+	if item.Title != "Abonelere özel analiz" || item.SourceName != "Kapalı Gazete" || !strings.Contains(item.Content, "PPK kararının ardından") {
+		t.Errorf("saved item %+v", item)
+	}
+	if h.Hits("kapali.test/analiz/faiz") != 0 {
+		t.Error("posted page was fetched")
+	}
+	// The archived article of the URL is not replaced by the posted page
+	if got, _ := s.archive.Get(archived.GUID); got.Content != archived.Content {
+		t.Errorf("archived content %q after posting the page", got.Content)
+	}
+
+	if rec := save("yanlis", url.Values{"url": {"https://haber.test/"}}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalid key: status %d", rec.Code)
+	}
+
+	list := func(key string) feedResponse {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/saved", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		s.handleSaved(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		var resp feedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := list("anahtar-1"); len(resp.Items) != 2 || resp.Items[0].Link != "https://kapali.test/analiz/faiz" || resp.Items[1].Title != "Meclis yeni yasama yılına başladı" {
+		t.Errorf("reading list %+v", resp.Items)
+	}
+	if resp := list("anahtar-2"); len(resp.Items) != 0 {
+		t.Errorf("another key sees %d items", len(resp.Items))
+	}
+	// Saving again moves the article to the top instead of adding it twice
+	if rec := save("anahtar-1", url.Values{"url": {"https://haber.test/gundem/meclis-yeni-yasama-yilina-basladi"}}); rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if resp := list("anahtar-1"); len(resp.Items) != 2 || resp.Items[0].Title != "Meclis yeni yasama yılına başladı" {
+		t.Errorf("reading list after saving again %+v", resp.Items)
+	}
+	// Reading lists are kept in accounts, not in the archive
+	if feeds := s.archive.Feeds(); len(feeds) != 1 || feeds[0] != "https://kapali.test/rss" {
+		t.Errorf("archive feeds %v", feeds)
+	}
+}
+
+func TestSitePackRules(t *testing.T) {
+	dir := t.TempDir()
+	for name, config := range map[string]string{
+		"ntv.com.tr.txt":         "body: //article\n",
+		"example.org.txt":        "body: //div[@class='story']\n",
+		"configured.example.txt": "body: //main\n",
+		"rendered.example.txt":   "css_body: .post\nrender: js\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &Config{
+		SitePacks: []string{dir},
+		SiteRules: []extractors.SiteRule{{Domain: "www.configured.example", ContentSelector: "article"}},
+	}
+	rules := sitePackRules(newExtractorRegistry(nil, ""), cfg, nil)
+	if len(rules) != 1 || rules[0].Domain != "example.org" || rules[0].ContentSelector != `div[class="story"]` {
+		t.Errorf("pack rules = %+v; want example.org's only", rules)
+	}
+}
+
+func TestFilterRegistryExcludesSections(t *testing.T) {
+	reg := newFilterRegistry()
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.ntv.com.tr/otomobil/yeni-model-tanitildi,abc", false},
+		{"https://www.ntv.com.tr/ekonomi/faiz-karari-aciklandi,abc", true},
+		{"https://www.ilketv.com.tr/kose-yazilari/bir-yazi-123", false},
+		{"https://www.ilketv.com.tr/gundem/bir-haber-123", true},
+		{"https://artigercek.com/roportaj/politika/bir-soylesi", false},
+		{"https://artigercek.com/politika/bir-haber", true},
+		{"https://www.cnbce.com/tv/bir-program", false},
+		{"https://www.ekonomim.com/ekonomi/bir-haber-123", true},
+		{"https://www.ekonomim.com.tr/yazarlar/bir-yazi-123", false},
+		{"https://www.notekonomim.com/yazarlar/bir-yazi-123", true},
+	}
+	for _, tt := range tests {
+		if got := reg.ShouldProcess(tt.url); got != tt.want {
+			t.Errorf("ShouldProcess(%s) = %v; want %v", tt.url, got, tt.want)
+		}
+	}
+}
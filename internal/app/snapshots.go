@@ -0,0 +1,216 @@
+// internal/app/snapshots.go
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/storage"
+)
+
+// snapshotKeyPrefix is the storage key prefix of article snapshots.
+const snapshotKeyPrefix = "snapshots/"
+
+// maxSnapshotSize is the largest page kept; bigger ones are passed through
+// without being stored.
+const maxSnapshotSize = 5 << 20
+
+// snapshotTimeLayout names snapshots so their keys sort by fetch time.
+const snapshotTimeLayout = "20060102T150405.000000000Z"
+
+// Snapshot is the raw HTML of an article page as it was fetched.
+type Snapshot struct {
+	URL       string    `json:"url"`
+	FetchedAt time.Time `json:"fetched_at"`
+	HTML      string    `json:"html"`
+}
+
+// Snapshots stores the raw HTML of fetched article pages, gzip-compressed,
+// so extraction can be run again later without fetching the pages anew and
+// bug reports can include the exact page that failed.
+type Snapshots struct {
+	store storage.Store
+	keep  int
+	clock Clock
+}
+
+// NewSnapshots creates Snapshots kept in store, at most keep per URL
+// (non-positive keeps every one). clock may be nil.
+func NewSnapshots(store storage.Store, keep int, clock Clock) *Snapshots {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &Snapshots{store: store, keep: keep, clock: clock}
+}
+
+// snapshotPrefix returns the key prefix of the snapshots of pageURL.
+func snapshotPrefix(pageURL string) string {
+	return snapshotKeyPrefix + extractors.GenerateGUIDFromURL(pageURL) + "/"
+}
+
+// Save stores page as fetched from pageURL now, dropping the oldest
+// snapshots of the URL beyond the limit.
+func (s *Snapshots) Save(pageURL string, page []byte) error {
+	snap := Snapshot{URL: pageURL, FetchedAt: s.clock.Now().UTC(), HTML: string(page)}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	prefix := snapshotPrefix(pageURL)
+	if err := s.store.Put(prefix+snap.FetchedAt.Format(snapshotTimeLayout), buf.Bytes()); err != nil {
+		return err
+	}
+
+	if s.keep <= 0 {
+		return nil
+	}
+	keys, err := s.store.Keys(prefix)
+	if err != nil {
+		return err
+	}
+	for len(keys) > s.keep {
+		s.store.Delete(keys[0])
+		keys = keys[1:]
+	}
+	return nil
+}
+
+// Latest returns the most recent snapshot of pageURL.
+func (s *Snapshots) Latest(pageURL string) (Snapshot, bool) {
+	keys, err := s.store.Keys(snapshotPrefix(pageURL))
+	if err != nil || len(keys) == 0 {
+		return Snapshot{}, false
+	}
+	return s.load(keys[len(keys)-1])
+}
+
+// Prune drops the snapshots fetched before cutoff, returning how many.
+func (s *Snapshots) Prune(cutoff time.Time) int {
+	keys, err := s.store.Keys(snapshotKeyPrefix)
+	if err != nil {
+		return 0
+	}
+	pruned := 0
+	for _, key := range keys {
+		at, err := time.Parse(snapshotTimeLayout, key[strings.LastIndexByte(key, '/')+1:])
+		if err == nil && at.Before(cutoff) && s.store.Delete(key) == nil {
+			pruned++
+		}
+	}
+	return pruned
+}
+
+func (s *Snapshots) load(key string) (Snapshot, bool) {
+	data, err := s.store.Get(key)
+	if err != nil {
+		return Snapshot{}, false
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("⚠️  Corrupt snapshot %s: %v", key, err)
+		return Snapshot{}, false
+	}
+	var snap Snapshot
+	if err := json.NewDecoder(zr).Decode(&snap); err != nil {
+		log.Printf("⚠️  Corrupt snapshot %s: %v", key, err)
+		return Snapshot{}, false
+	}
+	return snap, true
+}
+
+// Transport returns a RoundTripper snapshotting the HTML pages fetched
+// through base (http.DefaultTransport when nil).
+func (s *Snapshots) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &snapshotTransport{base: base, snapshots: s}
+}
+
+// snapshotTransport stores the successful HTML responses to GET requests.
+type snapshotTransport struct {
+	base      http.RoundTripper
+	snapshots *Snapshots
+}
+
+func (t *snapshotTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK || !isHTMLResponse(resp) {
+		return resp, err
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotSize+1))
+	if err != nil || len(page) > maxSnapshotSize {
+		// Hand what was read back to the extractor with the rest of the body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(page), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(page))
+
+	// Pages reached through redirects are stored under the URL asked for,
+	// which is the one the archive knows
+	first := req
+	for first.Response != nil && first.Response.Request != nil {
+		first = first.Response.Request
+	}
+	if err := t.snapshots.Save(first.URL.String(), page); err != nil {
+		log.Printf("⚠️  Could not snapshot %s: %v", first.URL, err)
+	}
+	return resp, nil
+}
+
+// isHTMLResponse reports whether resp holds an HTML page; responses without
+// a content type are taken to be pages too.
+func isHTMLResponse(resp *http.Response) bool {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// handleSnapshot serves the latest stored HTML of an article page, as
+// fetched, for attaching to bug reports.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if s.snapshots == nil {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "snapshots are disabled"))
+		return
+	}
+	pageURL := strings.TrimSpace(r.URL.Query().Get("url"))
+	if _, apiErr := validateTargetURL("url", pageURL); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	snap, ok := s.snapshots.Latest(pageURL)
+	if !ok {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "no snapshot of this page").with("url", pageURL))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Last-Modified", snap.FetchedAt.Format(http.TimeFormat))
+	w.Header().Set("X-Snapshot-URL", snap.URL)
+	// Never run the page's scripts in the API's origin
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	io.WriteString(w, snap.HTML)
+}
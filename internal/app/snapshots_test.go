@@ -0,0 +1,88 @@
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/storage"
+)
+
+func TestSnapshotsKeepLatest(t *testing.T) {
+	clock := newFakeClock()
+	snaps := NewSnapshots(storage.NewMemoryStore(), 2, clock)
+	const page = "https://news.example.com/a/1"
+	for _, body := range []string{"<p>one</p>", "<p>two</p>", "<p>three</p>"} {
+		if err := snaps.Save(page, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Minute)
+	}
+
+	snap, ok := snaps.Latest(page)
+	if !ok || snap.HTML != "<p>three</p>" || snap.URL != page {
+		t.Fatalf("latest = %+v, %v", snap, ok)
+	}
+	keys, _ := snaps.store.Keys(snapshotPrefix(page))
+	if len(keys) != 2 {
+		t.Errorf("kept %d snapshots, want 2", len(keys))
+	}
+
+	if n := snaps.Prune(clock.Now().Add(-90 * time.Second)); n != 1 {
+		t.Errorf("pruned %d snapshots, want 1", n)
+	}
+	if _, ok := snaps.Latest("https://news.example.com/a/2"); ok {
+		t.Error("found a snapshot of a page never fetched")
+	}
+}
+
+func TestSnapshotTransport(t *testing.T) {
+	snaps := NewSnapshots(storage.NewMemoryStore(), 0, nil)
+	client := &http.Client{Transport: snaps.Transport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		resp := respond(http.StatusOK, "<html><body>"+r.URL.Path+"</body></html>")
+		resp.Header.Set("Content-Type", "text/html; charset=utf-8")
+		if strings.HasSuffix(r.URL.Path, ".jpg") {
+			resp.Header.Set("Content-Type", "image/jpeg")
+		}
+		return resp, nil
+	}))}
+
+	for _, target := range []string{"https://news.example.com/a/1", "https://news.example.com/i/1.jpg"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), "/") {
+			t.Errorf("%s: body not passed through: %q", target, body)
+		}
+	}
+
+	if snap, ok := snaps.Latest("https://news.example.com/a/1"); !ok || snap.HTML != "<html><body>/a/1</body></html>" {
+		t.Errorf("page snapshot = %+v, %v", snap, ok)
+	}
+	if _, ok := snaps.Latest("https://news.example.com/i/1.jpg"); ok {
+		t.Error("image was snapshotted")
+	}
+}
+
+func TestHandleSnapshot(t *testing.T) {
+	s := &Server{cfg: &Config{}, snapshots: NewSnapshots(storage.NewMemoryStore(), 1, nil)}
+	s.snapshots.Save("https://news.example.com/a/1", []byte("<p>page</p>"))
+
+	rec := httptest.NewRecorder()
+	s.handleSnapshot(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot?url=https://news.example.com/a/1", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "<p>page</p>" || rec.Header().Get("Content-Security-Policy") != "sandbox" {
+		t.Errorf("got %d %q %v", rec.Code, rec.Body, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleSnapshot(rec, httptest.NewRequest(http.MethodGet, "/admin/snapshot?url=https://news.example.com/a/2", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing snapshot: %d", rec.Code)
+	}
+}
@@ -0,0 +1,60 @@
+// internal/app/stampede.go
+package app
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// lockGeneration waits until no other request is generating the feed cached
+// under cacheKey and returns the function ending this one's generation, so
+// when a popular entry expires a single request renders it while the others
+// wait to be served what it cached. It returns false if ctx ends first.
+func (h *FeedHandler) lockGeneration(ctx context.Context, cacheKey string) (func(), bool) {
+	for {
+		h.refreshMu.Lock()
+		if h.generating == nil {
+			h.generating = make(map[string]chan struct{})
+		}
+		done, busy := h.generating[cacheKey]
+		if !busy {
+			done = make(chan struct{})
+			h.generating[cacheKey] = done
+			h.refreshMu.Unlock()
+			return func() {
+				h.refreshMu.Lock()
+				delete(h.generating, cacheKey)
+				h.refreshMu.Unlock()
+				close(done)
+			}, true
+		}
+		h.refreshMu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// refreshEarly reports whether a fresh entry age old should be refreshed
+// ahead of its expiry: never before the last EarlyRefresh of the cache TTL,
+// then with a probability growing to one at expiry, so a single request
+// among the many hitting a popular entry regenerates it in the background
+// and the entry never expires under load.
+func (h *FeedHandler) refreshEarly(age time.Duration) bool {
+	if h.EarlyRefresh <= 0 {
+		return false
+	}
+	remaining := h.Cache.ttl - age
+	if remaining >= h.EarlyRefresh {
+		return false
+	}
+	random := rand.Float64
+	if h.random != nil {
+		random = h.random
+	}
+	return random() >= float64(remaining)/float64(h.EarlyRefresh)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeedGeneratedOnceOnMiss(t *testing.T) {
+	var calls atomic.Int32
+	unblock := make(chan struct{})
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		<-unblock
+		return respond(http.StatusOK, testFeed), nil
+	}, newFakeClock(), 0)
+
+	const clients = 5
+	recs := make([]*httptest.ResponseRecorder, clients)
+	var wg sync.WaitGroup
+	for i := range clients {
+		recs[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			h.ServeHTTP(recs[i], httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL, nil))
+		})
+	}
+	// Let every request reach the cache before the upstream answers
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream fetched %d times; want once", n)
+	}
+	misses := 0
+	for _, rec := range recs {
+		if rec.Code != http.StatusOK {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+		if rec.Header().Get("X-Cache") == "MISS" {
+			misses++
+		}
+	}
+	if misses != 1 {
+		t.Errorf("%d requests generated the feed; want 1", misses)
+	}
+}
+
+func TestEarlyRefresh(t *testing.T) {
+	var calls atomic.Int32
+	clock := newFakeClock()
+	h := newTestFeedHandler(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return respond(http.StatusOK, testFeed), nil
+	}, clock, 0)
+	h.EarlyRefresh = 10 * time.Second
+	roll := 0.0
+	h.random = func() float64 { return roll }
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL, nil))
+		return rec.Header().Get("X-Cache")
+	}
+	get()
+
+	// Before the early refresh window nothing is refreshed
+	roll = 0.99
+	clock.Advance(45 * time.Second)
+	if got := get(); got != "HIT" || calls.Load() != 1 {
+		t.Fatalf("X-Cache %s after %d fetches; want a HIT without refresh", got, calls.Load())
+	}
+
+	// Halfway through the window, half the requests refresh
+	clock.Advance(10 * time.Second)
+	roll = 0.4
+	if got := get(); got != "HIT" || calls.Load() != 1 {
+		t.Fatalf("X-Cache %s after %d fetches; want a HIT without refresh", got, calls.Load())
+	}
+	roll = 0.6
+	if got := get(); got != "HIT" {
+		t.Fatalf("X-Cache %s; want HIT", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("upstream fetched %d times; want an early refresh", n)
+	}
+}
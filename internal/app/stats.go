@@ -0,0 +1,54 @@
+// internal/app/stats.go
+package app
+
+import "net/http"
+
+// ServerStats is returned by GET /stats.
+type ServerStats struct {
+	Cache struct {
+		Entries int `json:"entries"`
+	} `json:"cache"`
+	Archive struct {
+		Items int `json:"items"`
+		Feeds int `json:"feeds"`
+	} `json:"archive"`
+	// Circuits lists the sites whose extractions are failing; an open
+	// circuit means the site is skipped until retry_at
+	Circuits []CircuitState `json:"circuits"`
+	// Bandwidth is the bytes downloaded from each domain over the last
+	// days, with the domain's daily quota
+	Bandwidth []DomainBandwidth `json:"bandwidth"`
+	// Routes counts the requests served by each route
+	Routes map[string]RouteStats `json:"routes"`
+	// Extractions is the state of the extraction slots shared by requests
+	Extractions ExtractionStats `json:"extractions"`
+	// Load is the state of the load guard, when one is configured
+	Load *LoadStats `json:"load,omitempty"`
+}
+
+// handleStats serves GET /stats, the state of the caches and of the
+// per-site circuit breakers.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	var stats ServerStats
+	stats.Cache.Entries = s.cache.Size()
+	stats.Archive.Items = s.archive.Size()
+	stats.Archive.Feeds = len(s.archive.Feeds())
+	stats.Circuits = []CircuitState{}
+	if s.breaker != nil {
+		stats.Circuits = s.breaker.States()
+	}
+	stats.Bandwidth = []DomainBandwidth{}
+	if s.bandwidth != nil {
+		stats.Bandwidth = s.bandwidth.Usage()
+	}
+	stats.Routes = map[string]RouteStats{}
+	if s.metrics != nil {
+		stats.Routes = s.metrics.Routes()
+	}
+	stats.Extractions = s.feedHandler.extractions().stats()
+	if s.load != nil {
+		load := s.load.Stats()
+		stats.Load = &load
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,114 @@
+// internal/app/stories.go
+package app
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Story clustering modes selected with the cluster query parameter.
+const (
+	// clusterRepresentatives returns only the first item of every story
+	clusterRepresentatives = "representatives"
+	// clusterGrouped returns the first item of every story with the others
+	// listed under related
+	clusterGrouped = "grouped"
+)
+
+// storyWindow is how far apart two items may be published and still be
+// considered the same story.
+const storyWindow = 48 * time.Hour
+
+// Similarity thresholds for titles and article texts.
+const (
+	storyTitleSimilarity   = 0.5
+	storyContentSimilarity = 0.6
+)
+
+// RelatedItem is another item covering the same story as the item it is
+// listed under.
+type RelatedItem struct {
+	Title      string `json:"title"`
+	Link       string `json:"link"`
+	SourceName string `json:"source_name,omitempty"`
+}
+
+// storyGroups groups items that cover the same story.
+type storyGroups struct {
+	groups []*storyGroup
+}
+
+type storyGroup struct {
+	item    Item
+	title   map[string]bool
+	content map[string]bool
+	related []RelatedItem
+}
+
+// add files item under the story it belongs to and reports whether it
+// started a new story.
+func (s *storyGroups) add(item Item) bool {
+	title := storyWords(item.Title)
+	content := storyWords(cleanHTMLTags(item.Content))
+	for _, g := range s.groups {
+		if !withinStoryWindow(g.item.Published, item.Published) {
+			continue
+		}
+		if jaccard(g.title, title) >= storyTitleSimilarity ||
+			(len(content) > 0 && jaccard(g.content, content) >= storyContentSimilarity) {
+			g.related = append(g.related, RelatedItem{Title: item.Title, Link: item.Link, SourceName: item.SourceName})
+			return false
+		}
+	}
+	s.groups = append(s.groups, &storyGroup{item: item, title: title, content: content})
+	return true
+}
+
+// items returns the first item of every story with the related items set.
+func (s *storyGroups) items() []Item {
+	items := make([]Item, 0, len(s.groups))
+	for _, g := range s.groups {
+		item := g.item
+		item.Related = g.related
+		items = append(items, item)
+	}
+	return items
+}
+
+// storyWords returns the distinct content words of text.
+func storyWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, token := range tokenize(strings.ToLowerSpecial(unicode.TurkishCase, text)) {
+		if len([]rune(token)) >= 3 && !tagStopwords[token] {
+			words[token] = true
+		}
+	}
+	return words
+}
+
+// jaccard returns the Jaccard similarity of two word sets.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// withinStoryWindow reports whether two RFC3339 publication times are close
+// enough to be the same story. Items without a date always are.
+func withinStoryWindow(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return true
+	}
+	d := ta.Sub(tb)
+	return d <= storyWindow && d >= -storyWindow
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStoryGroups(t *testing.T) {
+	items := []Item{
+		{Title: "Merkez Bankası politika faizini sabit bıraktı", Link: "https://a.test/1", Published: "2024-10-03T14:00:00Z"},
+		{Title: "Borsa güne yükselişle başladı", Link: "https://a.test/2", Published: "2024-10-03T09:00:00Z"},
+		{Title: "Merkez Bankası faizi sabit bıraktı", Link: "https://b.test/1", SourceName: "B", Published: "2024-10-03T15:30:00Z"},
+		// Same headline a week later is a new story
+		{Title: "Merkez Bankası politika faizini sabit bıraktı", Link: "https://a.test/3", Published: "2024-10-10T14:00:00Z"},
+	}
+
+	var s storyGroups
+	var started []bool
+	for _, it := range items {
+		started = append(started, s.add(it))
+	}
+	if want := []bool{true, true, false, true}; !slices.Equal(started, want) {
+		t.Fatalf("add = %v; want %v", started, want)
+	}
+
+	got := s.items()
+	if len(got) != 3 || len(got[0].Related) != 1 || got[0].Related[0].Link != "https://b.test/1" || got[0].Related[0].SourceName != "B" {
+		t.Errorf("items = %+v", got)
+	}
+	if len(got[1].Related) != 0 || len(got[2].Related) != 0 {
+		t.Errorf("unrelated items were grouped: %+v", got)
+	}
+}
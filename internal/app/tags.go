@@ -0,0 +1,142 @@
+// internal/app/tags.go
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxTags is how many keywords are kept per item.
+const maxTags = 5
+
+// tagStopwords are Turkish and English words that never start, end or
+// appear inside a keyword.
+var tagStopwords = toSet(strings.Fields(`
+acaba ama ancak artık aslında az bazı belki ben beri bile bir biri birkaç birşey biz bu buna bunda bundan bunlar bunları bunların bunu bunun burada çok çünkü da daha dahi de defa diğer diye dolayı en gibi göre hem hep hepsi her hiç için ile ise işte kadar karşı kendi ki kim mı mi mu mü nasıl ne neden nerede niçin o olan olarak olduğu olduğunu olmak olması olup on ona ondan onlar onu onun oysa öyle pek sadece sanki şey şöyle şu şunu tarafından üzere ve veya ya yani yapılan yaptı yeni yine yüzde etti ettiği eden edildi oldu olacak olan sonra önce ayrıca değil var yok
+a about after all also an and any are as at be been but by can could did do for from had has have he her his how if in into is it its more most new no not of on or our out over said she so some than that the their them then there these they this to up was we were what when which who will with would you
+`))
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// extractTags picks keywords from an article in the spirit of RAKE: the text
+// is split into runs of content words at stopwords and punctuation, and the
+// phrases of up to three words inside those runs are scored by how often they
+// occur times their length. Phrases seen only once are ignored.
+func extractTags(title, content string) []string {
+	text := title + ". " + cleanHTMLTags(content)
+	lower := strings.ToLowerSpecial(unicode.TurkishCase, text)
+
+	counts := make(map[string]int)
+	var run []string
+	flush := func() {
+		for i := range run {
+			for n := 1; n <= 3 && i+n <= len(run); n++ {
+				counts[strings.Join(run[i:i+n], " ")]++
+			}
+		}
+		run = nil
+	}
+	for _, token := range tokenize(lower) {
+		if token == "" || tagStopwords[token] || len([]rune(token)) < 3 || isNumber(token) {
+			flush()
+			continue
+		}
+		run = append(run, token)
+	}
+	flush()
+
+	score := func(phrase string) int {
+		return counts[phrase] * len(strings.Fields(phrase))
+	}
+	var candidates []string
+	for phrase, n := range counts {
+		if n > 1 {
+			candidates = append(candidates, phrase)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if score(a) != score(b) {
+			return score(a) > score(b)
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	var tags []string
+	for _, c := range candidates {
+		if len(tags) == maxTags {
+			break
+		}
+		if !overlapsTag(tags, c) {
+			tags = append(tags, c)
+		}
+	}
+	return tags
+}
+
+// tokenize splits text into words, with an empty token at every
+// punctuation mark so phrases do not run across sentences.
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’':
+			word.WriteRune(r)
+		default:
+			if word.Len() > 0 {
+				tokens = append(tokens, stripSuffixApostrophe(word.String()))
+				word.Reset()
+			}
+			if !unicode.IsSpace(r) {
+				tokens = append(tokens, "")
+			}
+		}
+	}
+	if word.Len() > 0 {
+		tokens = append(tokens, stripSuffixApostrophe(word.String()))
+	}
+	return tokens
+}
+
+// stripSuffixApostrophe drops Turkish case suffixes written after an
+// apostrophe, so "Ankara'da" and "Ankara'nın" count as "ankara".
+func stripSuffixApostrophe(word string) string {
+	if i := strings.IndexAny(word, "'’"); i > 0 {
+		return word[:i]
+	}
+	return word
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsTag reports whether candidate repeats a word of a chosen tag.
+func overlapsTag(tags []string, candidate string) bool {
+	for _, t := range tags {
+		for _, w := range strings.Fields(candidate) {
+			for _, tw := range strings.Fields(t) {
+				if w == tw {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	content := `<p>Türkiye Cumhuriyet Merkez Bankası, politika faizini yüzde 50'de sabit bıraktı.
+Merkez Bankası açıklamasında enflasyon beklentilerine dikkat çekti.</p>
+<p>Piyasalar Merkez Bankası'nın kararının ardından yükselişe geçti. Enflasyon ve
+politika faizi, yatırımcıların gündeminde.</p>`
+
+	got := extractTags("Merkez Bankası faiz kararını açıkladı", content)
+	want := []string{"merkez bankası", "enflasyon", "politika"}
+	if !slices.Equal(got, want) {
+		t.Errorf("extractTags = %q; want %q", got, want)
+	}
+}
+
+func TestExtractTagsIgnoresSingleMentions(t *testing.T) {
+	if got := extractTags("Kısa haber", "<p>Bugün hava güneşli olacak.</p>"); len(got) != 0 {
+		t.Errorf("extractTags = %q; want none", got)
+	}
+}
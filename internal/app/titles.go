@@ -0,0 +1,139 @@
+// internal/app/titles.go
+package app
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// titleSuffixes are the site names the known sites append to their titles,
+// by domain. Suffixes equal to the domain's name ("T24" on t24.com.tr) are
+// recognized without being listed.
+var titleSuffixes = map[string][]string{
+	"dunya.com":      {"Dünya Gazetesi", "Dünya"},
+	"ntv.com.tr":     {"NTV", "NTV Haber"},
+	"cnbce.com":      {"CNBC-e"},
+	"ekonomim.com":   {"Ekonomim", "ekonomim.com"},
+	"kisadalga.net":  {"Kısa Dalga"},
+	"ilketv.com.tr":  {"İlke TV", "İLKE TV"},
+	"artigercek.com": {"Artı Gerçek", "Artıgerçek"},
+}
+
+// titleSeparators separate a title from the site name appended to it.
+var titleSeparators = []string{" - ", " | ", " – ", " — ", " :: ", " « ", " » ", " / "}
+
+// titleAcronyms keep their capitals when an all-caps title is re-cased.
+var titleAcronyms = stringSet(
+	"AB", "ABD", "AKP", "AK", "BM", "CHP", "MHP", "HDP", "DEM", "İYİ", "TBMM", "TCMB", "TÜİK", "BIST",
+	"SGK", "MEB", "YÖK", "YSK", "AİHM", "DSÖ", "NATO", "IMF", "FED", "ECB", "AA", "TL", "KDV", "ÖTV",
+	"THY", "TRT", "NTV", "CNN", "BBC", "PKK", "IŞİD", "FETÖ", "UEFA", "FIFA", "TFF", "İBB", "ABB", "AFAD",
+)
+
+// normalizeTitle cleans up a feed item's title: entities are decoded,
+// whitespace collapsed, the site name appended by link's site removed and an
+// ALL CAPS title turned into sentence case.
+func normalizeTitle(title, link string) string {
+	// Some feeds escape twice ("&amp;quot;")
+	for range 2 {
+		title = html.UnescapeString(title)
+	}
+	title = strings.TrimSpace(collapseWhitespace(strings.ReplaceAll(title, " ", " ")))
+	title = stripTitleSuffix(title, circuitDomain(link))
+	if isAllCaps(title) {
+		title = sentenceCase(title)
+	}
+	return title
+}
+
+// stripTitleSuffix removes a trailing "- Site Name" naming the site at host.
+func stripTitleSuffix(title, host string) string {
+	for _, sep := range titleSeparators {
+		i := strings.LastIndex(title, sep)
+		if i <= 0 {
+			continue
+		}
+		suffix := strings.TrimSpace(title[i+len(sep):])
+		if isSiteName(suffix, host) {
+			return strings.TrimSpace(title[:i])
+		}
+	}
+	return title
+}
+
+// isSiteName reports whether name is the name of the site at host.
+func isSiteName(name, host string) bool {
+	if name == "" || host == "" {
+		return false
+	}
+	folded := foldName(name)
+	for domain, suffixes := range titleSuffixes {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		for _, s := range suffixes {
+			if foldName(s) == folded {
+				return true
+			}
+		}
+	}
+	// The domain's own name: "T24" on t24.com.tr, "Haber Test" on haber.test
+	label, _, _ := strings.Cut(host, ".")
+	return folded == foldName(label) || folded == foldName(host)
+}
+
+// foldName reduces a site name to lower-case ASCII letters and digits, so
+// "Dünya" matches "dunya" and "Haber Test" matches "habertest".
+func foldName(s string) string {
+	s = strings.NewReplacer(
+		"ç", "c", "Ç", "c", "ğ", "g", "Ğ", "g", "ı", "i", "İ", "i",
+		"ö", "o", "Ö", "o", "ş", "s", "Ş", "s", "ü", "u", "Ü", "u",
+	).Replace(s)
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isAllCaps reports whether title is written in capitals throughout.
+func isAllCaps(title string) bool {
+	letters, upper := 0, 0
+	for _, r := range title {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 8 && upper == letters
+}
+
+// sentenceCase lower-cases an all-caps title with Turkish casing rules,
+// capitalizing its first letter and the known acronyms.
+func sentenceCase(title string) string {
+	words := strings.Fields(title)
+	for i, w := range words {
+		core := strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) })
+		// Suffixed acronyms stay capitalized too: "ABD'DE" becomes "ABD'de"
+		stem, rest, _ := strings.Cut(core, "'")
+		if titleAcronyms[stem] {
+			lowered := strings.ToLowerSpecial(unicode.TurkishCase, rest)
+			words[i] = strings.Replace(w, core, stem+strings.TrimSuffix("'"+lowered, "'"), 1)
+			continue
+		}
+		words[i] = strings.ToLowerSpecial(unicode.TurkishCase, w)
+	}
+	title = strings.Join(words, " ")
+	for i, r := range title {
+		if unicode.IsLetter(r) {
+			first := strings.ToUpperSpecial(unicode.TurkishCase, string(r))
+			return title[:i] + first + title[i+utf8.RuneLen(r):]
+		}
+	}
+	return title
+}
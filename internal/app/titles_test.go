@@ -0,0 +1,24 @@
+package app
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title, link, want string
+	}{
+		{"Asgari ücret açıklandı - T24", "https://t24.com.tr/haber/x", "Asgari ücret açıklandı"},
+		{"Borsa güne yükselişle başladı | Dünya Gazetesi", "https://www.dunya.com/finans/x", "Borsa güne yükselişle başladı"},
+		{"Meclis açıldı - Haber Test", "https://haber.test/gundem/x", "Meclis açıldı"},
+		// Only the site's own name is a suffix
+		{"Tarihi karar - Anayasa Mahkemesi", "https://t24.com.tr/haber/x", "Tarihi karar - Anayasa Mahkemesi"},
+		{"Enflasyon &amp;quot;zirve&amp;quot; yaptı &#8211; yeni rekor", "https://haber.test/x", "Enflasyon \"zirve\" yaptı – yeni rekor"},
+		{"  Çok   boşluklu\n başlık ", "https://haber.test/x", "Çok boşluklu başlık"},
+		{"İSTANBUL'DA ŞİDDETLİ YAĞIŞ: ABD'DEN UYARI", "https://haber.test/x", "İstanbul'da şiddetli yağış: ABD'den uyarı"},
+		{"TBMM ve CHP", "https://haber.test/x", "TBMM ve CHP"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.title, tt.link); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q; want %q", tt.title, got, tt.want)
+		}
+	}
+}
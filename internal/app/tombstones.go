@@ -0,0 +1,83 @@
+// internal/app/tombstones.go
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gofull/internal/extractors"
+)
+
+// linkIsGone reports whether the article at link answers 404 or 410, so it
+// was taken down rather than failing to extract.
+func (h *FeedHandler) linkIsGone(link string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), deadLinkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone
+}
+
+// articleGone reports whether the article at link, which its extractor
+// failed to read with err, was taken down. The status the extractor got
+// settles it; pages that timed out are not fetched again.
+func (h *FeedHandler) articleGone(link string, err error) bool {
+	var status *extractors.UpstreamStatusError
+	if errors.As(err, &status) {
+		return status.Gone()
+	}
+	if errors.Is(err, extractors.ErrTimeout) {
+		return false
+	}
+	return h.linkIsGone(link)
+}
+
+// removedItems returns the archived items of the feed at feedURL that it no
+// longer lists although it still lists older ones: they were retracted, not
+// pushed out by newer items. They are marked deleted in the archive.
+func (h *FeedHandler) removedItems(feed *gofeed.Feed, feedURL string) []Item {
+	listed := make(map[string]bool)
+	var oldest time.Time
+	for _, feedItem := range feed.Items {
+		if feedItem.Link == "" {
+			continue
+		}
+		listed[extractors.GenerateGUIDFromURL(feedItem.Link)] = true
+		if p := feedItem.PublishedParsed; p != nil && (oldest.IsZero() || p.Before(oldest)) {
+			oldest = *p
+		}
+	}
+	if oldest.IsZero() {
+		return nil
+	}
+
+	var removed []Item
+	for _, item := range h.Archive.Items(feedURL) {
+		if listed[item.GUID] {
+			continue
+		}
+		published, err := time.Parse(time.RFC3339, item.Published)
+		if err != nil || published.Before(oldest) {
+			continue
+		}
+		if !item.Deleted {
+			log.Printf("🪦 Removed from %s: %s", feedURL, item.Link)
+			item.Deleted, item.DeletedAt = true, h.now().Format(time.RFC3339)
+			h.Archive.Put(feedURL, item)
+		}
+		removed = append(removed, item)
+	}
+	return removed
+}
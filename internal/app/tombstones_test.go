@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+const tombstoneEntry = `<item><title>Item %[1]d</title><link>https://news.example.com/a/%[1]d</link><pubDate>Wed, 01 May 2024 1%[1]d:00:00 +0000</pubDate></item>`
+
+func newTombstoneTestHandler(items *[]int, gone map[string]bool) *FeedHandler {
+	registry := extractors.NewRegistry()
+	registry.RegisterDefault(extractorFunc(func(input any) (string, []string, error) {
+		link := input.(map[string]interface{})["link"].(string)
+		if gone[link] {
+			return "", nil, errors.New("not found")
+		}
+		return "<p>full article</p>", nil, nil
+	}))
+	doer := func(r *http.Request) (*http.Response, error) {
+		if gone[r.URL.String()] {
+			return respond(http.StatusNotFound, ""), nil
+		}
+		var b strings.Builder
+		for _, n := range *items {
+			fmt.Fprintf(&b, tombstoneEntry, n)
+		}
+		return respond(http.StatusOK, `<rss version="2.0"><channel><title>Test</title>`+b.String()+`</channel></rss>`), nil
+	}
+	h := NewFeedHandler(nil, doerFunc(doer), registry, filters.NewFilterRegistry(), NewArchive(0), nil)
+	h.Tombstones = true
+	return h
+}
+
+func getTombstoneFeed(t *testing.T, h *FeedHandler, format string) string {
+	t.Helper()
+	h.Cache = NewCache(time.Minute, 0)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format="+format+"&url="+url.QueryEscape(testFeedURL), nil))
+	return rec.Body.String()
+}
+
+func TestFeedHandlerTombstonesForRemovedItems(t *testing.T) {
+	items := []int{3, 2, 1}
+	h := newTombstoneTestHandler(&items, nil)
+	getTombstoneFeed(t, h, formatJSON)
+
+	// Item 2 vanishes while the older item 1 stays: it was retracted
+	items = []int{3, 1}
+	var resp struct {
+		feedResponse
+		Deleted int `json:"items_deleted"`
+	}
+	if err := json.Unmarshal([]byte(getTombstoneFeed(t, h, formatJSON)), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Returned != 2 || resp.Deleted != 1 || len(resp.Items) != 3 {
+		t.Fatalf("returned %d, deleted %d, items %d", resp.Returned, resp.Deleted, len(resp.Items))
+	}
+	if tomb := resp.Items[2]; !tomb.Deleted || tomb.Link != "https://news.example.com/a/2" || tomb.DeletedAt == "" {
+		t.Errorf("tombstone = %+v", tomb)
+	}
+
+	rss := getTombstoneFeed(t, h, formatRSS)
+	want := `<at:deleted-entry ref="` + extractors.GenerateGUIDFromURL("https://news.example.com/a/2") + `" when="`
+	if !strings.Contains(rss, want) || !strings.Contains(rss, `xmlns:at="http://purl.org/atompub/tombstones/1.0"`) {
+		t.Errorf("RSS output lacks the deleted entry:\n%s", rss)
+	}
+
+	// Items pushed out by newer ones are not retracted
+	items = []int{5, 4, 3}
+	if body := getTombstoneFeed(t, h, formatJSON); strings.Contains(body, `"deleted": true`) {
+		t.Errorf("pushed out items reported deleted:\n%s", body)
+	}
+}
+
+func TestFeedHandlerTombstonesForGoneArticles(t *testing.T) {
+	items := []int{1}
+	gone := map[string]bool{"https://news.example.com/a/1": true}
+	h := newTombstoneTestHandler(&items, gone)
+
+	body := getTombstoneFeed(t, h, formatJSON)
+	if !strings.Contains(body, `"deleted": true`) || !strings.Contains(body, `"items_deleted": 1`) {
+		t.Fatalf("gone article not reported deleted:\n%s", body)
+	}
+
+	// The article coming back restores it
+	delete(gone, "https://news.example.com/a/1")
+	if body := getTombstoneFeed(t, h, formatJSON); strings.Contains(body, `"deleted": true`) || !strings.Contains(body, "full article") {
+		t.Errorf("restored article still deleted:\n%s", body)
+	}
+}
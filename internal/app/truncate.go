@@ -0,0 +1,145 @@
+// internal/app/truncate.go
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Strategies for content over MaxContentSize: cut it after the last block
+// fitting, or leave it out; either way a link to the reader view follows.
+const (
+	TruncateParagraph = "paragraph"
+	TruncateOmit      = "omit"
+)
+
+// truncationContainers are the blocks descended into when they do not fit
+// whole, as extracted articles are often wrapped in one of them.
+var truncationContainers = atomSet(atom.Div, atom.Section, atom.Article, atom.Main, atom.Blockquote, atom.Ul, atom.Ol)
+
+// truncate cuts the content of item over MaxContentSize bytes, marking it
+// truncated and linking to the reader view serving the whole article.
+func (h *FeedHandler) truncate(item Item) Item {
+	if h.MaxContentSize <= 0 || len(item.Content) <= h.MaxContentSize || item.Link == "" {
+		return item
+	}
+	item.FullTextURL = h.readerURL(item.Link)
+	content := ""
+	if h.ContentTruncation != TruncateOmit {
+		content = truncateHTML(item.Content, h.MaxContentSize)
+	}
+	item.Content = content + fmt.Sprintf(`<p><a href="%s">Read the full article</a></p>`, html.EscapeString(item.FullTextURL))
+	item.Truncated = true
+	return item
+}
+
+// readerURL returns the link to the reader view of the article at link, on
+// PublicURL or relative when it is not set.
+func (h *FeedHandler) readerURL(link string) string {
+	return strings.TrimSuffix(h.PublicURL, "/") + "/read?url=" + url.QueryEscape(link)
+}
+
+// truncateHTML cuts content, an article's HTML, to at most max bytes after
+// the last paragraph or other block fitting whole. Content whose first
+// block alone is over max is cut as text at a word boundary.
+func truncateHTML(content string, max int) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return truncateText(cleanHTMLTags(content), max)
+	}
+	body := findBody(doc)
+	if body == nil {
+		return truncateText(cleanHTMLTags(content), max)
+	}
+	text := nodeText(body)
+	if trimBlocks(body, max) == 0 {
+		return truncateText(text, max)
+	}
+
+	var buf bytes.Buffer
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return truncateText(text, max)
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// trimBlocks removes the children of n past the first budget bytes of
+// rendered HTML, descending into the first child not fitting whole when it
+// is a container. It returns the bytes kept.
+func trimBlocks(n *html.Node, budget int) int {
+	used := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		size := renderedSize(c)
+		if used+size <= budget {
+			used += size
+			continue
+		}
+		cut := c
+		if c.Type == html.ElementNode && truncationContainers[c.DataAtom] {
+			// The container's tags count toward the budget
+			tags := size
+			for gc := c.FirstChild; gc != nil; gc = gc.NextSibling {
+				tags -= renderedSize(gc)
+			}
+			if budget-used > tags {
+				if kept := trimBlocks(c, budget-used-tags); kept > 0 {
+					used += tags + kept
+					cut = c.NextSibling
+				}
+			}
+		}
+		for cut != nil {
+			next := cut.NextSibling
+			n.RemoveChild(cut)
+			cut = next
+		}
+		break
+	}
+	return used
+}
+
+// renderedSize returns the bytes of n rendered as HTML.
+func renderedSize(n *html.Node) int {
+	var buf bytes.Buffer
+	if err := html.Render(&buf, n); err != nil {
+		return 0
+	}
+	return buf.Len()
+}
+
+// truncateText returns text cut to at most max bytes at a word boundary,
+// as an escaped paragraph ending in an ellipsis.
+func truncateText(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	// The paragraph tags and ellipsis count toward max
+	limit := max - len("<p>…</p>")
+	if limit <= 0 {
+		return ""
+	}
+	if len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if space := strings.LastIndexByte(text[:cut], ' '); space > 0 {
+			cut = space
+		}
+		text = text[:cut]
+	}
+	// Escaping may grow the text past the limit again
+	escaped := html.EscapeString(text)
+	for len(escaped) > limit && text != "" {
+		_, size := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-size]
+		escaped = html.EscapeString(text)
+	}
+	return "<p>" + escaped + "…</p>"
+}
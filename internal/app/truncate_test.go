@@ -0,0 +1,53 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateHTML(t *testing.T) {
+	para := func(n int) string { return "<p>" + strings.Repeat("a", n) + "</p>" }
+	tests := []struct {
+		name    string
+		content string
+		max     int
+		want    string
+	}{
+		{"paragraph boundary", para(10) + para(10) + para(10), 40, para(10) + para(10)},
+		{"wrapped article", `<div class="body">` + para(10) + para(10) + "</div>", 45, `<div class="body">` + para(10) + "</div>"},
+		{"single long paragraph", "<p>" + strings.Repeat("kelime ", 20) + "</p>", 40, "<p>kelime kelime kelime kelime…</p>"},
+	}
+	for _, tt := range tests {
+		got := truncateHTML(tt.content, tt.max)
+		if got != tt.want {
+			t.Errorf("%s: truncateHTML = %q; want %q", tt.name, got, tt.want)
+		}
+		if len(got) > tt.max {
+			t.Errorf("%s: %d bytes; want at most %d", tt.name, len(got), tt.max)
+		}
+	}
+}
+
+func TestTruncateItem(t *testing.T) {
+	h := &FeedHandler{MaxContentSize: 40, PublicURL: "https://gofull.example.com/"}
+	long := Item{Link: "https://news.example.com/a/1?x=1", Content: strings.Repeat("<p>canlı yayın</p>", 10)}
+
+	item := h.truncate(long)
+	wantURL := "https://gofull.example.com/read?url=https%3A%2F%2Fnews.example.com%2Fa%2F1%3Fx%3D1"
+	if !item.Truncated || item.FullTextURL != wantURL {
+		t.Errorf("truncated = %v, full_text_url = %q; want true, %q", item.Truncated, item.FullTextURL, wantURL)
+	}
+	if !strings.HasPrefix(item.Content, "<p>canlı yayın</p><p>canlı yayın</p><p><a") || !strings.Contains(item.Content, "read?url=") {
+		t.Errorf("content = %q; want two paragraphs and the reader link", item.Content)
+	}
+
+	h.ContentTruncation = TruncateOmit
+	if item := h.truncate(long); !strings.HasPrefix(item.Content, "<p><a href=") {
+		t.Errorf("omitted content = %q; want only the reader link", item.Content)
+	}
+
+	short := Item{Link: long.Link, Content: "<p>kısa</p>"}
+	if item := h.truncate(short); item.Truncated || item.Content != short.Content {
+		t.Errorf("short item = %+v; want it untouched", item)
+	}
+}
@@ -0,0 +1,93 @@
+// internal/app/turkish.go
+package app
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+// utf8Mojibake maps the Turkish letters and punctuation of UTF-8 text that
+// was decoded as Windows-1252 back to what they were.
+var utf8Mojibake = strings.NewReplacer(
+	"Ã¼", "ü", "Ãœ", "Ü", "Ã¶", "ö", "Ã–", "Ö", "Ã§", "ç", "Ã‡", "Ç",
+	"ÅŸ", "ş", "Åž", "Ş", "ÄŸ", "ğ", "Äž", "Ğ", "Ä±", "ı", "Ä°", "İ",
+	"Ã¢", "â", "Ã®", "î", "Ã»", "û",
+	"â€™", "’", "â€˜", "‘", "â€œ", "“", "â€\u009d", "”", "â€“", "–", "â€”", "—", "â€¦", "…",
+)
+
+// latin1Mojibake maps the letters of ISO-8859-9 (Turkish) text decoded as
+// ISO-8859-1 back to Turkish ones.
+var latin1Mojibake = strings.NewReplacer("ý", "ı", "Ý", "İ", "þ", "ş", "Þ", "Ş", "ð", "ğ", "Ð", "Ğ")
+
+// typography normalizes quotes, dashes and invisible characters to the forms
+// downstream systems expect.
+var typography = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`,
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'",
+	"‐", "-", "‑", "-", "‒", "-", "−", "-", "―", "—",
+	"\u00a0", " ", "\u00ad", "", "\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "",
+	// "i" followed by a combining dot is what non-Turkish lower-casing makes
+	// of "İ"; "I" followed by one is composed into "İ" by NFC
+	"i\u0307", "i",
+)
+
+// normalizeTurkish repairs Turkish text: mojibake left by wrong decoding is
+// fixed, quotes and dashes are normalized and the result is NFC normalized.
+func normalizeTurkish(s string) string {
+	if s == "" {
+		return s
+	}
+	if strings.ContainsAny(s, "ÃÅÄâ") {
+		s = utf8Mojibake.Replace(s)
+	}
+	// ý, þ and ð are not Turkish: text holding them and none of the letters
+	// they stand for was decoded with the wrong Latin charset
+	if strings.ContainsAny(s, "ýÝþÞðÐ") && !strings.ContainsAny(s, "ıİşŞğĞ") {
+		s = latin1Mojibake.Replace(s)
+	}
+	return norm.NFC.String(typography.Replace(s))
+}
+
+// normalizeTurkishHTML applies normalizeTurkish to the text and the alt and
+// title attributes of an HTML fragment.
+func normalizeTurkishHTML(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return content
+	}
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return normalizeTurkish(content)
+	}
+	body := findBody(root)
+	if body == nil {
+		return content
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				c.Data = normalizeTurkish(c.Data)
+			case html.ElementNode:
+				for i, a := range c.Attr {
+					if a.Key == "alt" || a.Key == "title" {
+						c.Attr[i].Val = normalizeTurkish(a.Val)
+					}
+				}
+				walk(c)
+			}
+		}
+	}
+	walk(body)
+
+	var buf bytes.Buffer
+	for c := body.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return content
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,35 @@
+package app
+
+import "testing"
+
+func TestNormalizeTurkish(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// UTF-8 read as Windows-1252
+		{"Ä°stanbul'da gÃ¼neÅŸli hava", "İstanbul'da güneşli hava"},
+		{"â€œKarar verildiâ€\u009d dedi", `"Karar verildi" dedi`},
+		// ISO-8859-9 read as ISO-8859-1
+		{"Ýzmir'de yaðýþ bekleniyor", "İzmir'de yağış bekleniyor"},
+		// Circumflexes are Turkish and stay
+		{"Hâlâ kâr ediyor", "Hâlâ kâr ediyor"},
+		// Text that already has Turkish letters is not re-decoded
+		{"Şýk bir ýþýk", "Şýk bir ýþýk"},
+		{"Gelir–gider dengesi ‒ ‘kısa’ vade", "Gelir–gider dengesi - 'kısa' vade"},
+		{"i̇stanbul", "istanbul"},
+		{"İSTANBUL", "İSTANBUL"},
+		{"Bo şluk​ ve­heceleme", "Bo şluk veheceleme"},
+		{"Süt", "Süt"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTurkish(tt.in); got != tt.want {
+			t.Errorf("normalizeTurkish(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+
+	got := normalizeTurkishHTML("<p title=\"“Başlık”\">Ã‡ok â€œgÃ¼zelâ€\u009d</p><img alt=\"Ä°zmir\" src=\"/a.jpg\">")
+	want := `<p title="&#34;Başlık&#34;">Çok &#34;güzel&#34;</p><img alt="İzmir" src="/a.jpg"/>`
+	if got != want {
+		t.Errorf("normalizeTurkishHTML = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// internal/app/updates.go
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// ItemChanges summarizes how an article changed since its previous version.
+type ItemChanges struct {
+	// Fields lists what changed: "title", "content" and "image"
+	Fields            []string `json:"fields"`
+	PreviousTitle     string   `json:"previous_title,omitempty"`
+	WordsAdded        int      `json:"words_added"`
+	WordsRemoved      int      `json:"words_removed"`
+	PreviousUpdatedAt string   `json:"previous_updated_at,omitempty"`
+	Summary           string   `json:"summary"`
+}
+
+// revisedUpstream reports whether the feed gives feedItem an update date
+// other than the one of the archived version stored.
+func revisedUpstream(feedItem *gofeed.Item, stored Item) bool {
+	updated := formatTime(feedItem.UpdatedParsed)
+	return updated != "" && updated != stored.UpdatedAt
+}
+
+// trackRevision compares item, extracted again after an upstream update,
+// with its previous version and marks it updated when its title, text or
+// image changed. A revision changing nothing visible keeps the previous
+// marking.
+func trackRevision(previous, item Item) Item {
+	changes := diffItems(previous, item)
+	if changes == nil {
+		item.Updated, item.Changes, item.Revision = previous.Updated, previous.Changes, previous.Revision
+		return item
+	}
+	changes.PreviousUpdatedAt = previous.UpdatedAt
+	item.Updated = true
+	item.Changes = changes
+	// The first version is revision 1
+	item.Revision = max(previous.Revision, 1) + 1
+	return item
+}
+
+// diffItems returns the changes from previous to item, or nil if there are
+// none.
+func diffItems(previous, item Item) *ItemChanges {
+	changes := &ItemChanges{}
+	var summary []string
+	if previous.Title != item.Title {
+		changes.Fields = append(changes.Fields, "title")
+		changes.PreviousTitle = previous.Title
+		summary = append(summary, "title changed")
+	}
+	changes.WordsAdded, changes.WordsRemoved = wordDelta(cleanHTMLTags(previous.Content), cleanHTMLTags(item.Content))
+	if changes.WordsAdded > 0 || changes.WordsRemoved > 0 {
+		changes.Fields = append(changes.Fields, "content")
+		summary = append(summary, fmt.Sprintf("%d words added, %d removed", changes.WordsAdded, changes.WordsRemoved))
+	}
+	if previous.Image != "" && item.Image != "" && previous.Image != item.Image {
+		changes.Fields = append(changes.Fields, "image")
+		summary = append(summary, "image changed")
+	}
+	if len(changes.Fields) == 0 {
+		return nil
+	}
+	changes.Summary = strings.Join(summary, "; ")
+	return changes
+}
+
+// wordDelta counts the words of after missing from before and the words of
+// before missing from after, each word counted as often as it occurs.
+func wordDelta(before, after string) (added, removed int) {
+	counts := make(map[string]int)
+	for _, w := range strings.Fields(before) {
+		counts[w]++
+	}
+	for _, w := range strings.Fields(after) {
+		counts[w]--
+	}
+	for _, n := range counts {
+		if n > 0 {
+			removed += n
+		} else {
+			added -= n
+		}
+	}
+	return added, removed
+}
+
+// archiveItem archives item, extracted for the feed at feedURL, after
+// comparing it with previous, the version archived before an upstream update.
+func (h *FeedHandler) archiveItem(feedURL string, previous *Item, item Item) Item {
+	if previous != nil {
+		item = trackRevision(*previous, item)
+		if item.Revision > previous.Revision {
+			log.Printf("📝 %s changed (revision %d): %s", item.Link, item.Revision, item.Changes.Summary)
+		}
+	}
+	if h.Archive != nil && item.Link != "" {
+		h.Archive.Put(feedURL, item)
+	}
+	return item
+}
+
+// present returns item as written with p: when re-emitting, updated items
+// get a GUID of their own so readers show them again.
+func (p feedParams) present(item Item) Item {
+	if p.Reemit && item.Updated {
+		item.GUID = fmt.Sprintf("%s#r%d", item.GUID, item.Revision)
+	}
+	return item
+}
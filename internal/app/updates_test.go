@@ -0,0 +1,101 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gofull/internal/extractors"
+	"gofull/internal/extractors/filters"
+)
+
+const updatesFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Updates</title>
+  <entry>
+    <title>%s</title>
+    <link href="https://news.example.com/a/1"/>
+    <id>https://news.example.com/a/1</id>
+    <published>2024-05-01T10:00:00Z</published>
+    <updated>%s</updated>
+  </entry>
+</feed>`
+
+func TestFeedHandlerDetectsUpdates(t *testing.T) {
+	title, updated := "Deprem", "2024-05-01T10:00:00Z"
+	article := "<p>Kandilli bir deprem bildirdi.</p>"
+	extractions := 0
+	registry := extractors.NewRegistry()
+	registry.RegisterDefault(extractorFunc(func(any) (string, []string, error) {
+		extractions++
+		return article, nil, nil
+	}))
+	doer := func(*http.Request) (*http.Response, error) {
+		return respond(http.StatusOK, fmt.Sprintf(updatesFeed, title, updated)), nil
+	}
+	h := NewFeedHandler(nil, doerFunc(doer), registry, filters.NewFilterRegistry(), NewArchive(0), nil)
+	get := func(query string) Item {
+		t.Helper()
+		h.Cache = NewCache(time.Minute, 0)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?url="+url.QueryEscape(testFeedURL)+query, nil))
+		var resp feedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid JSON response: %v\n%s", err, rec.Body)
+		}
+		if len(resp.Items) != 1 {
+			t.Fatalf("got %d items", len(resp.Items))
+		}
+		return resp.Items[0]
+	}
+
+	if item := get(""); item.Updated || item.UpdatedAt != updated {
+		t.Fatalf("first version: updated %v, updated_at %q", item.Updated, item.UpdatedAt)
+	}
+	get("")
+	if extractions != 1 {
+		t.Fatalf("unchanged article extracted %d times, want 1", extractions)
+	}
+
+	// A new update date with the same text is not an update
+	updated = "2024-05-01T11:00:00Z"
+	if item := get(""); item.Updated || extractions != 2 {
+		t.Fatalf("unchanged text: updated %v after %d extractions", item.Updated, extractions)
+	}
+
+	updated = "2024-05-01T12:00:00Z"
+	title = "Deprem: büyüklük 5,1"
+	article = "<p>Kandilli 5,1 büyüklüğünde bir deprem bildirdi.</p>"
+	item := get("")
+	if !item.Updated || item.Revision != 2 || item.Changes == nil {
+		t.Fatalf("changed article: updated %v, revision %d, changes %+v", item.Updated, item.Revision, item.Changes)
+	}
+	c := item.Changes
+	if strings.Join(c.Fields, ",") != "title,content" || c.PreviousTitle != "Deprem" || c.WordsAdded != 2 || c.WordsRemoved != 0 {
+		t.Errorf("changes = %+v", c)
+	}
+	if c.PreviousUpdatedAt != "2024-05-01T11:00:00Z" || c.Summary != "title changed; 2 words added, 0 removed" {
+		t.Errorf("previous_updated_at %q, summary %q", c.PreviousUpdatedAt, c.Summary)
+	}
+
+	// The archived update keeps its marking and is re-emitted on request
+	item = get("&reemit_updated=true")
+	if !item.Updated || item.GUID != extractors.GenerateGUIDFromURL(item.Link)+"#r2" {
+		t.Errorf("re-emitted item: updated %v, guid %q", item.Updated, item.GUID)
+	}
+	if extractions != 3 {
+		t.Errorf("extracted %d times, want 3", extractions)
+	}
+}
+
+func TestWordDelta(t *testing.T) {
+	added, removed := wordDelta("bir iki iki üç", "iki üç üç dört")
+	if added != 2 || removed != 2 {
+		t.Errorf("wordDelta = +%d -%d, want +2 -2", added, removed)
+	}
+}
@@ -0,0 +1,308 @@
+// internal/app/validate.go
+package app
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// maxValidateFeedSize bounds how much of a feed /validate reads.
+const maxValidateFeedSize = 10 << 20
+
+// Severities of validation issues. Errors break /feed or lose items;
+// warnings degrade the output.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// ValidationReport is the result of GET /validate.
+type ValidationReport struct {
+	URL         string `json:"url"`
+	Valid       bool   `json:"valid"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	FeedType    string `json:"feed_type,omitempty"`
+	FeedVersion string `json:"feed_version,omitempty"`
+	Items       int    `json:"items"`
+	Errors      int    `json:"errors"`
+	Warnings    int    `json:"warnings"`
+	// Issues lists the problems found, errors first
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// ValidationIssue is one problem found in a feed.
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	// Item is the 1-based position of the item concerned, if any
+	Item int `json:"item,omitempty"`
+	// Line is the line of the feed document concerned, if known
+	Line int `json:"line,omitempty"`
+}
+
+// xmlEncodingDecl finds the encoding of an XML declaration.
+var xmlEncodingDecl = regexp.MustCompile(`^\s*<\?xml[^>]*encoding\s*=\s*["']([^"']+)["']`)
+
+// feedContentTypes are the content types feeds are commonly served with.
+var feedContentTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/xml":       true,
+	"text/xml":              true,
+	"application/rdf+xml":   true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+// handleValidate serves GET /validate?url=..., a report of the problems in a
+// feed: parse errors, encoding trouble, and items without GUIDs, links or
+// parseable dates. It answers 200 with the report even when the feed is
+// broken; only the URL itself is checked up front.
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	target := strings.TrimSpace(r.URL.Query().Get("url"))
+	if _, apiErr := validateTargetURL("url", target); apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.validateFeed(target))
+}
+
+// validateFeed fetches and checks the feed at target.
+func (s *Server) validateFeed(target string) *ValidationReport {
+	report := &ValidationReport{URL: target, Issues: []ValidationIssue{}}
+	defer report.finish()
+
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		report.add(severityError, "invalid_url", "The URL cannot be requested: "+err.Error(), 0, 0)
+		return report
+	}
+	resp, err := s.feedHandler.client().Do(req)
+	if err != nil {
+		apiErr := upstreamError(target, nil, err)
+		report.add(severityError, apiErr.Code, "The feed could not be fetched ("+apiErr.Message+"). Check that the host is up and reachable from this server.", 0, 0)
+		return report
+	}
+	defer resp.Body.Close()
+	report.Status = resp.StatusCode
+	report.ContentType = resp.Header.Get("Content-Type")
+	if resp.StatusCode >= 400 {
+		report.add(severityError, "http_status", fmt.Sprintf("The server answered %d %s. Check the feed URL; the feed may have moved.", resp.StatusCode, http.StatusText(resp.StatusCode)), 0, 0)
+		return report
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxValidateFeedSize))
+	if err != nil {
+		report.add(severityError, CodeUpstreamUnreachable, "The feed could not be read completely: "+err.Error(), 0, 0)
+		return report
+	}
+
+	report.checkContentType(body)
+	report.checkEncoding(body)
+
+	feed, err := parseFeed(body)
+	if err != nil {
+		report.addParseError(body, err)
+		return report
+	}
+	report.FeedType, report.FeedVersion = feed.FeedType, feed.FeedVersion
+	report.Items = len(feed.Items)
+	report.checkFeed(feed)
+	return report
+}
+
+func (rep *ValidationReport) add(severity, code, message string, item, line int) {
+	rep.Issues = append(rep.Issues, ValidationIssue{Severity: severity, Code: code, Message: message, Item: item, Line: line})
+}
+
+// finish counts the issues and lists errors first.
+func (rep *ValidationReport) finish() {
+	var errs, warnings []ValidationIssue
+	for _, issue := range rep.Issues {
+		if issue.Severity == severityError {
+			errs = append(errs, issue)
+		} else {
+			warnings = append(warnings, issue)
+		}
+	}
+	rep.Errors, rep.Warnings = len(errs), len(warnings)
+	rep.Issues = append(append([]ValidationIssue{}, errs...), warnings...)
+	rep.Valid = rep.Errors == 0
+}
+
+// checkContentType warns about feeds served with a content type feed readers
+// may refuse.
+func (rep *ValidationReport) checkContentType(body []byte) {
+	mediaType, _, _ := mime.ParseMediaType(rep.ContentType)
+	if feedContentTypes[mediaType] {
+		return
+	}
+	if mediaType == "text/html" && looksLikeHTML(body) {
+		return // reported as a parse error
+	}
+	rep.add(severityWarning, "content_type", fmt.Sprintf("The feed is served as %q. Serve it as application/rss+xml, application/atom+xml or application/xml so all readers accept it.", rep.ContentType), 0, 0)
+}
+
+// checkEncoding looks for bytes that are invalid in the declared encoding and
+// for encodings declared differently by the HTTP header and the document.
+func (rep *ValidationReport) checkEncoding(body []byte) {
+	_, params, _ := mime.ParseMediaType(rep.ContentType)
+	headerCharset := strings.ToLower(params["charset"])
+	docCharset := ""
+	if m := xmlEncodingDecl.FindSubmatch(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))); m != nil {
+		docCharset = strings.ToLower(string(m[1]))
+	}
+	rep.Encoding = firstNonEmpty(docCharset, headerCharset, "utf-8")
+
+	if headerCharset != "" && docCharset != "" && !sameCharset(headerCharset, docCharset) {
+		rep.add(severityWarning, "encoding_mismatch", fmt.Sprintf("The HTTP header declares %s but the document declares %s. Readers disagree on which wins; make them match.", headerCharset, docCharset), 0, 0)
+	}
+	if !sameCharset(rep.Encoding, "utf-8") {
+		return
+	}
+	if !utf8.Valid(body) {
+		offset := invalidUTF8Offset(body)
+		rep.add(severityError, "invalid_encoding", fmt.Sprintf("The feed is declared as UTF-8 but contains an invalid byte sequence at byte %d; text around it will be garbled. It is probably %s; fix the declared encoding or the content.", offset, guessLegacyCharset(body[offset])), 0, bytes.Count(body[:offset], []byte("\n"))+1)
+	}
+	if bytes.Contains(body, []byte(string(utf8.RuneError))) {
+		rep.add(severityWarning, "replacement_characters", "The feed contains U+FFFD replacement characters, a sign it was converted from the wrong encoding upstream.", 0, 0)
+	}
+}
+
+// addParseError reports why the feed could not be parsed, pointing at the
+// offending line of XML documents.
+func (rep *ValidationReport) addParseError(body []byte, err error) {
+	if looksLikeHTML(body) {
+		rep.add(severityError, "not_a_feed", "The URL returns an HTML page, not a feed. Use /meta?url=... to find the feeds the site advertises.", 0, 0)
+		return
+	}
+	if errors.Is(err, gofeed.ErrFeedTypeNotDetected) {
+		rep.add(severityError, "not_a_feed", "The document is neither RSS, Atom nor JSON Feed.", 0, 0)
+		return
+	}
+	format := detectFeedFormat(body)
+	if format == inputJSON {
+		rep.add(severityError, "parse_error", "The JSON Feed could not be parsed: "+errors.Unwrap(err).Error(), 0, 0)
+		return
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	for {
+		if _, tokErr := dec.Token(); tokErr != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(tokErr, &syntaxErr) {
+				rep.add(severityError, "parse_error", fmt.Sprintf("The XML is malformed: %s. Unescaped & or < characters in titles and descriptions are the usual cause.", syntaxErr.Msg), 0, syntaxErr.Line)
+				return
+			}
+			break
+		}
+	}
+	rep.add(severityError, "parse_error", fmt.Sprintf("The %s feed could not be parsed: %v", formatName(format), errors.Unwrap(err)), 0, 0)
+}
+
+// checkFeed checks the feed's metadata and items.
+func (rep *ValidationReport) checkFeed(feed *gofeed.Feed) {
+	if strings.TrimSpace(feed.Title) == "" {
+		rep.add(severityWarning, "missing_title", "The feed has no title; readers will show its URL instead.", 0, 0)
+	}
+	if strings.TrimSpace(feed.Link) == "" {
+		rep.add(severityWarning, "missing_link", "The feed does not link to its site, so /meta cannot describe the source.", 0, 0)
+	}
+	if len(feed.Items) == 0 {
+		rep.add(severityWarning, "no_items", "The feed has no items.", 0, 0)
+	}
+
+	links := make(map[string]int)
+	guids := make(map[string]int)
+	for i, item := range feed.Items {
+		n := i + 1
+		switch link := strings.TrimSpace(item.Link); {
+		case link == "":
+			rep.add(severityError, "missing_link", "The item has no link, so its article cannot be extracted.", n, 0)
+		case !isAbsoluteHTTP(link):
+			rep.add(severityError, "invalid_link", fmt.Sprintf("The link %q is not an absolute http(s) URL, so its article cannot be extracted.", link), n, 0)
+		default:
+			if first, ok := links[link]; ok {
+				rep.add(severityWarning, "duplicate_link", fmt.Sprintf("The link %s is also used by item %d; /feed will show the same article twice.", link, first), n, 0)
+			} else {
+				links[link] = n
+			}
+		}
+
+		switch guid := strings.TrimSpace(item.GUID); {
+		case guid == "":
+			rep.add(severityWarning, "missing_guid", "The item has no GUID; readers fall back to the link and show the item again whenever it changes.", n, 0)
+		default:
+			if first, ok := guids[guid]; ok {
+				rep.add(severityError, "duplicate_guid", fmt.Sprintf("The GUID %q is also used by item %d; readers will drop one of them.", guid, first), n, 0)
+			} else {
+				guids[guid] = n
+			}
+		}
+
+		if strings.TrimSpace(item.Title) == "" {
+			rep.add(severityWarning, "missing_title", "The item has no title.", n, 0)
+		}
+		switch {
+		case item.Published != "" && item.PublishedParsed == nil:
+			rep.add(severityWarning, "invalid_date", fmt.Sprintf("The publication date %q cannot be parsed; use RFC 822 (RSS) or RFC 3339 (Atom) dates.", item.Published), n, 0)
+		case item.Updated != "" && item.UpdatedParsed == nil:
+			rep.add(severityWarning, "invalid_date", fmt.Sprintf("The update date %q cannot be parsed; use RFC 822 (RSS) or RFC 3339 (Atom) dates.", item.Updated), n, 0)
+		case item.Published == "" && item.Updated == "":
+			rep.add(severityWarning, "missing_date", "The item has no date; readers cannot order it and Cache-Control falls back to the default.", n, 0)
+		}
+	}
+}
+
+// looksLikeHTML reports whether body is an HTML page.
+func looksLikeHTML(body []byte) bool {
+	head := bytes.ToLower(bytes.TrimSpace(body[:min(len(body), 512)]))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+func isAbsoluteHTTP(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// sameCharset compares charset names ignoring case and dashes.
+func sameCharset(a, b string) bool {
+	norm := func(s string) string { return strings.ReplaceAll(strings.ToLower(s), "-", "") }
+	return norm(a) == norm(b)
+}
+
+// invalidUTF8Offset returns the offset of the first invalid UTF-8 sequence.
+func invalidUTF8Offset(body []byte) int {
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return len(body)
+}
+
+// guessLegacyCharset names the single-byte encoding the first invalid byte
+// most likely comes from; Turkish letters point at Windows-1254.
+func guessLegacyCharset(b byte) string {
+	switch b {
+	case 0xf0, 0xfe, 0xfd, 0xd0, 0xde, 0xdd: // ğ ş ı Ğ Ş İ
+		return "windows-1254 (Turkish)"
+	}
+	return "windows-1252 or ISO-8859-1"
+}
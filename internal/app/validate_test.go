@@ -0,0 +1,84 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func validateBody(t *testing.T, contentType, body string) *ValidationReport {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(upstream.Close)
+	s := &Server{feedHandler: &FeedHandler{Client: upstream.Client()}}
+	return s.validateFeed(upstream.URL + "/rss")
+}
+
+func issueCodes(report *ValidationReport) map[string][]int {
+	codes := make(map[string][]int)
+	for _, issue := range report.Issues {
+		codes[issue.Code] = append(codes[issue.Code], issue.Item)
+	}
+	return codes
+}
+
+func TestValidateFeedItems(t *testing.T) {
+	report := validateBody(t, "application/rss+xml; charset=utf-8", `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title>Test</title><link>https://haber.test/</link>
+<item><title>Bir</title><link>https://haber.test/bir</link><guid>1</guid><pubDate>Mon, 01 Oct 2024 14:00:00 +0300</pubDate></item>
+<item><title>İki</title><link>https://haber.test/bir</link><guid>1</guid><pubDate>dün akşam</pubDate></item>
+<item><title>Üç</title><link>/uc</link></item>
+</channel></rss>`)
+
+	if report.Valid || report.Items != 3 || report.FeedType != "rss" {
+		t.Errorf("valid %v, items %d, type %q", report.Valid, report.Items, report.FeedType)
+	}
+	codes := issueCodes(report)
+	for code, items := range map[string][]int{
+		"duplicate_link": {2},
+		"duplicate_guid": {2},
+		"invalid_date":   {2},
+		"invalid_link":   {3},
+		"missing_guid":   {3},
+		"missing_date":   {3},
+	} {
+		if got := codes[code]; len(got) != len(items) || got[0] != items[0] {
+			t.Errorf("%s reported for items %v, want %v", code, got, items)
+		}
+	}
+	if report.Issues[0].Severity != severityError || report.Issues[len(report.Issues)-1].Severity != severityWarning {
+		t.Error("errors are not listed first")
+	}
+}
+
+func TestValidateFeedEncoding(t *testing.T) {
+	// "Şirket" in Windows-1254 in a feed declared as UTF-8
+	report := validateBody(t, "text/xml; charset=iso-8859-9", "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<rss version=\"2.0\"><channel><title>Test</title>\n<item><title>\xdeirket</title></item></channel></rss>")
+	codes := issueCodes(report)
+	if _, ok := codes["encoding_mismatch"]; !ok {
+		t.Error("header and declaration mismatch not reported")
+	}
+	if _, ok := codes["invalid_encoding"]; !ok {
+		t.Fatalf("invalid UTF-8 not reported: %+v", report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if issue.Code == "invalid_encoding" && issue.Line != 3 {
+			t.Errorf("invalid_encoding on line %d, want 3", issue.Line)
+		}
+	}
+}
+
+func TestValidateFeedParseErrors(t *testing.T) {
+	report := validateBody(t, "application/rss+xml", "<?xml version=\"1.0\"?>\n<rss version=\"2.0\"><channel>\n<title>Test</title>\n<item><title>Kâr & zarar</title></item>\n</channel>")
+	if report.Valid || len(report.Issues) == 0 || report.Issues[0].Code != "parse_error" || report.Issues[0].Line != 4 {
+		t.Fatalf("issues %+v", report.Issues)
+	}
+
+	report = validateBody(t, "text/html", "<!DOCTYPE html><html><head><title>Site</title></head></html>")
+	if codes := issueCodes(report); len(report.Issues) != 1 || codes["not_a_feed"] == nil {
+		t.Errorf("issues %+v", report.Issues)
+	}
+}
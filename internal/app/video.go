@@ -0,0 +1,183 @@
+// internal/app/video.go
+package app
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// videoPaths mark the URLs of video pages. Site filters usually block them;
+// media=include lets them through, with galleries, to be extracted as
+// their video's metadata.
+var videoPaths = []string{"/video/", "/videolar/", "/video-galeri/", "/izle/", "/videos/"}
+
+// Values of the media parameter.
+const (
+	mediaExclude = "exclude"
+	mediaInclude = "include"
+)
+
+// ItemVideo describes the video of a video page.
+type ItemVideo struct {
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// Duration is the length of the video in seconds
+	Duration int `json:"duration,omitempty"`
+	// PlayerURL is the embeddable player, ContentURL the video file or
+	// stream
+	PlayerURL  string `json:"player_url,omitempty"`
+	ContentURL string `json:"content_url,omitempty"`
+	UploadDate string `json:"upload_date,omitempty"`
+}
+
+// isoDurationRegex matches an ISO 8601 duration such as "PT1M30S".
+var isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// isVideoURL reports whether link is a video page.
+func isVideoURL(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	path := strings.ToLower(u.Path)
+	for _, p := range videoPaths {
+		if strings.Contains(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractVideo fetches a video page and returns its video, nil when the
+// page does not describe one.
+func (h *FeedHandler) extractVideo(pageURL string) *ItemVideo {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	doc, ok := h.getDocument(pageURL)
+	if !ok {
+		return nil
+	}
+	return findVideo(doc, base)
+}
+
+// findVideo returns the video a page describes: its JSON-LD VideoObject,
+// completed from the og:video meta tags.
+func findVideo(doc *goquery.Document, base *url.URL) *ItemVideo {
+	video := &ItemVideo{}
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		var data any
+		if json.Unmarshal([]byte(s.Text()), &data) == nil {
+			if obj := jsonLDVideo(data); obj != nil {
+				video = videoFromJSONLD(obj, base)
+				return false
+			}
+		}
+		return true
+	})
+
+	attr := func(selector string) string {
+		v, _ := doc.Find(selector).First().Attr("content")
+		return strings.TrimSpace(v)
+	}
+	video.Title = firstNonEmpty(video.Title, attr(`meta[property="og:title"]`))
+	video.Description = firstNonEmpty(video.Description, attr(`meta[property="og:description"]`), attr(`meta[name="description"]`))
+	video.ThumbnailURL = firstNonEmpty(video.ThumbnailURL, resolveAgainst(base, attr(`meta[property="og:image"]`)))
+	video.PlayerURL = firstNonEmpty(video.PlayerURL, resolveAgainst(base, attr(`meta[name="twitter:player"]`)))
+	video.ContentURL = firstNonEmpty(video.ContentURL, resolveAgainst(base, firstNonEmpty(
+		attr(`meta[property="og:video:secure_url"]`), attr(`meta[property="og:video:url"]`), attr(`meta[property="og:video"]`))))
+	if video.Duration == 0 {
+		video.Duration = videoDuration(attr(`meta[property="video:duration"]`))
+	}
+	if video.PlayerURL == "" && video.ContentURL == "" {
+		return nil
+	}
+	return video
+}
+
+// jsonLDVideo looks for a VideoObject in JSON-LD data, following @graph and
+// nested objects.
+func jsonLDVideo(data any) map[string]any {
+	switch v := data.(type) {
+	case []any:
+		for _, elem := range v {
+			if obj := jsonLDVideo(elem); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		if jsonLDType(v["@type"]) == "VideoObject" {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage", "video"} {
+			if obj := jsonLDVideo(v[key]); obj != nil {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// videoFromJSONLD converts a schema.org VideoObject.
+func videoFromJSONLD(obj map[string]any, base *url.URL) *ItemVideo {
+	str := func(key string) string {
+		switch v := obj[key].(type) {
+		case string:
+			return strings.TrimSpace(html.UnescapeString(v))
+		case []any:
+			if len(v) > 0 {
+				s, _ := v[0].(string)
+				return strings.TrimSpace(s)
+			}
+		case map[string]any:
+			s, _ := v["url"].(string)
+			return strings.TrimSpace(s)
+		}
+		return ""
+	}
+	return &ItemVideo{
+		Title:        str("name"),
+		Description:  str("description"),
+		ThumbnailURL: resolveAgainst(base, str("thumbnailUrl")),
+		Duration:     videoDuration(str("duration")),
+		PlayerURL:    resolveAgainst(base, str("embedUrl")),
+		ContentURL:   resolveAgainst(base, str("contentUrl")),
+		UploadDate:   str("uploadDate"),
+	}
+}
+
+// videoDuration parses a duration given in seconds or in ISO 8601, 0 when
+// it is neither.
+func videoDuration(s string) int {
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return n
+	}
+	m := isoDurationRegex.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(m[1])
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	seconds, _ := strconv.ParseFloat(m[4], 64)
+	return days*86400 + hours*3600 + minutes*60 + int(seconds)
+}
+
+// videoContent is the content of a video item: the video's description and
+// a link to its player.
+func videoContent(video *ItemVideo) string {
+	var b strings.Builder
+	if video.Description != "" {
+		b.WriteString("<p>" + html.EscapeString(video.Description) + "</p>")
+	}
+	b.WriteString(`<p><a href="` + html.EscapeString(firstNonEmpty(video.PlayerURL, video.ContentURL)) + `">Watch the video</a></p>`)
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"gofull/internal/extractors/filters"
+)
+
+const testVideoPage = `<html><head>
+<meta property="og:title" content="Derbi özeti">
+<meta property="og:image" content="/thumbs/derbi.jpg">
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"VideoObject","name":"Derbi özeti","description":"Maçın golleri ve önemli anları","thumbnailUrl":["https://cdn.example.com/derbi.jpg"],"duration":"PT2M30S","embedUrl":"https://player.example.com/embed/42","uploadDate":"2024-01-01T10:00:00+03:00"}</script>
+</head><body></body></html>`
+
+func TestFindVideo(t *testing.T) {
+	base, _ := url.Parse("https://news.example.com/video/derbi-ozeti")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(testVideoPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ItemVideo{
+		Title:        "Derbi özeti",
+		Description:  "Maçın golleri ve önemli anları",
+		ThumbnailURL: "https://cdn.example.com/derbi.jpg",
+		Duration:     150,
+		PlayerURL:    "https://player.example.com/embed/42",
+		UploadDate:   "2024-01-01T10:00:00+03:00",
+	}
+	if got := findVideo(doc, base); got == nil || *got != want {
+		t.Errorf("findVideo = %+v; want %+v", got, want)
+	}
+
+	// Pages without JSON-LD are read from their og:video tags
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<meta property="og:title" content="Haber bülteni">
+<meta property="og:image" content="/thumbs/bulten.jpg">
+<meta property="og:video:secure_url" content="https://cdn.example.com/bulten.mp4">
+<meta property="video:duration" content="95">`))
+	want = ItemVideo{Title: "Haber bülteni", ThumbnailURL: "https://news.example.com/thumbs/bulten.jpg", Duration: 95, ContentURL: "https://cdn.example.com/bulten.mp4"}
+	if got := findVideo(doc, base); got == nil || *got != want {
+		t.Errorf("findVideo from og tags = %+v; want %+v", got, want)
+	}
+
+	// Pages without a video describe none
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<meta property="og:title" content="Yazı">`))
+	if got := findVideo(doc, base); got != nil {
+		t.Errorf("findVideo without a video = %+v; want nil", got)
+	}
+}
+
+func TestVideoDuration(t *testing.T) {
+	for in, want := range map[string]int{"PT2M30S": 150, "PT1H": 3600, "pt45s": 45, "P1DT1S": 86401, "90": 90, "": 0, "iki dakika": 0} {
+		if got := videoDuration(in); got != want {
+			t.Errorf("videoDuration(%q) = %d; want %d", in, got, want)
+		}
+	}
+}
+
+func TestFeedMediaInclude(t *testing.T) {
+	const videoLink = "https://news.example.com/video/derbi-ozeti"
+	feed := `<?xml version="1.0"?><rss version="2.0"><channel><title>Example News</title>
+<item><title>Derbi özeti</title><link>` + videoLink + `</link><pubDate>Mon, 01 Jan 2024 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+	h := newTestFeedHandler(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() == videoLink {
+			return respond(http.StatusOK, testVideoPage), nil
+		}
+		return respond(http.StatusOK, feed), nil
+	}, newFakeClock(), 0)
+	h.FilterReg.Register(filters.URLFilter{Domain: "news.example.com", BlockedPaths: []string{"/video/"}})
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feed?format=json&url="+testFeedURL+query, nil))
+		return rec
+	}
+	if rec := get("&media=all"); rec.Code != http.StatusBadRequest {
+		t.Errorf("media=all: status %d; want 400", rec.Code)
+	}
+	var resp struct{ Items []Item }
+	if err := json.Unmarshal(get("").Body.Bytes(), &resp); err != nil || len(resp.Items) != 0 {
+		t.Errorf("items without media = %+v (%v); want the video filtered out", resp.Items, err)
+	}
+	if err := json.Unmarshal(get("&media=include").Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Video == nil || resp.Items[0].Video.Duration != 150 {
+		t.Fatalf("items = %+v; want the video with its metadata", resp.Items)
+	}
+	item := resp.Items[0]
+	if item.Image != "https://cdn.example.com/derbi.jpg" || !strings.Contains(item.Content, "https://player.example.com/embed/42") {
+		t.Errorf("image %q, content %q; want the thumbnail and a link to the player", item.Image, item.Content)
+	}
+}
@@ -0,0 +1,132 @@
+// internal/app/warm.go
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gofull/internal/extractors"
+)
+
+// warmRequest is the body accepted by POST /admin/warm.
+type warmRequest struct {
+	// Feeds to warm; when empty every feed already known to the archive is used
+	Feeds []string `json:"feeds"`
+	// Limit caps the number of items warmed per feed (0 means all items)
+	Limit int `json:"limit"`
+}
+
+// handleWarm starts a background job extracting every item of the requested
+// feeds into the archive, so the first real requests after a deploy are fast.
+func (s *Server) handleWarm(w http.ResponseWriter, r *http.Request) {
+	var req warmRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, newAPIError(http.StatusBadRequest, CodeInvalidBody,
+				fmt.Sprintf("invalid request body: %v", err)))
+			return
+		}
+	}
+
+	feeds := make([]string, 0, len(req.Feeds))
+	for _, f := range req.Feeds {
+		if f = strings.TrimSpace(f); f != "" {
+			feeds = append(feeds, f)
+		}
+	}
+	if len(feeds) == 0 {
+		feeds = s.archive.Feeds()
+	}
+	if len(feeds) == 0 {
+		writeError(w, r, newAPIError(http.StatusBadRequest, CodeMissingParameter,
+			"no feeds to warm: pass 'feeds' in the request body").with("parameter", "feeds"))
+		return
+	}
+
+	job := s.jobs.Create("warm")
+	go s.runWarmJob(job.ID, feeds, req.Limit)
+
+	log.Printf("🔥 Started warm-up job %s for %d feeds", job.ID, len(feeds))
+	writeJSON(w, http.StatusAccepted, WarmJobAccepted{
+		JobID:     job.ID,
+		StatusURL: "/admin/warm/" + job.ID,
+		Feeds:     len(feeds),
+	})
+}
+
+// handleWarmStatus reports the progress of a warm-up job.
+func (s *Server) handleWarmStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(r.PathValue("id"))
+	if !ok || job.Kind != "warm" {
+		writeError(w, r, newAPIError(http.StatusNotFound, CodeNotFound, "job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// runWarmJob fetches each feed and queues extraction of its new items on the
+// shared worker pool.
+func (s *Server) runWarmJob(jobID string, feeds []string, limit int) {
+	s.jobs.Update(jobID, func(job *Job) { job.Status = JobRunning })
+
+	var wg sync.WaitGroup
+	var errs []string
+	flow := s.feedHandler.extractions().newFlow(backgroundWeight)
+
+	for _, feedURL := range feeds {
+		feed, err := s.feedHandler.fetchFeed(feedURL, false)
+		if err != nil {
+			log.Printf("⚠️  Warm-up could not fetch %s: %v", feedURL, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", feedURL, err))
+			continue
+		}
+
+		queued := 0
+		for _, feedItem := range feed.Items {
+			if limit > 0 && queued >= limit {
+				break
+			}
+			if feedItem.Link == "" || !s.feedHandler.filterSet("").ShouldProcess(feedItem.Link) {
+				continue
+			}
+			queued++
+			s.jobs.Update(jobID, func(job *Job) { job.Total++ })
+
+			// Already archived items need no work
+			if _, ok := s.archive.Get(extractors.GenerateGUIDFromURL(feedItem.Link)); ok {
+				s.jobs.Update(jobID, func(job *Job) { job.Done++ })
+				continue
+			}
+
+			wg.Add(1)
+			s.warmPool.Submit(func() {
+				defer wg.Done()
+				item := s.feedHandler.extract(feedItem, flow)
+				if item.Content == "" || item.partial {
+					s.jobs.Update(jobID, func(job *Job) { job.Failed++ })
+					return
+				}
+				item.attribute(feed, feedURL)
+				s.archive.Put(feedURL, item)
+				s.jobs.Update(jobID, func(job *Job) { job.Done++ })
+			})
+		}
+	}
+
+	wg.Wait()
+
+	s.jobs.Update(jobID, func(job *Job) {
+		job.Status = JobDone
+		if len(errs) > 0 {
+			job.Error = strings.Join(errs, "; ")
+			if job.Total == 0 {
+				job.Status = JobFailed
+			}
+		}
+	})
+	log.Printf("🔥 Warm-up job %s finished", jobID)
+}
@@ -0,0 +1,106 @@
+// internal/app/wayback.go
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// defaultWaybackAPI is the Wayback Machine availability API.
+const defaultWaybackAPI = "https://archive.org/wayback/available"
+
+// deadLinkTimeout bounds the request that checks whether an article is gone.
+const deadLinkTimeout = 15 * time.Second
+
+// waybackAvailability is the response of the availability API.
+type waybackAvailability struct {
+	ArchivedSnapshots struct {
+		Closest struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// waybackTimestamp finds the timestamp in a snapshot URL, after which "id_"
+// selects the original page without the Wayback toolbar.
+var waybackTimestamp = regexp.MustCompile(`/web/(\d{14})/`)
+
+// linkIsDead reports whether the article is gone: it answers 404 or 410, or
+// does not answer in time.
+func (h *FeedHandler) linkIsDead(link string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), deadLinkTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		var netErr net.Error
+		return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone
+}
+
+// waybackSnapshot returns the most recent Wayback Machine snapshot of link.
+func (h *FeedHandler) waybackSnapshot(link string) (snapshotURL string, at time.Time, ok bool) {
+	api := h.WaybackAPI
+	if api == "" {
+		api = defaultWaybackAPI
+	}
+	req, err := http.NewRequest(http.MethodGet, api+"?url="+url.QueryEscape(link), nil)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	resp, err := h.client().Do(req)
+	if err != nil {
+		log.Printf("⚠️  Wayback lookup failed for %s: %v", link, err)
+		return "", time.Time{}, false
+	}
+	defer resp.Body.Close()
+
+	var avail waybackAvailability
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&avail) != nil {
+		return "", time.Time{}, false
+	}
+	closest := avail.ArchivedSnapshots.Closest
+	if !closest.Available || closest.URL == "" {
+		return "", time.Time{}, false
+	}
+	at, _ = time.Parse("20060102150405", closest.Timestamp)
+	return waybackTimestamp.ReplaceAllString(closest.URL, "/web/${1}id_/"), at, true
+}
+
+// extractFromWayback extracts a dead article from its latest snapshot using
+// the extractor of the original site.
+func (h *FeedHandler) extractFromWayback(link string) (content, image, snapshotURL string, at time.Time) {
+	if !h.linkIsDead(link) {
+		return "", "", "", time.Time{}
+	}
+	snapshotURL, at, ok := h.waybackSnapshot(link)
+	if !ok {
+		log.Printf("🪦 %s is gone and has no Wayback snapshot", link)
+		return "", "", "", time.Time{}
+	}
+
+	extracted, images, err := safeExtract(h.Registry.ForURL(link), map[string]interface{}{"link": snapshotURL})
+	if err != nil || textLength(extracted) == 0 {
+		log.Printf("⚠️  Could not extract Wayback snapshot %s: %v", snapshotURL, err)
+		return "", "", "", time.Time{}
+	}
+	if len(images) > 0 {
+		image = images[0]
+	}
+	log.Printf("🗄️  Extracted %s from Wayback snapshot %s", link, snapshotURL)
+	return cleanHTMLContent(extracted), image, snapshotURL, at
+}
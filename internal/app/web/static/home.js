@@ -0,0 +1,41 @@
+// Builds the /feed request of the form as it is filled in. Fields left at
+// their default are left out, and the item type selectors become the
+// include_types and exclude_types parameters.
+(function () {
+    var form = document.getElementById("builder");
+    var output = document.getElementById("request");
+
+    function query() {
+        var params = new URLSearchParams();
+        var include = [], exclude = [];
+        new FormData(form).forEach(function (value, name) {
+            if (value === "") {
+                return;
+            }
+            if (name.indexOf("type:") === 0) {
+                (value === "include" ? include : exclude).push(name.slice(5));
+                return;
+            }
+            params.append(name, value);
+        });
+        if (include.length) {
+            params.set("include_types", include.join(","));
+        }
+        if (exclude.length) {
+            params.set("exclude_types", exclude.join(","));
+        }
+        return params;
+    }
+
+    function update() {
+        output.textContent = "/feed?" + query().toString();
+    }
+
+    form.addEventListener("input", update);
+    form.addEventListener("change", update);
+    form.addEventListener("submit", function (event) {
+        event.preventDefault();
+        window.location.href = "/feed?" + query().toString();
+    });
+    update();
+})();
@@ -0,0 +1,128 @@
+// Extracts the article of the form through /playground/extract and shows
+// the result: the extracted content in a sandboxed frame, the images chosen
+// and the trace of the extraction. The compare button shows how the
+// article's extractor does against plain readability instead.
+(function () {
+    var form = document.getElementById("playground");
+    var status = document.getElementById("status");
+    var result = document.getElementById("result");
+    var comparison = document.getElementById("comparison");
+
+    function text(tag, value) {
+        var el = document.createElement(tag);
+        el.textContent = value;
+        return el;
+    }
+
+    function summarize(data) {
+        var summary = document.getElementById("summary");
+        summary.replaceChildren();
+        var rows = [["Extractor", data.extractor], ["Title", data.item.title]];
+        var extraction = data.item.extraction;
+        if (extraction) {
+            rows.push(["Method", extraction.method]);
+            rows.push(["Confidence", extraction.confidence.toFixed(2)]);
+            rows.push(["Duration", extraction.duration_ms + " ms"]);
+        }
+        if (data.filter) {
+            rows.push(["Filtered by", data.filter]);
+        }
+        rows.forEach(function (row) {
+            summary.append(text("dt", row[0]), text("dd", row[1] || "—"));
+        });
+    }
+
+    function show(data) {
+        summarize(data);
+        document.getElementById("content").srcdoc = data.item.content || "";
+
+        var images = document.getElementById("images");
+        images.replaceChildren();
+        (data.item.images || []).forEach(function (image) {
+            var figure = document.createElement("figure");
+            var img = document.createElement("img");
+            img.src = image.url;
+            img.alt = image.caption || "";
+            figure.append(img);
+            var caption = [image.caption, image.credit].filter(Boolean).join(" — ");
+            if (caption) {
+                figure.append(text("figcaption", caption));
+            }
+            images.append(figure);
+        });
+        if (!images.children.length) {
+            images.append(text("p", "No images chosen."));
+        }
+
+        var trace = document.getElementById("trace");
+        trace.replaceChildren();
+        (data.trace || []).forEach(function (step) {
+            trace.append(text("li", step));
+        });
+        result.hidden = false;
+    }
+
+    function list(id, paragraphs) {
+        var ul = document.getElementById(id);
+        ul.replaceChildren();
+        paragraphs.forEach(function (paragraph) {
+            ul.append(text("li", paragraph));
+        });
+        if (!paragraphs.length) {
+            ul.append(text("li", "Nothing."));
+        }
+    }
+
+    function compare(data) {
+        var summary = document.getElementById("comparison-summary");
+        summary.replaceChildren();
+        var delta = (data.word_delta > 0 ? "+" : "") + data.word_delta;
+        [
+            ["Extractor", data.extractor],
+            ["Extractor words", data.extractor_error ? "failed: " + data.extractor_error : data.extractor_words],
+            ["Readability words", data.readability_error ? "failed: " + data.readability_error : data.readability_words],
+            ["Difference", delta]
+        ].forEach(function (row) {
+            summary.append(text("dt", row[0]), text("dd", row[1]));
+        });
+        list("missing", data.missing_paragraphs);
+        list("extra", data.extra_paragraphs);
+        comparison.hidden = false;
+    }
+
+    function run(endpoint, message, render) {
+        if (!form.reportValidity()) {
+            return;
+        }
+        var url = new FormData(form).get("url");
+        status.className = "";
+        status.textContent = message;
+        result.hidden = true;
+        comparison.hidden = true;
+        fetch(endpoint + "?url=" + encodeURIComponent(url))
+            .then(function (response) {
+                return response.json().then(function (data) {
+                    if (!response.ok) {
+                        throw new Error(data.message || response.statusText);
+                    }
+                    return data;
+                });
+            })
+            .then(function (data) {
+                status.textContent = "";
+                render(data);
+            })
+            .catch(function (err) {
+                status.className = "error";
+                status.textContent = err.message;
+            });
+    }
+
+    form.addEventListener("submit", function (event) {
+        event.preventDefault();
+        run("/playground/extract", "Extracting…", show);
+    });
+    document.getElementById("compare").addEventListener("click", function () {
+        run("/playground/compare", "Comparing…", compare);
+    });
+})();
@@ -0,0 +1,40 @@
+// internal/app/workerpool.go
+package app
+
+import "sync"
+
+// WorkerPool runs submitted tasks on a fixed number of goroutines.
+type WorkerPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+}
+
+// NewWorkerPool starts a pool with the given number of workers. Submit blocks
+// once queueSize tasks are waiting.
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &WorkerPool{tasks: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit queues a task for execution.
+func (p *WorkerPool) Submit(task func()) {
+	p.tasks <- task
+}
+
+// Close stops accepting tasks and waits for queued tasks to finish.
+func (p *WorkerPool) Close() {
+	close(p.tasks)
+	p.wg.Wait()
+}
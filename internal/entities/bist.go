@@ -0,0 +1,52 @@
+// internal/entities/bist.go
+package entities
+
+// BIST lists widely covered Borsa Istanbul companies. Aliases avoid generic
+// words, e.g. "Garanti" alone also means "guarantee".
+var BIST = []Company{
+	{Ticker: "AEFES", Name: "Anadolu Efes"},
+	{Ticker: "AKBNK", Name: "Akbank"},
+	{Ticker: "AKSEN", Name: "Aksa Enerji"},
+	{Ticker: "ALARK", Name: "Alarko Holding"},
+	{Ticker: "ARCLK", Name: "Arçelik"},
+	{Ticker: "ASELS", Name: "Aselsan"},
+	{Ticker: "ASTOR", Name: "Astor Enerji"},
+	{Ticker: "BIMAS", Name: "BİM", Aliases: []string{"BİM Birleşik Mağazalar"}},
+	{Ticker: "CCOLA", Name: "Coca-Cola İçecek"},
+	{Ticker: "DOAS", Name: "Doğuş Otomotiv"},
+	{Ticker: "DOHOL", Name: "Doğan Holding"},
+	{Ticker: "EKGYO", Name: "Emlak Konut", Aliases: []string{"Emlak Konut GYO"}},
+	{Ticker: "ENJSA", Name: "Enerjisa"},
+	{Ticker: "ENKAI", Name: "Enka İnşaat"},
+	{Ticker: "EREGL", Name: "Ereğli Demir Çelik", Aliases: []string{"Erdemir"}},
+	{Ticker: "FROTO", Name: "Ford Otosan"},
+	{Ticker: "GARAN", Name: "Garanti BBVA", Aliases: []string{"Garanti Bankası"}},
+	{Ticker: "GUBRF", Name: "Gübretaş", Aliases: []string{"Gübre Fabrikaları"}},
+	{Ticker: "HALKB", Name: "Halkbank", Aliases: []string{"Halk Bankası"}},
+	{Ticker: "HEKTS", Name: "Hektaş"},
+	{Ticker: "ISCTR", Name: "İş Bankası", Aliases: []string{"Türkiye İş Bankası", "İşbank"}},
+	{Ticker: "KCHOL", Name: "Koç Holding"},
+	{Ticker: "KONTR", Name: "Kontrolmatik"},
+	{Ticker: "KOZAL", Name: "Koza Altın"},
+	{Ticker: "KRDMD", Name: "Kardemir"},
+	{Ticker: "MGROS", Name: "Migros"},
+	{Ticker: "ODAS", Name: "Odaş Elektrik"},
+	{Ticker: "OTKAR", Name: "Otokar"},
+	{Ticker: "PETKM", Name: "Petkim"},
+	{Ticker: "PGSUS", Name: "Pegasus", Aliases: []string{"Pegasus Hava Yolları"}},
+	{Ticker: "SAHOL", Name: "Sabancı Holding"},
+	{Ticker: "SASA", Name: "SASA Polyester"},
+	{Ticker: "SISE", Name: "Şişecam"},
+	{Ticker: "TAVHL", Name: "TAV Havalimanları"},
+	{Ticker: "TCELL", Name: "Turkcell"},
+	{Ticker: "THYAO", Name: "Türk Hava Yolları", Aliases: []string{"THY"}},
+	{Ticker: "TKFEN", Name: "Tekfen Holding"},
+	{Ticker: "TOASO", Name: "Tofaş"},
+	{Ticker: "TSKB", Name: "TSKB", Aliases: []string{"Türkiye Sınai Kalkınma Bankası"}},
+	{Ticker: "TTKOM", Name: "Türk Telekom"},
+	{Ticker: "TUPRS", Name: "Tüpraş"},
+	{Ticker: "ULKER", Name: "Ülker"},
+	{Ticker: "VAKBN", Name: "VakıfBank", Aliases: []string{"Vakıflar Bankası"}},
+	{Ticker: "VESTL", Name: "Vestel"},
+	{Ticker: "YKBNK", Name: "Yapı Kredi", Aliases: []string{"Yapı Kredi Bankası"}},
+}
@@ -0,0 +1,108 @@
+// internal/entities/entities.go
+package entities
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Company is a listed company and the names it is mentioned by.
+type Company struct {
+	Ticker string
+	Name   string
+	// Aliases are other names the company appears under in the press
+	Aliases []string
+}
+
+// Entities are the companies and ticker symbols mentioned in a text.
+type Entities struct {
+	Companies []string
+	Tickers   []string
+}
+
+// Recognizer finds mentions of known companies and their ticker symbols.
+type Recognizer struct {
+	byTicker map[string]*Company
+	names    []companyName
+}
+
+type companyName struct {
+	name    string // lowercased
+	company *Company
+}
+
+// tickerPattern matches upper case words that may be BIST tickers.
+var tickerPattern = regexp.MustCompile(`\b[A-Z]{4,5}\b`)
+
+// NewRecognizer creates a Recognizer for the given companies.
+func NewRecognizer(companies []Company) *Recognizer {
+	r := &Recognizer{byTicker: make(map[string]*Company, len(companies))}
+	for i := range companies {
+		c := &companies[i]
+		r.byTicker[c.Ticker] = c
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			r.names = append(r.names, companyName{name: lower(name), company: c})
+		}
+	}
+	// Try longer names first so "Türkiye İş Bankası" wins over "İş Bankası"
+	sort.Slice(r.names, func(i, j int) bool { return len(r.names[i].name) > len(r.names[j].name) })
+	return r
+}
+
+// Recognize returns the companies named in text and the tickers of those
+// companies together with any known ticker symbols written out.
+func (r *Recognizer) Recognize(text string) Entities {
+	found := make(map[*Company]bool)
+
+	for _, ticker := range tickerPattern.FindAllString(text, -1) {
+		if c, ok := r.byTicker[ticker]; ok {
+			found[c] = true
+		}
+	}
+
+	lowered := lower(text)
+	for _, n := range r.names {
+		if !found[n.company] && containsWord(lowered, n.name) {
+			found[n.company] = true
+		}
+	}
+
+	var e Entities
+	for c := range found {
+		e.Companies = append(e.Companies, c.Name)
+		e.Tickers = append(e.Tickers, c.Ticker)
+	}
+	sort.Strings(e.Companies)
+	sort.Strings(e.Tickers)
+	return e
+}
+
+func lower(s string) string {
+	return strings.ToLowerSpecial(unicode.TurkishCase, s)
+}
+
+// containsWord reports whether text contains word as a whole word. A word may
+// be followed by an apostrophe and a case suffix, as in "Aselsan'ın".
+func containsWord(text, word string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
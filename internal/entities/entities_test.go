@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecognize(t *testing.T) {
+	r := NewRecognizer(BIST)
+	text := `Türk Hava Yolları'nın yolcu sayısı arttı. Aselsan'ın yeni sözleşmesi ve
+TUPRS hisselerindeki yükseliş endeksi destekledi. Garantiler sürecek.`
+
+	got := r.Recognize(text)
+	if want := []string{"Aselsan", "Tüpraş", "Türk Hava Yolları"}; !slices.Equal(got.Companies, want) {
+		t.Errorf("companies = %q; want %q", got.Companies, want)
+	}
+	if want := []string{"ASELS", "THYAO", "TUPRS"}; !slices.Equal(got.Tickers, want) {
+		t.Errorf("tickers = %q; want %q", got.Tickers, want)
+	}
+}
+
+func TestRecognizeWholeWords(t *testing.T) {
+	r := NewRecognizer(BIST)
+	// "Migrosa" is not "Migros", and unknown upper case words are no tickers
+	got := r.Recognize("MIGROSA benzer isim, ABCDE ve BIST 100 endeksi")
+	if len(got.Companies) != 0 || len(got.Tickers) != 0 {
+		t.Errorf("Recognize = %+v; want nothing", got)
+	}
+}
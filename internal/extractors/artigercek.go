@@ -6,7 +6,6 @@ import (
 	"html"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -68,41 +67,19 @@ func (e *ArtigercekExtractor) Extract(input any) (string, []string, error) {
 
 // extractFromURL fetches the URL and extracts content.
 func (e *ArtigercekExtractor) extractFromURL(url string) (string, []string, error) {
-	// Skip processing for filtered URLs
-	filteredPrefixes := []string{
-		"https://www.artigercek.com/video/",
-		"https://www.artigercek.com/galeri/",
-		"https://www.artigercek.com/foto-galeri/",
-		"https://www.artigercek.com/yazarlar/",
-		"https://www.artigercek.com/kose-yazilari/",
-		"https://www.artigercek.com/roportaj/",
-		"https://artigercek.com/video/",
-		"https://artigercek.com/galeri/",
-		"https://artigercek.com/foto-galeri/",
-		"https://artigercek.com/yazarlar/",
-		"https://artigercek.com/kose-yazilari/",
-		"https://artigercek.com/roportaj/",
-	}
-
-	for _, prefix := range filteredPrefixes {
-		if strings.HasPrefix(url, prefix) {
-			return "", nil, fmt.Errorf("URL is in filtered list: %s", url)
-		}
-	}
-
 	// Only process article URLs - be more flexible with URL patterns
 	if !strings.Contains(url, "artigercek.com/") {
-		return "", nil, fmt.Errorf("not an Artigercek article URL: %s", url)
+		return "", nil, fmt.Errorf("%w: %s is not an Artigercek article", ErrFiltered, url)
 	}
 
 	resp, err := e.httpClient.Get(url)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	content, images, err := e.extractFromHTML(resp.Body)
@@ -125,7 +102,7 @@ func (e *ArtigercekExtractor) extractFromHTML(reader io.Reader) (string, []strin
 	sel := doc.Find("div.content-text[property=\"articleBody\"]")
 	if sel.Length() > 0 {
 		// Remove ad scripts and unwanted elements
-		sel.Find("script, style, iframe, noscript, .adpro, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info, .category-info").Remove()
+		sel.FindMatcher(artigercekClutterSel).Remove()
 		
 		// Get the clean HTML
 		html, _ := sel.Html()
@@ -149,7 +126,7 @@ func (e *ArtigercekExtractor) extractFromHTML(reader io.Reader) (string, []strin
 		for _, selector := range selectors {
 			doc.Find(selector).Each(func(i int, s *goquery.Selection) {
 				// Remove unwanted elements
-				s.Find("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info, .category-info").Remove()
+				s.FindMatcher(artigercekFallback).Remove()
 				
 				// Get the clean HTML
 				html, _ := s.Html()
@@ -218,16 +195,13 @@ func (e *ArtigercekExtractor) cleanContent(content string) string {
 	content = strings.ReplaceAll(content, "]]>", "")
 
 	// Remove ad scripts specifically
-	adScriptRegex := regexp.MustCompile(`(?s)<script[^>]*>.*?</script>`)
-	content = adScriptRegex.ReplaceAllString(content, "")
+	content = scriptBlockRegex.ReplaceAllString(content, "")
 
 	// Remove ad containers
-	adContainerRegex := regexp.MustCompile(`(?s)<div[^>]*class="[^"]*adpro[^"]*"[^>]*>.*?</div>`)
-	content = adContainerRegex.ReplaceAllString(content, "")
+	content = adproContainerRegex.ReplaceAllString(content, "")
 
 	// Remove all HTML tags
-	re := regexp.MustCompile(`<[^>]*>`)
-	content = re.ReplaceAllString(content, "")
+	content = htmlTagRegex.ReplaceAllString(content, "")
 
 	// Remove "Artı Gerçek-" prefix from the beginning
 	content = strings.TrimPrefix(content, "Artı Gerçek-")
@@ -288,14 +262,6 @@ func (e *ArtigercekExtractor) cleanContent(content string) string {
 	)
 	content = replacer.Replace(content)
 
-	// Replace "(Haber Merkezi)" with "Brief.tr" before HTML entity decoding
-	content = strings.Replace(content, "&#34; (Haber Merkezi)", "Brief.tr", -1)
-	content = strings.Replace(content, "\" (Haber Merkezi)", "Brief.tr", -1)
-	content = strings.Replace(content, " (Haber Merkezi)", "Brief.tr", -1)
-	content = strings.Replace(content, "(Haber Merkezi)", "Brief.tr", -1)
-	content = strings.Replace(content, " Haber Merkezi", "Brief.tr", -1)
-	content = strings.Replace(content, "Haber Merkezi", "Brief.tr", -1)
-
 	// Use html.UnescapeString to decode any remaining HTML entities
 	content = html.UnescapeString(content)
 
@@ -371,7 +337,7 @@ func (e *ArtigercekExtractor) extractImagesFromMeta(doc *goquery.Document) []str
 					url = html.UnescapeString(url)
 					
 					if !strings.HasPrefix(url, "http") && !strings.HasPrefix(url, "//") {
-						url = "https://www.artigercek.com" + strings.TrimLeft(url, "/")
+						url = "https://www.artigercek.com/" + strings.TrimLeft(url, "/")
 					} else if strings.HasPrefix(url, "//") {
 						url = "https:" + url
 					}
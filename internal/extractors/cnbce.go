@@ -6,7 +6,6 @@ import (
 	"html"
 	"io"
 	"net/http"
-	"net/url"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -28,97 +27,22 @@ func NewCNBCEExtractor(client *http.Client) *CNBCEExtractor {
 	}
 }
 
-// isFilteredURL checks if the URL matches any of the filtered patterns
-func (c *CNBCEExtractor) isFilteredURL(inputURL string) bool {
-	// Parse the URL
-	parsedURL, err := url.Parse(inputURL)
-	if err != nil {
-		// If parsing fails, it's likely not a valid URL to process anyway,
-		// but for filtering logic, we might consider it not filtered.
-		// Or, you might want to log this error.
-		// Returning false here means a malformed URL is not filtered.
-		return false
-	}
-
-	// Normalize Host (Domain) to lowercase
-	normalizedHost := strings.ToLower(parsedURL.Host)
-
-	// Normalize Path: Ensure it starts with /, and handle trailing slash if necessary for specific checks
-	// The path from url.Parse is already in a standard format.
-	path := parsedURL.Path
-
-	// Define domains to check against (normalize once)
-	allowedDomains := []string{"cnbce.com", "www.cnbce.com"} // Add other variations if needed
-	hostMatched := false
-	for _, domain := range allowedDomains {
-		if normalizedHost == domain { // Exact match for host
-			hostMatched = true
-			break
-		}
-	}
-
-	if !hostMatched {
-		return false // Not a cnbce.com domain, not filtered
-	}
-
-	// If it's a cnbce.com domain, check the path
-	// Define path prefixes to filter
-	filteredPathPrefixes := []string{
-		"/haberler",
-		"/tv",
-		"/art-e",
-		"/gundem",
-		"/son-dakika",
-		// Add other prefixes as needed
-	}
-
-	for _, prefix := range filteredPathPrefixes {
-		// Check if the path starts with the filtered prefix.
-		// This correctly handles /haberler, /haberler/, /haberler/some-article, etc.
-		if strings.HasPrefix(path, prefix) {
-			return true
-		}
-	}
-
-	// Specific exact path matches could be added here if needed
-	// Example: if path == "/some-specific-page" { return true }
-
-	return false
-}
-
 // Extract implements the Extractor interface for cnbce.com URLs.
 func (c *CNBCEExtractor) Extract(input any) (string, []string, error) {
 	switch v := input.(type) {
 	case string:
-		// Input is a URL, check if it's filtered
-		if c.isFilteredURL(v) {
-			return "", nil, fmt.Errorf("URL is in filtered list: %s", v)
-		}
+		// Input is a URL, fetch and extract content
 		return c.extractFromURL(v)
 
 	case map[string]string:
 		// Handle map[string]string with "html" key
 		if htmlContent, ok := v["html"]; ok {
-			// Check if URL is provided in the map and if it's filtered
-			if url, exists := v["url"]; exists && c.isFilteredURL(url) {
-				return "", nil, fmt.Errorf("URL is in filtered list: %s", url)
-			}
-			if link, exists := v["link"]; exists && c.isFilteredURL(link) {
-				return "", nil, fmt.Errorf("URL is in filtered list: %s", link)
-			}
 			return c.extractFromHTML(htmlContent)
 		}
 
 	case map[string]interface{}:
 		// Handle map[string]interface{} with "html" key
 		if htmlContent, ok := v["html"].(string); ok {
-			// Check if URL is provided in the map and if it's filtered
-			if url, exists := v["url"].(string); exists && c.isFilteredURL(url) {
-				return "", nil, fmt.Errorf("URL is in filtered list: %s", url)
-			}
-			if link, exists := v["link"].(string); exists && c.isFilteredURL(link) {
-				return "", nil, fmt.Errorf("URL is in filtered list: %s", link)
-			}
 			return c.extractFromHTML(htmlContent)
 		}
 		// If no html key, try to get URL from common fields
@@ -148,12 +72,12 @@ func (c *CNBCEExtractor) extractFromURL(articleURL string) (string, []string, er
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -193,7 +117,7 @@ func (c *CNBCEExtractor) extractFromHTML(htmlContent string) (string, []string,
 			img = strings.TrimSpace(img)
 			if img != "" {
 				if !strings.HasPrefix(img, "http") && !strings.HasPrefix(img, "//") {
-					img = "https://www.cnbce.com" + strings.TrimLeft(img, "/")
+					img = "https://www.cnbce.com/" + strings.TrimLeft(img, "/")
 				} else if strings.HasPrefix(img, "//") {
 					img = "https:" + img
 				}
@@ -260,7 +184,7 @@ func (c *CNBCEExtractor) extractFromHTML(htmlContent string) (string, []string,
 	// If no meta tag image found, try to find it in the content
 	if len(images) == 0 {
 		// Look for images in the content area
-		contentDiv.Find("img").Each(func(i int, s *goquery.Selection) {
+		contentDiv.FindMatcher(imgSel).Each(func(i int, s *goquery.Selection) {
 			if src, exists := s.Attr("src"); exists && src != "" {
 				src = strings.TrimSpace(src)
 				if src == "" {
@@ -271,7 +195,7 @@ func (c *CNBCEExtractor) extractFromHTML(htmlContent string) (string, []string,
 				if strings.HasPrefix(src, "//") {
 					src = "https:" + src
 				} else if !strings.HasPrefix(src, "http") {
-					src = "https://www.cnbce.com" + strings.TrimLeft(src, "/")
+					src = "https://www.cnbce.com/" + strings.TrimLeft(src, "/")
 				}
 
 				// Skip small images and icons
@@ -287,7 +211,7 @@ func (c *CNBCEExtractor) extractFromHTML(htmlContent string) (string, []string,
 	}
 
 	// Clean up the content
-	contentDiv.Find(`script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author, .date`).Remove()
+	contentDiv.FindMatcher(articleClutterSel).Remove()
 
 	// Get the HTML content
 	content, err := contentDiv.Html()
@@ -2,6 +2,7 @@
 package extractors
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -16,6 +17,9 @@ import (
 	"github.com/go-shiori/go-readability"
 )
 
+// DefaultUserAgent is the User-Agent the default extractor sends.
+const DefaultUserAgent = "Mozilla/5.0 (compatible; GoFullFeedBot/1.1; +https://gofull.app/bot)"
+
 // DefaultExtractor uses go-readability primarily and goquery as a fallback.
 type DefaultExtractor struct {
 	httpClient *http.Client
@@ -32,10 +36,15 @@ func NewDefaultExtractor(client *http.Client) *DefaultExtractor {
 	}
 	return &DefaultExtractor{
 		httpClient: client,
-		userAgent:  "Mozilla/5.0 (compatible; GoFullFeedBot/1.1; +https://gofull.app/bot)",
+		userAgent:  DefaultUserAgent,
 	}
 }
 
+// SetUserAgent sets the User-Agent sent fetching pages.
+func (d *DefaultExtractor) SetUserAgent(userAgent string) {
+	d.userAgent = userAgent
+}
+
 // Extract tries to extract readable HTML and image URLs from a URL or raw HTML string.
 func (d *DefaultExtractor) Extract(input any) (string, []string, error) {
 	switch v := input.(type) {
@@ -70,18 +79,11 @@ func (d *DefaultExtractor) Extract(input any) (string, []string, error) {
 }
 
 func (d *DefaultExtractor) extractFromURL(articleURL string) (string, []string, error) {
-	// First: try go-readability
-	doc, err := readability.FromURL(articleURL, 15*time.Second)
-	if err == nil && strings.TrimSpace(doc.Content) != "" {
-		// Try to get meta tag images first, then fall back to content images
-		imgUrls := extractImagesFromMetaTags(doc.Content)
-		if len(imgUrls) == 0 {
-			imgUrls = extractImagesFromHTMLWithBase(doc.Content, articleURL)
-		}
-		return sanitizeHTML(doc.Content), imgUrls, nil
+	parsedURL, err := url.Parse(articleURL)
+	if err != nil {
+		return "", nil, err
 	}
 
-	// Second: manual fetch and goquery fallback
 	req, err := http.NewRequestWithContext(context.Background(), "GET", articleURL, nil)
 	if err != nil {
 		return "", nil, err
@@ -90,18 +92,32 @@ func (d *DefaultExtractor) extractFromURL(articleURL string) (string, []string,
 
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("unexpected HTTP status %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", nil, err
 	}
+
+	// First: try go-readability
+	doc, err := readability.FromReader(bytes.NewReader(bodyBytes), parsedURL)
+	if err == nil && strings.TrimSpace(doc.Content) != "" {
+		// Images are scored on the whole page: readability's content has
+		// neither the meta tags nor the page layout
+		var imgUrls []string
+		if page, err := goquery.NewDocumentFromReader(bytes.NewReader(bodyBytes)); err == nil {
+			imgUrls = LeadImages(page, articleURL)
+		}
+		return sanitizeHTML(doc.Content), imgUrls, nil
+	}
+
+	// Second: goquery fallback on the same page
 	return d.extractFromHTMLWithBase(string(bodyBytes), articleURL)
 }
 
@@ -117,73 +133,10 @@ func (d *DefaultExtractor) extractFromHTMLWithBase(body, baseURL string) (string
 		return "", nil, err
 	}
 
-	// First, try to get the main image from meta tags (most reliable)
-	var images []string
-
-	// Try to get the main image from meta tags in order of preference
-	metaSelectors := []struct {
-		selector string
-		attr     string
-	}{
-		{`meta[property="og:image"]`, "content"},
-		{`meta[name="twitter:image"]`, "content"},
-		{`link[rel="image_src"]`, "href"},
-		{`meta[property="og:image:url"]`, "content"},
-		{`meta[name="twitter:image:src"]`, "content"},
-		{`meta[property="og:image:secure_url"]`, "content"},
-	}
-
-	for _, meta := range metaSelectors {
-		if img, exists := doc.Find(meta.selector).First().Attr(meta.attr); exists && img != "" {
-			img = strings.TrimSpace(img)
-			if img != "" {
-				images = []string{img} // We found our main image
-				break
-			}
-		}
-	}
-
-	// If no meta tag image found, try to find it in the content
-	if len(images) == 0 {
-		// Look for figure with post-image class first, then other common patterns
-		// Add cnbce.com specific selectors
-		selectors := "figure.post-image img, .post-image img, figure img, img, .article-image img, .entry-thumbnail img, .wp-post-image, .post-thumbnail img, .article-header-image img, .featured-image img, .post-featured-image img, .td-post-featured-image img"
-		
-		doc.Find(selectors).Each(func(i int, s *goquery.Selection) {
-			if src, exists := s.Attr("src"); exists && src != "" {
-				src = strings.TrimSpace(src)
-				if src == "" {
-					return
-				}
-
-				// Handle different URL formats
-				if strings.HasPrefix(src, "//") {
-					src = "https:" + src
-				} else if !strings.HasPrefix(src, "http") {
-					if baseURL != "" {
-						base, err := url.Parse(baseURL)
-						if err == nil {
-							src = base.Scheme + "://" + base.Host + "/" + strings.TrimLeft(src, "/")
-						}
-					} else {
-						src = "https://" + strings.TrimLeft(src, "/")
-					}
-				}
-
-				// Skip small images and icons
-				lowerSrc := strings.ToLower(src)
-				if !strings.HasSuffix(lowerSrc, ".svg") &&
-					!strings.Contains(lowerSrc, "icon") &&
-					!strings.Contains(lowerSrc, "logo") &&
-					!contains(images, src) {
-					images = append(images, src)
-				}
-			}
-		})
-	}
+	images := LeadImages(doc, baseURL)
 
 	// Get the main content
-	contentDiv := doc.Find(`article, main, [role="main"], [itemprop="articleBody"], .post-content, .entry-content, .article-content, .content, body`).First()
+	contentDiv := doc.FindMatcher(defaultContentSel).First()
 
 	// Clean up the content
 	content, err := contentDiv.Html()
@@ -208,95 +161,3 @@ func sanitizeHTML(htmlContent string) string {
 	}
 	return htmlContent
 }
-
-// extractImagesFromMetaTags extracts image URLs from Open Graph and Twitter Card meta tags.
-func extractImagesFromMetaTags(htmlContent string) []string {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil
-	}
-
-	var urls []string
-
-	// Check common meta tags for images
-	metaSelectors := []struct {
-		selector string
-		attr     string
-	}{
-		{`meta[property="og:image"]`, "content"},
-		{`meta[name="twitter:image"]`, "content"},
-		{`link[rel="image_src"]`, "href"},
-		{`meta[property="og:image:url"]`, "content"},
-		{`meta[name="twitter:image:src"]`, "content"},
-		{`meta[property="og:image:secure_url"]`, "content"},
-		{`meta[name="thumbnail"]`, "content"},
-		{`meta[itemprop="image"]`, "content"},
-	}
-
-	for _, meta := range metaSelectors {
-		if img, exists := doc.Find(meta.selector).First().Attr(meta.attr); exists && img != "" {
-			img = strings.TrimSpace(img)
-			if img != "" && !contains(urls, img) {
-				urls = append(urls, img)
-			}
-		}
-	}
-
-	return urls
-}
-
-// extractImagesFromHTMLWithBase collects all non-empty <img src="..."> values.
-func extractImagesFromHTMLWithBase(htmlContent, baseURL string) []string {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
-	if err != nil {
-		return nil
-	}
-
-	var images []string
-
-	doc.Find("img").Each(func(i int, s *goquery.Selection) {
-		if src, exists := s.Attr("src"); exists && src != "" {
-			src = strings.TrimSpace(src)
-			if src == "" {
-				return
-			}
-
-			// Handle protocol-relative URLs
-			if strings.HasPrefix(src, "//") {
-				src = "https:" + src
-			} else if baseURL != "" && !strings.HasPrefix(src, "http") {
-				// Handle relative URLs if baseURL is provided
-				base, err := url.Parse(baseURL)
-				if err == nil {
-					// Create absolute URL from relative path
-					if strings.HasPrefix(src, "/") {
-						// Absolute path
-						src = base.Scheme + "://" + base.Host + src
-					} else {
-						// Relative path
-						basePath := base.Path
-						if !strings.HasSuffix(basePath, "/") {
-							// Remove filename from base path
-							lastSlash := strings.LastIndex(basePath, "/")
-							if lastSlash >= 0 {
-								basePath = basePath[:lastSlash+1]
-							}
-						}
-						src = base.Scheme + "://" + base.Host + basePath + src
-					}
-				}
-			}
-
-			// Skip small images and icons
-			lowerSrc := strings.ToLower(src)
-			if !strings.HasSuffix(lowerSrc, ".svg") &&
-				!strings.Contains(lowerSrc, "icon") &&
-				!strings.Contains(lowerSrc, "logo") &&
-				!contains(images, src) {
-				images = append(images, src)
-			}
-		}
-	})
-
-	return images
-}
\ No newline at end of file
@@ -67,12 +67,12 @@ func (d *DunyaExtractor) Extract(input any) (string, []string, error) {
 func (d *DunyaExtractor) extractFromURL(articleURL string) (string, []string, error) {
 	resp, err := d.httpClient.Get(articleURL)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -109,7 +109,7 @@ func (d *DunyaExtractor) extractFromHTML(htmlContent string) (string, []string,
 			img = strings.TrimSpace(img)
 			if img != "" {
 				if !strings.HasPrefix(img, "http") && !strings.HasPrefix(img, "//") {
-					img = "https://www.dunya.com" + strings.TrimLeft(img, "/")
+					img = "https://www.dunya.com/" + strings.TrimLeft(img, "/")
 				} else if strings.HasPrefix(img, "//") {
 					img = "https:" + img
 				}
@@ -128,11 +128,11 @@ func (d *DunyaExtractor) extractFromHTML(htmlContent string) (string, []string,
 
 	// If we still don't have content, return an error
 	if contentDiv.Length() == 0 {
-		return "", images, fmt.Errorf("could not find main content in the page")
+		return "", images, ErrNoContent
 	}
 
 	// Remove unwanted elements that might be inside the content
-	contentDiv.Find(`script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author, .date`).Remove()
+	contentDiv.FindMatcher(articleClutterSel).Remove()
 
 	// Clean up the content
 	content, err := contentDiv.Html()
@@ -145,7 +145,7 @@ func (d *DunyaExtractor) extractFromHTML(htmlContent string) (string, []string,
 	// If no meta tag image found, try to find it in the content
 	if len(images) == 0 {
 		// Look for images only within the content area
-		contentDiv.Find("img").Each(func(i int, s *goquery.Selection) {
+		contentDiv.FindMatcher(imgSel).Each(func(i int, s *goquery.Selection) {
 			if src, exists := s.Attr("src"); exists && src != "" {
 				src = strings.TrimSpace(src)
 				if src == "" {
@@ -156,7 +156,7 @@ func (d *DunyaExtractor) extractFromHTML(htmlContent string) (string, []string,
 				if strings.HasPrefix(src, "//") {
 					src = "https:" + src
 				} else if !strings.HasPrefix(src, "http") {
-					src = "https://www.dunya.com" + strings.TrimLeft(src, "/")
+					src = "https://www.dunya.com/" + strings.TrimLeft(src, "/")
 				}
 
 				// Skip small images and icons
@@ -171,28 +171,6 @@ func (d *DunyaExtractor) extractFromHTML(htmlContent string) (string, []string,
 		})
 	}
 
-	processedImages := []string{}
-	for _, imgURL := range images {
-		parts := strings.Split(imgURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			fileName = strings.Split(fileName, "?")[0]
-			fileName = strings.TrimSpace(html.UnescapeString(fileName))
-			fileName = strings.ToLower(fileName)
-
-			if strings.Contains(fileName, "son-dakika") {
-				processedImages = append(processedImages, "https://newstr.netlify.app/public/images/breaking-news.jpg")
-			} else {
-				processedImages = append(processedImages, imgURL)
-			}
-		} else {
-			// URL parse edilemediyse, olduğu gibi koru (dikkatli olunmalı)
-			processedImages = append(processedImages, imgURL)
-		}
-	}
-	// İşlenmiş listeyi orijinal images değişkenine atayın
-	images = processedImages
-
 	// Ensure we return at least an empty slice, not nil
 	if images == nil {
 		images = []string{}
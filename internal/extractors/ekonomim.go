@@ -73,12 +73,12 @@ func (e *EkonomimExtractor) extractFromURL(articleURL string) (string, []string,
 
 	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -115,7 +115,7 @@ func (e *EkonomimExtractor) extractFromHTML(htmlContent string) (string, []strin
 			img = strings.TrimSpace(img)
 			if img != "" {
 				if !strings.HasPrefix(img, "http") && !strings.HasPrefix(img, "//") {
-					img = "https://www.ekonomim.com" + strings.TrimLeft(img, "/")
+					img = "https://www.ekonomim.com/" + strings.TrimLeft(img, "/")
 				} else if strings.HasPrefix(img, "//") {
 					img = "https:" + img
 				}
@@ -147,13 +147,11 @@ func (e *EkonomimExtractor) extractFromHTML(htmlContent string) (string, []strin
 
 	// If we still don't have content, return an error
 	if contentDiv.Length() == 0 {
-		return "", images, fmt.Errorf("could not find main content in the page")
+		return "", images, ErrNoContent
 	}
 
 	// Remove unwanted elements that might be inside the content
-	contentDiv.Find(`script, style, iframe, noscript, .ad, .advertisement, .social-share, 
-		.related-news, .tags, .author, .date, .comments, .adpro, the-ads, 
-		.picture-bottom_wrapper, .google-news_wrapper, .mceNonEditable`).Remove()
+	contentDiv.FindMatcher(ekonomimClutterSel).Remove()
 
 	// Clean up the content
 	content, err := contentDiv.Html()
@@ -166,7 +164,7 @@ func (e *EkonomimExtractor) extractFromHTML(htmlContent string) (string, []strin
 	// If no meta tag image found, try to find it in the content
 	if len(images) == 0 {
 		// Look for images only within the content area
-		contentDiv.Find("img").Each(func(i int, s *goquery.Selection) {
+		contentDiv.FindMatcher(imgSel).Each(func(i int, s *goquery.Selection) {
 			if src, exists := s.Attr("src"); exists && src != "" {
 				src = strings.TrimSpace(src)
 				if src == "" {
@@ -177,7 +175,7 @@ func (e *EkonomimExtractor) extractFromHTML(htmlContent string) (string, []strin
 				if strings.HasPrefix(src, "//") {
 					src = "https:" + src
 				} else if !strings.HasPrefix(src, "http") {
-					src = "https://www.ekonomim.com" + strings.TrimLeft(src, "/")
+					src = "https://www.ekonomim.com/" + strings.TrimLeft(src, "/")
 				}
 
 				// Skip small images and icons
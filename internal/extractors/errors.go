@@ -0,0 +1,55 @@
+// internal/extractors/errors.go
+package extractors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Errors extractors return, matched with errors.Is, so that callers tell a
+// URL an extractor does not read from a site that is down or a page
+// without an article.
+var (
+	// ErrFiltered is returned for URLs that are not articles the extractor
+	// reads: sections, galleries, columns, feeds
+	ErrFiltered = errors.New("filtered URL")
+	// ErrNoContent is returned for pages without article content
+	ErrNoContent = errors.New("no article content found")
+	// ErrTimeout is returned when fetching the page timed out
+	ErrTimeout = errors.New("timed out")
+)
+
+// UpstreamStatusError is returned when an article page answers with a
+// status other than 200 OK.
+type UpstreamStatusError struct {
+	Code int
+}
+
+func (e *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("HTTP status %d", e.Code)
+}
+
+// Gone reports whether the status says the article was removed.
+func (e *UpstreamStatusError) Gone() bool {
+	return e.Code == http.StatusNotFound || e.Code == http.StatusGone
+}
+
+// SiteDown reports whether err says the article's site failed to answer:
+// it timed out or answered with a server error.
+func SiteDown(err error) bool {
+	var status *UpstreamStatusError
+	return errors.Is(err, ErrTimeout) || errors.As(err, &status) && status.Code >= 500
+}
+
+// fetchError wraps the error of fetching a page, marking timeouts with
+// ErrTimeout.
+func fetchError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("failed to fetch URL: %w: %w", ErrTimeout, err)
+	}
+	return fmt.Errorf("failed to fetch URL: %w", err)
+}
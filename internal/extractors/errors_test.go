@@ -0,0 +1,58 @@
+package extractors
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchPageErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+
+	tests := []struct {
+		path       string
+		gone, down bool
+		timeout    bool
+	}{
+		{"/gone", true, false, false},
+		{"/down", false, true, false},
+		{"/slow", false, true, true},
+	}
+	for _, tt := range tests {
+		_, _, err := fetchPage(client, srv.URL+tt.path)
+		var status *UpstreamStatusError
+		if gone := errors.As(err, &status) && status.Gone(); gone != tt.gone {
+			t.Errorf("%s: gone = %v; want %v (%v)", tt.path, gone, tt.gone, err)
+		}
+		if SiteDown(err) != tt.down {
+			t.Errorf("%s: SiteDown = %v; want %v (%v)", tt.path, !tt.down, tt.down, err)
+		}
+		if errors.Is(err, ErrTimeout) != tt.timeout {
+			t.Errorf("%s: timed out = %v; want %v (%v)", tt.path, !tt.timeout, tt.timeout, err)
+		}
+	}
+}
+
+func TestSelectorExtractorNoContent(t *testing.T) {
+	e, err := NewSelectorExtractor(SiteRule{Domain: "news.example.com", ContentSelector: "article"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = e.Extract(map[string]interface{}{"html": "<html><body><p>no article</p></body></html>", "link": "https://news.example.com/a"})
+	if !errors.Is(err, ErrNoContent) {
+		t.Errorf("Extract() err = %v; want ErrNoContent", err)
+	}
+}
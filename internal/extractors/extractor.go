@@ -44,6 +44,11 @@ func (r *Registry) RegisterDefault(e Extractor) {
 	r.defaultExtractor = e
 }
 
+// Default returns the default fallback extractor, or nil if none is set.
+func (r *Registry) Default() Extractor {
+	return r.defaultExtractor
+}
+
 // ForURL returns the best extractor for a given URL.
 // It checks for domain-specific extractors first, then falls back to default.
 func (r *Registry) ForURL(urlStr string) Extractor {
@@ -2,14 +2,23 @@
 package filters
 
 import (
+	"fmt"
+	"net/url"
 	"strings"
 )
 
 // URLFilter defines filtering rules for a specific domain
 type URLFilter struct {
-	Domain       string
-	AllowedPaths []string // If empty, allow all paths
-	BlockedPaths []string // Takes priority over AllowedPaths
+	Domain       string   `json:"domain"`                  // Matches the host and its subdomains
+	AllowedPaths []string `json:"allowed_paths,omitempty"` // If empty, allow all paths
+	BlockedPaths []string `json:"blocked_paths,omitempty"` // Takes priority over AllowedPaths
+}
+
+// matchesHost reports whether host is the filter's domain or one of its
+// subdomains.
+func (f *URLFilter) matchesHost(host string) bool {
+	domain := strings.ToLower(f.Domain)
+	return host == domain || strings.HasSuffix(host, "."+domain)
 }
 
 // FilterRegistry manages URL filtering rules
@@ -31,10 +40,29 @@ func (r *FilterRegistry) Register(filter URLFilter) {
 
 // ShouldProcess checks if a URL should be processed based on registered filters
 func (r *FilterRegistry) ShouldProcess(urlStr string) bool {
+	ok, _ := r.Explain(urlStr)
+	return ok
+}
+
+// Explain reports whether a URL should be processed and, when it should
+// not, describes the rule excluding it. A nil registry processes every URL.
+func (r *FilterRegistry) Explain(urlStr string) (bool, string) {
+	if r == nil {
+		return true, ""
+	}
+
+	// Paths are matched against the path alone, not the query or fragment
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return true, ""
+	}
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+
 	// Find matching filter for this URL's domain
 	var matchedFilter *URLFilter
 	for i := range r.filters {
-		if strings.Contains(urlStr, r.filters[i].Domain) {
+		if r.filters[i].matchesHost(host) {
 			matchedFilter = &r.filters[i]
 			break
 		}
@@ -42,28 +70,28 @@ func (r *FilterRegistry) ShouldProcess(urlStr string) bool {
 
 	// If no filter matches, allow processing
 	if matchedFilter == nil {
-		return true
+		return true, ""
 	}
 
 	// Check blocked paths first (highest priority)
 	for _, blocked := range matchedFilter.BlockedPaths {
-		if strings.Contains(urlStr, blocked) {
-			return false
+		if strings.Contains(path, blocked) {
+			return false, fmt.Sprintf("%s: blocked path %q", matchedFilter.Domain, blocked)
 		}
 	}
 
 	// If no allowed paths specified, allow all (except blocked)
 	if len(matchedFilter.AllowedPaths) == 0 {
-		return true
+		return true, ""
 	}
 
 	// Check if URL matches any allowed path
 	for _, allowed := range matchedFilter.AllowedPaths {
-		if strings.Contains(urlStr, allowed) {
-			return true
+		if strings.Contains(path, allowed) {
+			return true, ""
 		}
 	}
 
 	// Doesn't match any allowed path
-	return false
+	return false, fmt.Sprintf("%s: not under an allowed path (%s)", matchedFilter.Domain, strings.Join(matchedFilter.AllowedPaths, ", "))
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package filters
+
+import "testing"
+
+func TestFilterRegistryExplain(t *testing.T) {
+	reg := NewFilterRegistry()
+	reg.Register(URLFilter{
+		Domain:       "dunya.com",
+		AllowedPaths: []string{"/ekonomi/"},
+		BlockedPaths: []string{"/spor/"},
+	})
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.dunya.com/ekonomi/bir-haber", true},
+		{"https://DUNYA.com/ekonomi/bir-haber", true},
+		{"https://www.dunya.com/spor/ekonomi/bir-haber", false},
+		{"https://www.dunya.com/gundem/bir-haber", false},
+		// Query strings and fragments are not paths
+		{"https://www.dunya.com/gundem/bir-haber?from=/ekonomi/", false},
+		{"https://www.dunya.com/ekonomi/bir-haber#/spor/", true},
+		// Other hosts merely containing the domain are not filtered
+		{"https://notdunya.com.example/gundem/bir-haber", true},
+		{"https://example.com/dunya.com/gundem/", true},
+	}
+	for _, tt := range tests {
+		if got, rule := reg.Explain(tt.url); got != tt.want {
+			t.Errorf("Explain(%s) = %v (%s); want %v", tt.url, got, rule, tt.want)
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"html"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -68,34 +67,19 @@ func (e *IlketvExtractor) Extract(input any) (string, []string, error) {
 
 // extractFromURL fetches the URL and extracts content.
 func (e *IlketvExtractor) extractFromURL(url string) (string, []string, error) {
-	// Skip processing for filtered URLs
-	filteredPrefixes := []string{
-		"https://www.ilketv.com.tr/video/",
-		"https://www.ilketv.com.tr/galeri/",
-		"https://www.ilketv.com.tr/foto-galeri/",
-		"https://www.ilketv.com.tr/yazarlar/",
-		"https://www.ilketv.com.tr/kose-yazilari/",
-	}
-
-	for _, prefix := range filteredPrefixes {
-		if strings.HasPrefix(url, prefix) {
-			return "", nil, fmt.Errorf("URL is in filtered list: %s", url)
-		}
-	}
-
 	// Only process article URLs
 	if !strings.Contains(url, "ilketv.com.tr/") || !strings.Contains(url, "-") {
-		return "", nil, fmt.Errorf("not an Ilketv article URL: %s", url)
+		return "", nil, fmt.Errorf("%w: %s is not an Ilketv article", ErrFiltered, url)
 	}
 
 	resp, err := e.httpClient.Get(url)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	content, images, err := e.extractFromHTML(resp.Body)
@@ -134,7 +118,7 @@ func (e *IlketvExtractor) extractFromHTML(reader io.Reader) (string, []string, e
 	for _, selector := range selectors {
 		doc.Find(selector).Each(func(i int, s *goquery.Selection) {
 			// Remove unwanted elements
-			s.Find("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info").Remove()
+			s.FindMatcher(ilketvClutterSel).Remove()
 			
 			// Get the clean HTML
 			html, _ := s.Html()
@@ -184,8 +168,7 @@ func (e *IlketvExtractor) cleanContent(content string) string {
 	content = strings.ReplaceAll(content, "]]>", "")
 
 	// Remove HTML tags using a simple regex
-	re := regexp.MustCompile(`<[^>]*>`)
-	content = re.ReplaceAllString(content, "")
+	content = htmlTagRegex.ReplaceAllString(content, "")
 
 	// Replace HTML entities
 	replacer := strings.NewReplacer(
@@ -234,7 +217,7 @@ func (e *IlketvExtractor) extractImagesFromMeta(doc *goquery.Document) []string
 				url = html.UnescapeString(url)
 				
 				if !strings.HasPrefix(url, "http") && !strings.HasPrefix(url, "//") {
-					url = "https://www.ilketv.com.tr" + strings.TrimLeft(url, "/")
+					url = "https://www.ilketv.com.tr/" + strings.TrimLeft(url, "/")
 				} else if strings.HasPrefix(url, "//") {
 					url = "https:" + url
 				}
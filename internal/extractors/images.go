@@ -0,0 +1,365 @@
+// internal/extractors/images.go
+package extractors
+
+import (
+	"cmp"
+	"math"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Filename and class hints, matched as substrings of the lower-cased URL
+// path or class/id attributes.
+var (
+	leadImageBadHints  = []string{"logo", "icon", "avatar", "author", "yazar", "profile", "sprite", "pixel", "spacer", "blank", "placeholder", "loading", "banner", "reklam", "badge", "emoji", "button"}
+	leadImageWeakHints = []string{"thumb", "small", "mini", "related", "_xs", "-xs", "150x", "100x"}
+	leadImageGoodHints = []string{"featured", "lead", "hero", "cover", "manset", "main", "large", "original", "post-image", "wp-post-image", "article-image", "detail"}
+)
+
+// imageCandidate is an image found on a page with its score.
+type imageCandidate struct {
+	url   string
+	size  int
+	score int
+	order int
+}
+
+// LeadImages returns the images of a page ordered by how likely each is the
+// article's lead image. Every image is scored on its position in the page,
+// its declared size and aspect ratio, hints in its file name and classes
+// ("logo", "avatar", "featured") and whether it sits in a figure inside the
+// article; social meta images get a head start since sites pick them by hand.
+// Images that are clearly not content, like logos, icons and tracking pixels,
+// are left out, and size variants of one image are collapsed into the largest
+// (see CollapseImageVariants). baseURL resolves relative URLs and may be empty.
+func LeadImages(doc *goquery.Document, baseURL string) []string {
+	base, _ := url.Parse(baseURL)
+	byURL := make(map[string]*imageCandidate)
+	var candidates []*imageCandidate
+	add := func(raw string, score int) {
+		u := resolveImageURL(raw, base)
+		if u == "" {
+			return
+		}
+		key, size := imageVariant(u)
+		if c, ok := byURL[key]; ok {
+			// Found twice, in the meta tags and the body or in several
+			// sizes: the site chose it more than once
+			c.score = max(c.score, score) + 15
+			if size > c.size {
+				c.url, c.size = u, size
+			}
+			return
+		}
+		c := &imageCandidate{url: u, size: size, score: score, order: len(candidates)}
+		byURL[key] = c
+		candidates = append(candidates, c)
+	}
+
+	doc.FindMatcher(leadImageMetaSel).Each(func(_ int, s *goquery.Selection) {
+		raw := s.AttrOr("content", s.AttrOr("href", ""))
+		add(raw, 40+imageHintScore(raw, ""))
+	})
+
+	imgs := doc.FindMatcher(imgSel)
+	total := imgs.Length()
+	imgs.Each(func(i int, s *goquery.Selection) {
+		raw := imageSource(s)
+		if raw == "" {
+			return
+		}
+		add(raw, scoreImageElement(s, raw, i, total))
+	})
+
+	candidates = slices.DeleteFunc(candidates, func(c *imageCandidate) bool { return c.score <= 0 })
+	slices.SortStableFunc(candidates, func(a, b *imageCandidate) int {
+		if a.score != b.score {
+			return cmp.Compare(b.score, a.score)
+		}
+		return cmp.Compare(a.order, b.order)
+	})
+	urls := make([]string, len(candidates))
+	for i, c := range candidates {
+		urls[i] = c.url
+	}
+	return urls
+}
+
+// CollapseImageVariants removes duplicate images from urls, keeping the order
+// of first appearance. Size variants of one image (photo_640.jpg,
+// photo-1280x720.jpg, photo.jpg?w=320) are collapsed into the largest one,
+// and an image served by a CDN with different resizing or cache-busting query
+// strings is kept once.
+func CollapseImageVariants(urls []string) []string {
+	type variant struct {
+		url  string
+		size int
+	}
+	index := make(map[string]int, len(urls))
+	variants := make([]variant, 0, len(urls))
+	for _, u := range urls {
+		key, size := imageVariant(u)
+		if i, ok := index[key]; ok {
+			if size > variants[i].size {
+				variants[i] = variant{u, size}
+			}
+			continue
+		}
+		index[key] = len(variants)
+		variants = append(variants, variant{u, size})
+	}
+	out := make([]string, len(variants))
+	for i, v := range variants {
+		out[i] = v.url
+	}
+	return out
+}
+
+// imageSizeParams are the query parameters image CDNs use to resize,
+// re-encode or cache-bust an image rather than to pick it.
+var imageSizeParams = map[string]bool{
+	"w": true, "width": true, "h": true, "height": true, "resize": true, "fit": true, "crop": true,
+	"quality": true, "q": true, "size": true, "format": true, "fm": true, "auto": true, "dpr": true,
+	"strip": true, "ssl": true, "v": true, "ver": true, "version": true, "itok": true, "_": true,
+}
+
+// Relative sizes of image variants that carry no width: the unsized original
+// is the largest, except for retina (@2x) variants; WordPress' -scaled copy is
+// just below it.
+const (
+	imageSizeOriginal = math.MaxInt32
+	imageSizeScaled   = imageSizeOriginal - 1
+)
+
+// imageVariant returns the key the size variants of the image at raw share,
+// and the size of this variant: its width where the URL names one.
+func imageVariant(raw string) (key string, size int) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw, imageSizeOriginal
+	}
+	size = imageSizeOriginal
+
+	query := u.Query()
+	for name, values := range query {
+		lower := strings.ToLower(name)
+		if !imageSizeParams[lower] {
+			continue
+		}
+		if lower == "w" || lower == "width" {
+			if n, err := strconv.Atoi(values[0]); err == nil {
+				size = n
+			}
+		}
+		query.Del(name)
+	}
+
+	dir, file := path.Split(u.Path)
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(dir, "/"), "/") {
+		m := imageSizeSegmentRegex.FindStringSubmatch(segment)
+		if m == nil {
+			segments = append(segments, segment)
+			continue
+		}
+		if n := firstNumber(m[1], m[3], m[4]); n > 0 {
+			size = n
+		}
+	}
+
+	if m := imageSizeSuffixRegex.FindStringSubmatch(file); m != nil {
+		switch {
+		case m[2] != "":
+			file = m[1] + m[6]
+			size, _ = strconv.Atoi(m[2])
+		case m[4] != "":
+			// A bare number is only a width when it looks like one, so that
+			// photo_2024.jpg and photo_12.jpg stay apart
+			if n, _ := strconv.Atoi(m[4]); plausibleImageWidth(n) {
+				file = m[1] + m[6]
+				size = n
+			}
+		case m[5] != "":
+			n, _ := strconv.Atoi(m[5])
+			file = m[1] + m[6]
+			size = imageSizeOriginal + n
+		default:
+			file = m[1] + m[6]
+			size = imageSizeScaled
+		}
+	}
+
+	key = strings.ToLower(u.Host) + "/" + strings.Join(segments, "/") + "/" + file
+	if q := query.Encode(); q != "" {
+		key += "?" + q
+	}
+	return key, size
+}
+
+// plausibleImageWidth reports whether n, found in a file name, is likely an
+// image width rather than a year or a sequence number.
+func plausibleImageWidth(n int) bool {
+	if n < 100 || (n >= 1900 && n < 2100) {
+		return false
+	}
+	return n%10 == 0 || n%16 == 0
+}
+
+// firstNumber returns the first of values that is a number, or 0.
+func firstNumber(values ...string) int {
+	for _, v := range values {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// scoreImageElement scores the i-th of total <img> elements of a page.
+func scoreImageElement(s *goquery.Selection, src string, i, total int) int {
+	// Earlier images are more likely to lead the article
+	score := 20 - 20*i/max(total, 1)
+
+	if s.ParentsMatcher(leadImageMainSel).Length() > 0 {
+		score += 15
+	}
+	if s.ParentsMatcher(leadImageNoiseSel).Length() > 0 {
+		score -= 30
+	}
+	if s.ParentsFiltered("figure").Length() > 0 {
+		score += 10
+		if s.ParentsFiltered("figure").First().Find("figcaption").Length() > 0 {
+			score += 5
+		}
+	}
+
+	// Declared dimensions
+	width, _ := strconv.Atoi(strings.TrimSuffix(s.AttrOr("width", ""), "px"))
+	height, _ := strconv.Atoi(strings.TrimSuffix(s.AttrOr("height", ""), "px"))
+	switch {
+	case width > 0 && width < 150, height > 0 && height < 100:
+		score -= 40
+	case width >= 600 || height >= 400:
+		score += 25
+	case width >= 300 || height >= 200:
+		score += 15
+	}
+	if width > 0 && height > 0 {
+		ratio := float64(width) / float64(height)
+		switch {
+		case ratio >= 1.2 && ratio <= 2.2:
+			score += 10
+		case ratio > 3 || ratio < 0.5:
+			score -= 15
+		case ratio > 0.9 && ratio < 1.1 && width < 300:
+			// Small squares are usually avatars
+			score -= 15
+		}
+	}
+
+	classes := strings.ToLower(s.AttrOr("class", "") + " " + s.AttrOr("id", "") + " " + s.Parent().AttrOr("class", ""))
+	return score + imageHintScore(src, classes) + imageHintScore(s.AttrOr("alt", ""), "")/2
+}
+
+// imageHintScore scores the file name of src and the classes around it.
+func imageHintScore(src, classes string) int {
+	file := strings.ToLower(src)
+	if u, err := url.Parse(src); err == nil {
+		file = strings.ToLower(u.Path)
+	}
+	score := 0
+	if ext := path.Ext(file); ext == ".svg" || ext == ".ico" {
+		score -= 60
+	}
+	for _, text := range []string{file, classes} {
+		if text == "" {
+			continue
+		}
+		switch {
+		case containsAny(text, leadImageBadHints):
+			score -= 60
+		case containsAny(text, leadImageWeakHints):
+			score -= 15
+		case containsAny(text, leadImageGoodHints):
+			score += 15
+		}
+	}
+	return score
+}
+
+// imageSource returns the URL an <img> displays, preferring the largest
+// srcset candidate and lazy-loading attributes over placeholder sources.
+func imageSource(s *goquery.Selection) string {
+	if srcset := s.AttrOr("srcset", s.AttrOr("data-srcset", "")); srcset != "" {
+		if best := largestSrcsetURL(srcset); best != "" {
+			return best
+		}
+	}
+	for _, attr := range []string{"data-src", "data-lazy-src", "data-original", "src"} {
+		if v := strings.TrimSpace(s.AttrOr(attr, "")); v != "" && !strings.HasPrefix(v, "data:") {
+			return v
+		}
+	}
+	return ""
+}
+
+// largestSrcsetURL returns the widest candidate of a srcset attribute.
+func largestSrcsetURL(srcset string) string {
+	best, bestWidth := "", -1
+	for _, part := range strings.Split(srcset, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		width := 0
+		if len(fields) > 1 {
+			descriptor := fields[1]
+			if n, err := strconv.Atoi(strings.TrimSuffix(descriptor, "w")); err == nil && strings.HasSuffix(descriptor, "w") {
+				width = n
+			} else if f, err := strconv.ParseFloat(strings.TrimSuffix(descriptor, "x"), 64); err == nil {
+				width = int(f * 1000)
+			}
+		}
+		if width > bestWidth {
+			best, bestWidth = fields[0], width
+		}
+	}
+	return best
+}
+
+// resolveImageURL makes raw absolute against base, returning "" for values
+// that are not http(s) image URLs.
+func resolveImageURL(raw string, base *url.URL) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "data:") {
+		return ""
+	}
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base != nil && base.Host != "" {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package extractors
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestLeadImages(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want []string
+	}{
+		{
+			name: "lead figure beats avatar and related thumbs",
+			page: `<html><body>
+				<header><img src="/static/site-logo.png"></header>
+				<div class="byline"><img class="author-photo" src="/u/ayse.jpg" width="64" height="64"></div>
+				<article>
+					<figure><img src="/img/2024/storm.jpg" width="1200" height="675"><figcaption>The storm</figcaption></figure>
+					<p>Text</p>
+					<img src="/img/2024/chart.png" width="800" height="500">
+				</article>
+				<aside class="related"><img src="/img/other-thumb.jpg" width="300" height="200"></aside>
+				<img src="/pixel.gif" width="1" height="1">
+			</body></html>`,
+			want: []string{
+				"https://news.test/img/2024/storm.jpg",
+				"https://news.test/img/2024/chart.png",
+			},
+		},
+		{
+			name: "og:image matching the body image leads",
+			page: `<html><head><meta property="og:image" content="https://cdn.test/lead.jpg"></head><body>
+				<article>
+					<img src="/img/first.jpg" width="640" height="360">
+					<img data-src="https://cdn.test/lead.jpg" src="data:image/gif;base64,R0lGOD">
+				</article>
+			</body></html>`,
+			want: []string{
+				"https://cdn.test/lead.jpg",
+				"https://news.test/img/first.jpg",
+			},
+		},
+		{
+			name: "logo og:image loses to article image",
+			page: `<html><head><meta property="og:image" content="/brand/logo-share.png"></head><body>
+				<main><img srcset="/img/a-480.jpg 480w, /img/a-1024.jpg 1024w" src="/img/a-480.jpg"></main>
+			</body></html>`,
+			want: []string{"https://news.test/img/a-1024.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := LeadImages(doc, "https://news.test/2024/story.html"); !slices.Equal(got, tt.want) {
+				t.Errorf("LeadImages = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapseImageVariants(t *testing.T) {
+	got := CollapseImageVariants([]string{
+		"https://cdn.test/2024/05/storm_640.jpg",
+		"https://cdn.test/2024/05/photo-300x200.jpg",
+		"https://cdn.test/2024/05/storm_1280.jpg",
+		"https://cdn.test/2024/05/photo.jpg",
+		"https://img.test/a.jpg?w=320&v=3",
+		"https://img.test/a.jpg?width=1024&v=7",
+		"https://img.test/a.jpg?id=2",
+		"https://res.test/image/upload/w_400,c_fill/lead.jpg",
+		"https://res.test/image/upload/w_1600,c_fill/lead.jpg",
+		"https://cdn.test/gallery_2023.jpg",
+		"https://cdn.test/gallery_2024.jpg",
+		"https://cdn.test/retina.png",
+		"https://cdn.test/retina@2x.png",
+	})
+	want := []string{
+		"https://cdn.test/2024/05/storm_1280.jpg",
+		"https://cdn.test/2024/05/photo.jpg",
+		"https://img.test/a.jpg?width=1024&v=7",
+		"https://img.test/a.jpg?id=2",
+		"https://res.test/image/upload/w_1600,c_fill/lead.jpg",
+		"https://cdn.test/gallery_2023.jpg",
+		"https://cdn.test/gallery_2024.jpg",
+		"https://cdn.test/retina@2x.png",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("CollapseImageVariants =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -64,12 +64,12 @@ func (k *KisadalgaExtractor) Extract(input any) (string, []string, error) {
 func (k *KisadalgaExtractor) extractFromURL(articleURL string) (string, []string, error) {
 	resp, err := k.httpClient.Get(articleURL)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -96,13 +96,11 @@ func (k *KisadalgaExtractor) extractFromHTML(htmlContent string) (string, []stri
 
 	// If we still don't have content, return an error
 	if contentDiv.Length() == 0 {
-		return "", nil, fmt.Errorf("could not find main content in the page")
+		return "", nil, ErrNoContent
 	}
 
 	// Remove unwanted elements that might be inside the content
-	contentDiv.Find(`script, style, iframe, noscript, 
-		.ad, .advertisement, .banner, .banner-wide, .rel-link,
-		.social-share, .related-news, .tags, .author, .date`).Remove()
+	contentDiv.FindMatcher(kisadalgaClutterSel).Remove()
 	
 	// Remove any divs that look like ads or scripts
 	contentDiv.Find(`div`).Each(func(i int, s *goquery.Selection) {
@@ -133,12 +131,12 @@ func (k *KisadalgaExtractor) extractFromHTML(htmlContent string) (string, []stri
 
 	// Extract images from the content
 	var images []string
-	contentDiv.Find("img").Each(func(i int, s *goquery.Selection) {
+	contentDiv.FindMatcher(imgSel).Each(func(i int, s *goquery.Selection) {
 		if src, exists := s.Attr("src"); exists && src != "" {
 			src = strings.TrimSpace(src)
 			if src != "" {
 				if !strings.HasPrefix(src, "http") && !strings.HasPrefix(src, "//") {
-					src = "https://kisadalga.net" + strings.TrimLeft(src, "/")
+					src = "https://kisadalga.net/" + strings.TrimLeft(src, "/")
 				} else if strings.HasPrefix(src, "//") {
 					src = "https:" + src
 				}
@@ -164,7 +162,7 @@ func (k *KisadalgaExtractor) extractFromHTML(htmlContent string) (string, []stri
 				img = strings.TrimSpace(img)
 				if img != "" {
 					if !strings.HasPrefix(img, "http") && !strings.HasPrefix(img, "//") {
-						img = "https://kisadalga.net" + strings.TrimLeft(img, "/")
+						img = "https://kisadalga.net/" + strings.TrimLeft(img, "/")
 					} else if strings.HasPrefix(img, "//") {
 						img = "https:" + img
 					}
@@ -175,25 +173,5 @@ func (k *KisadalgaExtractor) extractFromHTML(htmlContent string) (string, []stri
 		}
 	}
 
-	processedImages := []string{}
-	for _, imgURL := range images {
-		parts := strings.Split(imgURL, "/")
-		if len(parts) > 0 {
-			fileName := parts[len(parts)-1]
-			fileName = strings.Split(fileName, "?")[0]
-			fileName = strings.TrimSpace(html.UnescapeString(fileName))
-			fileName = strings.ToLower(fileName)
-
-			if strings.Contains(fileName, "son-dakika") {
-				processedImages = append(processedImages, "https://newstr.netlify.app/public/images/breaking-news.jpg")
-			} else {
-				processedImages = append(processedImages, imgURL)
-			}
-		} else {
-			processedImages = append(processedImages, imgURL)
-		}
-	}
-	images = processedImages
-
 	return content, images, nil
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+// internal/extractors/links.go
+package extractors
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// linkAttrRegex matches the link and source attributes of HTML elements.
+var linkAttrRegex = regexp.MustCompile(`(?i)(\s(?:href|src)\s*=\s*)(["'])([^"']*)(["'])`)
+
+// LinkRewrites point the links and embeds of articles at privacy-friendly
+// front ends, e.g. twitter.com at a Nitter instance and youtube.com at an
+// Invidious one. A link to a domain or one of its subdomains keeps its path
+// and query on the front end's base URL.
+type LinkRewrites struct {
+	rules map[string]*url.URL // domain -> front end base
+}
+
+// NewLinkRewrites creates LinkRewrites from a map of domains to the base
+// URLs of their front ends.
+func NewLinkRewrites(rules map[string]string) (*LinkRewrites, error) {
+	lr := &LinkRewrites{rules: make(map[string]*url.URL, len(rules))}
+	for domain, base := range rules {
+		u, err := url.Parse(strings.TrimSpace(base))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("link rewrite for %q: %q is not an http(s) URL", domain, base)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		lr.rules[strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")] = u
+	}
+	return lr, nil
+}
+
+// Apply returns an article's HTML with the href and src attributes pointing
+// at rewritten domains moved to their front ends.
+func (lr *LinkRewrites) Apply(content string) string {
+	if lr == nil || len(lr.rules) == 0 || content == "" {
+		return content
+	}
+	return linkAttrRegex.ReplaceAllStringFunc(content, func(attr string) string {
+		m := linkAttrRegex.FindStringSubmatch(attr)
+		if m[2] != m[4] {
+			return attr
+		}
+		if rewritten, ok := lr.Rewrite(m[3]); ok {
+			return m[1] + m[2] + rewritten + m[4]
+		}
+		return attr
+	})
+}
+
+// Rewrite returns link moved to the front end of its domain, if it has one.
+func (lr *LinkRewrites) Rewrite(link string) (string, bool) {
+	if lr == nil {
+		return link, false
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+		return link, false
+	}
+	// The most specific domain wins, so m.youtube.com can go elsewhere
+	// than youtube.com
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	var match string
+	for domain := range lr.rules {
+		if domain != "" && inDomain(host, domain) && len(domain) > len(match) {
+			match = domain
+		}
+	}
+	if match == "" {
+		return link, false
+	}
+	// Everything after the host is kept as written, entities and all
+	base := lr.rules[match]
+	rest := link[strings.Index(link, u.Host)+len(u.Host):]
+	return base.Scheme + "://" + base.Host + base.Path + rest, true
+}
+
+// ParseLinkRewrites parses rewrites written as ";"-separated
+// "domain=base URL" entries, e.g.
+// "twitter.com=https://nitter.net;youtube.com=https://yewtu.be".
+func ParseLinkRewrites(spec string) (map[string]string, error) {
+	rules := make(map[string]string)
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		domain, base, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(domain) == "" || strings.TrimSpace(base) == "" {
+			return nil, fmt.Errorf("invalid link rewrite %q (want \"domain=base URL\")", strings.TrimSpace(entry))
+		}
+		rules[strings.TrimSpace(domain)] = strings.TrimSpace(base)
+	}
+	return rules, nil
+}
@@ -0,0 +1,44 @@
+package extractors
+
+import "testing"
+
+func TestLinkRewrites(t *testing.T) {
+	lr, err := NewLinkRewrites(map[string]string{
+		"twitter.com":   "https://nitter.test/",
+		"youtube.com":   "https://invidious.test",
+		"m.youtube.com": "https://mobile.test/yt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ in, want string }{
+		{`<a href="https://twitter.com/user/status/1">tweet</a>`, `<a href="https://nitter.test/user/status/1">tweet</a>`},
+		{`<iframe src='//www.youtube.com/embed/abc?start=5&amp;t=1'></iframe>`, `<iframe src='https://invidious.test/embed/abc?start=5&amp;t=1'></iframe>`},
+		{`<a href="https://m.youtube.com/watch?v=abc">v</a>`, `<a href="https://mobile.test/yt/watch?v=abc">v</a>`},
+		{`<a href="https://nottwitter.com/a">x</a>`, `<a href="https://nottwitter.com/a">x</a>`},
+		{`<p>twitter.com/user</p>`, `<p>twitter.com/user</p>`},
+	}
+	for _, tt := range tests {
+		if got := lr.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := NewLinkRewrites(map[string]string{"x.com": "nitter.test"}); err == nil {
+		t.Error("a base URL without scheme was accepted")
+	}
+}
+
+func TestParseLinkRewrites(t *testing.T) {
+	rules, err := ParseLinkRewrites("twitter.com=https://nitter.test; youtube.com = https://invidious.test;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules["youtube.com"] != "https://invidious.test" {
+		t.Errorf("rules = %v", rules)
+	}
+	if _, err := ParseLinkRewrites("twitter.com"); err == nil {
+		t.Error("an entry without a base URL was accepted")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -68,33 +67,19 @@ func (e *NTVExtractor) Extract(input any) (string, []string, error) {
 
 // extractFromURL fetches the URL and extracts content.
 func (e *NTVExtractor) extractFromURL(url string) (string, []string, error) {
-	// Skip processing for filtered URLs
-	filteredPrefixes := []string{
-		"https://www.ntv.com.tr/galeri/",
-		"https://www.ntv.com.tr/yazarlar/",
-		"https://www.ntv.com.tr/video/",
-		"https://www.ntv.com.tr/otomobil/",
-	}
-
-	for _, prefix := range filteredPrefixes {
-		if strings.HasPrefix(url, prefix) {
-			return "", nil, fmt.Errorf("URL is in filtered list: %s", url)
-		}
-	}
-
 	// Only process article URLs
 	if !strings.Contains(url, "ntv.com.tr/") || !strings.Contains(url, "-") {
-		return "", nil, fmt.Errorf("not an NTV article URL: %s", url)
+		return "", nil, fmt.Errorf("%w: %s is not an NTV article", ErrFiltered, url)
 	}
 
 	resp, err := e.httpClient.Get(url)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	content, images, err := e.extractFromHTML(resp.Body)
@@ -121,7 +106,7 @@ func (e *NTVExtractor) extractFromHTML(reader io.Reader) (string, []string, erro
 	var content string
 	doc.Find("div.category-detail-content-inner").Each(func(i int, s *goquery.Selection) {
 		// Remove unwanted elements
-		s.Find("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news").Remove()
+		s.FindMatcher(ntvClutterSel).Remove()
 		
 		// Get the clean HTML
 		html, _ := s.Html()
@@ -131,7 +116,7 @@ func (e *NTVExtractor) extractFromHTML(reader io.Reader) (string, []string, erro
 	// If no content found with the main selector, try alternative selectors
 	if content == "" {
 		doc.Find("div.article-content, article, .content, .article-body").Each(func(i int, s *goquery.Selection) {
-			s.Find("script, style, iframe, noscript, .ad, .advertisement, .social-share").Remove()
+			s.FindMatcher(ntvFallbackClutter).Remove()
 			html, _ := s.Html()
 			content = strings.TrimSpace(html)
 		})
@@ -166,8 +151,7 @@ func (e *NTVExtractor) cleanContent(content string) string {
 	content = strings.ReplaceAll(content, "]]>", "")
 
 	// Remove HTML tags using a simple regex (faster than parsing the HTML for simple cases)
-	re := regexp.MustCompile(`<[^>]*>`)
-	content = re.ReplaceAllString(content, "")
+	content = htmlTagRegex.ReplaceAllString(content, "")
 
 	// Replace HTML entities
 	replacer := strings.NewReplacer(
@@ -208,12 +192,7 @@ func (e *NTVExtractor) extractImagesFromMeta(doc *goquery.Document) []string {
 				if !strings.HasPrefix(url, "http") {
 					url = "https://www.ntv.com.tr" + url
 				}
-				
-				// Replace son-dakika images with the specified URL
-				if strings.Contains(url, "son-dakika-gorselleri") || strings.Contains(url, "son-dakika") {
-					url = "https://inewstr.netlify.app/breaking.jpg"
-				}
-				
+
 				images = append(images, url)
 			}
 		})
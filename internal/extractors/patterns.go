@@ -0,0 +1,136 @@
+// internal/extractors/patterns.go
+package extractors
+
+import (
+	"regexp"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// This file is the registry of compiled patterns shared by the extractors.
+// Regular expressions and CSS selectors are compiled once at package
+// initialisation instead of on every Extract call; selectors are used through
+// goquery's FindMatcher. An invalid pattern panics at startup rather than
+// silently matching nothing.
+
+// Regular expressions.
+var (
+	htmlTagRegex        = regexp.MustCompile(`<[^>]*>`)
+	scriptBlockRegex    = regexp.MustCompile(`(?s)<script[^>]*>.*?</script>`)
+	adproContainerRegex = regexp.MustCompile(`(?s)<div[^>]*class="[^"]*adpro[^"]*"[^>]*>.*?</div>`)
+
+	// Size markers of image URLs: photo-1280x720.jpg, photo_640.jpg,
+	// photo@2x.jpg and WordPress' photo-scaled.jpg; /640x360/ and /w_640/
+	// path segments, including Cloudinary's /w_640,c_fill/.
+	imageSizeSuffixRegex  = regexp.MustCompile(`(?i)^(.+?)(?:[-_](\d{2,4})x(\d{2,4})|[-_](\d{3,4})w?|@(\d)x|-scaled)(\.[a-z0-9]+)$`)
+	imageSizeSegmentRegex = regexp.MustCompile(`(?i)^(?:(\d{2,4})x(\d{2,4})|w_?(\d{2,4})(?:,[^/]*)?|(\d{2,4})w|resize|fit-in)$`)
+)
+
+// Content selectors.
+var (
+	defaultContentSel = mustSelector(`article, main, [role="main"], [itemprop="articleBody"], .post-content, .entry-content, .article-content, .content, body`)
+	imgSel            = mustSelector("img")
+	scriptSel         = mustSelector("script, style, noscript")
+)
+
+// Lead image selectors, used by LeadImages to score the images of a page.
+var (
+	leadImageMetaSel  = mustSelector(`meta[property="og:image"], meta[property="og:image:url"], meta[property="og:image:secure_url"], meta[name="twitter:image"], meta[name="twitter:image:src"], link[rel="image_src"]`)
+	leadImageMainSel  = mustSelector(`article, main, [role="main"], [itemprop="articleBody"], .post-content, .entry-content, .article-content, .news-detail, .haber-detay`)
+	leadImageNoiseSel = mustSelector(`header, footer, nav, aside, .sidebar, .related, .related-news, .related-posts, .author, .author-info, .avatar, .comments, .comment, .widget, .share, .social-share, .advertisement, .ad`)
+)
+
+// Clutter selectors removed from extracted article bodies.
+var (
+	articleClutterSel    = mustSelector(`script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author, .date`)
+	ekonomimClutterSel   = mustSelector(`script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author, .date, .comments, .adpro, the-ads, .picture-bottom_wrapper, .google-news_wrapper, .mceNonEditable`)
+	kisadalgaClutterSel  = mustSelector(`script, style, iframe, noscript, .ad, .advertisement, .banner, .banner-wide, .rel-link, .social-share, .related-news, .tags, .author, .date`)
+	ntvClutterSel        = mustSelector("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news")
+	ntvFallbackClutter   = mustSelector("script, style, iframe, noscript, .ad, .advertisement, .social-share")
+	ilketvClutterSel     = mustSelector("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info")
+	artigercekClutterSel = mustSelector("script, style, iframe, noscript, .adpro, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info, .category-info")
+	artigercekFallback   = mustSelector("script, style, iframe, noscript, .ad, .advertisement, .social-share, .related-news, .tags, .author-info, .date-info, .category-info")
+)
+
+// T24 cleanup selectors, applied in order by cleanContent.
+var (
+	t24TableSel   = mustSelector("table, tbody, thead, tfoot, tr, td, th")
+	t24CellSel    = mustSelector("td, th")
+	t24ClutterSel = mustSelector(`script, style, iframe, noscript,
+		.ad-container, .ad, .advertisement, .social-share,
+		.related-news, .tags, .comments, .author-info,
+		.article-footer, .article-meta, .article-share,
+		.article-tags, .article-related, .article-comments,
+		.article-author, .article-date, .article-category,
+		.article-source, .article-url, .article-title,
+		.article-image, .article-video, .article-audio,
+		.article-gallery, .article-pagination, .article-navigation,
+		.article-recommend, .article-popular, .article-most-read,
+		.recommended-news, .popular-news, .most-read-news,
+		.social-buttons, .share-buttons, .social-media-buttons,
+		.fb-like, .twitter-tweet, .instagram-media,
+		.newsletter, .subscription, .signup-form,
+		.comment-section, .comment-form, .comment-list,
+		.pagination, .page-navigation, .pager,
+		.breadcrumb, .breadcrumbs, .site-map,
+		.footer, .site-footer, .main-footer,
+		.header, .site-header, .main-header,
+		.sidebar, .side-bar, .widget-area,
+		.modal, .popup, .lightbox,
+		[class*='cookie'], [id*='cookie'],
+		[class*='gdpr'], [id*='gdpr'],
+		[class*='privacy'], [id*='privacy'],
+		[class*='banner'], [id*='banner'],
+		[class*='popup'], [id*='popup'],
+		[class*='modal'], [id*='modal'],
+		[class*='overlay'], [id*='overlay'],
+		[class*='notification'], [id*='notification']`)
+	t24EmptySel      = mustSelector("p:empty, div:empty, span:empty, a:empty")
+	t24TextBlockSel  = mustSelector("p, div, span")
+	t24AllSel        = mustSelector("*")
+	t24InlineAttrSel = mustSelector("[style], [onclick], [onload], [onerror]")
+	t24LinkSel       = mustSelector("a")
+	t24EmbeddedSel   = mustSelector("script, style, noscript, iframe, frame, object, embed, param, video, audio, source, track, canvas, svg, math")
+	t24EventAttrSel  = mustSelector("*[onclick], *[onload], *[onerror], *[onmouseover], *[onmouseout], *[onmousedown], *[onmouseup]")
+	t24TrailingSel   = []goquery.Matcher{
+		// Ads and trackers
+		mustSelector("ins.adsbygoogle, .ad, .advertisement, [id*='ad-'], [class*='ad-'], [id*='banner'], [class*='banner'], [id*='sponsor'], [class*='sponsor']"),
+		// Social media embeds
+		mustSelector(".fb-post, .fb-like, .fb-comments, .twitter-tweet, .instagram-media, .tiktok-embed"),
+		// Newsletter signups
+		mustSelector(".newsletter, .newsletter-form, .signup-form, .email-signup"),
+		// Related content
+		mustSelector(".related, .related-posts, .related-articles, .more-news, .read-more"),
+		// Navigation and pagination
+		mustSelector(".pagination, .page-navigation, .pager, .nav-links"),
+		// Breadcrumbs
+		mustSelector(".breadcrumb, .breadcrumbs"),
+		// Headers and footers
+		mustSelector("header, footer, .header, .footer, .site-header, .site-footer, .main-header, .main-footer"),
+		// Sidebars
+		mustSelector("aside, .sidebar, .side-bar, .widget-area"),
+		// Modals and popups
+		mustSelector(".modal, .popup, .lightbox, .overlay"),
+		// Cookie and GDPR notices
+		mustSelector(".cookie-consent, .gdpr-banner, .privacy-banner, [class*='cookie'], [id*='cookie'], [class*='gdpr'], [id*='gdpr'], [class*='privacy'], [id*='privacy']"),
+		// Banners and notifications
+		mustSelector(".banner, .notification, .alert, .notice, .message, [role='alert'], [role='status'], [role='banner']"),
+		// Tooltips and popovers
+		mustSelector("[data-toggle='tooltip'], [data-toggle='popover'], [data-bs-toggle='tooltip'], [data-bs-toggle='popover'], .tooltip, .popover"),
+		// Loading placeholders
+		mustSelector(".loading, .loader, .spinner, .skeleton, .shimmer"),
+		// Lazy-loaded elements
+		mustSelector("[data-src], [data-lazy], [lazyload], .lazy, .lazyload"),
+		// Hidden or offscreen elements
+		mustSelector("[hidden], [aria-hidden='true'], .hidden, .d-none, .invisible, .sr-only, .visually-hidden"),
+		// Empty containers
+		mustSelector("div:empty, span:empty, p:empty, a:empty, li:empty, td:empty, th:empty"),
+	}
+	t24WhitespaceSel = mustSelector("p, div, span, li, td, th")
+)
+
+// mustSelector compiles a CSS selector group for use with FindMatcher.
+func mustSelector(sel string) goquery.Matcher {
+	return cascadia.MustCompile(sel)
+}
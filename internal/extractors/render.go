@@ -0,0 +1,330 @@
+package extractors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRenderBlock are the resource types a rendered page does not load:
+// articles are read from the page's markup, so images, media and fonts only
+// cost time.
+var DefaultRenderBlock = []string{"image", "media", "font"}
+
+// DefaultRenderBlockPatterns match the ad and tracking requests blocked
+// while rendering.
+var DefaultRenderBlockPatterns = []string{
+	`doubleclick\.net`, `googlesyndication\.com`, `googletagmanager\.com`, `google-analytics\.com`,
+	`adservice\.google\.`, `facebook\.net`, `criteo\.`, `taboola\.com`, `outbrain\.com`,
+}
+
+// RenderWait says when a rendered page is ready to be read. Conditions
+// combine: the page waits for the network to settle, then for Selector,
+// then for Delay.
+type RenderWait struct {
+	// Selector waits until an element matching it exists
+	Selector string `json:"selector,omitempty"`
+	// NetworkIdle waits until the page has stopped loading resources
+	NetworkIdle bool `json:"network_idle,omitempty"`
+	// Delay waits a fixed time, for pages that fill in after loading
+	Delay Duration `json:"delay,omitempty"`
+}
+
+// Duration is a time.Duration read from JSON as a string like "1.5s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"2s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RendererOptions configure a Renderer.
+type RendererOptions struct {
+	// Endpoint is the base URL of a Browserless-compatible browser service,
+	// e.g. "http://chrome:3000?token=secret"
+	Endpoint string
+	// Stealth hides the signs of a headless browser from the sites
+	Stealth bool
+	// Block lists the resource types not loaded ("image", "font", ...)
+	Block []string
+	// BlockPatterns are regular expressions of request URLs not loaded
+	BlockPatterns []string
+	// Timeout bounds rendering a page
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Renderer loads pages in a headless browser run by a remote browser
+// service, for sites that build their articles with JavaScript.
+type Renderer struct {
+	opts RendererOptions
+
+	mu        sync.RWMutex
+	waits     map[string]RenderWait // by domain
+	checkedAt time.Time
+
+	available atomic.Bool
+}
+
+// ErrBrowserUnavailable is returned when the browser service cannot be
+// reached.
+var ErrBrowserUnavailable = errors.New("browser service unavailable")
+
+// renderRecheck is how often a browser service found unavailable is checked
+// again.
+const renderRecheck = time.Minute
+
+// NewRenderer creates a Renderer from opts.
+func NewRenderer(opts RendererOptions) (*Renderer, error) {
+	u, err := url.Parse(opts.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid browser service URL %q", opts.Endpoint)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.Client == nil {
+		// The service enforces the timeout; the client only guards against
+		// a service that hangs
+		opts.Client = &http.Client{Timeout: opts.Timeout + 10*time.Second}
+	}
+	return &Renderer{opts: opts, waits: make(map[string]RenderWait)}, nil
+}
+
+// Check asks the browser service for its version and records whether it
+// answered.
+func (r *Renderer) Check() error {
+	r.mu.Lock()
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint("/json/version"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.opts.Client.Do(req)
+	if err != nil {
+		r.setAvailable(false)
+		return fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		r.setAvailable(false)
+		return fmt.Errorf("%w: answered %d", ErrBrowserUnavailable, resp.StatusCode)
+	}
+	r.setAvailable(true)
+	return nil
+}
+
+// Available reports whether the browser service was reachable when last
+// used or checked.
+func (r *Renderer) Available() bool {
+	return r.available.Load()
+}
+
+// ready reports whether pages can be rendered, checking an unavailable
+// browser service again once renderRecheck has passed.
+func (r *Renderer) ready() bool {
+	if r.available.Load() {
+		return true
+	}
+	r.mu.RLock()
+	due := time.Since(r.checkedAt) >= renderRecheck
+	r.mu.RUnlock()
+	return due && r.Check() == nil
+}
+
+func (r *Renderer) setAvailable(ok bool) {
+	if r.available.Swap(ok) == ok {
+		return
+	}
+	if ok {
+		fmt.Println("✅ Browser service is available, rendering JavaScript sites")
+	} else {
+		fmt.Println("⚠️  Browser service is unavailable, JavaScript sites fall back to static extraction")
+	}
+}
+
+// SetWait sets when pages of domain and its subdomains are ready.
+func (r *Renderer) SetWait(domain string, wait RenderWait) {
+	r.mu.Lock()
+	r.waits[strings.TrimPrefix(strings.ToLower(domain), "www.")] = wait
+	r.mu.Unlock()
+}
+
+// wait returns the wait conditions of host's pages.
+func (r *Renderer) wait(host string) RenderWait {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for host != "" {
+		if w, ok := r.waits[strings.TrimPrefix(host, "www.")]; ok {
+			return w
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+	return RenderWait{}
+}
+
+// renderRequest is the body of a Browserless /content request.
+type renderRequest struct {
+	URL                  string            `json:"url"`
+	GotoOptions          map[string]any    `json:"gotoOptions"`
+	WaitForSelector      map[string]any    `json:"waitForSelector,omitempty"`
+	WaitForTimeout       int64             `json:"waitForTimeout,omitempty"`
+	RejectResourceTypes  []string          `json:"rejectResourceTypes,omitempty"`
+	RejectRequestPattern []string          `json:"rejectRequestPattern,omitempty"`
+	SetExtraHTTPHeaders  map[string]string `json:"setExtraHTTPHeaders,omitempty"`
+}
+
+// Render returns the HTML of pageURL once its scripts have built it.
+func (r *Renderer) Render(pageURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	wait := r.wait(strings.ToLower(u.Hostname()))
+	timeout := r.opts.Timeout.Milliseconds()
+
+	req := renderRequest{
+		URL:                  pageURL,
+		GotoOptions:          map[string]any{"waitUntil": "domcontentloaded", "timeout": timeout},
+		RejectResourceTypes:  r.opts.Block,
+		RejectRequestPattern: r.opts.BlockPatterns,
+		SetExtraHTTPHeaders:  map[string]string{"Accept-Language": "tr-TR,tr;q=0.9,en;q=0.8"},
+	}
+	if wait.NetworkIdle {
+		req.GotoOptions["waitUntil"] = "networkidle2"
+	}
+	if wait.Selector != "" {
+		req.WaitForSelector = map[string]any{"selector": wait.Selector, "timeout": timeout}
+	}
+	if wait.Delay > 0 {
+		req.WaitForTimeout = time.Duration(wait.Delay).Milliseconds()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := r.opts.Client.Post(r.endpoint("/content"), "application/json", bytes.NewReader(body))
+	if err != nil {
+		r.setAvailable(false)
+		return "", fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		r.setAvailable(false)
+		return "", fmt.Errorf("%w: answered %d", ErrBrowserUnavailable, resp.StatusCode)
+	}
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("browser service answered %d: %s", resp.StatusCode, strings.TrimSpace(string(page[:min(len(page), 200)])))
+	}
+	return string(page), nil
+}
+
+// endpoint returns the URL of the service's API at path, keeping the query
+// of the configured endpoint (such as its token) and adding the launch
+// options.
+func (r *Renderer) endpoint(path string) string {
+	u, _ := url.Parse(r.opts.Endpoint)
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	if r.opts.Stealth {
+		q := u.Query()
+		q.Set("launch", `{"stealth":true}`)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// RenderExtractor renders pages in a headless browser and hands the result
+// to a static extractor. While the browser service is unavailable, pages go
+// to the Unrendered extractor, or to the static one when it is nil.
+type RenderExtractor struct {
+	Renderer *Renderer
+	// Static extracts the article from the rendered page
+	Static Extractor
+	// Unrendered extracts articles without the browser, e.g. a
+	// StateExtractor
+	Unrendered Extractor
+}
+
+func (e *RenderExtractor) Extract(input any) (string, []string, error) {
+	link := inputLink(input)
+	if link == "" {
+		// Pages given as HTML have been rendered already
+		return e.Static.Extract(input)
+	}
+	if e.Renderer == nil {
+		return "", nil, errNoRenderer
+	}
+	if !e.Renderer.ready() {
+		fmt.Printf("⚠️  Browser service unavailable, extracting %s without rendering\n", link)
+		return e.unrendered().Extract(input)
+	}
+	page, err := e.Renderer.Render(link)
+	if errors.Is(err, ErrBrowserUnavailable) {
+		fmt.Printf("⚠️  %v, extracting %s without rendering\n", err, link)
+		return e.unrendered().Extract(input)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return e.Static.Extract(map[string]interface{}{"html": page, "link": link})
+}
+
+// unrendered returns the extractor of pages the browser cannot render.
+func (e *RenderExtractor) unrendered() Extractor {
+	if e.Unrendered != nil {
+		return e.Unrendered
+	}
+	return e.Static
+}
+
+// inputLink returns the URL an extractor input asks to fetch, or "" when
+// the input carries its HTML.
+func inputLink(input any) string {
+	switch v := input.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if _, ok := v["html"]; ok {
+			return ""
+		}
+		for _, key := range []string{"url", "link"} {
+			if link, ok := v[key].(string); ok && link != "" {
+				return link
+			}
+		}
+	}
+	return ""
+}
+
+// errNoRenderer is returned by a RenderExtractor without a Renderer.
+var errNoRenderer = errors.New("no browser service configured")
@@ -0,0 +1,97 @@
+package extractors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRendererRequest(t *testing.T) {
+	var got renderRequest
+	var query string
+	svc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/content" {
+			t.Errorf("path = %s; want /content", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		got = renderRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("<html><body>rendered</body></html>"))
+	}))
+	defer svc.Close()
+
+	r, err := NewRenderer(RendererOptions{
+		Endpoint: svc.URL + "?token=secret",
+		Stealth:  true,
+		Block:    DefaultRenderBlock,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetWait("www.news.test", RenderWait{Selector: ".article", NetworkIdle: true, Delay: Duration(500 * time.Millisecond)})
+
+	page, err := r.Render("https://m.news.test/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != "<html><body>rendered</body></html>" {
+		t.Errorf("page = %q", page)
+	}
+	if query != "launch=%7B%22stealth%22%3Atrue%7D&token=secret" {
+		t.Errorf("query = %s", query)
+	}
+	if got.GotoOptions["waitUntil"] != "networkidle2" {
+		t.Errorf("waitUntil = %v; want networkidle2", got.GotoOptions["waitUntil"])
+	}
+	if got.WaitForSelector["selector"] != ".article" || got.WaitForTimeout != 500 {
+		t.Errorf("waits = %v, %d", got.WaitForSelector, got.WaitForTimeout)
+	}
+	if !slices.Equal(got.RejectResourceTypes, DefaultRenderBlock) {
+		t.Errorf("blocked = %q", got.RejectResourceTypes)
+	}
+
+	// Sites without wait conditions are read once their markup is loaded
+	if _, err := r.Render("https://other.test/a"); err != nil {
+		t.Fatal(err)
+	}
+	if got.GotoOptions["waitUntil"] != "domcontentloaded" || got.WaitForSelector != nil || got.WaitForTimeout != 0 {
+		t.Errorf("default waits = %v, %v, %d", got.GotoOptions, got.WaitForSelector, got.WaitForTimeout)
+	}
+}
+
+func TestNewRendererInvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "chrome:3000", "ws://chrome:3000"} {
+		if _, err := NewRenderer(RendererOptions{Endpoint: endpoint}); err == nil {
+			t.Errorf("NewRenderer(%q) succeeded", endpoint)
+		}
+	}
+}
+
+func TestRenderExtractorFallsBackWhenUnavailable(t *testing.T) {
+	svc := httptest.NewServer(http.NotFoundHandler())
+	svc.Close()
+
+	r, err := NewRenderer(RendererOptions{Endpoint: svc.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Check(); !errors.Is(err, ErrBrowserUnavailable) {
+		t.Fatalf("Check() = %v; want ErrBrowserUnavailable", err)
+	}
+	if r.Available() {
+		t.Error("Available() = true after a failed check")
+	}
+
+	e := &RenderExtractor{Renderer: r, Static: stubExtractor("static")}
+	content, _, err := e.Extract("https://news.test/a")
+	if err != nil || content != "static" {
+		t.Errorf("Extract = %q, %v; want the static extraction", content, err)
+	}
+}
@@ -0,0 +1,95 @@
+// internal/extractors/rewrites.go
+package extractors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Fields a text rule applies to.
+const (
+	FieldTitle   = "title"
+	FieldContent = "content"
+)
+
+// TextRule rewrites the text of articles, typically to swap one brand name
+// for another. Find is replaced with Replace literally or, when Regexp is
+// set, as a regular expression whose groups Replace may refer to as $1.
+// The rule applies to the articles of Domain and its subdomains (every
+// article when empty) and to Field, "title" or "content" (both when empty).
+// Content rules see the article's HTML, so a quote appears as &#34;.
+type TextRule struct {
+	Domain  string `json:"domain,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+	Regexp  bool   `json:"regexp,omitempty"`
+}
+
+// DefaultTextRules credit Artıgerçek's agency copy to Brief.tr.
+var DefaultTextRules = []TextRule{
+	{Domain: "artigercek.com", Field: FieldContent, Find: `(?:&#34;|")? ?\(?Haber Merkezi\)?`, Replace: "Brief.tr", Regexp: true},
+}
+
+// TextRewrites applies text rules to articles.
+type TextRewrites struct {
+	rules []textRule
+}
+
+type textRule struct {
+	domain  string
+	field   string
+	find    *regexp.Regexp
+	replace string
+}
+
+// NewTextRewrites compiles rules, which are applied in order.
+func NewTextRewrites(rules []TextRule) (*TextRewrites, error) {
+	t := &TextRewrites{rules: make([]textRule, 0, len(rules))}
+	for _, r := range rules {
+		if r.Find == "" {
+			return nil, fmt.Errorf("text rule for %q has nothing to find", r.Domain)
+		}
+		switch r.Field {
+		case "", FieldTitle, FieldContent:
+		default:
+			return nil, fmt.Errorf("text rule %q: unknown field %q (use title or content)", r.Find, r.Field)
+		}
+		pattern, replace := r.Find, r.Replace
+		if !r.Regexp {
+			pattern = regexp.QuoteMeta(pattern)
+			replace = strings.ReplaceAll(replace, "$", "$$")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("text rule %q: %w", r.Find, err)
+		}
+		t.rules = append(t.rules, textRule{
+			domain:  strings.TrimPrefix(strings.ToLower(r.Domain), "www."),
+			field:   r.Field,
+			find:    re,
+			replace: replace,
+		})
+	}
+	return t, nil
+}
+
+// Apply returns text, the given field of the article at articleURL, with
+// the matching rules applied.
+func (t *TextRewrites) Apply(articleURL, field, text string) string {
+	if t == nil || text == "" {
+		return text
+	}
+	host := articleHost(articleURL)
+	for _, r := range t.rules {
+		if r.field != "" && r.field != field {
+			continue
+		}
+		if !inDomain(host, r.domain) {
+			continue
+		}
+		text = r.find.ReplaceAllString(text, r.replace)
+	}
+	return text
+}
@@ -0,0 +1,35 @@
+package extractors
+
+import "testing"
+
+func TestTextRewrites(t *testing.T) {
+	rw, err := NewTextRewrites(append([]TextRule{
+		{Find: "$5 off", Replace: "a $ discount"},
+		{Domain: "news.test", Field: FieldTitle, Find: `^(\w+) Wire: `, Replace: "[$1] ", Regexp: true},
+	}, DefaultTextRules...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		article, field, text, want string
+	}{
+		{"https://other.test/a", FieldContent, "Get $5 off today", "Get a $ discount today"},
+		{"https://www.news.test/a", FieldTitle, "Acme Wire: Rates rise", "[Acme] Rates rise"},
+		{"https://www.news.test/a", FieldContent, "Acme Wire: Rates rise", "Acme Wire: Rates rise"},
+		{"https://other.test/a", FieldTitle, "Acme Wire: Rates rise", "Acme Wire: Rates rise"},
+		{"https://artigercek.com/a", FieldContent, "<p>Sonuç açıklandı. (Haber Merkezi)</p>", "<p>Sonuç açıklandı.Brief.tr</p>"},
+		{"https://artigercek.com/a", FieldContent, "<p>&#34; Haber Merkezi</p>", "<p>Brief.tr</p>"},
+	}
+	for _, tt := range tests {
+		if got := rw.Apply(tt.article, tt.field, tt.text); got != tt.want {
+			t.Errorf("Apply(%s, %s, %q) = %q; want %q", tt.article, tt.field, tt.text, got, tt.want)
+		}
+	}
+
+	for _, bad := range []TextRule{{Find: ""}, {Find: "x", Field: "body"}, {Find: "(", Regexp: true}} {
+		if _, err := NewTextRewrites([]TextRule{bad}); err == nil {
+			t.Errorf("rule %+v was accepted", bad)
+		}
+	}
+}
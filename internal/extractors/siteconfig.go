@@ -0,0 +1,386 @@
+// internal/extractors/siteconfig.go
+package extractors
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// Site configs are the community rule format of FiveFilters' full-text RSS
+// (github.com/fivefilters/ftr-site-config): a text file per site named after
+// its domain, "example.com.txt" or ".example.com.txt" for its subdomains
+// too, holding a "directive: value" per line and # comments, e.g.
+//
+//	body: //div[@class='article-body']
+//	strip: //div[contains(@class, 'related')]
+//	strip_id_or_class: newsletter
+//	strip_image_src: /pixel.gif
+//	single_page_link: //a[contains(@href, 'page=all')]
+//
+// body picks the article (the first element any of several body lines
+// matches), strip removes elements from it, strip_id_or_class removes the
+// elements having the word as class or id and strip_image_src the images
+// whose source contains the value, and single_page_link picks the link to
+// the single-page version of articles split across pages (its /@href step
+// optional), which is read instead. XPath expressions are translated to CSS
+// selectors when they are paths of element steps with [n], [last()],
+// attribute tests, contains(), starts-with(), the class-word idiom, and,
+// not() and | unions, and evaluated as XPath otherwise (single_page_link
+// is CSS only); invalid ones are skipped, and a file with neither a usable
+// body nor a single-page link is rejected. Other
+// directives (title, date, author, next_page_link, find_string,
+// test_url...) are ignored.
+//
+// As a superset, css_body and css_strip take CSS selectors, render: js
+// renders the site's pages in the browser and wait the CSS selector a
+// rendered page is ready on. Packs may also hold .json files of SiteRules.
+
+// ParseSiteConfig reads the site config of domain into a site rule.
+func ParseSiteConfig(domain string, r io.Reader) (SiteRule, error) {
+	rule := SiteRule{Domain: domain}
+	var bodies, xpathBodies []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		directive, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "body":
+			if sel, err := XPathToCSS(value); err == nil {
+				bodies = append(bodies, sel)
+			} else if _, err := CompileXPath(value); err == nil {
+				xpathBodies = append(xpathBodies, value)
+			}
+		case "strip":
+			if sel, err := XPathToCSS(value); err == nil {
+				rule.Remove = append(rule.Remove, sel)
+			} else if _, err := CompileXPath(value); err == nil {
+				rule.RemoveXPath = append(rule.RemoveXPath, value)
+			}
+		case "strip_id_or_class":
+			word := cssString(strings.Trim(value, `'"`))
+			rule.Remove = append(rule.Remove, "[class~="+word+"], [id~="+word+"]")
+		case "strip_image_src":
+			rule.Remove = append(rule.Remove, "img[src*="+cssString(strings.Trim(value, `'"`))+"]")
+		case "single_page_link":
+			if rule.SinglePageLink != "" {
+				break
+			}
+			if sel, err := XPathToCSS(strings.TrimSuffix(value, "/@href")); err == nil {
+				rule.SinglePageLink = sel
+			}
+		case "css_body":
+			bodies = append(bodies, value)
+		case "css_strip":
+			rule.Remove = append(rule.Remove, value)
+		case "render":
+			rule.Render = value
+		case "wait":
+			rule.Wait.Selector = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return SiteRule{}, err
+	}
+	if len(bodies) == 0 && len(xpathBodies) == 0 && rule.SinglePageLink == "" {
+		return SiteRule{}, fmt.Errorf("site %s: no body or single page link selector", domain)
+	}
+	rule.ContentSelector = strings.Join(bodies, ", ")
+	rule.ContentXPath = strings.Join(xpathBodies, " | ")
+	if err := rule.Validate(); err != nil {
+		return SiteRule{}, err
+	}
+	return rule, nil
+}
+
+// LoadSiteConfigs reads the site configs (.txt) and site rule lists (.json)
+// of fsys, with its subdirectories. Files that fail to load are reported
+// and left out; global.txt, FiveFilters' fallback rules, is skipped.
+func LoadSiteConfigs(fsys fs.FS) ([]SiteRule, []error) {
+	var rules []SiteRule
+	var errs []error
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		loaded, err := parseSiteFile(path.Base(name), data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return nil
+		}
+		rules = append(rules, loaded...)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return rules, errs
+}
+
+// parseSiteFile reads the rules of a pack file named name; files of other
+// kinds hold none.
+func parseSiteFile(name string, data []byte) ([]SiteRule, error) {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		var rules []SiteRule
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			if err := rule.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		return rules, nil
+	case strings.HasSuffix(name, ".txt") && name != "global.txt":
+		domain := strings.TrimPrefix(strings.TrimSuffix(name, ".txt"), ".")
+		if !strings.Contains(domain, ".") {
+			return nil, nil
+		}
+		rule, err := ParseSiteConfig(domain, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return []SiteRule{rule}, nil
+	}
+	return nil, nil
+}
+
+// LoadSitePack loads the site rules of source: a directory, or the http(s)
+// URL of a zip archive of one (such as a GitHub repository download) or of
+// a single rule file.
+func LoadSitePack(source string, client *http.Client) ([]SiteRule, []error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return LoadSiteConfigs(os.DirFS(source))
+	}
+
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, []error{fmt.Errorf("%s: HTTP %d", source, resp.StatusCode)}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, []error{fmt.Errorf("%s: %w", source, err)}
+		}
+		return LoadSiteConfigs(archive)
+	}
+	rules, err := parseSiteFile(path.Base(resp.Request.URL.Path), data)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %w", source, err)}
+	}
+	return rules, nil
+}
+
+// Predicates of the XPath subset site configs use.
+var (
+	xpathAttrEquals = regexp.MustCompile(`^@([\w:-]+)\s*=\s*(?:'([^']*)'|"([^"]*)")$`)
+	xpathAttr       = regexp.MustCompile(`^@([\w:-]+)$`)
+	xpathContains   = regexp.MustCompile(`^contains\(\s*@([\w:-]+)\s*,\s*(?:'([^']*)'|"([^"]*)")\s*\)$`)
+	xpathStartsWith = regexp.MustCompile(`^starts-with\(\s*@([\w:-]+)\s*,\s*(?:'([^']*)'|"([^"]*)")\s*\)$`)
+	// contains(concat(' ', normalize-space(@class), ' '), ' word ')
+	xpathWord     = regexp.MustCompile(`^contains\(\s*concat\(\s*['"] ['"]\s*,\s*normalize-space\(\s*@([\w:-]+)\s*\)\s*,\s*['"] ['"]\s*\)\s*,\s*(?:' ([^']*) '|" ([^"]*) ")\s*\)$`)
+	xpathNodeTest = regexp.MustCompile(`^(?:\*|[a-zA-Z][\w-]*)$`)
+)
+
+// XPathToCSS translates an XPath expression of the subset site configs use
+// into a CSS selector.
+func XPathToCSS(expr string) (string, error) {
+	var selectors []string
+	for _, p := range splitOutside(expr, "|") {
+		sel, err := xpathPathToCSS(strings.TrimSpace(p))
+		if err != nil {
+			return "", fmt.Errorf("xpath %q: %w", expr, err)
+		}
+		selectors = append(selectors, sel)
+	}
+	sel := strings.Join(selectors, ", ")
+	if _, err := cascadia.Compile(sel); err != nil {
+		return "", fmt.Errorf("xpath %q: %w", expr, err)
+	}
+	return sel, nil
+}
+
+// xpathPathToCSS translates a location path, its steps joined by / (child)
+// or // (descendant).
+func xpathPathToCSS(p string) (string, error) {
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		return "", errors.New("empty path")
+	}
+	var b strings.Builder
+	for i := 0; i < len(p); {
+		combinator := ""
+		switch {
+		case strings.HasPrefix(p[i:], "//"):
+			i += 2
+			combinator = " "
+		case p[i] == '/':
+			i++
+			combinator = " > "
+		case i > 0:
+			return "", fmt.Errorf("unexpected %q", p[i:])
+		}
+		end := i + len(splitOutside(p[i:], "/")[0])
+		step, err := xpathStepToCSS(p[i:end])
+		if err != nil {
+			return "", err
+		}
+		if b.Len() > 0 {
+			b.WriteString(combinator)
+		}
+		b.WriteString(step)
+		i = end
+	}
+	return b.String(), nil
+}
+
+// xpathStepToCSS translates a step: an element name or * and predicates.
+func xpathStepToCSS(step string) (string, error) {
+	name, predicates, bracketed := strings.Cut(step, "[")
+	name = strings.TrimPrefix(strings.TrimSpace(name), "descendant::")
+	if !xpathNodeTest.MatchString(name) {
+		return "", fmt.Errorf("unsupported step %q", step)
+	}
+	sel := name
+	if bracketed {
+		predicates = "[" + predicates
+	}
+	for predicates != "" {
+		if predicates[0] != '[' {
+			return "", fmt.Errorf("unsupported step %q", step)
+		}
+		inner := splitOutside(predicates[1:], "]")[0]
+		if len(inner)+1 >= len(predicates) {
+			return "", fmt.Errorf("unclosed predicate in %q", step)
+		}
+		pred, err := xpathPredicateToCSS(strings.TrimSpace(inner))
+		if err != nil {
+			return "", err
+		}
+		sel += pred
+		predicates = strings.TrimSpace(predicates[len(inner)+2:])
+	}
+	if sel != "*" && strings.HasPrefix(sel, "*") {
+		sel = sel[1:]
+	}
+	return sel, nil
+}
+
+// xpathPredicateToCSS translates a predicate into a compound selector.
+func xpathPredicateToCSS(pred string) (string, error) {
+	if conds := splitOutside(pred, " and "); len(conds) > 1 {
+		var sel string
+		for _, cond := range conds {
+			s, err := xpathPredicateToCSS(strings.TrimSpace(cond))
+			if err != nil {
+				return "", err
+			}
+			sel += s
+		}
+		return sel, nil
+	}
+	if n, err := strconv.Atoi(pred); err == nil && n > 0 {
+		return fmt.Sprintf(":nth-of-type(%d)", n), nil
+	}
+	if pred == "last()" {
+		return ":last-of-type", nil
+	}
+	if inner, ok := strings.CutPrefix(pred, "not("); ok && strings.HasSuffix(inner, ")") {
+		s, err := xpathPredicateToCSS(strings.TrimSpace(strings.TrimSuffix(inner, ")")))
+		if err != nil {
+			return "", err
+		}
+		return ":not(" + s + ")", nil
+	}
+	for _, test := range []struct {
+		re *regexp.Regexp
+		op string
+	}{
+		{xpathAttrEquals, "="},
+		{xpathContains, "*="},
+		{xpathStartsWith, "^="},
+		{xpathWord, "~="},
+	} {
+		if m := test.re.FindStringSubmatch(pred); m != nil {
+			return "[" + m[1] + test.op + cssString(m[2]+m[3]) + "]", nil
+		}
+	}
+	if m := xpathAttr.FindStringSubmatch(pred); m != nil {
+		return "[" + m[1] + "]", nil
+	}
+	return "", fmt.Errorf("unsupported predicate %q", pred)
+}
+
+// splitOutside splits s around sep where it is outside quotes, brackets
+// and parentheses.
+func splitOutside(s, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// cssString quotes s as a CSS string.
+func cssString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
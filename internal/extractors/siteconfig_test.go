@@ -0,0 +1,102 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestXPathToCSS(t *testing.T) {
+	tests := []struct {
+		xpath, css string
+	}{
+		{"//article", "article"},
+		{"//div[@class='article-body']", `div[class="article-body"]`},
+		{`//div[@id="content"]//p`, `div[id="content"] p`},
+		{"/html/body/div[2]", "html > body > div:nth-of-type(2)"},
+		{"//*[contains(@class, 'post-text')]", `[class*="post-text"]`},
+		{"//div[contains(concat(' ',normalize-space(@class),' '),' entry ')]", `div[class~="entry"]`},
+		{"//div[starts-with(@id, 'post-') and @itemprop]", `div[id^="post-"][itemprop]`},
+		{"//aside | //div[not(@data-ad)]", `aside, div:not([data-ad])`},
+		{"//ul[@class='tags']/li[last()]", `ul[class="tags"] > li:last-of-type`},
+	}
+	for _, tt := range tests {
+		got, err := XPathToCSS(tt.xpath)
+		if err != nil || got != tt.css {
+			t.Errorf("XPathToCSS(%q) = %q, %v; want %q", tt.xpath, got, err, tt.css)
+		}
+	}
+
+	for _, xpath := range []string{
+		"//div[contains(text(), 'Advertisement')]",
+		"//img/@src",
+		"//div[@class='a' or @class='b']",
+		"//div[@class='a'",
+	} {
+		if got, err := XPathToCSS(xpath); err == nil {
+			t.Errorf("XPathToCSS(%q) = %q; want an error", xpath, got)
+		}
+	}
+}
+
+func TestParseSiteConfig(t *testing.T) {
+	config := `# Example News
+title: //h1
+body: //div[@class='story']
+body: //div[contains(text(), 'unsupported')]
+strip: //div[@class='related']
+strip: //p[contains(text(), 'Read more')]
+strip_id_or_class: newsletter
+strip_image_src: /pixel.gif
+single_page_link: //a[@class='show-all']/@href
+prune: no
+test_url: https://example.com/2024/01/story
+`
+	rule, err := ParseSiteConfig("example.com", strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Domain != "example.com" || rule.ContentSelector != `div[class="story"]` || rule.SinglePageLink != `a[class="show-all"]` {
+		t.Errorf("rule = %+v", rule)
+	}
+	if rule.ContentXPath != "//div[contains(text(), 'unsupported')]" || len(rule.RemoveXPath) != 1 {
+		t.Errorf("XPath the CSS selectors cannot express: %q, %q", rule.ContentXPath, rule.RemoveXPath)
+	}
+	want := []string{`div[class="related"]`, `[class~="newsletter"], [id~="newsletter"]`, `img[src*="/pixel.gif"]`}
+	if strings.Join(rule.Remove, "|") != strings.Join(want, "|") {
+		t.Errorf("Remove = %q; want %q", rule.Remove, want)
+	}
+
+	if _, err := ParseSiteConfig("example.com", strings.NewReader("title: //h1\nbody: //p[\n")); err == nil {
+		t.Error("config without a usable body accepted")
+	}
+	rule, err = ParseSiteConfig("example.com", strings.NewReader("single_page_link: //a[@rel='print']\n"))
+	if err != nil || rule.ContentSelector != "" || rule.SinglePageLink != `a[rel="print"]` {
+		t.Errorf("single page link only: %+v, %v", rule, err)
+	}
+}
+
+func TestLoadSiteConfigs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ftr-site-config/example.com.txt":    {Data: []byte("body: //article\n")},
+		"ftr-site-config/.news.example.txt":  {Data: []byte("css_body: .post-content\nrender: js\nwait: .post-content\n")},
+		"ftr-site-config/global.txt":         {Data: []byte("body: //main\n")},
+		"ftr-site-config/LICENSE.txt":        {Data: []byte("Public domain\n")},
+		"ftr-site-config/broken.example.txt": {Data: []byte("title: //h1\n")},
+		"rules/local.json":                   {Data: []byte(`[{"domain":"local.example","content_selector":".body"}]`)},
+	}
+	rules, errs := LoadSiteConfigs(fsys)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.example.txt") {
+		t.Errorf("errors = %v; want broken.example.txt's", errs)
+	}
+	var domains []string
+	for _, rule := range rules {
+		domains = append(domains, rule.Domain)
+	}
+	if got := strings.Join(domains, ","); got != "news.example,example.com,local.example" {
+		t.Errorf("domains = %s", got)
+	}
+	if len(rules) == 3 && (!rules[0].Rendered() || rules[0].Wait.Selector != ".post-content") {
+		t.Errorf("superset directives not read: %+v", rules[0])
+	}
+}
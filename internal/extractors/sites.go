@@ -0,0 +1,190 @@
+package extractors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// SiteRule configures the extraction of a site without writing an extractor
+// for it, e.g.
+//
+//	{"domain": "halktv.com", "render": "js", "wait": {"selector": ".post-content"},
+//	 "content_selector": ".post-content", "remove": [".ad", ".related"]}
+//	{"domain": "medyascope.tv", "data": {"body": "props.pageProps.post.content", "image": "props.pageProps.post.image.url"}}
+type SiteRule struct {
+	// Domain is the site, subdomains included
+	Domain string `json:"domain"`
+	// Render is "js" for sites that build their articles with JavaScript:
+	// their pages are rendered in the headless browser before extraction
+	Render string `json:"render,omitempty"`
+	// Wait says when a rendered page is ready
+	Wait RenderWait `json:"wait,omitempty"`
+	// ContentSelector picks the article body; the site's extractor, or the
+	// default one, reads the page when it is empty or matches nothing
+	ContentSelector string `json:"content_selector,omitempty"`
+	// Remove are selectors of elements taken out of the article body
+	Remove []string `json:"remove,omitempty"`
+	// ContentXPath and RemoveXPath are XPath expressions picking the
+	// article body, when ContentSelector matches nothing, and elements
+	// taken out of it, for pages CSS selectors cannot pick apart
+	ContentXPath string   `json:"content_xpath,omitempty"`
+	RemoveXPath  []string `json:"remove_xpath,omitempty"`
+	// SinglePageLink picks the link to the single-page version of articles
+	// split across pages, which is read instead when the page has one
+	SinglePageLink string `json:"single_page_link,omitempty"`
+	// Data locates the article in the page's state blob (__NEXT_DATA__,
+	// __NUXT_DATA__, window.__INITIAL_STATE__), which is then read before
+	// the site's extractor
+	Data *StatePaths `json:"data,omitempty"`
+}
+
+// Rendered reports whether the site's pages are rendered in the browser.
+func (r SiteRule) Rendered() bool {
+	return r.Render == "js"
+}
+
+// Validate reports the first problem of the rule.
+func (r SiteRule) Validate() error {
+	if strings.TrimSpace(r.Domain) == "" {
+		return errors.New("site rule without domain")
+	}
+	if r.Render != "" && r.Render != "js" {
+		return fmt.Errorf("site %s: unknown render mode %q (want \"js\")", r.Domain, r.Render)
+	}
+	if r.Data != nil && r.Data.Body == "" {
+		return fmt.Errorf("site %s: data without a body path", r.Domain)
+	}
+	for _, sel := range append([]string{r.ContentSelector, r.Wait.Selector, r.SinglePageLink}, r.Remove...) {
+		if sel == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(sel); err != nil {
+			return fmt.Errorf("site %s: invalid selector %q: %w", r.Domain, sel, err)
+		}
+	}
+	for _, expr := range append([]string{r.ContentXPath}, r.RemoveXPath...) {
+		if expr == "" {
+			continue
+		}
+		if _, err := CompileXPath(expr); err != nil {
+			return fmt.Errorf("site %s: %w", r.Domain, err)
+		}
+	}
+	return nil
+}
+
+// SelectorExtractor extracts the article body a CSS selector picks.
+type SelectorExtractor struct {
+	httpClient *http.Client
+	// content are the matchers of the article body, tried in turn
+	content    []goquery.Matcher
+	remove     []goquery.Matcher
+	singlePage goquery.Matcher
+	// fallback reads pages the selector finds nothing in
+	fallback Extractor
+}
+
+// NewSelectorExtractor creates the extractor of rule's content selector
+// and XPath and single-page link, falling back to fallback. If client is nil, a
+// client with a 15 second timeout is used.
+func NewSelectorExtractor(rule SiteRule, client *http.Client, fallback Extractor) (*SelectorExtractor, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if rule.ContentSelector == "" && rule.ContentXPath == "" && rule.SinglePageLink == "" {
+		return nil, fmt.Errorf("site %s: no content selector", rule.Domain)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	e := &SelectorExtractor{
+		httpClient: client,
+		fallback:   fallback,
+	}
+	if rule.ContentSelector != "" {
+		e.content = append(e.content, cascadia.MustCompile(rule.ContentSelector))
+	}
+	if rule.ContentXPath != "" {
+		e.content = append(e.content, MustCompileXPath(rule.ContentXPath))
+	}
+	if rule.SinglePageLink != "" {
+		e.singlePage = cascadia.MustCompile(rule.SinglePageLink)
+	}
+	for _, sel := range rule.Remove {
+		e.remove = append(e.remove, cascadia.MustCompile(sel))
+	}
+	for _, expr := range rule.RemoveXPath {
+		e.remove = append(e.remove, MustCompileXPath(expr))
+	}
+	return e, nil
+}
+
+func (e *SelectorExtractor) Extract(input any) (string, []string, error) {
+	page, link, err := fetchPage(e.httpClient, input)
+	if err != nil {
+		return "", nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", nil, err
+	}
+	if single := e.singlePageURL(doc, link); single != "" {
+		// The first page is read when the single-page version fails
+		if singlePage, _, err := fetchPage(e.httpClient, single); err == nil {
+			if singleDoc, err := goquery.NewDocumentFromReader(strings.NewReader(singlePage)); err == nil {
+				page, link, doc = singlePage, single, singleDoc
+			}
+		}
+	}
+
+	var content *goquery.Selection
+	for _, m := range e.content {
+		if content = doc.FindMatcher(m).First(); content.Length() > 0 {
+			break
+		}
+	}
+	if content == nil || content.Length() == 0 {
+		if e.fallback == nil {
+			return "", nil, ErrNoContent
+		}
+		return e.fallback.Extract(map[string]interface{}{"html": page, "link": link})
+	}
+	for _, m := range e.remove {
+		content.FindMatcher(m).Remove()
+	}
+	content.FindMatcher(scriptSel).Remove()
+
+	body, err := goquery.OuterHtml(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("error getting HTML content: %v", err)
+	}
+	return sanitizeHTML(body), LeadImages(doc, link), nil
+}
+
+// singlePageURL returns the absolute URL of the single-page version doc
+// links to, or "" when it has none or is that version.
+func (e *SelectorExtractor) singlePageURL(doc *goquery.Document, link string) string {
+	if e.singlePage == nil {
+		return ""
+	}
+	href, ok := doc.FindMatcher(e.singlePage).First().Attr("href")
+	if !ok || strings.TrimSpace(href) == "" {
+		return ""
+	}
+	base, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	single, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (single.Scheme != "http" && single.Scheme != "https") || single.String() == link {
+		return ""
+	}
+	return single.String()
+}
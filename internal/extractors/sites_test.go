@@ -0,0 +1,124 @@
+package extractors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// stubExtractor returns its content for every input.
+type stubExtractor string
+
+func (s stubExtractor) Extract(input any) (string, []string, error) {
+	return string(s), nil, nil
+}
+
+// pageExtractor returns the page it is given.
+type pageExtractor struct{}
+
+func (pageExtractor) Extract(input any) (string, []string, error) {
+	page, _, err := fetchPage(nil, input)
+	return page, nil, err
+}
+
+func TestSelectorExtractor(t *testing.T) {
+	e, err := NewSelectorExtractor(SiteRule{
+		Domain:          "news.test",
+		ContentSelector: ".post-content",
+		Remove:          []string{".ad"},
+	}, nil, stubExtractor("fallback"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := `<html><body><div class="post-content"><p>Body</p><div class="ad">Buy</div><script>x()</script></div></body></html>`
+	content, _, err := e.Extract(map[string]interface{}{"html": page, "link": "https://news.test/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "<p>Body</p>") || strings.Contains(content, "Buy") || strings.Contains(content, "x()") {
+		t.Errorf("content = %q", content)
+	}
+
+	// Pages the selector finds nothing in go to the fallback
+	content, _, err = e.Extract(map[string]interface{}{"html": "<p>Other</p>"})
+	if err != nil || content != "fallback" {
+		t.Errorf("Extract = %q, %v; want the fallback", content, err)
+	}
+}
+
+func TestSelectorExtractorXPath(t *testing.T) {
+	e, err := NewSelectorExtractor(SiteRule{
+		Domain:          "news.test",
+		ContentSelector: ".post-content",
+		ContentXPath:    "//div[h1[contains(., 'Story')]]",
+		RemoveXPath:     []string{"//p[starts-with(normalize-space(), 'Advertisement')]"},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := `<div><h1>Story</h1><p>Body</p><p> Advertisement: buy</p></div>`
+	content, _, err := e.Extract(map[string]interface{}{"html": page, "link": "https://news.test/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "<p>Body</p>") || strings.Contains(content, "buy") {
+		t.Errorf("content = %q", content)
+	}
+}
+
+func TestSelectorExtractorSinglePageLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "all" {
+			fmt.Fprint(w, `<div class="post-content"><p>Page one</p><p>Page two</p></div>`)
+			return
+		}
+		fmt.Fprint(w, `<a class="all" href="?page=all">Single page</a><div class="post-content"><p>Page one</p></div>`)
+	}))
+	defer server.Close()
+
+	e, err := NewSelectorExtractor(SiteRule{
+		Domain:          "news.test",
+		ContentSelector: ".post-content",
+		SinglePageLink:  "a.all",
+	}, server.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _, err := e.Extract(server.URL + "/story")
+	if err != nil || !strings.Contains(content, "Page two") {
+		t.Errorf("Extract = %q, %v; want the single-page version", content, err)
+	}
+
+	// Without a content selector, the fallback reads the single page
+	e, err = NewSelectorExtractor(SiteRule{Domain: "news.test", SinglePageLink: "a.all"}, server.Client(), pageExtractor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _, err := e.Extract(server.URL + "/story"); err != nil || !strings.Contains(content, "Page two") {
+		t.Errorf("Extract = %q, %v; want the single-page version", content, err)
+	}
+}
+
+func TestSiteRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule SiteRule
+		ok   bool
+	}{
+		{SiteRule{Domain: "news.test", Render: "js", ContentSelector: "article"}, true},
+		{SiteRule{Domain: "news.test", Render: "js", Wait: RenderWait{NetworkIdle: true}}, true},
+		{SiteRule{ContentSelector: "article"}, false},
+		{SiteRule{Domain: "news.test", Render: "browser"}, false},
+		{SiteRule{Domain: "news.test", ContentSelector: "div["}, false},
+		{SiteRule{Domain: "news.test", Wait: RenderWait{Selector: "::"}}, false},
+		{SiteRule{Domain: "news.test", ContentXPath: "//div[p]", RemoveXPath: []string{"//aside"}}, true},
+		{SiteRule{Domain: "news.test", RemoveXPath: []string{"//div["}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v", tt.rule, err)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package extractors
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// stateGlobals are the globals single-page apps assign their initial state
+// to in an inline script.
+var stateGlobals = []string{"__INITIAL_STATE__", "__PRELOADED_STATE__", "__APOLLO_STATE__", "__APP_STATE__", "__STATE__", "__NUXT__"}
+
+// stateBodyKeys are the keys, lower-cased, under which state blobs keep an
+// article's body.
+var stateBodyKeys = map[string]bool{
+	"body": true, "bodyhtml": true, "articlebody": true, "content": true,
+	"contenthtml": true, "html": true, "text": true, "fulltext": true, "detail": true,
+}
+
+// minStateText is the amount of text below which a state string is not
+// taken for an article body.
+const minStateText = 200
+
+// StatePaths locate an article in a page's state blob. Paths are keys
+// separated by dots, with array indexes as numbers, e.g.
+// "props.pageProps.article.body" or "data.0.content".
+type StatePaths struct {
+	Body  string `json:"body,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// StateExtractor recovers the articles of JavaScript sites without a
+// browser: single-page apps ship the article they render in a JSON state
+// blob (Next.js' __NEXT_DATA__, Nuxt's __NUXT_DATA__ and window.__NUXT__,
+// window.__INITIAL_STATE__ and the like). The body is read at the configured
+// path or, without one, taken to be the longest body-like string. Pages
+// without such a blob go to the fallback extractor.
+type StateExtractor struct {
+	httpClient *http.Client
+	paths      StatePaths
+	fallback   Extractor
+}
+
+// NewStateExtractor creates a StateExtractor reading articles at paths and
+// falling back to fallback. If client is nil, a client with a 15 second
+// timeout is used.
+func NewStateExtractor(client *http.Client, paths StatePaths, fallback Extractor) *StateExtractor {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &StateExtractor{httpClient: client, paths: paths, fallback: fallback}
+}
+
+func (e *StateExtractor) Extract(input any) (string, []string, error) {
+	page, link, err := fetchPage(e.httpClient, input)
+	if err != nil {
+		return "", nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var body, image string
+	for _, blob := range stateBlobs(doc) {
+		b, _ := statePath(blob, e.paths.Body).(string)
+		if e.paths.Body == "" {
+			b = stateBody(blob)
+		}
+		if textLen(b) > textLen(body) {
+			body = b
+			image, _ = statePath(blob, e.paths.Image).(string)
+		}
+	}
+	if textLen(body) < minStateText {
+		if e.fallback == nil {
+			return "", nil, fmt.Errorf("%w: no article state in the page", ErrNoContent)
+		}
+		return e.fallback.Extract(map[string]interface{}{"html": page, "link": link})
+	}
+
+	images := LeadImages(doc, link)
+	if image != "" {
+		images = append([]string{image}, images...)
+	}
+	return sanitizeHTML(stateHTML(body)), images, nil
+}
+
+// statePath returns the value at path in blob, or nil.
+func statePath(blob any, path string) any {
+	if path == "" {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := blob.(type) {
+		case map[string]any:
+			blob = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			blob = v[i]
+		default:
+			return nil
+		}
+	}
+	return blob
+}
+
+// stateBlobs returns the decoded state blobs of a page.
+func stateBlobs(doc *goquery.Document) []any {
+	var blobs []any
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		text := s.Text()
+		switch id, _ := s.Attr("id"); id {
+		case "__NEXT_DATA__":
+			var v any
+			if json.Unmarshal([]byte(text), &v) == nil {
+				blobs = append(blobs, v)
+			}
+			return
+		case "__NUXT_DATA__":
+			var v []any
+			if json.Unmarshal([]byte(text), &v) == nil && len(v) > 0 {
+				blobs = append(blobs, unflattenNuxt(v))
+			}
+			return
+		}
+		for _, global := range stateGlobals {
+			i := strings.Index(text, global)
+			if i < 0 {
+				continue
+			}
+			rest := strings.TrimLeft(text[i+len(global):], " \t\r\n")
+			if !strings.HasPrefix(rest, "=") {
+				continue
+			}
+			// The decoder stops after the value, before any trailing code
+			var v any
+			if json.NewDecoder(strings.NewReader(rest[1:])).Decode(&v) == nil {
+				blobs = append(blobs, v)
+			}
+		}
+	})
+	return blobs
+}
+
+// nuxtWrappers are the types Nuxt 3 serializes as ["Type", index]; the
+// value they wrap is at index.
+var nuxtWrappers = map[string]bool{
+	"Reactive": true, "ShallowReactive": true, "Ref": true, "ShallowRef": true,
+}
+
+// unflattenNuxt rebuilds the state Nuxt 3 serializes in __NUXT_DATA__: a
+// flat array whose first element is the root, where the members of objects
+// and arrays are indexes of their values in the array.
+func unflattenNuxt(flat []any) any {
+	var hydrate func(i, depth int) any
+	hydrate = func(i, depth int) any {
+		// Negative indexes stand for undefined, NaN and the like; the
+		// depth bound guards against cycles
+		if i < 0 || i >= len(flat) || depth > 64 {
+			return nil
+		}
+		switch v := flat[i].(type) {
+		case map[string]any:
+			obj := make(map[string]any, len(v))
+			for key, ref := range v {
+				if n, ok := ref.(float64); ok {
+					obj[key] = hydrate(int(n), depth+1)
+				}
+			}
+			return obj
+		case []any:
+			if len(v) == 2 {
+				if kind, ok := v[0].(string); ok && nuxtWrappers[kind] {
+					n, _ := v[1].(float64)
+					return hydrate(int(n), depth+1)
+				}
+			}
+			if len(v) > 0 {
+				if _, ok := v[0].(string); ok {
+					// Dates, sets, maps and other special types
+					return nil
+				}
+			}
+			arr := make([]any, 0, len(v))
+			for _, ref := range v {
+				n, _ := ref.(float64)
+				arr = append(arr, hydrate(int(n), depth+1))
+			}
+			return arr
+		default:
+			return v
+		}
+	}
+	return hydrate(0, 0)
+}
+
+// stateBody returns the longest string kept under a body-like key of blob.
+func stateBody(blob any) string {
+	var best string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				if s, ok := value.(string); ok && stateBodyKeys[strings.ToLower(key)] {
+					if textLen(s) > textLen(best) {
+						best = s
+					}
+					continue
+				}
+				walk(value)
+			}
+		case []any:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+	walk(blob)
+	return best
+}
+
+// stateHTML returns a state body as HTML: bodies kept as plain text get a
+// paragraph per line.
+func stateHTML(body string) string {
+	if htmlTagRegex.MatchString(body) {
+		return body
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.WriteString("<p>" + html.EscapeString(line) + "</p>")
+		}
+	}
+	return b.String()
+}
+
+// textLen returns the number of characters of text in an HTML fragment.
+func textLen(s string) int {
+	return len([]rune(strings.TrimSpace(htmlTagRegex.ReplaceAllString(s, ""))))
+}
+
+// fetchPage returns the HTML of an extractor input, fetching it with client
+// when the input is a URL, and the page's URL when known.
+func fetchPage(client *http.Client, input any) (string, string, error) {
+	switch v := input.(type) {
+	case map[string]string:
+		if page, ok := v["html"]; ok {
+			return page, v["link"], nil
+		}
+	case map[string]interface{}:
+		if page, ok := v["html"].(string); ok {
+			link, _ := v["link"].(string)
+			return page, link, nil
+		}
+	}
+	link := inputLink(input)
+	if link == "" {
+		return "", "", fmt.Errorf("unsupported input type: %T", input)
+	}
+
+	resp, err := client.Get(link)
+	if err != nil {
+		return "", "", fetchError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", &UpstreamStatusError{Code: resp.StatusCode}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return string(body), link, nil
+}
@@ -0,0 +1,76 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateExtractor(t *testing.T) {
+	body := strings.Repeat("Haberin gövdesi burada. ", 20)
+	tests := []struct {
+		name, page, want string
+	}{
+		{"next data", `<html><body><div id="__next"></div>
+<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"article":{"title":"T","body":"<p>` + body + `</p>"}}}}</script>
+</body></html>`, "<p>" + body + "</p>"},
+		{"initial state", `<html><body><script>window.__INITIAL_STATE__ = {"news":{"summary":"short","content":"` + body + `\nSecond paragraph"}};window.foo=1;</script></body></html>`,
+			"<p>" + strings.TrimSpace(body) + "</p><p>Second paragraph</p>"},
+		{"no state", `<html><body><script>var x = 1;</script></body></html>`, "fallback"},
+	}
+
+	e := NewStateExtractor(nil, StatePaths{}, stubExtractor("fallback"))
+	for _, tt := range tests {
+		content, _, err := e.Extract(map[string]interface{}{"html": tt.page, "link": "https://news.test/a"})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(content, tt.want) {
+			t.Errorf("%s: content = %q; want %q", tt.name, content, tt.want)
+		}
+	}
+}
+
+func TestStateExtractorPaths(t *testing.T) {
+	body := strings.Repeat("Makalenin metni. ", 20)
+	// The configured path wins over the longer teaser
+	page := `<script id="__NEXT_DATA__" type="application/json">{"props":{"pageProps":{"teaser":{"content":"` + body + body + `"},"post":{"text":"` + body + `","cover":{"url":"https://cdn.news.test/cover.jpg"}}}}}</script>`
+
+	e := NewStateExtractor(nil, StatePaths{Body: "props.pageProps.post.text", Image: "props.pageProps.post.cover.url"}, nil)
+	content, images, err := e.Extract(map[string]interface{}{"html": page, "link": "https://news.test/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(content, "Makalenin metni.") != 20 {
+		t.Errorf("content = %q; want the post's text", content)
+	}
+	if len(images) == 0 || images[0] != "https://cdn.news.test/cover.jpg" {
+		t.Errorf("images = %q; want the cover first", images)
+	}
+}
+
+func TestUnflattenNuxt(t *testing.T) {
+	// {"data": {"article": {"title": "T", "tags": ["a", "b"]}}}, with the
+	// state wrapped in a reactive and a date left out
+	flat := []any{
+		[]any{"ShallowReactive", 1.0},
+		map[string]any{"data": 2.0},
+		map[string]any{"article": 3.0},
+		map[string]any{"title": 4.0, "tags": 5.0, "date": 8.0},
+		"T",
+		[]any{6.0, 7.0},
+		"a",
+		"b",
+		[]any{"Date", "2024-01-01T00:00:00.000Z"},
+	}
+	got := unflattenNuxt(flat)
+	if title := statePath(got, "data.article.title"); title != "T" {
+		t.Errorf("title = %v; want T", title)
+	}
+	if tag := statePath(got, "data.article.tags.1"); tag != "b" {
+		t.Errorf("second tag = %v; want b", tag)
+	}
+	if date := statePath(got, "data.article.date"); date != nil {
+		t.Errorf("date = %v; want nil", date)
+	}
+}
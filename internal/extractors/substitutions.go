@@ -0,0 +1,96 @@
+// internal/extractors/substitutions.go
+package extractors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ImageRule replaces the images whose URL matches Match, a regular
+// expression, with Replace. The rule applies to the articles of Domain and
+// its subdomains, or to every article when Domain is empty.
+type ImageRule struct {
+	Domain  string
+	Match   string
+	Replace string
+}
+
+// DefaultImageRules replace the generic "son-dakika" (breaking news) stock
+// images some sites attach to every breaking story with a neutral one.
+var DefaultImageRules = []ImageRule{
+	{Domain: "dunya.com", Match: `(?i)son-dakika[^/]*$`, Replace: "https://newstr.netlify.app/public/images/breaking-news.jpg"},
+	{Domain: "kisadalga.net", Match: `(?i)son-dakika[^/]*$`, Replace: "https://newstr.netlify.app/public/images/breaking-news.jpg"},
+	{Domain: "ntv.com.tr", Match: `(?i)son-dakika`, Replace: "https://inewstr.netlify.app/breaking.jpg"},
+}
+
+// ImageSubstitutions applies image rules to the images of articles.
+type ImageSubstitutions struct {
+	rules []imageRule
+}
+
+type imageRule struct {
+	domain  string
+	match   *regexp.Regexp
+	replace string
+}
+
+// NewImageSubstitutions compiles rules, which are tried in order.
+func NewImageSubstitutions(rules []ImageRule) (*ImageSubstitutions, error) {
+	s := &ImageSubstitutions{rules: make([]imageRule, 0, len(rules))}
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("image rule %q: %w", r.Match, err)
+		}
+		s.rules = append(s.rules, imageRule{
+			domain:  strings.TrimPrefix(strings.ToLower(r.Domain), "www."),
+			match:   re,
+			replace: r.Replace,
+		})
+	}
+	return s, nil
+}
+
+// Apply returns the image to use instead of imageURL on the article at
+// articleURL: the replacement of the first matching rule, or imageURL itself.
+// The match is made against the image URL without its query string.
+func (s *ImageSubstitutions) Apply(articleURL, imageURL string) string {
+	if s == nil || imageURL == "" {
+		return imageURL
+	}
+	host := articleHost(articleURL)
+	target, _, _ := strings.Cut(imageURL, "?")
+	for _, r := range s.rules {
+		if !inDomain(host, r.domain) {
+			continue
+		}
+		if r.match.MatchString(target) {
+			return r.replace
+		}
+	}
+	return imageURL
+}
+
+// ParseImageRules parses rules written one per ";"-separated entry as
+// "[domain] pattern => replacement", e.g.
+// "dunya.com son-dakika => https://example.com/breaking.jpg".
+func ParseImageRules(spec string) ([]ImageRule, error) {
+	var rules []ImageRule
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		match, replace, ok := strings.Cut(entry, "=>")
+		fields := strings.Fields(match)
+		if !ok || len(fields) == 0 || len(fields) > 2 || strings.TrimSpace(replace) == "" {
+			return nil, fmt.Errorf("invalid image rule %q (want \"[domain] pattern => replacement\")", strings.TrimSpace(entry))
+		}
+		rule := ImageRule{Match: fields[len(fields)-1], Replace: strings.TrimSpace(replace)}
+		if len(fields) == 2 {
+			rule.Domain = fields[0]
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,50 @@
+package extractors
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImageSubstitutions(t *testing.T) {
+	rules, err := ParseImageRules("example.com stock/.*\\.jpg$ => https://img.test/stock.jpg; placeholder => https://img.test/blank.png;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ImageRule{
+		{Domain: "example.com", Match: `stock/.*\.jpg$`, Replace: "https://img.test/stock.jpg"},
+		{Match: "placeholder", Replace: "https://img.test/blank.png"},
+	}
+	if !slices.Equal(rules, want) {
+		t.Fatalf("ParseImageRules = %+v; want %+v", rules, want)
+	}
+	s, err := NewImageSubstitutions(append(rules, DefaultImageRules...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		article, image, want string
+	}{
+		{"https://www.example.com/a", "https://cdn.test/stock/fire.jpg?w=640", "https://img.test/stock.jpg"},
+		{"https://news.example.com/a", "https://cdn.test/stock/fire.jpg", "https://img.test/stock.jpg"},
+		// Domain rules only apply to their own articles
+		{"https://other.test/a", "https://cdn.test/stock/fire.jpg", "https://cdn.test/stock/fire.jpg"},
+		{"https://other.test/a", "https://cdn.test/placeholder.png", "https://img.test/blank.png"},
+		{"https://www.dunya.com/a", "https://i.dunya.com/images/Son-Dakika-1.jpg", "https://newstr.netlify.app/public/images/breaking-news.jpg"},
+		// Only the file name counts on dunya.com
+		{"https://www.dunya.com/a", "https://i.dunya.com/son-dakika/fabrika.jpg", "https://i.dunya.com/son-dakika/fabrika.jpg"},
+		{"https://www.ntv.com.tr/a", "https://cdn.ntv.com.tr/son-dakika-gorselleri/x.jpg", "https://inewstr.netlify.app/breaking.jpg"},
+	}
+	for _, tt := range tests {
+		if got := s.Apply(tt.article, tt.image); got != tt.want {
+			t.Errorf("Apply(%s, %s) = %s; want %s", tt.article, tt.image, got, tt.want)
+		}
+	}
+
+	if _, err := ParseImageRules("no arrow here"); err == nil {
+		t.Error("rule without => was accepted")
+	}
+	if _, err := NewImageSubstitutions([]ImageRule{{Match: "("}}); err == nil {
+		t.Error("invalid pattern was accepted")
+	}
+}
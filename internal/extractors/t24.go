@@ -3,41 +3,19 @@
 package extractors
 
 import (
-	"encoding/xml"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
 )
 
-// RSSFeed represents the structure of an RSS feed
-type RSSFeed struct {
-	XMLName xml.Name `xml:"rss"`
-	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Items       []RSSItem `xml:"item"`
-	} `xml:"channel"`
-}
-
-// RSSItem represents an item in an RSS feed
-type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-	Enclosure   struct {
-		URL string `xml:"url,attr"`
-	} `xml:"enclosure"`
-}
-
 // T24Extractor handles content extraction for t24.com.tr domain.
 // It extracts content from the articleBody property.
 type T24Extractor struct {
@@ -54,238 +32,104 @@ func NewT24Extractor(client *http.Client) *T24Extractor {
 	}
 }
 
-// Extract implements the Extractor interface for t24.com.tr URLs.
+// Extract implements the Extractor interface for t24.com.tr URLs. Besides a
+// URL or a map with html/url/link keys it accepts the parsed *gofeed.Item
+// (directly or under the "item" key), whose image and description are used
+// when the article page yields none.
 func (t *T24Extractor) Extract(input any) (string, []string, error) {
 	var (
-		images []string
-		title  string
-		urlStr string
+		item     *gofeed.Item
+		urlStr   string
+		htmlBody string
 	)
-	
-	// Print debug info about the input type
-	fmt.Printf("T24Extractor.Extract called with type: %T, value: %+v\n", input, input)
 
-	// Extract URL and title from input
 	switch v := input.(type) {
+	case *gofeed.Item:
+		item = v
 	case string:
 		urlStr = v
-		// Check if this is an RSS feed URL
-		if strings.Contains(urlStr, "/rss/") {
-			return t.extractFromRSSFeed(urlStr)
-		}
-		// Handle regular article URL
-		return t.extractFromURL(urlStr)
-		
 	case map[string]string:
-		if t, ok := v["title"]; ok {
-			title = t
-		}
-		if u, ok := v["url"]; ok {
-			urlStr = u
-		} else if u, ok := v["link"]; ok {
-			urlStr = u
-		}
-		
-		// If we have a URL, process it
-		if urlStr != "" {
-			if strings.Contains(urlStr, "/rss/") {
-				return t.extractFromRSSFeed(urlStr)
-			}
-			return t.extractFromURL(urlStr)
-		}
-		
+		urlStr = firstNonEmpty(v["url"], v["link"])
+		htmlBody = v["html"]
 	case map[string]interface{}:
-		// Extract title if available
-		if t, ok := v["title"].(string); ok {
-			title = t
-		}
-		
-		// Extract URL from either 'url' or 'link' field
-		if u, ok := v["url"].(string); ok && u != "" {
-			urlStr = u
-		} else if u, ok := v["link"].(string); ok && u != "" {
-			urlStr = u
-		}
-		
-		// If we have a URL, process it
-		if urlStr != "" {
-			if strings.Contains(urlStr, "/rss/") {
-				return t.extractFromRSSFeed(urlStr)
-			}
-			return t.extractFromURL(urlStr)
-		}
-	}
-
-	// Check for enclosure URL in the input (from RSS feed)
-	switch v := input.(type) {
-	case map[string]string:
-		// Check for enclosure URL in the map
-		if enclosureURL, ok := v["enclosure"]; ok && enclosureURL != "" {
-			images = append(images, enclosureURL)
-		}
-		// Handle HTML content if present
-		if htmlContent, ok := v["html"]; ok {
-			content, htmlImages, err := t.extractFromHTML(htmlContent)
-			if err != nil {
-				return title, images, nil // Return title as description if content extraction fails
-			}
-			// Prepend the enclosure image to the beginning of the images slice
-			images = append(images, htmlImages...)
-			// If content is empty, use title as description
-			if strings.TrimSpace(content) == "" {
-				return title, images, nil
-			}
-			return content, images, nil
-		}
-
-	case map[string]interface{}:
-		// Check for enclosure URL in the map
-		if enclosureVal, ok := v["enclosure"]; ok {
-			if enclosureURL, ok := enclosureVal.(string); ok && enclosureURL != "" {
-				images = append(images, enclosureURL)
-			}
-		}
-		// Handle HTML content if present
-		if htmlContent, ok := v["html"].(string); ok {
-			content, htmlImages, err := t.extractFromHTML(htmlContent)
-			if err != nil {
-				return title, images, nil // Return title as description if content extraction fails
-			}
-			// Prepend the enclosure image to the beginning of the images slice
-			images = append(images, htmlImages...)
-			// If content is empty, use title as description
-			if strings.TrimSpace(content) == "" {
-				return title, images, nil
-			}
-			return content, images, nil
-		}
-
-	case string:
-		// Input is a URL, fetch and extract content
-		content, htmlImages, err := t.extractFromURL(v)
-		if err != nil {
-			return title, images, nil // Return title as description if content extraction fails
-		}
-		images = append(images, htmlImages...)
-		// If content is empty, use title as description
-		if strings.TrimSpace(content) == "" {
-			return title, images, nil
-		}
-		return content, images, nil
-
+		item, _ = v["item"].(*gofeed.Item)
+		u, _ := v["url"].(string)
+		l, _ := v["link"].(string)
+		urlStr = firstNonEmpty(u, l)
+		htmlBody, _ = v["html"].(string)
 	default:
 		return "", nil, errors.New("unsupported input type for T24Extractor")
 	}
-
-	// If we get here but have an enclosure image, return it even without content
-	if len(images) > 0 {
-		return "", images, nil
+	if urlStr == "" && item != nil {
+		urlStr = item.Link
 	}
 
-	return "", nil, errors.New("no valid content found in input")
-}
-
-// extractFromRSSFeed fetches and parses an RSS feed, then extracts content from each article
-func (t *T24Extractor) extractFromRSSFeed(feedURL string) (string, []string, error) {
-	fmt.Printf("Fetching RSS feed from: %s\n", feedURL)
-	
-	// Fetch the RSS feed
-	resp, err := t.httpClient.Get(feedURL)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch RSS feed: %v", err)
+	var (
+		content string
+		images  []string
+		err     error
+	)
+	switch {
+	case htmlBody != "":
+		content, images, err = t.extractFromHTML(htmlBody)
+	case urlStr != "":
+		// Feeds are parsed by the feed handler, which passes their items here
+		if strings.Contains(urlStr, "/rss") {
+			return "", nil, fmt.Errorf("%w: %s is a feed, not an article", ErrFiltered, urlStr)
+		}
+		content, images, err = t.extractFromURL(urlStr)
+	default:
+		return "", nil, errors.New("no valid content found in input")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("failed to fetch RSS feed: %s", resp.Status)
+	// Fall back to what the feed item carries itself
+	if item != nil {
+		if len(images) == 0 {
+			images = feedItemImages(item)
+		}
+		if (err != nil || strings.TrimSpace(content) == "") && strings.TrimSpace(item.Description) != "" {
+			content, err = item.Description, nil
+		}
 	}
-
-	// Read and parse the RSS feed
-	var rssFeed RSSFeed
-	decoder := xml.NewDecoder(resp.Body)
-	if err := decoder.Decode(&rssFeed); err != nil {
-		return "", nil, fmt.Errorf("failed to parse RSS feed: %v", err)
+	if err == nil && strings.TrimSpace(content) == "" {
+		err = ErrNoContent
 	}
+	return content, images, err
+}
 
-	// Build the feed content
-	var feedContent strings.Builder
-	feedContent.WriteString(fmt.Sprintf("# %s\n\n", rssFeed.Channel.Title))
-	feedContent.WriteString(fmt.Sprintf("%s\n\n", rssFeed.Channel.Description))
-
+// feedItemImages returns the images attached to a feed item: image
+// enclosures, the item image and the first image in its description.
+func feedItemImages(item *gofeed.Item) []string {
 	var images []string
-
-	// Limit the number of items to process
-	maxItems := 10
-	if len(rssFeed.Channel.Items) > maxItems {
-		rssFeed.Channel.Items = rssFeed.Channel.Items[:maxItems]
+	for _, enc := range item.Enclosures {
+		if enc.URL != "" && (enc.Type == "" || strings.HasPrefix(enc.Type, "image/")) {
+			images = append(images, enc.URL)
+		}
 	}
-
-	// Process each item in the feed
-	for i, item := range rssFeed.Channel.Items {
-		// Add the item title and link
-		feedContent.WriteString(fmt.Sprintf("## [%s](%s)\n", item.Title, item.Link))
-		
-		// Add publication date if available
-		if item.PubDate != "" {
-			pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
-			if err == nil {
-				feedContent.WriteString(fmt.Sprintf("*%s*\n\n", pubDate.Format("2006-01-02 15:04:05")))
-			} else {
-				feedContent.WriteString(fmt.Sprintf("*%s*\n\n", item.PubDate))
+	if item.Image != nil && item.Image.URL != "" && !contains(images, item.Image.URL) {
+		images = append(images, item.Image.URL)
+	}
+	if len(images) == 0 && item.Description != "" {
+		if doc, err := goquery.NewDocumentFromReader(strings.NewReader(item.Description)); err == nil {
+			if src, ok := doc.FindMatcher(imgSel).First().Attr("src"); ok && src != "" {
+				images = append(images, src)
 			}
 		}
+	}
+	return images
+}
 
-		// Add the item description
-		feedContent.WriteString(fmt.Sprintf("%s\n\n", item.Description))
-
-			// Try to get the best possible image
-		var imageURL string
-		
-		// 1. Try to get Open Graph image from the article
-		_, articleImages, err := t.extractFromURL(item.Link)
-		if err == nil && len(articleImages) > 0 {
-			imageURL = articleImages[0]
-		} 
-		
-		// 2. Fall back to enclosure URL
-		if imageURL == "" && item.Enclosure.URL != "" {
-			imageURL = item.Enclosure.URL
-		}
-		
-		// 3. Fall back to the first image in the description
-		if imageURL == "" {
-			doc, err := goquery.NewDocumentFromReader(strings.NewReader(item.Description))
-			if err == nil {
-				doc.Find("img").First().Each(func(i int, s *goquery.Selection) {
-					if src, exists := s.Attr("src"); exists && src != "" {
-						imageURL = src
-					}
-				})
-			}
-		}
-		
-		// Add the image if found
-		if imageURL != "" {
-			images = append(images, imageURL)
-			feedContent.WriteString(fmt.Sprintf("![](%s)\n\n", imageURL))
-		}
-		
-		// Add a separator between items
-		if i < len(rssFeed.Channel.Items)-1 {
-			feedContent.WriteString("---\n\n")
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
 		}
 	}
-
-	return feedContent.String(), images, nil
+	return ""
 }
 
 // extractFromURL fetches the URL and extracts content.
 func (t *T24Extractor) extractFromURL(articleURL string) (string, []string, error) {
-	// Check if this is an RSS feed URL
-	if strings.Contains(articleURL, "/rss/") {
-		return t.extractFromRSSFeed(articleURL)
-	}
-
 	// Create a new request
 	req, err := http.NewRequest("GET", articleURL, nil)
 	if err != nil {
@@ -300,12 +144,12 @@ func (t *T24Extractor) extractFromURL(articleURL string) (string, []string, erro
 	// Send the request
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to fetch article: %v", err)
+		return "", nil, fetchError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", nil, fmt.Errorf("HTTP request failed with status: %s", resp.Status)
+		return "", nil, &UpstreamStatusError{Code: resp.StatusCode}
 	}
 
 	// Read the response body with proper encoding detection
@@ -316,6 +160,9 @@ func (t *T24Extractor) extractFromURL(articleURL string) (string, []string, erro
 
 	return t.extractFromHTML(string(htmlContent))
 }
+
+// extractFromHTML extracts the article body as HTML paragraphs and headings,
+// matching the output of the other extractors.
 func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
@@ -374,7 +221,7 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 	}
 	
 	if articleBody.Length() == 0 {
-		return "", nil, errors.New("article body not found - no matching selectors found")
+		return "", nil, fmt.Errorf("%w: no matching selectors found", ErrNoContent)
 	}
 
 	// Clean up the content
@@ -382,12 +229,10 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 
 	// Process the content
 	var contentBuilder strings.Builder
-	
-	// Add title if available
-	title := doc.Find("h1").First().Text()
-	if title != "" {
-		contentBuilder.WriteString(title)
-		contentBuilder.WriteString("\n\n")
+	writeBlock := func(tag, text string) {
+		contentBuilder.WriteString("<" + tag + ">")
+		contentBuilder.WriteString(html.EscapeString(text))
+		contentBuilder.WriteString("</" + tag + ">\n")
 	}
 
 	// Process the main content - look for direct <p> and <h3> elements
@@ -398,11 +243,10 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 		}
 
 		// Check if this is a direct child with the content we want
-		if goquery.NodeName(s) == "p" || goquery.NodeName(s) == "h3" {
+		if tag := goquery.NodeName(s); tag == "p" || tag == "h3" {
 			text := strings.TrimSpace(s.Text())
 			if text != "" {
-				contentBuilder.WriteString(text)
-				contentBuilder.WriteString("\n\n")
+				writeBlock(tag, text)
 			}
 		}
 
@@ -430,8 +274,7 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 			}
 
 			// Add the text to the content
-			contentBuilder.WriteString(text)
-			contentBuilder.WriteString("\n\n")
+			writeBlock(goquery.NodeName(inner), text)
 		})
 	})
 
@@ -442,8 +285,7 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 		doc.Find("p").Each(func(i int, s *goquery.Selection) {
 			text := strings.TrimSpace(s.Text())
 			if len(text) > 50 { // Only include paragraphs with substantial content
-				contentBuilder.WriteString(text)
-				contentBuilder.WriteString("\n\n")
+				writeBlock("p", text)
 			}
 		})
 		content = strings.TrimSpace(contentBuilder.String())
@@ -505,55 +347,27 @@ func (t *T24Extractor) extractFromHTML(htmlContent string) (string, []string, er
 // cleanContent removes unwanted elements from the article content.
 func cleanContent(s *goquery.Selection) {
 	// First remove all tables and their content
-	s.Find("table, tbody, thead, tfoot, tr, td, th").Each(func(i int, el *goquery.Selection) {
+	s.FindMatcher(t24TableSel).Each(func(i int, el *goquery.Selection) {
 		// Replace table cells with their text content
-		if el.Is("td, th") {
+		if el.IsMatcher(t24CellSel) {
 			el.AfterHtml(el.Text())
 		}
 		el.Remove()
 	})
 
 	// Remove script and style elements
-	s.Find(`script, style, iframe, noscript, 
-		.ad-container, .ad, .advertisement, .social-share, 
-		.related-news, .tags, .comments, .author-info, 
-		.article-footer, .article-meta, .article-share, 
-		.article-tags, .article-related, .article-comments, 
-		.article-author, .article-date, .article-category, 
-		.article-source, .article-url, .article-title, 
-		.article-image, .article-video, .article-audio, 
-		.article-gallery, .article-pagination, .article-navigation,
-		.article-recommend, .article-popular, .article-most-read,
-		.recommended-news, .popular-news, .most-read-news,
-		.social-buttons, .share-buttons, .social-media-buttons,
-		.fb-like, .twitter-tweet, .instagram-media,
-		.newsletter, .subscription, .signup-form,
-		.comment-section, .comment-form, .comment-list,
-		.pagination, .page-navigation, .pager,
-		.breadcrumb, .breadcrumbs, .site-map,
-		.footer, .site-footer, .main-footer,
-		.header, .site-header, .main-header,
-		.sidebar, .side-bar, .widget-area,
-		.modal, .popup, .lightbox,
-		[class*='cookie'], [id*='cookie'],
-		[class*='gdpr'], [id*='gdpr'],
-		[class*='privacy'], [id*='privacy'],
-		[class*='banner'], [id*='banner'],
-		[class*='popup'], [id*='popup'],
-		[class*='modal'], [id*='modal'],
-		[class*='overlay'], [id*='overlay'],
-		[class*='notification'], [id*='notification']`).Remove()
+	s.FindMatcher(t24ClutterSel).Remove()
 
 	// Remove empty elements
-	s.Find("p:empty, div:empty, span:empty, a:empty").Remove()
+	s.FindMatcher(t24EmptySel).Remove()
 
 	// Remove elements that only contain whitespace
-	s.Find("p, div, span").FilterFunction(func(i int, s *goquery.Selection) bool {
+	s.FindMatcher(t24TextBlockSel).FilterFunction(func(i int, s *goquery.Selection) bool {
 		return strings.TrimSpace(s.Text()) == ""
 	}).Remove()
 
 	// Clean up attributes
-	s.Find("*").Each(func(i int, el *goquery.Selection) {
+	s.FindMatcher(t24AllSel).Each(func(i int, el *goquery.Selection) {
 		el.RemoveAttr("style")
 		el.RemoveAttr("class")
 		el.RemoveAttr("id")
@@ -562,10 +376,10 @@ func cleanContent(s *goquery.Selection) {
 	})
 
 	// Remove inline styles and scripts
-	s.Find("[style], [onclick], [onload], [onerror]").RemoveAttr("style onclick onload onerror")
+	s.FindMatcher(t24InlineAttrSel).RemoveAttr("style onclick onload onerror")
 
 	// Clean up links
-	s.Find("a").Each(func(i int, el *goquery.Selection) {
+	s.FindMatcher(t24LinkSel).Each(func(i int, el *goquery.Selection) {
 		// Remove tracking parameters from URLs
 		if href, exists := el.Attr("href"); exists {
 			// Convert relative URLs to absolute
@@ -592,7 +406,7 @@ func cleanContent(s *goquery.Selection) {
 	})
 
 	// Clean up images
-	s.Find("img").Each(func(i int, el *goquery.Selection) {
+	s.FindMatcher(imgSel).Each(func(i int, el *goquery.Selection) {
 		// Convert relative URLs to absolute
 		if src, exists := el.Attr("src"); exists && strings.HasPrefix(src, "/") {
 			el.SetAttr("src", "https://t24.com.tr"+src)
@@ -618,64 +432,18 @@ func cleanContent(s *goquery.Selection) {
 	})
 	
 	// Remove any remaining script and style tags that might have been missed
-	s.Find("script, style, noscript, iframe, frame, object, embed, param, video, audio, source, track, canvas, svg, math").Remove()
+	s.FindMatcher(t24EmbeddedSel).Remove()
 	
 	// Remove any elements that might contain scripts or styles
-	s.Find("*[onclick], *[onload], *[onerror], *[onmouseover], *[onmouseout], *[onmousedown], *[onmouseup]").RemoveAttr("onclick onload onerror onmouseover onmouseout onmousedown onmouseup")
-	
-	// Remove any elements with suspicious attributes
-	s.Find("*[data-], *[ng-], *[v-], *[x-], *[@*], *[:*]").Remove()
-	
-	// Remove any elements that are likely ads or trackers
-	s.Find("ins.adsbygoogle, .ad, .advertisement, [id*='ad-'], [class*='ad-'], [id*='banner'], [class*='banner'], [id*='sponsor'], [class*='sponsor']").Remove()
-	
-	// Remove any elements that are likely social media embeds
-	s.Find(".fb-post, .fb-like, .fb-comments, .twitter-tweet, .instagram-media, .tiktok-embed").Remove()
-	
-	// Remove any elements that are likely newsletter signups
-	s.Find(".newsletter, .newsletter-form, .signup-form, .email-signup").Remove()
-	
-	// Remove any elements that are likely related content
-	s.Find(".related, .related-posts, .related-articles, .more-news, .read-more").Remove()
+	s.FindMatcher(t24EventAttrSel).RemoveAttr("onclick onload onerror onmouseover onmouseout onmousedown onmouseup")
 	
-	// Remove any elements that are likely navigation or pagination
-	s.Find(".pagination, .page-navigation, .pager, .nav-links").Remove()
-	
-	// Remove any elements that are likely breadcrumbs
-	s.Find(".breadcrumb, .breadcrumbs").Remove()
-	
-	// Remove any elements that are likely headers or footers
-	s.Find("header, footer, .header, .footer, .site-header, .site-footer, .main-header, .main-footer").Remove()
-	
-	// Remove any elements that are likely sidebars
-	s.Find("aside, .sidebar, .side-bar, .widget-area").Remove()
-	
-	// Remove any elements that are likely modals or popups
-	s.Find(".modal, .popup, .lightbox, .overlay").Remove()
-	
-	// Remove any elements that are likely cookie or GDPR notices
-	s.Find(".cookie-consent, .gdpr-banner, .privacy-banner, [class*='cookie'], [id*='cookie'], [class*='gdpr'], [id*='gdpr'], [class*='privacy'], [id*='privacy']").Remove()
-	
-	// Remove any elements that are likely banners or notifications
-	s.Find(".banner, .notification, .alert, .notice, .message, [role='alert'], [role='status'], [role='banner']").Remove()
-	
-	// Remove any elements that are likely tooltips or popovers
-	s.Find("[data-toggle='tooltip'], [data-toggle='popover'], [data-bs-toggle='tooltip'], [data-bs-toggle='popover'], .tooltip, .popover").Remove()
-	
-	// Remove any elements that are likely loading placeholders
-	s.Find(".loading, .loader, .spinner, .skeleton, .shimmer").Remove()
-	
-	// Remove any elements that are likely lazy-loaded
-	s.Find("[data-src], [data-lazy], [lazyload], .lazy, .lazyload").Remove()
-	
-	// Remove any elements that are likely hidden or offscreen
-	s.Find("[hidden], [aria-hidden='true'], .hidden, .d-none, .invisible, .sr-only, .visually-hidden").Remove()
-	
-	// Remove any elements that are likely empty containers
-	s.Find("div:empty, span:empty, p:empty, a:empty, li:empty, td:empty, th:empty").Remove()
+	// Remove ads, embeds, page chrome, hidden and empty elements
+	for _, sel := range t24TrailingSel {
+		s.FindMatcher(sel).Remove()
+	}
 	
 	// Remove any elements that only contain whitespace
-	s.Find("p, div, span, li, td, th").FilterFunction(func(i int, s *goquery.Selection) bool {
+	s.FindMatcher(t24WhitespaceSel).FilterFunction(func(i int, s *goquery.Selection) bool {
 		return strings.TrimSpace(s.Text()) == ""
 	}).Remove()
 }
@@ -1,6 +1,11 @@
 // internal/extractors/utils.go
 package extractors
 
+import (
+	"net/url"
+	"strings"
+)
+
 // Helper function to check if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -10,3 +15,18 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// articleHost returns the host of link, lower-cased and without "www.".
+func articleHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// inDomain reports whether host is domain or one of its subdomains; an
+// empty domain matches every host.
+func inDomain(host, domain string) bool {
+	return domain == "" || host == domain || strings.HasSuffix(host, "."+domain)
+}
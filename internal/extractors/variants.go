@@ -0,0 +1,85 @@
+package extractors
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// DefaultVariantPatterns are the print and reader versions tried for domains
+// without their own patterns.
+var DefaultVariantPatterns = []string{"?print=1", "/print{path}", "{path}/amp", "/amp{path}"}
+
+// Variants knows the print and reader versions of article pages, which often
+// have much cleaner markup than the page itself.
+//
+// A pattern starting with "?" adds query parameters to the article URL
+// ("?print=1"); any other pattern is the variant's path, with {path} standing
+// for the article path without its trailing slash ("/amp{path}").
+type Variants struct {
+	mu       sync.RWMutex
+	domains  map[string][]string
+	defaults []string
+}
+
+// NewVariants creates a Variants using defaults for unregistered domains.
+func NewVariants(defaults []string) *Variants {
+	return &Variants{domains: make(map[string][]string), defaults: defaults}
+}
+
+// Register sets the patterns for domain and its subdomains. Registering no
+// patterns disables variants for the domain.
+func (v *Variants) Register(domain string, patterns ...string) {
+	v.mu.Lock()
+	v.domains[strings.TrimPrefix(strings.ToLower(domain), "www.")] = patterns
+	v.mu.Unlock()
+}
+
+// URLs returns the variant URLs to try for articleURL, in order.
+func (v *Variants) URLs(articleURL string) []string {
+	u, err := url.Parse(articleURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+
+	var urls []string
+	for _, pattern := range v.patterns(u.Hostname()) {
+		variant := *u
+		variant.Fragment = ""
+		if strings.HasPrefix(pattern, "?") {
+			query := variant.Query()
+			extra, err := url.ParseQuery(pattern[1:])
+			if err != nil {
+				continue
+			}
+			for k, values := range extra {
+				query[k] = values
+			}
+			variant.RawQuery = query.Encode()
+		} else {
+			variant.Path = strings.ReplaceAll(pattern, "{path}", strings.TrimSuffix(u.Path, "/"))
+			variant.RawPath = ""
+		}
+		if s := variant.String(); s != articleURL && !contains(urls, s) {
+			urls = append(urls, s)
+		}
+	}
+	return urls
+}
+
+// patterns returns the patterns registered for host or its parent domains.
+func (v *Variants) patterns(host string) []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	domain := strings.TrimPrefix(strings.ToLower(host), "www.")
+	for {
+		if patterns, ok := v.domains[domain]; ok {
+			return patterns
+		}
+		i := strings.Index(domain, ".")
+		if i < 0 || !strings.Contains(domain[i+1:], ".") {
+			return v.defaults
+		}
+		domain = domain[i+1:]
+	}
+}
@@ -0,0 +1,35 @@
+package extractors
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVariantURLs(t *testing.T) {
+	v := NewVariants(DefaultVariantPatterns)
+	v.Register("example.com", "?output=print", "/reader{path}")
+	v.Register("quiet.test")
+
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://news.test/a/b/?id=1#top", []string{
+			"https://news.test/a/b/?id=1&print=1",
+			"https://news.test/print/a/b?id=1",
+			"https://news.test/a/b/amp?id=1",
+			"https://news.test/amp/a/b?id=1",
+		}},
+		// Registered patterns apply to subdomains too
+		{"https://www.sub.example.com/x", []string{
+			"https://www.sub.example.com/x?output=print",
+			"https://www.sub.example.com/reader/x",
+		}},
+		{"https://quiet.test/x", nil},
+	}
+	for _, tt := range tests {
+		if got := v.URLs(tt.url); !slices.Equal(got, tt.want) {
+			t.Errorf("URLs(%s) = %q; want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// internal/extractors/xpath.go
+package extractors
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// XPath is a compiled XPath expression evaluated over parsed HTML by
+// antchfx/xpath, for the site rules whose pages CSS selectors cannot pick
+// apart. Names are matched as written, and HTML is parsed lowercased.
+//
+// An XPath is a goquery.Matcher, so it is used with FindMatcher like a
+// compiled CSS selector. Expressions select the elements of the whole
+// document, and Find keeps those within the selection: //div[1] picks the
+// first div of its parent, not of the selection.
+type XPath struct {
+	expr *xpath.Expr
+}
+
+// CompileXPath compiles an XPath expression.
+func CompileXPath(expr string) (*XPath, error) {
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("xpath %q: %w", expr, err)
+	}
+	return &XPath{expr: compiled}, nil
+}
+
+// MustCompileXPath is like CompileXPath but panics if expr is invalid.
+func MustCompileXPath(expr string) *XPath {
+	x, err := CompileXPath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// String returns the source of the expression.
+func (x *XPath) String() string {
+	return x.expr.String()
+}
+
+// Select returns the elements the expression selects in the document of
+// n, in document order.
+func (x *XPath) Select(n *html.Node) []*html.Node {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	var elements []*html.Node
+	iter := x.expr.Select(htmlquery.CreateXPathNavigator(root))
+	for iter.MoveNext() {
+		nav := iter.Current().(*htmlquery.NodeNavigator)
+		if nav.NodeType() != xpath.ElementNode {
+			continue
+		}
+		if node := nav.Current(); !slices.Contains(elements, node) {
+			elements = append(elements, node)
+		}
+	}
+	return elements
+}
+
+// Match reports whether the expression selects n.
+func (x *XPath) Match(n *html.Node) bool {
+	return slices.Contains(x.Select(n), n)
+}
+
+// MatchAll returns the elements the expression selects among n and its
+// descendants.
+func (x *XPath) MatchAll(n *html.Node) []*html.Node {
+	var within []*html.Node
+	for _, m := range x.Select(n) {
+		for p := m; p != nil; p = p.Parent {
+			if p == n {
+				within = append(within, m)
+				break
+			}
+		}
+	}
+	return within
+}
+
+// Filter returns the nodes the expression selects.
+func (x *XPath) Filter(nodes []*html.Node) []*html.Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	selected := x.Select(nodes[0])
+	var kept []*html.Node
+	for _, n := range nodes {
+		if slices.Contains(selected, n) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
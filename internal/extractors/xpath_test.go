@@ -0,0 +1,89 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+const xpathTestPage = `<html><body>
+<div id="main" class="story  wide">
+	<h1>Title</h1>
+	<p class="lead">Lead</p>
+	<p>Advertisement</p>
+	<p>Second</p>
+	<ul><li>One</li><li>Two</li><li>Three</li></ul>
+</div>
+<div class="Related"><a href="/a" rel="next">Next</a></div>
+</body></html>`
+
+func TestXPathSelect(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(xpathTestPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want string // the text of the selected elements, joined by |
+	}{
+		{"//h1", "Title"},
+		{"//div[@id='main']/p[1]", "Lead"},
+		{"//div[@id='main']/p[last()]", "Second"},
+		{"//p[contains(text(), 'Advert')]", "Advertisement"},
+		{"//p[normalize-space() = 'Second' or @class = 'lead']", "Lead|Second"},
+		{"//div[contains(concat(' ', normalize-space(@class), ' '), ' story ')]/h1", "Title"},
+		{"//div[contains(translate(@class, 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz'), 'related')]/a", "Next"},
+		{"//li[position() > 1]", "Two|Three"},
+		{"//li[not(following-sibling::li)]", "Three"},
+		{"//p[preceding-sibling::p[1][@class='lead']]", "Advertisement"},
+		{"//a[@rel='next']/..", "Next"},
+		{"//li[. = 'Two']/ancestor::div/h1", "Title"},
+		{"(//li)[2]", "Two"},
+		{"//h1 | //li[1]", "Title|One"},
+		{"//ul[count(li) = 3]/li[string-length(.) = 3]", "One|Two"},
+		{"//p[starts-with(., 'Sec')] | //*[@href][substring-after(@href, '/') = 'a']", "Second|Next"},
+		{"//a/@href", ""},
+		{"//P", ""},
+	}
+	for _, tt := range tests {
+		x, err := CompileXPath(tt.expr)
+		if err != nil {
+			t.Errorf("CompileXPath(%q): %v", tt.expr, err)
+			continue
+		}
+		var texts []string
+		for _, n := range x.Select(doc) {
+			texts = append(texts, htmlquery.InnerText(n))
+		}
+		if got := strings.Join(texts, "|"); got != tt.want {
+			t.Errorf("%s selects %q; want %q", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "//div[", "//div[@id='x'", "//p[frobnicate()]", "//div/", "//p[text(1)]"} {
+		if _, err := CompileXPath(expr); err == nil {
+			t.Errorf("CompileXPath(%q) succeeded; want an error", expr)
+		}
+	}
+}
+
+func TestXPathMatcher(t *testing.T) {
+	page := `<div class="a"><p>In</p></div><div class="b"><p>Out</p></div>`
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := MustCompileXPath("//p")
+	var a *html.Node
+	for _, n := range MustCompileXPath("//div[@class='a']").Select(doc) {
+		a = n
+	}
+	if got := x.MatchAll(a); len(got) != 1 || got[0].FirstChild.Data != "In" {
+		t.Errorf("MatchAll kept %d nodes; want the paragraph within", len(got))
+	}
+	if !x.Match(a.FirstChild) || x.Match(a) {
+		t.Error("Match does not follow the selection")
+	}
+}
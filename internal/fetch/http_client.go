@@ -8,24 +8,55 @@ import (
 	"github.com/hashicorp/go-retryablehttp"
 )
 
+// Doer sends HTTP requests. *http.Client and *Client implement it, and tests
+// substitute fakes to simulate upstream responses and failures.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 // ClientOptions for the fetch client.
 type ClientOptions struct {
 	Timeout   time.Duration
 	UserAgent string
+	// RetryMax is the number of retries after the first attempt (2 when
+	// zero, none when negative)
+	RetryMax int
+	// RetryWaitMin and RetryWaitMax bound the backoff between retries;
+	// zero values keep the retryablehttp defaults
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// Transport performs the individual attempts (http.DefaultTransport-like
+	// pooled transport when nil)
+	Transport http.RoundTripper
 }
 
 // Client is a small wrapper around retryablehttp to provide timeouts and UA.
 type Client struct {
-	inner *retryablehttp.Client
+	inner     *retryablehttp.Client
+	userAgent string
 }
 
-// NewClient creates a new Client.
+// NewClient creates a new Client. After the last retry the final response is
+// returned as-is, so callers can inspect its status code.
 func NewClient(opts ClientOptions) *Client {
 	r := retryablehttp.NewClient()
 	r.RetryMax = 2
+	if opts.RetryMax != 0 {
+		r.RetryMax = max(opts.RetryMax, 0)
+	}
 	r.HTTPClient.Timeout = opts.Timeout
-	// default backoff and logger are fine
-	return &Client{inner: r}
+	if opts.RetryWaitMin > 0 {
+		r.RetryWaitMin = opts.RetryWaitMin
+	}
+	if opts.RetryWaitMax > 0 {
+		r.RetryWaitMax = opts.RetryWaitMax
+	}
+	if opts.Transport != nil {
+		r.HTTPClient.Transport = opts.Transport
+	}
+	r.Logger = nil
+	r.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	return &Client{inner: r, userAgent: opts.UserAgent}
 }
 
 // StandardClient returns the underlying *http.Client.
@@ -33,14 +64,22 @@ func (c *Client) StandardClient() *http.Client {
 	return c.inner.StandardClient()
 }
 
+// Do sends req with retries.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.inner.StandardClient().Do(req)
+}
+
 // Get returns the HTTP response body bytes via http.Get-style convenience.
 func (c *Client) Get(url string, headers map[string]string) (*http.Response, error) {
-	req, err := retryablehttp.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
-	return c.inner.StandardClient().Do(req.Request)
-}
\ No newline at end of file
+	return c.Do(req)
+}
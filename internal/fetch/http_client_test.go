@@ -0,0 +1,107 @@
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func response(status int) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(http.StatusText(status))),
+	}
+}
+
+func newTestClient(retries int, rt roundTripFunc) *Client {
+	return NewClient(ClientOptions{
+		Timeout:      time.Second,
+		UserAgent:    "gofull-test",
+		RetryMax:     retries,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		Transport:    rt,
+	})
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(3, func(*http.Request) (*http.Response, error) {
+		if attempts.Add(1) < 3 {
+			return response(http.StatusServiceUnavailable), nil
+		}
+		return response(http.StatusOK), nil
+	})
+
+	resp, err := client.Get("https://example.com/feed", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+		t.Errorf("Get() = %d after %d attempts; want 200 after 3", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestClientReturnsLastResponseAfterRetries(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(2, func(*http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return response(http.StatusBadGateway), nil
+	})
+
+	resp, err := client.Get("https://example.com/feed", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("StatusCode = %d; want the last upstream status 502", resp.StatusCode)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d; want 3", attempts.Load())
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	client := newTestClient(3, func(*http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return response(http.StatusNotFound), nil
+	})
+
+	resp, err := client.Get("https://example.com/feed", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if attempts.Load() != 1 {
+		t.Errorf("attempts = %d; want 1", attempts.Load())
+	}
+}
+
+func TestClientSetsHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(-1, func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Clone()
+		return response(http.StatusOK), nil
+	})
+
+	resp, err := client.Get("https://example.com/feed", map[string]string{"Accept": "application/rss+xml"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if got.Get("User-Agent") != "gofull-test" || got.Get("Accept") != "application/rss+xml" {
+		t.Errorf("request headers = %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+// internal/sentiment/sentiment.go
+package sentiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Labels assigned to scored texts.
+const (
+	Positive = "positive"
+	Neutral  = "neutral"
+	Negative = "negative"
+)
+
+// Result is the tone of a text. Score ranges from -1 (negative) to 1
+// (positive).
+type Result struct {
+	Score float64 `json:"score"`
+	Label string  `json:"label"`
+}
+
+// Analyzer scores the sentiment of a text.
+type Analyzer interface {
+	Analyze(text string) (Result, error)
+}
+
+// New returns the analyzer named by spec: "lexicon" for the built-in word
+// list or an http(s) URL of an external scoring service.
+func New(spec string) (Analyzer, error) {
+	switch {
+	case spec == "lexicon":
+		return NewLexicon(), nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return NewRemote(spec, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown sentiment analyzer %q (use lexicon or a URL)", spec)
+	}
+}
+
+// LabelFor maps a score to a label.
+func LabelFor(score float64) string {
+	switch {
+	case score >= 0.1:
+		return Positive
+	case score <= -0.1:
+		return Negative
+	default:
+		return Neutral
+	}
+}
+
+// Lexicon scores texts by counting positive and negative words.
+type Lexicon struct {
+	words map[string]float64
+}
+
+// NewLexicon creates a Lexicon with the built-in Turkish and English words.
+func NewLexicon() *Lexicon {
+	words := make(map[string]float64)
+	for _, w := range strings.Fields(positiveWords) {
+		words[w] = 1
+	}
+	for _, w := range strings.Fields(negativeWords) {
+		words[w] = -1
+	}
+	return &Lexicon{words: words}
+}
+
+// Analyze implements Analyzer. Words match by prefix so Turkish suffixes
+// ("artış", "artışla", "artışın") count alike.
+func (l *Lexicon) Analyze(text string) (Result, error) {
+	var sum float64
+	var hits int
+	for _, word := range strings.FieldsFunc(strings.ToLowerSpecial(unicode.TurkishCase, text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if v, ok := l.lookup(word); ok {
+			sum += v
+			hits++
+		}
+	}
+	if hits == 0 {
+		return Result{Label: Neutral}, nil
+	}
+	score := sum / float64(hits)
+	return Result{Score: score, Label: LabelFor(score)}, nil
+}
+
+func (l *Lexicon) lookup(word string) (float64, bool) {
+	for n := len([]rune(word)); n >= 4; n-- {
+		if v, ok := l.words[string([]rune(word)[:n])]; ok {
+			return v, true
+		}
+	}
+	v, ok := l.words[word]
+	return v, ok
+}
+
+// Remote scores texts with an external service. It POSTs {"text": "..."}
+// and expects {"score": 0.5} back, optionally with a "label".
+type Remote struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemote creates a Remote analyzer for the service at url.
+func NewRemote(url string, client *http.Client) *Remote {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Remote{url: url, client: client}
+}
+
+// Analyze implements Analyzer.
+func (r *Remote) Analyze(text string) (Result, error) {
+	body, _ := json.Marshal(map[string]string{"text": text})
+	resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("sentiment service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("sentiment service: %s", resp.Status)
+	}
+	var res Result
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Result{}, fmt.Errorf("sentiment service: invalid response: %w", err)
+	}
+	if res.Label == "" {
+		res.Label = LabelFor(res.Score)
+	}
+	return res, nil
+}
+
+// Word lists are stems: a word counts when it starts with one of them.
+const positiveWords = `
+artış arttı yükseliş yükseldi rekor kazan kazanç kazandı büyüme büyüdü olumlu başarı başarılı iyileşme toparlanma kâr karlılık güçlü destek anlaşma
+ödül kutlama sevindir umut memnun zafer galibiyet fırsat gelişme istikrar iyimser
+gain gains growth grew rise rose record profit success successful strong improve recovery win wins won agreement optimism optimistic boost surge
+`
+
+const negativeWords = `
+düşüş düştü geriledi gerileme kayıp kaybetti zarar kriz daralma olumsuz başarısız endişe korku tehdit saldırı ölüm öldü yaralı kaza deprem yangın
+iflas işsizlik enflasyon protesto gerginlik çatışma savaş skandal soruşturma gözaltı tutuklandı ceza uyarı risk belirsizlik yenilgi
+fall fell drop decline loss losses lost crisis weak fear threat attack death died killed injured crash disaster bankruptcy unemployment
+protest conflict war scandal investigation arrest risk uncertainty defeat
+`
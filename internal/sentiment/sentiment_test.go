@@ -0,0 +1,49 @@
+package sentiment
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLexicon(t *testing.T) {
+	l := NewLexicon()
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Borsa güne yükselişle başladı, bankacılık endeksinde artış sürüyor.", Positive},
+		{"Depremde çok sayıda bina yıkıldı, yaralılar hastaneye kaldırıldı.", Negative},
+		{"Toplantı yarın saat onda yapılacak.", Neutral},
+		// Negated stems win over their positive prefixes
+		{"Görüşmeler başarısız oldu.", Negative},
+	}
+	for _, tt := range tests {
+		got, err := l.Analyze(tt.text)
+		if err != nil || got.Label != tt.want {
+			t.Errorf("Analyze(%q) = %+v, %v; want %s", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Text != "metin" {
+			t.Errorf("service got text %q", req.Text)
+		}
+		w.Write([]byte(`{"score": -0.7}`))
+	}))
+	defer srv.Close()
+
+	a, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.Analyze("metin")
+	if err != nil || got.Score != -0.7 || got.Label != Negative {
+		t.Errorf("Analyze = %+v, %v; want -0.7 negative", got, err)
+	}
+}
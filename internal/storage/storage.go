@@ -0,0 +1,234 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist.
+var ErrNotFound = errors.New("storage: key not found")
+
+// Store is a minimal key-value backend used to persist server state such as
+// background jobs. Keys are slash-separated paths like "jobs/<id>".
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+	// Keys returns all keys starting with prefix, sorted.
+	Keys(prefix string) ([]string, error)
+	// CompareAndSwap stores value if the current value of key is still old,
+	// nil standing for no value, and reports whether it did. It is how
+	// read-modify-write updates keep from losing concurrent writes.
+	CompareAndSwap(key string, old, value []byte) (bool, error)
+}
+
+// SharedStore is a Store shared between server instances. Besides plain
+// values it supports expiring values and leases used to coordinate work.
+type SharedStore interface {
+	Store
+	// PutTTL stores value and expires it after ttl.
+	PutTTL(key string, value []byte, ttl time.Duration) error
+	// Acquire takes the lease key for owner unless another owner holds it.
+	// Leases expire after ttl so a crashed instance cannot hold them forever.
+	Acquire(key, owner string, ttl time.Duration) (bool, error)
+	// Release gives up the lease if it is still held by owner.
+	Release(key, owner string) error
+}
+
+// New creates a Store for the named backend ("memory", "file" or "redis").
+// location is the data directory for the file backend and the connection
+// URL for the redis backend.
+func New(backend, location string) (Store, error) {
+	switch strings.ToLower(backend) {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "file":
+		return NewFileStore(location)
+	case "redis":
+		return NewRedisStore(location)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// MemoryStore keeps values in process memory.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string][]byte
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string][]byte)}
+}
+
+func (m *MemoryStore) Get(key string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (m *MemoryStore) Put(key string, value []byte) error {
+	m.mu.Lock()
+	m.items[key] = append([]byte(nil), value...)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryStore) CompareAndSwap(key string, old, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.items[key]
+	if ok != (old != nil) || !bytes.Equal(current, old) {
+		return false, nil
+	}
+	m.items[key] = append([]byte(nil), value...)
+	return true, nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	delete(m.items, key)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryStore) Keys(prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []string
+	for k := range m.items {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// FileStore keeps each value in its own file below a data directory. It
+// serves a single instance: its swaps are atomic within the process.
+type FileStore struct {
+	dir string
+	// swapMu serializes CompareAndSwap
+	swapMu sync.Mutex
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("storage: file backend requires a directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: create data directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path maps a key to a file path, rejecting keys that escape the directory.
+func (f *FileStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(f.dir, clean), nil
+}
+
+func (f *FileStore) Get(key string) ([]byte, error) {
+	p, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (f *FileStore) Put(key string, value []byte) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see partial values
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (f *FileStore) CompareAndSwap(key string, old, value []byte) (bool, error) {
+	f.swapMu.Lock()
+	defer f.swapMu.Unlock()
+	current, err := f.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if (current != nil) != (old != nil) || !bytes.Equal(current, old) {
+		return false, nil
+	}
+	return true, f.Put(key, value)
+}
+
+func (f *FileStore) Delete(key string) error {
+	p, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(f.dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(f.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return keys, err
+}